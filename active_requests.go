@@ -0,0 +1,70 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const activeRequestsMetricName = "http.client.active_requests"
+const requestsMetricName = "com.microsoft.kiota.requests"
+
+// activeRequestsInstruments holds the instruments recording the requests in flight and the completed requests for a meter
+type activeRequestsInstruments struct {
+	activeRequests metric.Int64UpDownCounter
+	requests       metric.Int64Counter
+}
+
+var activeRequestsInstrumentsByMeter sync.Map
+
+// getActiveRequestsInstruments returns the active requests instruments of the meter with the given name
+func getActiveRequestsInstruments(meterName string) *activeRequestsInstruments {
+	return getInstruments(&activeRequestsInstrumentsByMeter, meterName, func(meter metric.Meter) *activeRequestsInstruments {
+		instruments := &activeRequestsInstruments{}
+		// the instruments are no-ops when they fail to be created
+		instruments.activeRequests, _ = meter.Int64UpDownCounter(activeRequestsMetricName,
+			metric.WithUnit("{request}"),
+			metric.WithDescription("Number of HTTP requests in flight"))
+		instruments.requests, _ = meter.Int64Counter(requestsMetricName,
+			metric.WithUnit("{request}"),
+			metric.WithDescription("Number of HTTP requests completed, its rate is the throughput of the client"))
+		return instruments
+	})
+}
+
+// GetActiveRequestCount returns the number of requests of the request adapter in flight
+func (a *NetHttpRequestAdapter) GetActiveRequestCount() int64 {
+	return atomic.LoadInt64(&a.activeRequests)
+}
+
+// startActiveRequest counts the request as in flight until the returned function is called with its outcome
+func (a *NetHttpRequestAdapter) startActiveRequest(ctx context.Context, request *nethttp.Request) func(response *nethttp.Response, err error) {
+	atomic.AddInt64(&a.activeRequests, 1)
+	instruments := getActiveRequestsInstruments(a.getObservabilityName(ctx))
+	attributes := []attribute.KeyValue{httpRequestMethodAttribute.String(request.Method)}
+	if request.URL != nil {
+		attributes = append(attributes, serverAddressAttribute.String(request.URL.Hostname()))
+	}
+	if instruments.activeRequests != nil {
+		instruments.activeRequests.Add(ctx, 1, metric.WithAttributes(attributes...))
+	}
+	return func(response *nethttp.Response, err error) {
+		atomic.AddInt64(&a.activeRequests, -1)
+		if instruments.activeRequests != nil {
+			instruments.activeRequests.Add(ctx, -1, metric.WithAttributes(attributes...))
+		}
+		if instruments.requests == nil {
+			return
+		}
+		if err != nil {
+			attributes = append(attributes, errorTypeAttribute.String(getErrorType(err)))
+		} else if response != nil {
+			attributes = append(attributes, httpResponseStatusCodeAttribute.Int(response.StatusCode))
+		}
+		instruments.requests.Add(ctx, 1, metric.WithAttributes(attributes...))
+	}
+}
@@ -0,0 +1,51 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+	"time"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItTracksTheActiveRequests(t *testing.T) {
+	provider := useTestMeterProvider(t)
+	release := make(chan struct{})
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		<-release
+		res.WriteHeader(204)
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.Nil(t, err)
+	adapter.SetBaseUrl(testServer.URL)
+
+	done := make(chan error)
+	go func() {
+		request := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/items", map[string]string{})
+		done <- adapter.SendNoContent(context.Background(), request, nil)
+	}()
+	assert.Eventually(t, func() bool {
+		return adapter.GetActiveRequestCount() == 1
+	}, time.Second, time.Millisecond)
+	close(release)
+	assert.Nil(t, <-done)
+	assert.Equal(t, int64(0), adapter.GetActiveRequestCount())
+
+	active := provider.getMeasurements(activeRequestsMetricName)
+	if assert.Equal(t, 2, len(active)) {
+		assert.Equal(t, float64(1), active[0].value)
+		assert.Equal(t, float64(-1), active[1].value)
+		method, _ := active[0].attributes.Value(httpRequestMethodAttribute)
+		assert.Equal(t, "GET", method.AsString())
+	}
+	requests := provider.getMeasurements(requestsMetricName)
+	if assert.Equal(t, 1, len(requests)) {
+		statusCode, _ := requests[0].attributes.Value(httpResponseStatusCodeAttribute)
+		assert.Equal(t, int64(204), statusCode.AsInt64())
+	}
+}
@@ -0,0 +1,132 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+	"strings"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AllowedHostsHandler validates the host of every outgoing request against a list of allowed hosts.
+// Requests targeting a host which is not allowed are never sent, which prevents leaking tokens to injected URLs.
+type AllowedHostsHandler struct {
+	options AllowedHostsOptions
+}
+
+// AllowedHostsOptions to use when validating the host of the requests.
+type AllowedHostsOptions struct {
+	// AllowedHosts is the list of hosts requests can be sent to. Entries starting with "*." match any sub-domain. An empty list allows all hosts.
+	AllowedHosts []string
+}
+
+// HostNotAllowedError is returned when a request targets a host which is not allowed.
+type HostNotAllowedError struct {
+	// Host is the host of the request which was rejected
+	Host string
+}
+
+// Error returns the error message
+func (e *HostNotAllowedError) Error() string {
+	return "the request host " + e.Host + " is not in the list of allowed hosts"
+}
+
+var allowedHostsKeyValue = abs.RequestOptionKey{
+	Key: "AllowedHostsHandler",
+}
+
+// allowedHostsPipelineKey holds the allowed hosts of the request so the redirect handler validates the targets of the redirects
+var allowedHostsPipelineKey = NewPipelineKey[[]string]("AllowedHosts")
+
+type allowedHostsOptionsInt interface {
+	abs.RequestOption
+	GetAllowedHosts() []string
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *AllowedHostsOptions) GetKey() abs.RequestOptionKey {
+	return allowedHostsKeyValue
+}
+
+// GetAllowedHosts returns the list of allowed hosts
+func (options *AllowedHostsOptions) GetAllowedHosts() []string {
+	return options.AllowedHosts
+}
+
+// IsHostAllowed returns whether the given host is allowed by the options
+func (options *AllowedHostsOptions) IsHostAllowed(host string) bool {
+	return isHostAllowed(options.GetAllowedHosts(), host)
+}
+
+// NewAllowedHostsHandler creates a new AllowedHostsHandler allowing the given hosts
+func NewAllowedHostsHandler(allowedHosts ...string) *AllowedHostsHandler {
+	return NewAllowedHostsHandlerWithOptions(AllowedHostsOptions{AllowedHosts: allowedHosts})
+}
+
+// NewAllowedHostsHandlerWithOptions creates a new AllowedHostsHandler with the given options
+func NewAllowedHostsHandlerWithOptions(options AllowedHostsOptions) *AllowedHostsHandler {
+	normalized := make([]string, 0, len(options.AllowedHosts))
+	for _, host := range options.AllowedHosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host != "" {
+			normalized = append(normalized, host)
+		}
+	}
+	options.AllowedHosts = normalized
+	return &AllowedHostsHandler{options: options}
+}
+
+// Intercept implements the interface and rejects requests which target a host that is not allowed.
+func (middleware AllowedHostsHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(allowedHostsKeyValue).(allowedHostsOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	ctx := req.Context()
+	var span trace.Span
+	if obsOptions != nil {
+		ctx, span = getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "AllowedHostsHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.allowed_hosts.enable", true))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	host := req.URL.Hostname()
+	if !isHostAllowed(reqOption.GetAllowedHosts(), host) {
+		err := &HostNotAllowedError{Host: host}
+		if span != nil {
+			span.RecordError(err)
+		}
+		return nil, err
+	}
+	req = SetPipelineValue(req, allowedHostsPipelineKey, reqOption.GetAllowedHosts())
+	return pipeline.Next(req, middlewareIndex)
+}
+
+// checkAllowedHost returns a HostNotAllowedError when the allowed hosts handler of the pipeline doesn't allow the host of the request
+func checkAllowedHost(req *nethttp.Request) error {
+	allowedHosts, ok := GetPipelineValue(req.Context(), allowedHostsPipelineKey)
+	if !ok || isHostAllowed(allowedHosts, req.URL.Hostname()) {
+		return nil
+	}
+	return &HostNotAllowedError{Host: req.URL.Hostname()}
+}
+
+func isHostAllowed(allowedHosts []string, host string) bool {
+	if len(allowedHosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, allowedHost := range allowedHosts {
+		allowedHost = strings.ToLower(allowedHost)
+		if strings.HasPrefix(allowedHost, "*.") {
+			if strings.HasSuffix(host, allowedHost[1:]) {
+				return true
+			}
+		} else if host == allowedHost {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package nethttplibrary
+
+import (
+	"errors"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItMatchesAllowedHosts(t *testing.T) {
+	testData := []struct {
+		allowedHosts []string
+		host         string
+		expected     bool
+	}{
+		{[]string{}, "graph.microsoft.com", true},
+		{[]string{"graph.microsoft.com"}, "graph.microsoft.com", true},
+		{[]string{"graph.microsoft.com"}, "GRAPH.microsoft.com", true},
+		{[]string{"graph.microsoft.com"}, "evil.com", false},
+		{[]string{"*.microsoft.com"}, "graph.microsoft.com", true},
+		{[]string{"*.microsoft.com"}, "microsoft.com", false},
+		{[]string{"*.microsoft.com"}, "graph.microsoft.com.evil.com", false},
+	}
+	for _, data := range testData {
+		assert.Equal(t, data.expected, isHostAllowed(data.allowedHosts, data.host), data.host)
+	}
+}
+
+func TestItRejectsRequestsToHostsNotAllowed(t *testing.T) {
+	handler := NewAllowedHostsHandler("graph.microsoft.com")
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://evil.com/users", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	pipeline := newSpyPipeline()
+	resp, err := handler.Intercept(pipeline, 0, req)
+	assert.Nil(t, resp)
+	var hostErr *HostNotAllowedError
+	assert.True(t, errors.As(err, &hostErr))
+	assert.Equal(t, "evil.com", hostErr.Host)
+	assert.Nil(t, pipeline.GetReceivedRequest())
+}
+
+func TestItRejectsRedirectsToHostsNotAllowed(t *testing.T) {
+	requestCount := 0
+	var testServer *httptest.Server
+	testServer = httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		requestCount++
+		res.Header().Set("Location", strings.Replace(testServer.URL, "127.0.0.1", "localhost", 1)+"/foreign")
+		res.WriteHeader(302)
+	}))
+	defer testServer.Close()
+	client := GetDefaultClient(NewAllowedHostsHandler("127.0.0.1"), NewRedirectHandler())
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, resp)
+	var hostErr *HostNotAllowedError
+	if assert.True(t, errors.As(err, &hostErr)) {
+		assert.Equal(t, "localhost", hostErr.Host)
+	}
+	assert.Equal(t, 1, requestCount)
+}
+
+func TestItAllowsRequestsToAllowedHosts(t *testing.T) {
+	handler := NewAllowedHostsHandler("*.microsoft.com")
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com/users", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	pipeline := newSpyPipeline()
+	_, _ = handler.Intercept(pipeline, 0, req)
+	assert.NotNil(t, pipeline.GetReceivedRequest())
+}
@@ -0,0 +1,63 @@
+package nethttplibrary
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// attemptGroupIdAttribute is shared by the spans of all the attempts of a logical operation (retries, continuous access evaluation challenges)
+var attemptGroupIdAttribute = attribute.Key("com.microsoft.kiota.attempt_group.id")
+
+type attemptGroupKey struct{}
+
+type previousAttemptKey struct{}
+
+// withAttemptGroup returns a context carrying an attempt group id, reusing the one of the given context when present
+func withAttemptGroup(ctx context.Context) (context.Context, string) {
+	if groupId := getAttemptGroupId(ctx); groupId != "" {
+		return ctx, groupId
+	}
+	groupId := newAttemptGroupId()
+	return context.WithValue(ctx, attemptGroupKey{}, groupId), groupId
+}
+
+// getAttemptGroupId returns the attempt group id of the context or an empty string
+func getAttemptGroupId(ctx context.Context) string {
+	groupId, _ := ctx.Value(attemptGroupKey{}).(string)
+	return groupId
+}
+
+func newAttemptGroupId() string {
+	value := make([]byte, 8)
+	if _, err := rand.Read(value); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(value)
+}
+
+// withPreviousAttempt returns a context carrying the span context of the previous attempt of the operation
+func withPreviousAttempt(ctx context.Context, previous trace.SpanContext) context.Context {
+	return context.WithValue(ctx, previousAttemptKey{}, previous)
+}
+
+// getAttemptSpanOptions returns the options linking the span of an attempt to the previous attempt and tagging it with the attempt group
+func getAttemptSpanOptions(ctx context.Context, previous trace.SpanContext) []trace.SpanStartOption {
+	options := make([]trace.SpanStartOption, 0, 2)
+	if previous.IsValid() {
+		options = append(options, trace.WithLinks(trace.Link{SpanContext: previous}))
+	}
+	if groupId := getAttemptGroupId(ctx); groupId != "" {
+		options = append(options, trace.WithAttributes(attemptGroupIdAttribute.String(groupId)))
+	}
+	return options
+}
+
+// getPreviousAttempt returns the span context of the previous attempt of the operation carried by the context
+func getPreviousAttempt(ctx context.Context) trace.SpanContext {
+	previous, _ := ctx.Value(previousAttemptKey{}).(trace.SpanContext)
+	return previous
+}
@@ -0,0 +1,90 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"net/url"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItLinksTheSpansOfTheRetryAttempts(t *testing.T) {
+	provider := useTestTracerProvider(t)
+	callCount := 0
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		callCount++
+		if callCount < 3 {
+			res.Header().Set("Retry-After", "0")
+			res.WriteHeader(503)
+			return
+		}
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	client := GetDefaultClient(&observabilityOptionsHandler{}, NewRetryHandler())
+
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	handlerSpans := provider.getSpans("RetryHandler_Intercept")
+	firstAttempts := provider.getSpans("RetryHandler_Intercept - attempt 1")
+	secondAttempts := provider.getSpans("RetryHandler_Intercept - attempt 2")
+	if !assert.Equal(t, 1, len(handlerSpans)) || !assert.Equal(t, 1, len(firstAttempts)) || !assert.Equal(t, 1, len(secondAttempts)) {
+		return
+	}
+	assert.Equal(t, handlerSpans[0].SpanContext(), firstAttempts[0].links[0].SpanContext)
+	assert.Equal(t, firstAttempts[0].SpanContext(), secondAttempts[0].links[0].SpanContext)
+	groupId, ok := handlerSpans[0].getAttribute(attemptGroupIdAttribute)
+	assert.True(t, ok)
+	assert.NotEmpty(t, groupId.AsString())
+	for _, span := range []*testSpan{firstAttempts[0], secondAttempts[0]} {
+		value, _ := span.getAttribute(attemptGroupIdAttribute)
+		assert.Equal(t, groupId, value)
+	}
+}
+
+func TestItLinksTheSpansOfTheContinuousAccessEvaluationAttempts(t *testing.T) {
+	provider := useTestTracerProvider(t)
+	callCount := 0
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		callCount++
+		if callCount == 1 {
+			res.Header().Set("WWW-Authenticate", "Bearer realm=\"\", error=\"insufficient_claims\", claims=\"eyJhY2Nlc3NfdG9rZW4iOnt9fQ==\"")
+			res.WriteHeader(401)
+			return
+		}
+		res.WriteHeader(204)
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.Nil(t, err)
+	uri, _ := url.Parse(testServer.URL)
+	request := abs.NewRequestInformation()
+	request.SetUri(*uri)
+	request.Method = abs.GET
+
+	err = adapter.SendNoContent(context.Background(), request, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, callCount)
+
+	attempts := provider.getSpans("getHttpResponseMessage")
+	if !assert.Equal(t, 2, len(attempts)) {
+		return
+	}
+	assert.Empty(t, attempts[0].links)
+	assert.Equal(t, attempts[0].SpanContext(), attempts[1].links[0].SpanContext)
+	first, _ := attempts[0].getAttribute(attemptGroupIdAttribute)
+	second, _ := attempts[1].getAttribute(attemptGroupIdAttribute)
+	assert.NotEmpty(t, first.AsString())
+	assert.Equal(t, first, second)
+	retrySpans := provider.getSpans("RetryHandler_Intercept")
+	if assert.Equal(t, 2, len(retrySpans)) {
+		value, _ := retrySpans[1].getAttribute(attemptGroupIdAttribute)
+		assert.Equal(t, first, value)
+	}
+}
@@ -0,0 +1,107 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const authenticationChallengesMetricName = "com.microsoft.kiota.authenticate_challenge.count"
+const continuousAccessEvaluationRetriesMetricName = "com.microsoft.kiota.cae.retries"
+const continuousAccessEvaluationRetryDurationMetricName = "com.microsoft.kiota.cae.retry.duration"
+
+var (
+	authenticationChallengeSchemeAttribute        = attribute.Key("com.microsoft.kiota.authenticate_challenge.scheme")
+	authenticationChallengeClaimsPresentAttribute = attribute.Key("com.microsoft.kiota.authenticate_challenge.claims_present")
+	authenticationChallengeRetriedAttribute       = attribute.Key("com.microsoft.kiota.authenticate_challenge.retried")
+	caeClaimsLengthAttribute                      = attribute.Key("com.microsoft.kiota.cae.claims_length")
+	caeRetryStatusCodeAttribute                   = attribute.Key("com.microsoft.kiota.cae.retry.status_code")
+	caeRetryDurationAttribute                     = attribute.Key("com.microsoft.kiota.cae.retry.duration")
+)
+
+// authenticationChallengeInstruments holds the instruments recording the authentication challenges for a meter
+type authenticationChallengeInstruments struct {
+	challenges    metric.Int64Counter
+	caeRetries    metric.Int64Counter
+	caeRetryDelay metric.Float64Histogram
+}
+
+var authenticationChallengeInstrumentsByMeter sync.Map
+
+// getAuthenticationChallengeInstruments returns the authentication challenge instruments of the meter with the given name
+func getAuthenticationChallengeInstruments(meterName string) *authenticationChallengeInstruments {
+	return getInstruments(&authenticationChallengeInstrumentsByMeter, meterName, func(meter metric.Meter) *authenticationChallengeInstruments {
+		instruments := &authenticationChallengeInstruments{}
+		// the instruments are no-ops when they fail to be created
+		instruments.challenges, _ = meter.Int64Counter(authenticationChallengesMetricName,
+			metric.WithUnit("{challenge}"),
+			metric.WithDescription("Number of authentication challenges (401 responses with a WWW-Authenticate header) received, including continuous access evaluation challenges"))
+		instruments.caeRetries, _ = meter.Int64Counter(continuousAccessEvaluationRetriesMetricName,
+			metric.WithUnit("{retry}"),
+			metric.WithDescription("Number of requests sent again with the claims of a continuous access evaluation challenge"))
+		instruments.caeRetryDelay, _ = meter.Float64Histogram(continuousAccessEvaluationRetryDurationMetricName,
+			metric.WithUnit("s"),
+			metric.WithDescription("Latency added to the requests by the continuous access evaluation retries"))
+		return instruments
+	})
+}
+
+// getAuthenticationScheme returns the scheme of the first challenge of the WWW-Authenticate header (e.g. Bearer)
+func getAuthenticationScheme(authenticateHeaderValue string) string {
+	fields := strings.Fields(authenticateHeaderValue)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(fields[0], ",")
+}
+
+// recordAuthenticationChallenge adds the challenge received event to the span and increments the challenges counter
+func (a *NetHttpRequestAdapter) recordAuthenticationChallenge(ctx context.Context, span trace.Span, response *nethttp.Response, authenticateHeaderValue string, claimsPresent bool, retried bool) {
+	attributes := []attribute.KeyValue{
+		authenticationChallengeSchemeAttribute.String(getAuthenticationScheme(authenticateHeaderValue)),
+		authenticationChallengeClaimsPresentAttribute.Bool(claimsPresent),
+		authenticationChallengeRetriedAttribute.Bool(retried),
+	}
+	span.AddEvent(AuthenticateChallengedEventKey, trace.WithAttributes(attributes...))
+	instruments := getAuthenticationChallengeInstruments(a.getObservabilityName(ctx))
+	if instruments.challenges == nil {
+		return
+	}
+	if response.Request != nil && response.Request.URL != nil {
+		attributes = append(attributes, serverAddressAttribute.String(response.Request.URL.Hostname()))
+	}
+	instruments.challenges.Add(ctx, 1, metric.WithAttributes(attributes...))
+}
+
+// recordContinuousAccessEvaluationRetry sets the attributes of the continuous access evaluation retry on the span and records the retry metrics,
+// the outcome of the retry is its status code or the type of its error
+func (a *NetHttpRequestAdapter) recordContinuousAccessEvaluationRetry(ctx context.Context, span trace.Span, challenge *nethttp.Response, claims string, retryResponse *nethttp.Response, retryErr error, duration time.Duration) {
+	attributes := make([]attribute.KeyValue, 0, 2)
+	if challenge.Request != nil && challenge.Request.URL != nil {
+		attributes = append(attributes, serverAddressAttribute.String(challenge.Request.URL.Hostname()))
+	}
+	span.SetAttributes(
+		caeClaimsLengthAttribute.Int(len(claims)),
+		caeRetryDurationAttribute.Float64(duration.Seconds()),
+	)
+	if retryErr != nil {
+		attributes = append(attributes, errorTypeAttribute.String(getErrorType(retryErr)))
+		span.SetAttributes(errorTypeAttribute.String(getErrorType(retryErr)))
+	} else if retryResponse != nil {
+		attributes = append(attributes, httpResponseStatusCodeAttribute.Int(retryResponse.StatusCode))
+		span.SetAttributes(caeRetryStatusCodeAttribute.Int(retryResponse.StatusCode))
+	}
+	instruments := getAuthenticationChallengeInstruments(a.getObservabilityName(ctx))
+	if instruments.caeRetries != nil {
+		instruments.caeRetries.Add(ctx, 1, metric.WithAttributes(attributes...))
+	}
+	if instruments.caeRetryDelay != nil {
+		instruments.caeRetryDelay.Record(ctx, duration.Seconds(), metric.WithAttributes(attributes...))
+	}
+}
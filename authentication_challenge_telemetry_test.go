@@ -0,0 +1,105 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"net/url"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/stretchr/testify/assert"
+)
+
+func sendWithAuthenticationChallenges(t *testing.T, challenges ...string) error {
+	callCount := 0
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		if callCount < len(challenges) {
+			res.Header().Set("WWW-Authenticate", challenges[callCount])
+			callCount++
+			res.WriteHeader(401)
+			return
+		}
+		res.WriteHeader(204)
+	}))
+	t.Cleanup(testServer.Close)
+	adapter, err := NewNetHttpRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.Nil(t, err)
+	uri, _ := url.Parse(testServer.URL)
+	request := abs.NewRequestInformation()
+	request.SetUri(*uri)
+	request.Method = abs.GET
+	return adapter.SendNoContent(context.Background(), request, nil)
+}
+
+func TestItRecordsContinuousAccessEvaluationChallenges(t *testing.T) {
+	meterProvider := useTestMeterProvider(t)
+	tracerProvider := useTestTracerProvider(t)
+	err := sendWithAuthenticationChallenges(t, `Bearer realm="", error="insufficient_claims", claims="eyJhY2Nlc3NfdG9rZW4iOnt9fQ=="`)
+	assert.Nil(t, err)
+
+	spans := tracerProvider.getSpans("retryCAEResponseIfRequired")
+	if assert.Equal(t, 2, len(spans)) {
+		assert.Equal(t, []string{AuthenticateChallengedEventKey}, spans[0].events)
+		assert.Contains(t, spans[0].eventAttributes[0], authenticationChallengeSchemeAttribute.String("Bearer"))
+		assert.Contains(t, spans[0].eventAttributes[0], authenticationChallengeClaimsPresentAttribute.Bool(true))
+		assert.Contains(t, spans[0].eventAttributes[0], authenticationChallengeRetriedAttribute.Bool(true))
+		assert.Empty(t, spans[1].events)
+	}
+	challenges := meterProvider.getMeasurements(authenticationChallengesMetricName)
+	if assert.Equal(t, 1, len(challenges)) {
+		retried, _ := challenges[0].attributes.Value(authenticationChallengeRetriedAttribute)
+		assert.True(t, retried.AsBool())
+		host, _ := challenges[0].attributes.Value(serverAddressAttribute)
+		assert.Equal(t, "127.0.0.1", host.AsString())
+	}
+}
+
+func TestItRecordsChallengesWhichAreNotRetried(t *testing.T) {
+	meterProvider := useTestMeterProvider(t)
+	err := sendWithAuthenticationChallenges(t, `Basic realm="contoso"`)
+	assert.Error(t, err)
+
+	challenges := meterProvider.getMeasurements(authenticationChallengesMetricName)
+	if assert.Equal(t, 1, len(challenges)) {
+		scheme, _ := challenges[0].attributes.Value(authenticationChallengeSchemeAttribute)
+		assert.Equal(t, "Basic", scheme.AsString())
+		claimsPresent, _ := challenges[0].attributes.Value(authenticationChallengeClaimsPresentAttribute)
+		assert.False(t, claimsPresent.AsBool())
+		retried, _ := challenges[0].attributes.Value(authenticationChallengeRetriedAttribute)
+		assert.False(t, retried.AsBool())
+	}
+}
+
+func TestItRecordsContinuousAccessEvaluationRetries(t *testing.T) {
+	meterProvider := useTestMeterProvider(t)
+	tracerProvider := useTestTracerProvider(t)
+	claims := "eyJhY2Nlc3NfdG9rZW4iOnt9fQ=="
+	err := sendWithAuthenticationChallenges(t, `Bearer realm="", error="insufficient_claims", claims="`+claims+`"`)
+	assert.Nil(t, err)
+
+	spans := tracerProvider.getSpans("retryCAEResponseIfRequired")
+	if assert.Equal(t, 2, len(spans)) {
+		claimsLength, _ := spans[0].getAttribute(caeClaimsLengthAttribute)
+		assert.Equal(t, int64(len(claims)), claimsLength.AsInt64())
+		statusCode, _ := spans[0].getAttribute(caeRetryStatusCodeAttribute)
+		assert.Equal(t, int64(204), statusCode.AsInt64())
+		_, ok := spans[0].getAttribute(caeRetryDurationAttribute)
+		assert.True(t, ok)
+		_, ok = spans[1].getAttribute(caeClaimsLengthAttribute)
+		assert.False(t, ok)
+	}
+	retries := meterProvider.getMeasurements(continuousAccessEvaluationRetriesMetricName)
+	if assert.Equal(t, 1, len(retries)) {
+		statusCode, _ := retries[0].attributes.Value(httpResponseStatusCodeAttribute)
+		assert.Equal(t, int64(204), statusCode.AsInt64())
+		host, _ := retries[0].attributes.Value(serverAddressAttribute)
+		assert.Equal(t, "127.0.0.1", host.AsString())
+	}
+	assert.Equal(t, 1, len(meterProvider.getMeasurements(continuousAccessEvaluationRetryDurationMetricName)))
+
+	err = sendWithAuthenticationChallenges(t, `Bearer realm="", error="insufficient_claims"`)
+	assert.Error(t, err)
+	assert.Equal(t, 1, len(meterProvider.getMeasurements(continuousAccessEvaluationRetriesMetricName)))
+}
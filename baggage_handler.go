@@ -0,0 +1,95 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// BaggageHandler maps selected OpenTelemetry baggage entries of the request context to outbound headers.
+// Only the entries listed in the mappings are sent, which avoids leaking arbitrary baggage to the services.
+type BaggageHandler struct {
+	options BaggageHandlerOptions
+}
+
+// BaggageHandlerOptions to use when mapping the baggage entries to headers.
+type BaggageHandlerOptions struct {
+	// Enabled defines whether the baggage entries should be mapped
+	Enabled bool
+	// Mappings maps the allowed baggage entry keys to the name of the header their value is written to
+	Mappings map[string]string
+	// OverwriteHeaders defines whether headers already present on the request should be overwritten
+	OverwriteHeaders bool
+}
+
+var baggageKeyValue = abs.RequestOptionKey{
+	Key: "BaggageHandler",
+}
+
+type baggageHandlerOptionsInt interface {
+	abs.RequestOption
+	GetEnabled() bool
+	GetMappings() map[string]string
+	GetOverwriteHeaders() bool
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *BaggageHandlerOptions) GetKey() abs.RequestOptionKey {
+	return baggageKeyValue
+}
+
+// GetEnabled returns whether the baggage entries should be mapped
+func (options *BaggageHandlerOptions) GetEnabled() bool {
+	return options.Enabled
+}
+
+// GetMappings returns the allowed baggage entry keys and their header names
+func (options *BaggageHandlerOptions) GetMappings() map[string]string {
+	return options.Mappings
+}
+
+// GetOverwriteHeaders returns whether headers already present on the request should be overwritten
+func (options *BaggageHandlerOptions) GetOverwriteHeaders() bool {
+	return options.OverwriteHeaders
+}
+
+// NewBaggageHandler creates a new BaggageHandler mapping the given baggage entry keys to header names
+func NewBaggageHandler(mappings map[string]string) *BaggageHandler {
+	return NewBaggageHandlerWithOptions(BaggageHandlerOptions{Enabled: true, Mappings: mappings})
+}
+
+// NewBaggageHandlerWithOptions creates a new BaggageHandler with the given options
+func NewBaggageHandlerWithOptions(options BaggageHandlerOptions) *BaggageHandler {
+	return &BaggageHandler{options: options}
+}
+
+// Intercept implements the interface and adds the mapped baggage entries to the request headers.
+func (middleware BaggageHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(baggageKeyValue).(baggageHandlerOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	if obsOptions != nil {
+		ctx, span := getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(req.Context(), "BaggageHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.baggage.enable", reqOption.GetEnabled()))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	if reqOption.GetEnabled() && len(reqOption.GetMappings()) > 0 {
+		bag := baggage.FromContext(req.Context())
+		for key, headerName := range reqOption.GetMappings() {
+			member := bag.Member(key)
+			if member.Key() == "" || headerName == "" {
+				continue
+			}
+			if !reqOption.GetOverwriteHeaders() && req.Header.Get(headerName) != "" {
+				continue
+			}
+			req.Header.Set(headerName, member.Value())
+		}
+	}
+	return pipeline.Next(req, middlewareIndex)
+}
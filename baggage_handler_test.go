@@ -0,0 +1,35 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestItMapsAllowedBaggageEntriesToHeaders(t *testing.T) {
+	tenant, _ := baggage.NewMember("tenant", "contoso")
+	secret, _ := baggage.NewMember("secret", "value")
+	correlation, _ := baggage.NewMember("correlation", "abc")
+	bag, err := baggage.New(tenant, secret, correlation)
+	assert.Nil(t, err)
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+	handler := NewBaggageHandler(map[string]string{
+		"tenant":      "x-tenant-hint",
+		"correlation": "x-correlation-id",
+	})
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, "https://example.com", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("x-correlation-id", "existing")
+	pipeline := newSpyPipeline()
+	_, _ = handler.Intercept(pipeline, 0, req)
+	received := pipeline.GetReceivedRequest()
+	assert.Equal(t, "contoso", received.Header.Get("x-tenant-hint"))
+	assert.Equal(t, "existing", received.Header.Get("x-correlation-id"))
+	assert.Empty(t, received.Header.Get("secret"))
+}
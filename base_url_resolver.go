@@ -0,0 +1,78 @@
+package nethttplibrary
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BaseUrlResolver returns the base url of the requests, e.g. from a service discovery endpoint or a feature flag
+type BaseUrlResolver func(ctx context.Context) (string, error)
+
+// baseUrlResolution caches the base url returned by a resolver
+type baseUrlResolution struct {
+	resolver      BaseUrlResolver
+	cacheDuration time.Duration
+	mutex         sync.Mutex
+	baseUrl       string
+	expires       time.Time
+}
+
+// resolve returns the cached base url, or calls the resolver when it expired.
+// The mutex is held during the call so concurrent requests don't call the resolver more than once.
+func (r *baseUrlResolution) resolve(ctx context.Context) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := time.Now()
+	if r.cacheDuration > 0 && now.Before(r.expires) {
+		return r.baseUrl, nil
+	}
+	baseUrl, err := r.resolver(ctx)
+	if err != nil {
+		return "", err
+	}
+	r.baseUrl = baseUrl
+	r.expires = now.Add(r.cacheDuration)
+	return baseUrl, nil
+}
+
+// invalidate expires the cached base url
+func (r *baseUrlResolution) invalidate() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.expires = time.Time{}
+}
+
+// SetBaseUrlResolver sets a resolver consulted for the base url of every request instead of the base url set with SetBaseUrl,
+// the resolved base url is cached for the given duration (not at all when it's 0). The resolver is removed when nil.
+// The send methods return the error of the resolver when it fails.
+func (a *NetHttpRequestAdapter) SetBaseUrlResolver(resolver BaseUrlResolver, cacheDuration time.Duration) {
+	a.baseUrlMutex.Lock()
+	defer a.baseUrlMutex.Unlock()
+	if resolver == nil {
+		a.baseUrlResolution = nil
+		return
+	}
+	a.baseUrlResolution = &baseUrlResolution{resolver: resolver, cacheDuration: cacheDuration}
+}
+
+// InvalidateBaseUrl discards the base url cached from the resolver, the next request calls the resolver again
+func (a *NetHttpRequestAdapter) InvalidateBaseUrl() {
+	if resolution := a.getBaseUrlResolution(); resolution != nil {
+		resolution.invalidate()
+	}
+}
+
+func (a *NetHttpRequestAdapter) getBaseUrlResolution() *baseUrlResolution {
+	a.baseUrlMutex.Lock()
+	defer a.baseUrlMutex.Unlock()
+	return a.baseUrlResolution
+}
+
+// resolveBaseUrl returns the base url of a request, from the resolver when one is set
+func (a *NetHttpRequestAdapter) resolveBaseUrl(ctx context.Context) (string, error) {
+	if resolution := a.getBaseUrlResolution(); resolution != nil {
+		return resolution.resolve(ctx)
+	}
+	return a.GetBaseUrl(), nil
+}
@@ -0,0 +1,57 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+	"time"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItResolvesTheBaseUrlOfTheRequests(t *testing.T) {
+	paths := make([]string, 0)
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		paths = append(paths, req.URL.Path)
+		res.WriteHeader(204)
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.Nil(t, err)
+	adapter.SetBaseUrl("https://unused.example.com")
+	resolutions := 0
+	adapter.SetBaseUrlResolver(func(ctx context.Context) (string, error) {
+		resolutions++
+		if resolutions == 1 {
+			return testServer.URL + "/v1", nil
+		}
+		return testServer.URL + "/v2", nil
+	}, time.Hour)
+	send := func() error {
+		requestInfo := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/items", map[string]string{})
+		return adapter.SendNoContent(context.Background(), requestInfo, nil)
+	}
+
+	assert.Nil(t, send())
+	assert.Nil(t, send())
+	adapter.InvalidateBaseUrl()
+	assert.Nil(t, send())
+	assert.Equal(t, []string{"/v1/items", "/v1/items", "/v2/items"}, paths)
+	assert.Equal(t, 2, resolutions)
+	assert.Equal(t, "https://unused.example.com", adapter.GetBaseUrl())
+
+	resolverErr := errors.New("discovery failed")
+	adapter.SetBaseUrlResolver(func(ctx context.Context) (string, error) {
+		return "", resolverErr
+	}, 0)
+	assert.ErrorIs(t, send(), resolverErr)
+
+	adapter.SetBaseUrlResolver(nil, 0)
+	adapter.SetBaseUrl(testServer.URL)
+	assert.Nil(t, send())
+	assert.Equal(t, "/items", paths[len(paths)-1])
+}
@@ -0,0 +1,416 @@
+package nethttplibrary
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absser "github.com/microsoft/kiota-abstractions-go/serialization"
+)
+
+const batchUrlTemplate = "{+baseurl}/$batch"
+
+// BatchRequestItem is a request of a batch, its url is relative to the base url of the request adapter when possible
+type BatchRequestItem struct {
+	// Id identifies the request in the batch and its response
+	Id string
+	// Method is the HTTP method of the request
+	Method string
+	// Url is the url of the request
+	Url string
+	// Headers are the headers of the request, the values of multi-valued headers are comma separated
+	Headers map[string]string
+	// Body is the content of the request, nil when the request doesn't have a body
+	Body []byte
+	// DependsOn lists the ids of the requests which must complete before this one is executed
+	DependsOn []string
+}
+
+// BatchResponseItem is the response to a request of a batch
+type BatchResponseItem struct {
+	// Id is the id of the request the response is for
+	Id string
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Headers are the headers of the response
+	Headers map[string]string
+	// Body is the content of the response, nil when the response doesn't have a body
+	Body []byte
+}
+
+// getHeader returns the value of the header with the given name, the lookup is case insensitive
+func (item *BatchResponseItem) getHeader(name string) string {
+	return getBatchHeader(item.Headers, name)
+}
+
+// getBatchHeader returns the value of the header with the given name, the lookup is case insensitive
+func getBatchHeader(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// BatchFormat serializes the requests of a batch into the payload of the batch request and deserializes the responses from the batch response payload
+type BatchFormat interface {
+	// GetContentType returns the content type of the batch payload
+	GetContentType() string
+	// SerializeRequests serializes the requests into the batch payload
+	SerializeRequests(requests []BatchRequestItem) ([]byte, error)
+	// DeserializeResponses deserializes the responses from the batch response payload
+	DeserializeResponses(content []byte) ([]BatchResponseItem, error)
+}
+
+// JsonBatchFormat is the JSON batch format used by Microsoft Graph, the JSON bodies are embedded and the other ones base64 encoded.
+type JsonBatchFormat struct{}
+
+// NewJsonBatchFormat creates a new JsonBatchFormat
+func NewJsonBatchFormat() *JsonBatchFormat {
+	return &JsonBatchFormat{}
+}
+
+type jsonBatchRequestItem struct {
+	Id        string            `json:"id"`
+	Method    string            `json:"method"`
+	Url       string            `json:"url"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Body      json.RawMessage   `json:"body,omitempty"`
+	DependsOn []string          `json:"dependsOn,omitempty"`
+}
+
+type jsonBatchResponseItem struct {
+	Id      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// GetContentType returns the content type of the batch payload
+func (format *JsonBatchFormat) GetContentType() string {
+	return "application/json"
+}
+
+// isJsonContentType returns whether the content type is JSON or a JSON based format (e.g. application/problem+json)
+func isJsonContentType(contentType string) bool {
+	mediaType := getMediaType(contentType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// SerializeRequests serializes the requests into the batch payload
+func (format *JsonBatchFormat) SerializeRequests(requests []BatchRequestItem) ([]byte, error) {
+	items := make([]jsonBatchRequestItem, 0, len(requests))
+	for _, request := range requests {
+		item := jsonBatchRequestItem{
+			Id:        request.Id,
+			Method:    request.Method,
+			Url:       request.Url,
+			Headers:   request.Headers,
+			DependsOn: request.DependsOn,
+		}
+		if len(request.Body) != 0 {
+			if isJsonContentType(getBatchHeader(request.Headers, "Content-Type")) && json.Valid(request.Body) {
+				item.Body = request.Body
+			} else {
+				encoded, err := json.Marshal(base64.StdEncoding.EncodeToString(request.Body))
+				if err != nil {
+					return nil, err
+				}
+				item.Body = encoded
+			}
+		}
+		items = append(items, item)
+	}
+	return json.Marshal(struct {
+		Requests []jsonBatchRequestItem `json:"requests"`
+	}{Requests: items})
+}
+
+// DeserializeResponses deserializes the responses from the batch response payload
+func (format *JsonBatchFormat) DeserializeResponses(content []byte) ([]BatchResponseItem, error) {
+	var payload struct {
+		Responses []jsonBatchResponseItem `json:"responses"`
+	}
+	if err := json.Unmarshal(content, &payload); err != nil {
+		return nil, err
+	}
+	result := make([]BatchResponseItem, 0, len(payload.Responses))
+	for _, response := range payload.Responses {
+		item := BatchResponseItem{
+			Id:         response.Id,
+			StatusCode: response.Status,
+			Headers:    response.Headers,
+		}
+		if len(response.Body) != 0 && string(response.Body) != "null" {
+			item.Body = []byte(response.Body)
+			var encoded string
+			if !isJsonContentType(item.getHeader("Content-Type")) && json.Unmarshal(response.Body, &encoded) == nil {
+				if decoded, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+					item.Body = decoded
+				} else {
+					item.Body = []byte(encoded)
+				}
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// BatchRequestBuilder combines multiple requests into a single batch request sent through the request adapter.
+type BatchRequestBuilder struct {
+	adapter          abs.RequestAdapter
+	format           BatchFormat
+	parseNodeFactory absser.ParseNodeFactory
+	errorMappings    abs.ErrorMappings
+	requests         []BatchRequestItem
+}
+
+// NewBatchRequestBuilder creates a new BatchRequestBuilder sending the batch to the $batch endpoint of the base url of the adapter using the JSON batch format
+func NewBatchRequestBuilder(adapter abs.RequestAdapter) (*BatchRequestBuilder, error) {
+	if adapter == nil {
+		return nil, errors.New("adapter cannot be nil")
+	}
+	builder := &BatchRequestBuilder{
+		adapter:          adapter,
+		format:           NewJsonBatchFormat(),
+		parseNodeFactory: absser.DefaultParseNodeFactoryInstance,
+	}
+	if netHttpAdapter, ok := adapter.(*NetHttpRequestAdapter); ok && netHttpAdapter.getParseNodeFactory() != nil {
+		builder.parseNodeFactory = netHttpAdapter.getParseNodeFactory()
+	}
+	return builder, nil
+}
+
+// SetFormat sets the format of the batch payloads
+func (b *BatchRequestBuilder) SetFormat(format BatchFormat) {
+	if format != nil {
+		b.format = format
+	}
+}
+
+// SetParseNodeFactory sets the factory used to deserialize the bodies of the responses
+func (b *BatchRequestBuilder) SetParseNodeFactory(parseNodeFactory absser.ParseNodeFactory) {
+	if parseNodeFactory != nil {
+		b.parseNodeFactory = parseNodeFactory
+	}
+}
+
+// SetErrorMappings sets the error mappings used when the batch request itself fails
+func (b *BatchRequestBuilder) SetErrorMappings(errorMappings abs.ErrorMappings) {
+	b.errorMappings = errorMappings
+}
+
+// GetRequests returns the requests added to the batch
+func (b *BatchRequestBuilder) GetRequests() []BatchRequestItem {
+	result := make([]BatchRequestItem, len(b.requests))
+	copy(result, b.requests)
+	return result
+}
+
+// AddRequest adds the request to the batch and returns its id, the request is executed after the ones it depends on
+func (b *BatchRequestBuilder) AddRequest(requestInfo *abs.RequestInformation, dependsOn ...string) (string, error) {
+	if requestInfo == nil {
+		return "", errors.New("requestInfo cannot be nil")
+	}
+	for _, dependency := range dependsOn {
+		if !b.containsRequest(dependency) {
+			return "", fmt.Errorf("the batch doesn't contain a request with the id %s", dependency)
+		}
+	}
+	baseUrl := b.adapter.GetBaseUrl()
+	if requestInfo.PathParameters == nil {
+		requestInfo.PathParameters = make(map[string]string)
+	}
+	requestInfo.PathParameters["baseurl"] = baseUrl
+	uri, err := requestInfo.GetUri()
+	if err != nil {
+		return "", err
+	}
+	requestUrl := uri.String()
+	if baseUrl != "" && strings.HasPrefix(requestUrl, baseUrl) {
+		requestUrl = strings.TrimPrefix(requestUrl, baseUrl)
+		if !strings.HasPrefix(requestUrl, "/") {
+			requestUrl = "/" + requestUrl
+		}
+	}
+	var headers map[string]string
+	if keys := requestInfo.Headers.ListKeys(); len(keys) != 0 {
+		headers = make(map[string]string, len(keys))
+		for _, key := range keys {
+			headers[key] = strings.Join(requestInfo.Headers.Get(key), ", ")
+		}
+	}
+	item := BatchRequestItem{
+		Id:        strconv.Itoa(len(b.requests) + 1),
+		Method:    requestInfo.Method.String(),
+		Url:       requestUrl,
+		Headers:   headers,
+		Body:      requestInfo.Content,
+		DependsOn: dependsOn,
+	}
+	b.requests = append(b.requests, item)
+	return item.Id, nil
+}
+
+// containsRequest returns whether the batch contains a request with the given id
+func (b *BatchRequestBuilder) containsRequest(id string) bool {
+	for _, request := range b.requests {
+		if request.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Send sends the batch request and returns the responses to the requests of the batch
+func (b *BatchRequestBuilder) Send(ctx context.Context) (*BatchResponse, error) {
+	if len(b.requests) == 0 {
+		return nil, errors.New("the batch doesn't contain any request")
+	}
+	content, err := b.format.SerializeRequests(b.requests)
+	if err != nil {
+		return nil, err
+	}
+	requestInfo := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.POST, batchUrlTemplate, map[string]string{
+		"baseurl": b.adapter.GetBaseUrl(),
+	})
+	requestInfo.Headers.TryAdd("Accept", b.format.GetContentType())
+	requestInfo.SetStreamContentAndContentType(content, b.format.GetContentType())
+	result, err := b.adapter.SendPrimitive(ctx, requestInfo, "[]byte", b.errorMappings)
+	if err != nil {
+		return nil, err
+	}
+	payload, ok := result.([]byte)
+	if !ok || len(payload) == 0 {
+		return nil, errors.New("the batch response doesn't have a body")
+	}
+	items, err := b.format.DeserializeResponses(payload)
+	if err != nil {
+		return nil, err
+	}
+	responses := make(map[string]BatchResponseItem, len(items))
+	for _, item := range items {
+		responses[item.Id] = item
+	}
+	return &BatchResponse{
+		responses:        responses,
+		parseNodeFactory: b.parseNodeFactory,
+	}, nil
+}
+
+// BatchResponse holds the responses to the requests of a batch
+type BatchResponse struct {
+	responses        map[string]BatchResponseItem
+	parseNodeFactory absser.ParseNodeFactory
+}
+
+// GetResponse returns the response to the request with the given id
+func (r *BatchResponse) GetResponse(id string) (BatchResponseItem, bool) {
+	response, ok := r.responses[id]
+	return response, ok
+}
+
+// GetStatusCodes returns the status codes of the responses by request id
+func (r *BatchResponse) GetStatusCodes() map[string]int {
+	result := make(map[string]int, len(r.responses))
+	for id, response := range r.responses {
+		result[id] = response.StatusCode
+	}
+	return result
+}
+
+// GetError returns the error of the request with the given id, nil when the request succeeded.
+// The error is deserialized with the factory mapped to the status code, an ApiError is returned when none is mapped.
+func (r *BatchResponse) GetError(id string, errorMappings abs.ErrorMappings) error {
+	response, ok := r.responses[id]
+	if !ok {
+		return fmt.Errorf("the batch response doesn't contain a response for the request with the id %s", id)
+	}
+	return getBatchResponseItemError(response, r.parseNodeFactory, errorMappings)
+}
+
+// getBatchResponseItemError returns the error of the response, nil when the request succeeded
+func getBatchResponseItemError(response BatchResponseItem, parseNodeFactory absser.ParseNodeFactory, errorMappings abs.ErrorMappings) error {
+	if response.StatusCode < 400 {
+		return nil
+	}
+	responseHeaders := abs.NewResponseHeaders()
+	for key, value := range response.Headers {
+		responseHeaders.Add(key, value)
+	}
+	statusAsString := strconv.Itoa(response.StatusCode)
+	errorCtor := getErrorFactory(errorMappings, response.StatusCode)
+	if errorCtor == nil {
+		return &abs.ApiError{
+			Message:            "The server returned an unexpected status code and no error factory is registered for this code: " + statusAsString,
+			ResponseStatusCode: response.StatusCode,
+			ResponseHeaders:    responseHeaders,
+		}
+	}
+	errValue, err := getBatchResponseItemValue(response, parseNodeFactory, errorCtor)
+	if err != nil {
+		return err
+	} else if errValue == nil {
+		return &abs.ApiError{
+			Message:            "The server returned an unexpected status code but the error could not be deserialized: " + statusAsString,
+			ResponseStatusCode: response.StatusCode,
+			ResponseHeaders:    responseHeaders,
+		}
+	}
+	if apiErrorable, ok := errValue.(abs.ApiErrorable); ok {
+		apiErrorable.SetResponseHeaders(responseHeaders)
+		apiErrorable.SetStatusCode(response.StatusCode)
+	}
+	if err, ok := errValue.(error); ok {
+		return err
+	}
+	return &abs.ApiError{
+		Message:            "The server returned an unexpected status code and the error mapped for this code is not an error: " + statusAsString,
+		ResponseStatusCode: response.StatusCode,
+		ResponseHeaders:    responseHeaders,
+	}
+}
+
+// getBatchResponseItemValue deserializes the body of the response with the given factory
+func getBatchResponseItemValue(response BatchResponseItem, parseNodeFactory absser.ParseNodeFactory, factory absser.ParsableFactory) (absser.Parsable, error) {
+	if len(response.Body) == 0 || response.StatusCode == 204 {
+		return nil, nil
+	}
+	contentType, parameters := parseContentType(response.getHeader("Content-Type"))
+	if contentType == "" {
+		return nil, errors.New("the response of the request with the id " + response.Id + " doesn't have a content type")
+	}
+	rootNode, err := getRootParseNodeWithParameters(parseNodeFactory, contentType, parameters, response.Body)
+	if err != nil {
+		return nil, err
+	}
+	return rootNode.GetObjectValue(factory)
+}
+
+// GetBatchResponseValue returns the deserialized value of the response to the request with the given id.
+// The error of the request is returned when it failed, see BatchResponse.GetError.
+func GetBatchResponseValue[T absser.Parsable](response *BatchResponse, id string, factory absser.ParsableFactory, errorMappings abs.ErrorMappings) (T, error) {
+	var result T
+	if response == nil {
+		return result, errors.New("response cannot be nil")
+	}
+	if err := response.GetError(id, errorMappings); err != nil {
+		return result, err
+	}
+	value, err := getBatchResponseItemValue(response.responses[id], response.parseNodeFactory, factory)
+	if err != nil || value == nil {
+		return result, err
+	}
+	result, ok := value.(T)
+	if !ok {
+		return result, fmt.Errorf("the response is a %T where a %T was expected", value, result)
+	}
+	return result, nil
+}
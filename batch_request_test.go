@@ -0,0 +1,163 @@
+package nethttplibrary
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	absser "github.com/microsoft/kiota-abstractions-go/serialization"
+	"github.com/microsoft/kiota-http-go/testingutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// testContentParseNodeFactory returns parse nodes exposing the raw content to the factories
+type testContentParseNodeFactory struct {
+	testingutil.MockParseNodeFactory
+}
+
+type testContentParseNode struct {
+	testingutil.MockParseNode
+	content []byte
+}
+
+func (f *testContentParseNodeFactory) GetRootParseNode(contentType string, content []byte) (absser.ParseNode, error) {
+	return &testContentParseNode{content: content}, nil
+}
+
+func (n *testContentParseNode) GetObjectValue(ctor absser.ParsableFactory) (absser.Parsable, error) {
+	return ctor(n)
+}
+
+type testBatchError struct {
+	abs.ApiError
+	code string
+}
+
+func (e *testBatchError) Serialize(writer absser.SerializationWriter) error {
+	return nil
+}
+
+func (e *testBatchError) GetFieldDeserializers() map[string]func(absser.ParseNode) error {
+	return make(map[string]func(absser.ParseNode) error)
+}
+
+func testBatchErrorFactory(parseNode absser.ParseNode) (absser.Parsable, error) {
+	var content struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(parseNode.(*testContentParseNode).content, &content); err != nil {
+		return nil, err
+	}
+	return &testBatchError{code: content.Error.Code}, nil
+}
+
+func testBatchPageFactory(parseNode absser.ParseNode) (absser.Parsable, error) {
+	var content struct {
+		Value []string `json:"value"`
+	}
+	if err := json.Unmarshal(parseNode.(*testContentParseNode).content, &content); err != nil {
+		return nil, err
+	}
+	return &testPage{items: content.Value}, nil
+}
+
+func TestItSendsABatchRequest(t *testing.T) {
+	var receivedPath string
+	var receivedRequests []map[string]any
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		receivedPath = req.URL.Path
+		var reader io.Reader = req.Body
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			reader, _ = gzip.NewReader(req.Body)
+		}
+		body, _ := io.ReadAll(reader)
+		var payload struct {
+			Requests []map[string]any `json:"requests"`
+		}
+		json.Unmarshal(body, &payload)
+		receivedRequests = payload.Requests
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(200)
+		res.Write([]byte(`{"responses":[
+			{"id":"1","status":200,"headers":{"Content-Type":"application/json"},"body":{"value":["a","b"]}},
+			{"id":"2","status":404,"headers":{"content-type":"application/json"},"body":{"error":{"code":"itemNotFound"}}},
+			{"id":"3","status":204},
+			{"id":"4","status":500}
+		]}`))
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapterWithParseNodeFactory(&absauth.AnonymousAuthenticationProvider{}, &testContentParseNodeFactory{})
+	assert.Nil(t, err)
+	adapter.SetBaseUrl(testServer.URL + "/v1.0")
+	builder, err := NewBatchRequestBuilder(adapter)
+	assert.Nil(t, err)
+
+	getUsers := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/users", map[string]string{})
+	firstId, err := builder.AddRequest(getUsers)
+	assert.Nil(t, err)
+	getUser := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/users/missing", map[string]string{})
+	secondId, _ := builder.AddRequest(getUser, firstId)
+	deleteUser := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.DELETE, "{+baseurl}/users/1", map[string]string{})
+	thirdId, _ := builder.AddRequest(deleteUser)
+	upload := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.PUT, "{+baseurl}/drive/content", map[string]string{})
+	upload.SetStreamContentAndContentType([]byte("text"), "text/plain")
+	fourthId, _ := builder.AddRequest(upload)
+	_, err = builder.AddRequest(getUser, "42")
+	assert.Error(t, err)
+
+	response, err := builder.Send(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "/v1.0/$batch", receivedPath)
+	if assert.Equal(t, 4, len(receivedRequests)) {
+		assert.Equal(t, "/users", receivedRequests[0]["url"])
+		assert.Equal(t, []any{"1"}, receivedRequests[1]["dependsOn"])
+		assert.Equal(t, "DELETE", receivedRequests[2]["method"])
+		assert.Equal(t, "dGV4dA==", receivedRequests[3]["body"])
+	}
+	assert.Equal(t, map[string]int{"1": 200, "2": 404, "3": 204, "4": 500}, response.GetStatusCodes())
+
+	page, err := GetBatchResponseValue[*testPage](response, firstId, testBatchPageFactory, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, page.items)
+
+	errorMappings := abs.ErrorMappings{"4XX": testBatchErrorFactory}
+	_, err = GetBatchResponseValue[*testPage](response, secondId, testBatchPageFactory, errorMappings)
+	var batchErr *testBatchError
+	if assert.True(t, errors.As(err, &batchErr)) {
+		assert.Equal(t, "itemNotFound", batchErr.code)
+		assert.Equal(t, 404, batchErr.ResponseStatusCode)
+	}
+
+	page, err = GetBatchResponseValue[*testPage](response, thirdId, testBatchPageFactory, errorMappings)
+	assert.Nil(t, err)
+	assert.Nil(t, page)
+
+	var apiErr *abs.ApiError
+	assert.True(t, errors.As(response.GetError(fourthId, errorMappings), &apiErr))
+	assert.Equal(t, 500, apiErr.ResponseStatusCode)
+	assert.Error(t, response.GetError("42", nil))
+}
+
+func TestJsonBatchFormatRoundTripsBinaryBodies(t *testing.T) {
+	format := NewJsonBatchFormat()
+	content, err := format.SerializeRequests([]BatchRequestItem{
+		{Id: "1", Method: "POST", Url: "/items", Headers: map[string]string{"Content-Type": "application/json"}, Body: []byte(`{"name":"a"}`)},
+	})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"requests":[{"id":"1","method":"POST","url":"/items","headers":{"Content-Type":"application/json"},"body":{"name":"a"}}]}`, string(content))
+
+	responses, err := format.DeserializeResponses([]byte(`{"responses":[{"id":"1","status":200,"headers":{"Content-Type":"application/octet-stream"},"body":"AAEC"}]}`))
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(responses)) {
+		assert.Equal(t, []byte{0, 1, 2}, responses[0].Body)
+	}
+}
@@ -0,0 +1,129 @@
+package nethttplibrary
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+)
+
+// CertificatePinningOptions to use when validating the certificate chains of the servers against SPKI pins.
+type CertificatePinningOptions struct {
+	// Pins maps host names to the base64 encoded SHA-256 hashes of the subject public key info (SPKI) of the certificates they can present, see GetSpkiPin.
+	// A connection is accepted when any certificate of the chain matches a pin of the host, hosts without pins are not pinned.
+	Pins map[string][]string
+}
+
+// PinningError is returned when the certificate chain of a server doesn't match the pins configured for its host
+type PinningError struct {
+	// Host is the host the connection was established with
+	Host string
+	// ReceivedPins are the SPKI pins of the certificate chain presented by the server
+	ReceivedPins []string
+}
+
+// Error returns the error message
+func (e *PinningError) Error() string {
+	return fmt.Sprintf("the certificate chain presented by %s doesn't match any of the configured pins, received %s", e.Host, strings.Join(e.ReceivedPins, ", "))
+}
+
+// GetSpkiPin returns the base64 encoded SHA-256 hash of the subject public key info of the certificate
+func GetSpkiPin(certificate *x509.Certificate) string {
+	hash := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// getPinnedHost returns the configured host the connection was established with.
+// The server name is empty when connecting to IP addresses, the host the leaf certificate is valid for is used instead.
+func getPinnedHost(pins map[string][]string, state tls.ConnectionState) (string, bool) {
+	serverName := strings.ToLower(state.ServerName)
+	if serverName != "" {
+		_, ok := pins[serverName]
+		return serverName, ok
+	}
+	if len(state.PeerCertificates) == 0 {
+		return "", false
+	}
+	for host := range pins {
+		if state.PeerCertificates[0].VerifyHostname(host) == nil {
+			return host, true
+		}
+	}
+	return "", false
+}
+
+// getPinnableCertificates returns the certificates of the verified chains, the pins must not be matched against the other certificates sent by the server:
+// they aren't verified and anyone can append the public pinned certificate to their chain. Only the leaf is returned when the verification is disabled.
+func getPinnableCertificates(state tls.ConnectionState) []*x509.Certificate {
+	if len(state.VerifiedChains) == 0 {
+		if len(state.PeerCertificates) == 0 {
+			return nil
+		}
+		return state.PeerCertificates[:1]
+	}
+	certificates := make([]*x509.Certificate, 0, len(state.VerifiedChains[0]))
+	for _, chain := range state.VerifiedChains {
+		certificates = append(certificates, chain...)
+	}
+	return certificates
+}
+
+// getCertificatePinningVerifier returns the function validating the connections against the pins, chained to the existing verifier if any
+func getCertificatePinningVerifier(options CertificatePinningOptions, next func(tls.ConnectionState) error) func(tls.ConnectionState) error {
+	pins := make(map[string][]string, len(options.Pins))
+	for host, hostPins := range options.Pins {
+		pins[strings.ToLower(host)] = hostPins
+	}
+	return func(state tls.ConnectionState) error {
+		if next != nil {
+			if err := next(state); err != nil {
+				return err
+			}
+		}
+		host, ok := getPinnedHost(pins, state)
+		if !ok {
+			return nil
+		}
+		certificates := getPinnableCertificates(state)
+		receivedPins := make([]string, 0, len(certificates))
+		for _, certificate := range certificates {
+			pin := GetSpkiPin(certificate)
+			for _, expectedPin := range pins[host] {
+				if pin == expectedPin {
+					return nil
+				}
+			}
+			receivedPins = append(receivedPins, pin)
+		}
+		return &PinningError{
+			Host:         host,
+			ReceivedPins: receivedPins,
+		}
+	}
+}
+
+// configureCertificatePinning sets the pinning verifier on the TLS configuration
+func configureCertificatePinning(tlsConfig *tls.Config, options CertificatePinningOptions) {
+	tlsConfig.VerifyConnection = getCertificatePinningVerifier(options, tlsConfig.VerifyConnection)
+}
+
+// NewCertificatePinningTransport returns a clone of the transport validating the certificate chains of the servers against the SPKI pins.
+// The TLS handshake fails with a PinningError on mismatch, before the request is sent.
+func NewCertificatePinningTransport(transport *nethttp.Transport, options CertificatePinningOptions) (*nethttp.Transport, error) {
+	if transport == nil {
+		return nil, errors.New("transport cannot be nil")
+	}
+	if len(options.Pins) == 0 {
+		return nil, errors.New("at least one pin is required")
+	}
+	transport = transport.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	configureCertificatePinning(transport.TLSClientConfig, options)
+	return transport, nil
+}
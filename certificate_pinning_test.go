@@ -0,0 +1,93 @@
+package nethttplibrary
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItAcceptsPinnedCertificates(t *testing.T) {
+	testServer := httptest.NewTLSServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	transport, err := NewCertificatePinningTransport(testServer.Client().Transport.(*nethttp.Transport), CertificatePinningOptions{
+		Pins: map[string][]string{"127.0.0.1": {"other", GetSpkiPin(testServer.Certificate())}},
+	})
+	assert.Nil(t, err)
+	client := getDefaultClientWithoutMiddleware()
+	client.Transport = NewCustomTransportWithParentTransport(transport, NewHeadersInspectionHandler())
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestItRejectsCertificatesNotMatchingThePins(t *testing.T) {
+	requestCount := 0
+	testServer := httptest.NewTLSServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		requestCount++
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	client, err := NewKiotaClientBuilder().
+		WithTransport(testServer.Client().Transport).
+		WithCertificatePins("127.0.0.1", "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=").
+		WithMiddleware(NewHeadersInspectionHandler()).
+		Build()
+	assert.Nil(t, err)
+	_, err = client.Get(testServer.URL)
+	var pinningErr *PinningError
+	assert.True(t, errors.As(err, &pinningErr))
+	assert.Equal(t, "127.0.0.1", pinningErr.Host)
+	assert.Contains(t, pinningErr.ReceivedPins, GetSpkiPin(testServer.Certificate()))
+	assert.Equal(t, 0, requestCount)
+
+	client, _ = NewKiotaClientBuilder().
+		WithTransport(testServer.Client().Transport).
+		WithCertificatePins("graph.microsoft.com", "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=").
+		WithMiddleware(NewHeadersInspectionHandler()).
+		Build()
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestItDoesntMatchThePinsAgainstCertificatesOutsideTheVerifiedChain(t *testing.T) {
+	requestCount := 0
+	testServer := httptest.NewUnstartedServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		requestCount++
+		res.WriteHeader(200)
+	}))
+	testServer.StartTLS()
+	defer testServer.Close()
+	pinnedPem, _ := generateTestCertificate(t)
+	pinnedBlock, _ := pem.Decode(pinnedPem)
+	// the pinned certificate is sent after the trusted chain without being part of it
+	testServer.TLS.Certificates[0].Certificate = append(testServer.TLS.Certificates[0].Certificate, pinnedBlock.Bytes)
+	pinnedCertificate, err := x509.ParseCertificate(pinnedBlock.Bytes)
+	assert.Nil(t, err)
+	transport, err := NewCertificatePinningTransport(testServer.Client().Transport.(*nethttp.Transport), CertificatePinningOptions{
+		Pins: map[string][]string{"127.0.0.1": {GetSpkiPin(pinnedCertificate)}},
+	})
+	assert.Nil(t, err)
+	client := getDefaultClientWithoutMiddleware()
+	client.Transport = NewCustomTransportWithParentTransport(transport, NewHeadersInspectionHandler())
+
+	_, err = client.Get(testServer.URL)
+	var pinningErr *PinningError
+	assert.True(t, errors.As(err, &pinningErr))
+	assert.NotContains(t, pinningErr.ReceivedPins, GetSpkiPin(pinnedCertificate))
+	assert.Equal(t, 0, requestCount)
+}
+
+func TestNewCertificatePinningTransportValidatesTheOptions(t *testing.T) {
+	_, err := NewCertificatePinningTransport(nil, CertificatePinningOptions{Pins: map[string][]string{"contoso.com": {"pin"}}})
+	assert.Error(t, err)
+	_, err = NewCertificatePinningTransport(&nethttp.Transport{}, CertificatePinningOptions{})
+	assert.Error(t, err)
+}
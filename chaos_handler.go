@@ -1,15 +1,19 @@
 package nethttplibrary
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 	"math/rand"
 	nethttp "net/http"
 	"regexp"
 	"strings"
+	"strconv"
+	"sync/atomic"
 
 	abstractions "github.com/microsoft/kiota-abstractions-go"
-	"go.opentelemetry.io/otel"
+	absser "github.com/microsoft/kiota-abstractions-go/serialization"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -31,15 +35,29 @@ const (
 // ResponseBody The response body to be returned as part of the error response
 // Headers The response headers to be returned as part of the error response
 // StatusMap The Map passed by user containing url-statusCode info
+// ResponseContent The model (e.g. an error model) serialized as the body of the response, a JSON error is returned when nil
+// ContentType The content type the response content is serialized to, application/json when empty
+// SerializationWriterFactory The factory serializing the response content, the default registry when nil
+// IncludedHosts The hosts chaos is injected for (e.g. graph.microsoft.com or *.contoso.com), all the hosts when empty
+// ExcludedHosts The hosts chaos is never injected for, they take precedence over the included hosts
+// IncludedPathPrefixes The url path prefixes chaos is injected for (e.g. /v1.0/users), all the paths when empty
+// ExcludedPathPrefixes The url path prefixes chaos is never injected for, they take precedence over the included path prefixes
 type ChaosHandlerOptions struct {
-	BaseUrl         string
-	ChaosStrategy   ChaosStrategy
-	StatusCode      int
-	StatusMessage   string
-	ChaosPercentage int
-	ResponseBody    *nethttp.Response
-	Headers         map[string][]string
-	StatusMap       map[string]map[string]int
+	BaseUrl                    string
+	ChaosStrategy              ChaosStrategy
+	StatusCode                 int
+	StatusMessage              string
+	ChaosPercentage            int
+	ResponseBody               *nethttp.Response
+	Headers                    map[string][]string
+	StatusMap                  map[string]map[string]int
+	ResponseContent            absser.Parsable
+	ContentType                string
+	SerializationWriterFactory absser.SerializationWriterFactory
+	IncludedHosts              []string
+	ExcludedHosts              []string
+	IncludedPathPrefixes       []string
+	ExcludedPathPrefixes       []string
 }
 
 type chaosHandlerOptionsInt interface {
@@ -52,6 +70,13 @@ type chaosHandlerOptionsInt interface {
 	GetResponseBody() *nethttp.Response
 	GetHeaders() map[string][]string
 	GetStatusMap() map[string]map[string]int
+	GetResponseContent() absser.Parsable
+	GetContentType() string
+	GetSerializationWriterFactory() absser.SerializationWriterFactory
+	GetIncludedHosts() []string
+	GetExcludedHosts() []string
+	GetIncludedPathPrefixes() []string
+	GetExcludedPathPrefixes() []string
 }
 
 func (handlerOptions *ChaosHandlerOptions) GetBaseUrl() string {
@@ -86,8 +111,38 @@ func (handlerOptions *ChaosHandlerOptions) GetStatusMap() map[string]map[string]
 	return handlerOptions.StatusMap
 }
 
+func (handlerOptions *ChaosHandlerOptions) GetResponseContent() absser.Parsable {
+	return handlerOptions.ResponseContent
+}
+
+func (handlerOptions *ChaosHandlerOptions) GetContentType() string {
+	return handlerOptions.ContentType
+}
+
+func (handlerOptions *ChaosHandlerOptions) GetSerializationWriterFactory() absser.SerializationWriterFactory {
+	return handlerOptions.SerializationWriterFactory
+}
+
+func (handlerOptions *ChaosHandlerOptions) GetIncludedHosts() []string {
+	return handlerOptions.IncludedHosts
+}
+
+func (handlerOptions *ChaosHandlerOptions) GetExcludedHosts() []string {
+	return handlerOptions.ExcludedHosts
+}
+
+func (handlerOptions *ChaosHandlerOptions) GetIncludedPathPrefixes() []string {
+	return handlerOptions.IncludedPathPrefixes
+}
+
+func (handlerOptions *ChaosHandlerOptions) GetExcludedPathPrefixes() []string {
+	return handlerOptions.ExcludedPathPrefixes
+}
+
+// ChaosHandler returns random or configured failure responses in place of sending the requests, to test the resilience of the applications.
+// Its options can be updated while requests are in flight, each request uses a consistent snapshot of the options.
 type ChaosHandler struct {
-	options *ChaosHandlerOptions
+	options *atomic.Value
 }
 
 var chaosHandlerKey = abstractions.RequestOptionKey{Key: "ChaosHandler"}
@@ -97,33 +152,142 @@ func (handlerOptions *ChaosHandlerOptions) GetKey() abstractions.RequestOptionKe
 	return chaosHandlerKey
 }
 
-// NewChaosHandlerWithOptions creates a new ChaosHandler with the configured options
-func NewChaosHandlerWithOptions(handlerOptions *ChaosHandlerOptions) (*ChaosHandler, error) {
+// clone returns a copy of the options which doesn't share the headers and status maps
+func (handlerOptions *ChaosHandlerOptions) clone() *ChaosHandlerOptions {
+	result := *handlerOptions
+	result.IncludedHosts = append([]string(nil), handlerOptions.IncludedHosts...)
+	result.ExcludedHosts = append([]string(nil), handlerOptions.ExcludedHosts...)
+	result.IncludedPathPrefixes = append([]string(nil), handlerOptions.IncludedPathPrefixes...)
+	result.ExcludedPathPrefixes = append([]string(nil), handlerOptions.ExcludedPathPrefixes...)
+	if handlerOptions.Headers != nil {
+		result.Headers = make(map[string][]string, len(handlerOptions.Headers))
+		for key, values := range handlerOptions.Headers {
+			result.Headers[key] = append([]string(nil), values...)
+		}
+	}
+	if handlerOptions.StatusMap != nil {
+		result.StatusMap = make(map[string]map[string]int, len(handlerOptions.StatusMap))
+		for url, codes := range handlerOptions.StatusMap {
+			methodCodes := make(map[string]int, len(codes))
+			for method, code := range codes {
+				methodCodes[method] = code
+			}
+			result.StatusMap[url] = methodCodes
+		}
+	}
+	return &result
+}
+
+func validateChaosHandlerOptions(handlerOptions *ChaosHandlerOptions) error {
 	if handlerOptions == nil {
-		return nil, errors.New("unexpected argument ChaosHandlerOptions as nil")
+		return errors.New("unexpected argument ChaosHandlerOptions as nil")
 	}
 
 	if handlerOptions.ChaosPercentage < 0 || handlerOptions.ChaosPercentage > 100 {
-		return nil, errors.New("ChaosPercentage must be between 0 and 100")
+		return errors.New("ChaosPercentage must be between 0 and 100")
 	}
 	if handlerOptions.ChaosStrategy == Manual {
 		if handlerOptions.StatusCode == 0 {
-			return nil, errors.New("invalid status code for manual strategy")
+			return errors.New("invalid status code for manual strategy")
 		}
 	}
+	return nil
+}
 
-	return &ChaosHandler{options: handlerOptions}, nil
+// NewChaosHandlerWithOptions creates a new ChaosHandler with the configured options.
+// The handler keeps a copy of the options, use SetOptions or UpdateOptions to change them afterwards.
+func NewChaosHandlerWithOptions(handlerOptions *ChaosHandlerOptions) (*ChaosHandler, error) {
+	if err := validateChaosHandlerOptions(handlerOptions); err != nil {
+		return nil, err
+	}
+	handler := &ChaosHandler{options: &atomic.Value{}}
+	handler.options.Store(handlerOptions.clone())
+	return handler, nil
 }
 
 // NewChaosHandler creates a new ChaosHandler with default configuration options of Random errors at 10%
 func NewChaosHandler() *ChaosHandler {
-	return &ChaosHandler{
-		options: &ChaosHandlerOptions{
-			ChaosPercentage: 10,
-			ChaosStrategy:   Random,
-			StatusMessage:   "A random error message",
-		},
+	handler := &ChaosHandler{options: &atomic.Value{}}
+	handler.options.Store(&ChaosHandlerOptions{
+		ChaosPercentage: 10,
+		ChaosStrategy:   Random,
+		StatusMessage:   "A random error message",
+	})
+	return handler
+}
+
+// getOptions returns the current snapshot of the options, it must not be mutated
+func (middleware ChaosHandler) getOptions() *ChaosHandlerOptions {
+	if middleware.options == nil {
+		return nil
 	}
+	options, _ := middleware.options.Load().(*ChaosHandlerOptions)
+	return options
+}
+
+// GetOptions returns a copy of the current options of the handler
+func (middleware *ChaosHandler) GetOptions() *ChaosHandlerOptions {
+	options := middleware.getOptions()
+	if options == nil {
+		return nil
+	}
+	return options.clone()
+}
+
+// SetOptions replaces the options of the handler, the requests in flight keep using the options they started with
+func (middleware *ChaosHandler) SetOptions(handlerOptions *ChaosHandlerOptions) error {
+	if err := validateChaosHandlerOptions(handlerOptions); err != nil {
+		return err
+	}
+	if middleware.options == nil {
+		return errors.New("the chaos handler must be created with NewChaosHandler or NewChaosHandlerWithOptions")
+	}
+	middleware.options.Store(handlerOptions.clone())
+	return nil
+}
+
+// UpdateOptions applies the update to a copy of the current options and swaps them in when they are valid.
+// The update is applied again if the options were replaced concurrently, it should not have side effects.
+func (middleware *ChaosHandler) UpdateOptions(update func(handlerOptions *ChaosHandlerOptions)) error {
+	if update == nil {
+		return errors.New("update cannot be nil")
+	}
+	if middleware.options == nil {
+		return errors.New("the chaos handler must be created with NewChaosHandler or NewChaosHandlerWithOptions")
+	}
+	for {
+		current := middleware.getOptions()
+		updated := current.clone()
+		update(updated)
+		updated = updated.clone()
+		if err := validateChaosHandlerOptions(updated); err != nil {
+			return err
+		}
+		if middleware.options.CompareAndSwap(current, updated) {
+			return nil
+		}
+	}
+}
+
+// SetChaosPercentage sets the percentage of the requests receiving a chaos response
+func (middleware *ChaosHandler) SetChaosPercentage(chaosPercentage int) error {
+	return middleware.UpdateOptions(func(handlerOptions *ChaosHandlerOptions) {
+		handlerOptions.ChaosPercentage = chaosPercentage
+	})
+}
+
+// SetStatusCode sets the status code of the chaos responses, zero returns random or mapped status codes with the Random strategy
+func (middleware *ChaosHandler) SetStatusCode(statusCode int) error {
+	return middleware.UpdateOptions(func(handlerOptions *ChaosHandlerOptions) {
+		handlerOptions.StatusCode = statusCode
+	})
+}
+
+// SetStatusMap sets the status codes returned for the relative urls and methods with the Random strategy
+func (middleware *ChaosHandler) SetStatusMap(statusMap map[string]map[string]int) error {
+	return middleware.UpdateOptions(func(handlerOptions *ChaosHandlerOptions) {
+		handlerOptions.StatusMap = statusMap
+	})
 }
 
 var methodStatusCode = map[string][]int{
@@ -198,6 +362,31 @@ var httpStatusCode = map[int]string{
 	511: "Network Authentication Required",
 }
 
+// isChaosTarget returns true when the host and path filters of the options let chaos be injected for the request
+func isChaosTarget(handlerOptions chaosHandlerOptionsInt, req *nethttp.Request) bool {
+	host := req.URL.Hostname()
+	if !isHostAllowed(handlerOptions.GetIncludedHosts(), host) {
+		return false
+	}
+	if excludedHosts := handlerOptions.GetExcludedHosts(); len(excludedHosts) > 0 && isHostAllowed(excludedHosts, host) {
+		return false
+	}
+	path := req.URL.Path
+	if includedPathPrefixes := handlerOptions.GetIncludedPathPrefixes(); len(includedPathPrefixes) > 0 && !hasPathPrefix(includedPathPrefixes, path) {
+		return false
+	}
+	return !hasPathPrefix(handlerOptions.GetExcludedPathPrefixes(), path)
+}
+
+func hasPathPrefix(prefixes []string, path string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func generateRandomStatusCode(request *nethttp.Request) int {
 	statusCodeArray := methodStatusCode[request.Method]
 	return statusCodeArray[rand.Intn(len(statusCodeArray))]
@@ -247,32 +436,87 @@ func getStatusCode(handlerOptions chaosHandlerOptionsInt, req *nethttp.Request)
 	return generateRandomStatusCode(req)
 }
 
-func createResponseBody(handlerOptions chaosHandlerOptionsInt, statusCode int) *nethttp.Response {
+const chaosDefaultContentType = "application/json"
+
+type chaosErrorContent struct {
+	Error chaosErrorDetails `json:"error"`
+}
+
+type chaosErrorDetails struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// createResponseContent serializes the configured response content, or a JSON error for the error status codes, and returns it with its content type
+func createResponseContent(handlerOptions chaosHandlerOptionsInt, statusCode int) ([]byte, string, error) {
+	if content := handlerOptions.GetResponseContent(); content != nil {
+		contentType := handlerOptions.GetContentType()
+		if contentType == "" {
+			contentType = chaosDefaultContentType
+		}
+		factory := handlerOptions.GetSerializationWriterFactory()
+		if factory == nil {
+			factory = absser.DefaultSerializationWriterFactoryInstance
+		}
+		writer, err := factory.GetSerializationWriter(contentType)
+		if err != nil {
+			return nil, "", err
+		}
+		defer writer.Close()
+		if err := writer.WriteObjectValue("", content); err != nil {
+			return nil, "", err
+		}
+		body, err := writer.GetSerializedContent()
+		if err != nil {
+			return nil, "", err
+		}
+		return body, contentType, nil
+	}
+	if statusCode >= 400 {
+		body, err := json.Marshal(chaosErrorContent{
+			Error: chaosErrorDetails{
+				Code:    httpStatusCode[statusCode],
+				Message: handlerOptions.GetStatusMessage(),
+			},
+		})
+		return body, chaosDefaultContentType, err
+	}
+	return []byte("{}"), chaosDefaultContentType, nil
+}
+
+func createResponseBody(handlerOptions chaosHandlerOptionsInt, statusCode int, req *nethttp.Request) (*nethttp.Response, error) {
 	if handlerOptions.GetResponseBody() != nil {
-		return handlerOptions.GetResponseBody()
+		return handlerOptions.GetResponseBody(), nil
 	}
 
-	var stringReader *strings.Reader
-	if statusCode > 400 {
-		codeMessage := httpStatusCode[statusCode]
-		errMessage := handlerOptions.GetStatusMessage()
-		stringReader = strings.NewReader("error : { code :  " + codeMessage + " , message : " + errMessage + " }")
-	} else {
-		stringReader = strings.NewReader("{}")
+	body, contentType, err := createResponseContent(handlerOptions, statusCode)
+	if err != nil {
+		return nil, err
 	}
+	header := make(nethttp.Header)
+	for key, values := range handlerOptions.GetHeaders() {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", contentType)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
 
 	return &nethttp.Response{
-		StatusCode: statusCode,
-		Status:     handlerOptions.GetStatusMessage(),
-		Body:       io.NopCloser(stringReader),
-		Header:     handlerOptions.GetHeaders(),
-	}
+		StatusCode:    statusCode,
+		Status:        handlerOptions.GetStatusMessage(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		Header:        header,
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
 }
 
 func createChaosResponse(handler chaosHandlerOptionsInt, req *nethttp.Request) (*nethttp.Response, error) {
 	statusCode := getStatusCode(handler, req)
-	responseBody := createResponseBody(handler, statusCode)
-	return responseBody, nil
+	return createResponseBody(handler, statusCode, req)
 }
 
 // ChaosHandlerTriggeredEventKey is the key used for the open telemetry event
@@ -281,20 +525,20 @@ const ChaosHandlerTriggeredEventKey = "com.microsoft.kiota.chaos_handler_trigger
 func (middleware ChaosHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
 	reqOption, ok := req.Context().Value(chaosHandlerKey).(chaosHandlerOptionsInt)
 	if !ok {
-		reqOption = middleware.options
+		reqOption = middleware.getOptions()
 	}
 
 	obsOptions := GetObservabilityOptionsFromRequest(req)
 	ctx := req.Context()
 	var span trace.Span
 	if obsOptions != nil {
-		ctx, span = otel.GetTracerProvider().Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "ChaosHandler_Intercept")
+		ctx, span = getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "ChaosHandler_Intercept")
 		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.chaos.enable", true))
 		req = req.WithContext(ctx)
 		defer span.End()
 	}
 
-	if rand.Intn(100) < reqOption.GetChaosPercentage() {
+	if isChaosTarget(reqOption, req) && rand.Intn(100) < reqOption.GetChaosPercentage() {
 		if span != nil {
 			span.AddEvent(ChaosHandlerTriggeredEventKey)
 		}
@@ -1,10 +1,15 @@
 package nethttplibrary
 
 import (
-	"github.com/stretchr/testify/assert"
+	"encoding/json"
+	"io"
 	nethttp "net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+
+	absser "github.com/microsoft/kiota-abstractions-go/serialization"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestItCreatesANewChaosHandler(t *testing.T) {
@@ -56,3 +61,176 @@ func TestItCreatesANewChaosHandlerWithOptions(t *testing.T) {
 	assert.NotNil(t, resp)
 	assert.Equal(t, 400, resp.StatusCode)
 }
+
+func TestItUpdatesTheChaosHandlerOptionsAtRuntime(t *testing.T) {
+	options := &ChaosHandlerOptions{
+		ChaosPercentage: 0,
+		ChaosStrategy:   Random,
+		StatusCode:      503,
+	}
+	handler, err := NewChaosHandlerWithOptions(options)
+	assert.Nil(t, err)
+	options.ChaosPercentage = 100
+	assert.Equal(t, 0, handler.GetOptions().ChaosPercentage)
+
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	send := func() int {
+		req, err := nethttp.NewRequest(nethttp.MethodGet, testServer.URL, nil)
+		assert.Nil(t, err)
+		resp, err := handler.Intercept(newNoopPipeline(), 0, req)
+		assert.Nil(t, err)
+		return resp.StatusCode
+	}
+	assert.Equal(t, 200, send())
+
+	assert.Nil(t, handler.SetChaosPercentage(100))
+	assert.Equal(t, 503, send())
+	assert.Nil(t, handler.SetStatusCode(429))
+	assert.Equal(t, 429, send())
+	assert.NotNil(t, handler.SetChaosPercentage(101))
+	assert.Equal(t, 100, handler.GetOptions().ChaosPercentage)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.Nil(t, handler.SetStatusMap(map[string]map[string]int{"/items": {"GET": 500 + i}}))
+			send()
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 1, len(handler.GetOptions().StatusMap))
+
+	assert.Nil(t, handler.SetOptions(&ChaosHandlerOptions{ChaosStrategy: Manual, StatusCode: 502, ChaosPercentage: 100}))
+	assert.Equal(t, 502, send())
+	assert.NotNil(t, handler.SetOptions(nil))
+}
+
+type testChaosSerializationWriter struct {
+	absser.SerializationWriter
+	content string
+}
+
+func (w *testChaosSerializationWriter) WriteStringValue(key string, value *string) error {
+	w.content += key + "=" + *value + ";"
+	return nil
+}
+
+func (w *testChaosSerializationWriter) WriteObjectValue(key string, item absser.Parsable, additionalValuesToMerge ...absser.Parsable) error {
+	return item.Serialize(w)
+}
+
+func (w *testChaosSerializationWriter) GetSerializedContent() ([]byte, error) {
+	return []byte(w.content), nil
+}
+
+func (w *testChaosSerializationWriter) Close() error {
+	return nil
+}
+
+type testChaosSerializationWriterFactory struct {
+	contentType string
+}
+
+func (f *testChaosSerializationWriterFactory) GetValidContentType() (string, error) {
+	return "text/plain", nil
+}
+
+func (f *testChaosSerializationWriterFactory) GetSerializationWriter(contentType string) (absser.SerializationWriter, error) {
+	f.contentType = contentType
+	return &testChaosSerializationWriter{}, nil
+}
+
+type testChaosError struct {
+	code string
+}
+
+func (e *testChaosError) Serialize(writer absser.SerializationWriter) error {
+	return writer.WriteStringValue("code", &e.code)
+}
+
+func (e *testChaosError) GetFieldDeserializers() map[string]func(absser.ParseNode) error {
+	return nil
+}
+
+func TestItReturnsJsonChaosResponseBodies(t *testing.T) {
+	handler, err := NewChaosHandlerWithOptions(&ChaosHandlerOptions{
+		ChaosPercentage: 100,
+		ChaosStrategy:   Manual,
+		StatusCode:      503,
+		StatusMessage:   "the service is down",
+	})
+	assert.Nil(t, err)
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://example.com/items", nil)
+	assert.Nil(t, err)
+	resp, err := handler.Intercept(newNoopPipeline(), 0, req)
+	assert.Nil(t, err)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	body, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(body)), resp.ContentLength)
+	var content map[string]map[string]string
+	assert.Nil(t, json.Unmarshal(body, &content))
+	assert.Equal(t, "Service Unavailable", content["error"]["code"])
+	assert.Equal(t, "the service is down", content["error"]["message"])
+}
+
+func TestItSerializesTheChaosResponseContent(t *testing.T) {
+	factory := &testChaosSerializationWriterFactory{}
+	handler, err := NewChaosHandlerWithOptions(&ChaosHandlerOptions{
+		ChaosPercentage:            100,
+		ChaosStrategy:              Manual,
+		StatusCode:                 429,
+		ResponseContent:            &testChaosError{code: "throttled"},
+		ContentType:                "text/plain",
+		SerializationWriterFactory: factory,
+	})
+	assert.Nil(t, err)
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://example.com/items", nil)
+	assert.Nil(t, err)
+	resp, err := handler.Intercept(newNoopPipeline(), 0, req)
+	assert.Nil(t, err)
+	assert.Equal(t, "text/plain", factory.contentType)
+	assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "15", resp.Header.Get("Content-Length"))
+	body, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, "code=throttled;", string(body))
+}
+
+func TestItInjectsChaosOnlyForTheTargetedHostsAndPaths(t *testing.T) {
+	handler, err := NewChaosHandlerWithOptions(&ChaosHandlerOptions{
+		ChaosPercentage:      100,
+		ChaosStrategy:        Manual,
+		StatusCode:           503,
+		IncludedHosts:        []string{"*.contoso.com"},
+		ExcludedHosts:        []string{"auth.contoso.com"},
+		IncludedPathPrefixes: []string{"/v1/"},
+		ExcludedPathPrefixes: []string{"/v1/health"},
+	})
+	assert.Nil(t, err)
+	cases := map[string]bool{
+		"https://api.contoso.com/v1/items":  true,
+		"https://API.contoso.com/v1/items":  true,
+		"https://auth.contoso.com/v1/items": false,
+		"https://api.contoso.com/v2/items":  false,
+		"https://api.contoso.com/v1/health": false,
+		"https://example.com/v1/items":      false,
+	}
+	for url, injected := range cases {
+		req, err := nethttp.NewRequest(nethttp.MethodGet, url, nil)
+		assert.Nil(t, err)
+		pipeline := newSpyPipeline()
+		resp, _ := handler.Intercept(pipeline, 0, req)
+		if injected {
+			assert.Nil(t, pipeline.GetReceivedRequest(), url)
+			assert.Equal(t, 503, resp.StatusCode, url)
+		} else {
+			assert.NotNil(t, pipeline.GetReceivedRequest(), url)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+
+	"github.com/google/uuid"
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ClientRequestIdHandler adds a unique client request id to every request so failed calls can be correlated with the service logs.
+type ClientRequestIdHandler struct {
+	options ClientRequestIdHandlerOptions
+}
+
+// ClientRequestIdHandlerOptions to use when adding the client request id.
+type ClientRequestIdHandlerOptions struct {
+	// Enabled defines whether the client request id should be added
+	Enabled bool
+	// HeaderName is the name of the header the client request id is written to
+	HeaderName string
+	// GenerateId is the callback used to generate the client request id, a random UUID is used when nil
+	GenerateId func() string
+	// ValidateReturnedId defines whether the client request id returned by the service should be checked against the one sent.
+	// The return-client-request-id header is added to the request so the service echoes the id, mismatches and missing ids are recorded on the span.
+	ValidateReturnedId bool
+	// FailOnMismatch defines whether a ClientRequestIdMismatchError should be returned when the returned client request id doesn't match or is missing
+	FailOnMismatch bool
+}
+
+// ClientRequestIdMismatchError is returned when the client request id returned by the service doesn't match the one sent, which indicates a misrouted response.
+type ClientRequestIdMismatchError struct {
+	// ClientRequestId is the client request id sent with the request
+	ClientRequestId string
+	// ReturnedClientRequestId is the client request id returned by the service, empty when the service didn't return it
+	ReturnedClientRequestId string
+}
+
+// Error returns the error message
+func (e *ClientRequestIdMismatchError) Error() string {
+	if e.ReturnedClientRequestId == "" {
+		return "the service didn't return the client request id sent (" + e.ClientRequestId + ")"
+	}
+	return "the client request id returned by the service (" + e.ReturnedClientRequestId + ") doesn't match the one sent (" + e.ClientRequestId + ")"
+}
+
+// ClientRequestIdError wraps an error which occurred while sending a request with the client request id of that request.
+type ClientRequestIdError struct {
+	// ClientRequestId is the client request id of the failed request
+	ClientRequestId string
+	// Err is the underlying error
+	Err error
+}
+
+// Error returns the error message
+func (e *ClientRequestIdError) Error() string {
+	return e.Err.Error() + " (client request id: " + e.ClientRequestId + ")"
+}
+
+// Unwrap returns the underlying error
+func (e *ClientRequestIdError) Unwrap() error {
+	return e.Err
+}
+
+const defaultClientRequestIdHeaderName = "client-request-id"
+
+// ClientRequestIdAttributeName is the span attribute name used to record the client request id
+const ClientRequestIdAttributeName = "com.microsoft.kiota.client_request_id"
+
+// ReturnedClientRequestIdAttributeName is the span event attribute name used to record the client request id returned by the service
+const ReturnedClientRequestIdAttributeName = "com.microsoft.kiota.returned_client_request_id"
+
+// ClientRequestIdMismatchEventKey is the span event recorded when the client request id returned by the service doesn't match the one sent
+const ClientRequestIdMismatchEventKey = "com.microsoft.kiota.client_request_id_mismatch"
+
+const returnClientRequestIdHeaderName = "return-client-request-id"
+
+// ClientRequestIdPipelineKey is the key of the pipeline value holding the client request id of the request, so it can be read from the context of the request once the response is received
+var ClientRequestIdPipelineKey = NewPipelineKey[string]("ClientRequestId")
+
+var clientRequestIdKeyValue = abs.RequestOptionKey{
+	Key: "ClientRequestIdHandler",
+}
+
+type clientRequestIdHandlerOptionsInt interface {
+	abs.RequestOption
+	GetEnabled() bool
+	GetHeaderName() string
+	GetGenerateId() func() string
+	GetValidateReturnedId() bool
+	GetFailOnMismatch() bool
+}
+
+// NewClientRequestIdHandlerOptions creates a new ClientRequestIdHandlerOptions with the default values
+func NewClientRequestIdHandlerOptions() *ClientRequestIdHandlerOptions {
+	return &ClientRequestIdHandlerOptions{
+		Enabled:    true,
+		HeaderName: defaultClientRequestIdHeaderName,
+	}
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *ClientRequestIdHandlerOptions) GetKey() abs.RequestOptionKey {
+	return clientRequestIdKeyValue
+}
+
+// GetEnabled returns whether the client request id should be added
+func (options *ClientRequestIdHandlerOptions) GetEnabled() bool {
+	return options.Enabled
+}
+
+// GetHeaderName returns the name of the header the client request id is written to
+func (options *ClientRequestIdHandlerOptions) GetHeaderName() string {
+	if options.HeaderName == "" {
+		return defaultClientRequestIdHeaderName
+	}
+	return options.HeaderName
+}
+
+// GetGenerateId returns the callback used to generate the client request id
+func (options *ClientRequestIdHandlerOptions) GetGenerateId() func() string {
+	if options.GenerateId == nil {
+		return uuid.NewString
+	}
+	return options.GenerateId
+}
+
+// GetValidateReturnedId returns whether the client request id returned by the service should be validated
+func (options *ClientRequestIdHandlerOptions) GetValidateReturnedId() bool {
+	return options.ValidateReturnedId
+}
+
+// GetFailOnMismatch returns whether an error should be returned when the returned client request id doesn't match
+func (options *ClientRequestIdHandlerOptions) GetFailOnMismatch() bool {
+	return options.FailOnMismatch
+}
+
+// NewClientRequestIdHandler creates a new ClientRequestIdHandler with the default options
+func NewClientRequestIdHandler() *ClientRequestIdHandler {
+	return NewClientRequestIdHandlerWithOptions(*NewClientRequestIdHandlerOptions())
+}
+
+// NewClientRequestIdHandlerWithOptions creates a new ClientRequestIdHandler with the given options
+func NewClientRequestIdHandlerWithOptions(options ClientRequestIdHandlerOptions) *ClientRequestIdHandler {
+	return &ClientRequestIdHandler{options: options}
+}
+
+// Intercept implements the interface and adds the client request id to the request.
+// The id is set as the ClientRequestIdPipelineKey pipeline value and remains on the header of the request of the response.
+func (middleware ClientRequestIdHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(clientRequestIdKeyValue).(clientRequestIdHandlerOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+	if !reqOption.GetEnabled() {
+		return pipeline.Next(req, middlewareIndex)
+	}
+	headerName := reqOption.GetHeaderName()
+	clientRequestId := req.Header.Get(headerName)
+	if clientRequestId == "" {
+		clientRequestId = reqOption.GetGenerateId()()
+		req.Header.Set(headerName, clientRequestId)
+	}
+	req = SetPipelineValue(req, ClientRequestIdPipelineKey, clientRequestId)
+	if reqOption.GetValidateReturnedId() && req.Header.Get(returnClientRequestIdHeaderName) == "" {
+		req.Header.Set(returnClientRequestIdHeaderName, "true")
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	ctx := req.Context()
+	var span trace.Span
+	if obsOptions != nil {
+		ctx, span = getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "ClientRequestIdHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.client_request_id.enable", true),
+			attribute.String(ClientRequestIdAttributeName, clientRequestId))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	response, err := pipeline.Next(req, middlewareIndex)
+	if err != nil {
+		if span != nil {
+			span.RecordError(err)
+		}
+		return response, &ClientRequestIdError{ClientRequestId: clientRequestId, Err: err}
+	}
+	if response != nil && reqOption.GetValidateReturnedId() {
+		returnedId := response.Header.Get(headerName)
+		if returnedId != clientRequestId {
+			mismatchErr := &ClientRequestIdMismatchError{ClientRequestId: clientRequestId, ReturnedClientRequestId: returnedId}
+			if span != nil {
+				span.AddEvent(ClientRequestIdMismatchEventKey, trace.WithAttributes(
+					attribute.String(ClientRequestIdAttributeName, clientRequestId),
+					attribute.String(ReturnedClientRequestIdAttributeName, returnedId)))
+				span.RecordError(mismatchErr)
+				span.SetStatus(codes.Error, "client request id mismatch")
+			}
+			if reqOption.GetFailOnMismatch() {
+				if response.Body != nil {
+					response.Body.Close()
+				}
+				return nil, mismatchErr
+			}
+		}
+	}
+	return response, nil
+}
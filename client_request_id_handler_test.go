@@ -0,0 +1,122 @@
+package nethttplibrary
+
+import (
+	"errors"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestItAddsAClientRequestId(t *testing.T) {
+	var receivedId string
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		receivedId = req.Header.Get("client-request-id")
+		res.WriteHeader(500)
+		res.Write([]byte("body"))
+	}))
+	defer func() { testServer.Close() }()
+	client := GetDefaultClient(NewClientRequestIdHandler())
+	resp, err := client.Get(testServer.URL)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.NotEmpty(t, receivedId)
+	assert.Empty(t, resp.Header.Get("client-request-id"))
+	assert.Equal(t, receivedId, resp.Request.Header.Get("client-request-id"))
+	clientRequestId, ok := GetPipelineValue(resp.Request.Context(), ClientRequestIdPipelineKey)
+	assert.True(t, ok)
+	assert.Equal(t, receivedId, clientRequestId)
+}
+
+func TestItKeepsAnExistingClientRequestId(t *testing.T) {
+	options := NewClientRequestIdHandlerOptions()
+	options.HeaderName = "x-correlation-id"
+	handler := NewClientRequestIdHandlerWithOptions(*options)
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://example.com", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("x-correlation-id", "existing")
+	pipeline := newSpyPipeline()
+	_, err = handler.Intercept(pipeline, 0, req)
+	assert.Equal(t, "existing", pipeline.GetReceivedRequest().Header.Get("x-correlation-id"))
+
+	var idErr *ClientRequestIdError
+	assert.True(t, errors.As(err, &idErr))
+	assert.Equal(t, "existing", idErr.ClientRequestId)
+}
+
+func TestItValidatesTheReturnedClientRequestId(t *testing.T) {
+	provider := useTestTracerProvider(t)
+	var returnRequested string
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		returnRequested = req.Header.Get("return-client-request-id")
+		res.Header().Set("client-request-id", "other")
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	options := NewClientRequestIdHandlerOptions()
+	options.ValidateReturnedId = true
+	options.GenerateId = func() string { return "sent" }
+	client := GetDefaultClient(&observabilityOptionsHandler{}, NewClientRequestIdHandlerWithOptions(*options))
+
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "true", returnRequested)
+	spans := provider.getSpans("ClientRequestIdHandler_Intercept")
+	if assert.Equal(t, 1, len(spans)) {
+		assert.Equal(t, []string{ClientRequestIdMismatchEventKey}, spans[0].events)
+		assert.Contains(t, spans[0].eventAttributes[0], attribute.String(ReturnedClientRequestIdAttributeName, "other"))
+		assert.Equal(t, codes.Error, spans[0].status)
+	}
+
+	options.FailOnMismatch = true
+	client = GetDefaultClient(NewClientRequestIdHandlerWithOptions(*options))
+	_, err = client.Get(testServer.URL)
+	var mismatchErr *ClientRequestIdMismatchError
+	if assert.True(t, errors.As(err, &mismatchErr)) {
+		assert.Equal(t, "sent", mismatchErr.ClientRequestId)
+		assert.Equal(t, "other", mismatchErr.ReturnedClientRequestId)
+	}
+}
+
+func TestItTreatsAMissingReturnedClientRequestIdAsAMismatch(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	options := NewClientRequestIdHandlerOptions()
+	options.ValidateReturnedId = true
+	options.FailOnMismatch = true
+	options.GenerateId = func() string { return "sent" }
+	client := GetDefaultClient(NewClientRequestIdHandlerWithOptions(*options))
+
+	_, err := client.Get(testServer.URL)
+	var mismatchErr *ClientRequestIdMismatchError
+	if assert.True(t, errors.As(err, &mismatchErr)) {
+		assert.Equal(t, "sent", mismatchErr.ClientRequestId)
+		assert.Empty(t, mismatchErr.ReturnedClientRequestId)
+		assert.Contains(t, mismatchErr.Error(), "didn't return the client request id")
+	}
+}
+
+func TestItAcceptsAMatchingReturnedClientRequestId(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.Header().Set("client-request-id", req.Header.Get("client-request-id"))
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	options := NewClientRequestIdHandlerOptions()
+	options.ValidateReturnedId = true
+	options.FailOnMismatch = true
+	client := GetDefaultClient(NewClientRequestIdHandlerWithOptions(*options))
+
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+}
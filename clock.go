@@ -0,0 +1,54 @@
+package nethttplibrary
+
+import (
+	"context"
+	"time"
+)
+
+// Clock provides the current time and waits to the time based handlers, so fakes can be injected for fast and deterministic tests
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// Sleep waits for the given duration, it returns the error of the context when it's done first
+	Sleep(ctx context.Context, duration time.Duration) error
+}
+
+// systemClock is the clock of the system, used when no clock is set
+type systemClock struct{}
+
+// Now returns the current time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep waits for the given duration, it returns the error of the context when it's done first
+func (systemClock) Sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// clockOptionsInt is implemented by the options of the handlers accepting a clock
+type clockOptionsInt interface {
+	GetClock() Clock
+}
+
+// getClock returns the clock of the first options setting one, or the system clock
+func getClock(options ...any) Clock {
+	for _, option := range options {
+		if clockOptions, ok := option.(clockOptionsInt); ok {
+			if clock := clockOptions.GetClock(); clock != nil {
+				return clock
+			}
+		}
+	}
+	return systemClock{}
+}
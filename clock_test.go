@@ -0,0 +1,65 @@
+package nethttplibrary
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock records the sleeps and advances its time instead of waiting
+type fakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, duration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.sleeps = append(c.sleeps, duration)
+	if duration > 0 {
+		c.now = c.now.Add(duration)
+	}
+	return nil
+}
+
+func (c *fakeClock) getSleeps() []time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]time.Duration(nil), c.sleeps...)
+}
+
+func TestItSleepsOnTheSystemClockUntilTheContextIsDone(t *testing.T) {
+	clock := getClock(&RetryHandlerOptions{})
+	assert.Equal(t, systemClock{}, clock)
+	assert.Nil(t, clock.Sleep(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.ErrorIs(t, clock.Sleep(ctx, time.Minute), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestItUsesTheClockOfTheFirstOptionsSettingOne(t *testing.T) {
+	fake := newFakeClock()
+	assert.Equal(t, fake, getClock(&RetryHandlerOptions{}, &RetryHandlerOptions{Clock: fake}))
+	assert.Equal(t, fake, getClock(&RateLimitingHandlerOptions{Clock: fake}))
+	assert.Equal(t, systemClock{}, getClock(nil, "not options"))
+}
@@ -8,7 +8,6 @@ import (
 	"strings"
 
 	abstractions "github.com/microsoft/kiota-abstractions-go"
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -69,12 +68,16 @@ func (c *CompressionHandler) Intercept(pipeline Pipeline, middlewareIndex int, r
 	ctx := req.Context()
 	var span trace.Span
 	if obsOptions != nil {
-		ctx, span = otel.GetTracerProvider().Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "CompressionHandler_Intercept")
+		ctx, span = getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "CompressionHandler_Intercept")
 		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.compression.enable", true))
 		defer span.End()
 		req = req.WithContext(ctx)
 	}
 
+	if reqOption.ShouldCompress() {
+		req = RegisterFeatureUsage(req, CompressionHandlerEnabledFeatureUsageFlag)
+	}
+
 	if !reqOption.ShouldCompress() || contentRangeBytesIsPresent(req.Header) || contentEncodingIsPresent(req.Header) || req.Body == nil {
 		return pipeline.Next(req, middlewareIndex)
 	}
@@ -102,10 +105,17 @@ func (c *CompressionHandler) Intercept(pipeline Pipeline, middlewareIndex int, r
 	req.Header.Set("Content-Encoding", "gzip")
 	req.Body = compressedBody
 	req.ContentLength = int64(size)
+	req.GetBody = func() (io.ReadCloser, error) {
+		body, _, err := compressReqBody(unCompressedBody)
+		return body, err
+	}
 
 	if span != nil {
 		span.SetAttributes(httpRequestBodySizeAttribute.Int(int(req.ContentLength)))
 	}
+	if obsOptions != nil {
+		recordCompressionRatio(ctx, obsOptions.GetTracerInstrumentationName(), req, len(unCompressedBody), size)
+	}
 
 	// Sending request with compressed body
 	resp, err := pipeline.Next(req, middlewareIndex)
@@ -118,6 +128,9 @@ func (c *CompressionHandler) Intercept(pipeline Pipeline, middlewareIndex int, r
 		delete(req.Header, "Content-Encoding")
 		req.Body = io.NopCloser(bytes.NewBuffer(unCompressedBody))
 		req.ContentLength = unCompressedContentLength
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(unCompressedBody)), nil
+		}
 
 		if span != nil {
 			span.SetAttributes(httpRequestBodySizeAttribute.Int(int(req.ContentLength)),
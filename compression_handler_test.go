@@ -78,7 +78,9 @@ func TestCompressionHandlerCompressesRequestBodyWithRetry(t *testing.T) {
 	}))
 	defer testServer.Close()
 
-	client := GetDefaultClient(NewCompressionHandler(), NewRetryHandler())
+	retryHandler := NewRetryHandler()
+	retryHandler.options.Clock = newFakeClock()
+	client := GetDefaultClient(NewCompressionHandler(), retryHandler)
 	_, err := client.Post(testServer.URL, "application/json", bytes.NewBuffer(postBody))
 
 	assert.NotZero(t, len(compressedBody))
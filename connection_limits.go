@@ -0,0 +1,81 @@
+package nethttplibrary
+
+import (
+	"errors"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+)
+
+// ConnectionLimitsOptions to use when limiting the number of connections per host.
+type ConnectionLimitsOptions struct {
+	// MaxConnsPerHost limits the total number of connections per host, 0 means no limit
+	MaxConnsPerHost int
+	// PerHost overrides MaxConnsPerHost for the given host names (without port), the connections to these hosts are pooled separately
+	PerHost map[string]int
+}
+
+// connectionLimitedTransport sends the requests to the hosts with an overridden limit through dedicated transports
+// so a single greedy host cannot exhaust the connection pool used by the other hosts.
+type connectionLimitedTransport struct {
+	transport *nethttp.Transport
+	perHost   map[string]*nethttp.Transport
+}
+
+// NewConnectionLimitedTransport returns a round tripper based on clones of the transport limiting the number of connections per host
+func NewConnectionLimitedTransport(transport *nethttp.Transport, options ConnectionLimitsOptions) (nethttp.RoundTripper, error) {
+	if transport == nil {
+		return nil, errors.New("transport cannot be nil")
+	}
+	if options.MaxConnsPerHost < 0 {
+		return nil, errors.New("max connections per host cannot be negative")
+	}
+	transport = transport.Clone()
+	transport.MaxConnsPerHost = options.MaxConnsPerHost
+	if len(options.PerHost) == 0 {
+		return transport, nil
+	}
+	perHost := make(map[string]*nethttp.Transport, len(options.PerHost))
+	for host, maxConns := range options.PerHost {
+		if maxConns < 0 {
+			return nil, errors.New("max connections cannot be negative for host " + host)
+		}
+		hostTransport := transport.Clone()
+		hostTransport.MaxConnsPerHost = maxConns
+		perHost[strings.ToLower(host)] = hostTransport
+	}
+	return &connectionLimitedTransport{
+		transport: transport,
+		perHost:   perHost,
+	}, nil
+}
+
+// RoundTrip sends the request through the transport of its host
+func (transport *connectionLimitedTransport) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	if hostTransport, ok := transport.perHost[strings.ToLower(req.URL.Hostname())]; ok {
+		return hostTransport.RoundTrip(req)
+	}
+	return transport.transport.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of all the transports
+func (transport *connectionLimitedTransport) CloseIdleConnections() {
+	transport.transport.CloseIdleConnections()
+	for _, hostTransport := range transport.perHost {
+		hostTransport.CloseIdleConnections()
+	}
+}
+
+// withProxy returns a copy of the transports sending the requests through the proxy
+func (transport *connectionLimitedTransport) withProxy(proxy func(*nethttp.Request) (*url.URL, error)) (nethttp.RoundTripper, error) {
+	result := &connectionLimitedTransport{
+		transport: transport.transport.Clone(),
+		perHost:   make(map[string]*nethttp.Transport, len(transport.perHost)),
+	}
+	result.transport.Proxy = proxy
+	for host, hostTransport := range transport.perHost {
+		result.perHost[host] = hostTransport.Clone()
+		result.perHost[host].Proxy = proxy
+	}
+	return result, nil
+}
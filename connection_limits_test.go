@@ -0,0 +1,73 @@
+package nethttplibrary
+
+import (
+	"net"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItLimitsTheConnectionsPerHost(t *testing.T) {
+	var mutex sync.Mutex
+	active, maxActive := 0, 0
+	testServer := httptest.NewUnstartedServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		time.Sleep(20 * time.Millisecond)
+		res.WriteHeader(200)
+	}))
+	testServer.Config.ConnState = func(conn net.Conn, state nethttp.ConnState) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch state {
+		case nethttp.StateNew:
+			active++
+			if active > maxActive {
+				maxActive = active
+			}
+		case nethttp.StateClosed, nethttp.StateHijacked:
+			active--
+		}
+	}
+	testServer.Start()
+	defer testServer.Close()
+	serverUrl, _ := url.Parse(testServer.URL)
+
+	client, err := NewKiotaClientBuilder().
+		WithMaxConnsPerHost(10).
+		WithMaxConnsForHost("LOCALHOST", 1).
+		WithMiddleware(NewHeadersInspectionHandler()).
+		Build()
+	assert.Nil(t, err)
+	limitedTransport, ok := client.Transport.(*customTransport).middlewarePipeline.transport.(*connectionLimitedTransport)
+	assert.True(t, ok)
+	assert.Equal(t, 10, limitedTransport.transport.MaxConnsPerHost)
+	assert.Equal(t, 1, limitedTransport.perHost["localhost"].MaxConnsPerHost)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get("http://localhost:" + serverUrl.Port())
+			assert.Nil(t, err)
+			assert.Equal(t, 200, resp.StatusCode)
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, maxActive)
+}
+
+func TestNewConnectionLimitedTransportValidatesTheOptions(t *testing.T) {
+	transport, err := NewConnectionLimitedTransport(&nethttp.Transport{}, ConnectionLimitsOptions{MaxConnsPerHost: 4})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, transport.(*nethttp.Transport).MaxConnsPerHost)
+	_, err = NewConnectionLimitedTransport(nil, ConnectionLimitsOptions{})
+	assert.Error(t, err)
+	_, err = NewConnectionLimitedTransport(&nethttp.Transport{}, ConnectionLimitsOptions{PerHost: map[string]int{"contoso.com": -1}})
+	assert.Error(t, err)
+}
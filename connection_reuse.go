@@ -0,0 +1,75 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ConnectionReuseOptions is a request option controlling whether the connection used to send the request is kept alive,
+// e.g. to close the connection after a large download or while troubleshooting without reconfiguring the shared transport.
+type ConnectionReuseOptions struct {
+	// DisableKeepAlive sends the request with the Connection: close header and closes the connection once the response was read
+	DisableKeepAlive bool
+}
+
+var connectionReuseKeyValue = abs.RequestOptionKey{
+	Key: "ConnectionReuse",
+}
+
+type connectionReuseOptionsInt interface {
+	abs.RequestOption
+	GetDisableKeepAlive() bool
+}
+
+// NewConnectionReuseOptions creates a new ConnectionReuseOptions
+func NewConnectionReuseOptions(disableKeepAlive bool) *ConnectionReuseOptions {
+	return &ConnectionReuseOptions{DisableKeepAlive: disableKeepAlive}
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *ConnectionReuseOptions) GetKey() abs.RequestOptionKey {
+	return connectionReuseKeyValue
+}
+
+// GetDisableKeepAlive returns whether the connection is closed once the response was read
+func (options *ConnectionReuseOptions) GetDisableKeepAlive() bool {
+	return options.DisableKeepAlive
+}
+
+// ConnectionReuseHandler closes the connections of the requests once their response was read when the ConnectionReuseOptions disable keep-alive.
+type ConnectionReuseHandler struct {
+	options ConnectionReuseOptions
+}
+
+// NewConnectionReuseHandler creates a new ConnectionReuseHandler keeping the connections alive unless the request options disable it
+func NewConnectionReuseHandler() *ConnectionReuseHandler {
+	return NewConnectionReuseHandlerWithOptions(ConnectionReuseOptions{})
+}
+
+// NewConnectionReuseHandlerWithOptions creates a new ConnectionReuseHandler with the given options
+func NewConnectionReuseHandlerWithOptions(options ConnectionReuseOptions) *ConnectionReuseHandler {
+	return &ConnectionReuseHandler{options: options}
+}
+
+// Intercept implements the interface and sends the request with the Connection: close header when keep-alive is disabled.
+func (middleware ConnectionReuseHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(connectionReuseKeyValue).(connectionReuseOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	if obsOptions != nil {
+		ctx, span := getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(req.Context(), "ConnectionReuseHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.connection_reuse.disable_keep_alive", reqOption.GetDisableKeepAlive()))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	if reqOption.GetDisableKeepAlive() && !req.Close {
+		// the middlewares must not modify the request of the caller
+		req = req.WithContext(req.Context())
+		req.Close = true
+	}
+	return pipeline.Next(req, middlewareIndex)
+}
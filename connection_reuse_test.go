@@ -0,0 +1,61 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"net/url"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItClosesTheConnectionWhenKeepAliveIsDisabled(t *testing.T) {
+	var receivedClose []bool
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		receivedClose = append(receivedClose, req.Close)
+		res.WriteHeader(204)
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.Nil(t, err)
+	uri, err := url.Parse(testServer.URL)
+	assert.Nil(t, err)
+
+	request := abs.NewRequestInformation()
+	request.SetUri(*uri)
+	request.Method = abs.GET
+	request.AddRequestOptions([]abs.RequestOption{NewConnectionReuseOptions(true)})
+	err = adapter.SendNoContent(context.Background(), request, nil)
+	assert.Nil(t, err)
+
+	request = abs.NewRequestInformation()
+	request.SetUri(*uri)
+	request.Method = abs.GET
+	err = adapter.SendNoContent(context.Background(), request, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []bool{true, false}, receivedClose)
+}
+
+func TestItDoesntModifyTheRequestWhenKeepAliveIsEnabled(t *testing.T) {
+	handler := NewConnectionReuseHandler()
+	pipeline := newSpyPipeline()
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://localhost", nil)
+	assert.Nil(t, err)
+	_, _ = handler.Intercept(pipeline, 0, req)
+	assert.Same(t, req, pipeline.GetReceivedRequest())
+
+	req = req.WithContext(context.WithValue(req.Context(), connectionReuseKeyValue, NewConnectionReuseOptions(false)))
+	_, _ = handler.Intercept(pipeline, 0, req)
+	assert.Same(t, req, pipeline.GetReceivedRequest())
+
+	req = req.WithContext(context.WithValue(req.Context(), connectionReuseKeyValue, NewConnectionReuseOptions(true)))
+	_, _ = handler.Intercept(pipeline, 0, req)
+	assert.True(t, pipeline.GetReceivedRequest().Close)
+	assert.False(t, req.Close)
+
+	_, _ = NewConnectionReuseHandlerWithOptions(ConnectionReuseOptions{DisableKeepAlive: true}).Intercept(pipeline, 0, req.WithContext(context.Background()))
+	assert.True(t, pipeline.GetReceivedRequest().Close)
+}
@@ -0,0 +1,194 @@
+package nethttplibrary
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	dnsLookupSpanName       = "dns_lookup"
+	connectSpanName         = "connect"
+	tlsHandshakeSpanName    = "tls_handshake"
+	timeToFirstByteSpanName = "time_to_first_byte"
+)
+
+var (
+	connectionReusedAttribute      = attribute.Key("com.microsoft.kiota.connection.reused")
+	connectionWasIdleAttribute     = attribute.Key("com.microsoft.kiota.connection.was_idle")
+	dnsHostAttribute               = attribute.Key("com.microsoft.kiota.dns.host")
+	dnsAddressesAttribute          = attribute.Key("com.microsoft.kiota.dns.addresses")
+	networkTransportAttribute      = attribute.Key("network.transport")
+	networkPeerAddressAttribute    = attribute.Key("network.peer.address")
+	tlsProtocolVersionAttribute    = attribute.Key("tls.protocol.version")
+	tlsCipherAttribute             = attribute.Key("tls.cipher")
+	tlsResumedAttribute            = attribute.Key("tls.resumed")
+	tlsNegotiatedProtocolAttribute = attribute.Key("tls.next_protocol")
+)
+
+// connectionTracer creates child spans of the transport span for each phase of the connection (DNS lookup, TCP connect, TLS handshake and time to first byte).
+// The callbacks of the client trace can be invoked concurrently (e.g. when dialing multiple addresses), hence the lock.
+type connectionTracer struct {
+	ctx          context.Context
+	tracer       trace.Tracer
+	mutex        sync.Mutex
+	dnsSpan      trace.Span
+	connectSpans map[string]trace.Span
+	tlsSpan      trace.Span
+	ttfbSpan     trace.Span
+}
+
+// withConnectionTrace returns a context carrying a client trace which records the connection phases as child spans of the span in the given context,
+// and a function ending the spans left open when the request fails before completing all the phases
+func withConnectionTrace(ctx context.Context, tracer trace.Tracer) (context.Context, func(err error)) {
+	connTracer := &connectionTracer{
+		ctx:          ctx,
+		tracer:       tracer,
+		connectSpans: make(map[string]trace.Span),
+	}
+	return httptrace.WithClientTrace(ctx, connTracer.clientTrace()), connTracer.end
+}
+
+func (c *connectionTracer) end(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for key, span := range c.connectSpans {
+		endSpan(span, err)
+		delete(c.connectSpans, key)
+	}
+	for _, span := range []trace.Span{c.dnsSpan, c.tlsSpan, c.ttfbSpan} {
+		endSpan(span, err)
+	}
+	c.dnsSpan, c.tlsSpan, c.ttfbSpan = nil, nil, nil
+}
+
+func (c *connectionTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:             c.dnsStart,
+		DNSDone:              c.dnsDone,
+		ConnectStart:         c.connectStart,
+		ConnectDone:          c.connectDone,
+		TLSHandshakeStart:    c.tlsHandshakeStart,
+		TLSHandshakeDone:     c.tlsHandshakeDone,
+		GotConn:              c.gotConn,
+		WroteRequest:         c.wroteRequest,
+		GotFirstResponseByte: c.gotFirstResponseByte,
+	}
+}
+
+func (c *connectionTracer) startSpan(name string, attributes ...attribute.KeyValue) trace.Span {
+	_, span := c.tracer.Start(c.ctx, name, trace.WithAttributes(attributes...))
+	return span
+}
+
+func endSpan(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (c *connectionTracer) dnsStart(info httptrace.DNSStartInfo) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.dnsSpan = c.startSpan(dnsLookupSpanName, dnsHostAttribute.String(info.Host))
+}
+
+func (c *connectionTracer) dnsDone(info httptrace.DNSDoneInfo) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.dnsSpan == nil {
+		return
+	}
+	addresses := make([]string, len(info.Addrs))
+	for i, address := range info.Addrs {
+		addresses[i] = address.String()
+	}
+	c.dnsSpan.SetAttributes(dnsAddressesAttribute.StringSlice(addresses))
+	endSpan(c.dnsSpan, info.Err)
+	c.dnsSpan = nil
+}
+
+func (c *connectionTracer) connectStart(network, addr string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.connectSpans[network+addr] = c.startSpan(connectSpanName, networkTransportAttribute.String(network), networkPeerAddressAttribute.String(addr))
+}
+
+func (c *connectionTracer) connectDone(network, addr string, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	endSpan(c.connectSpans[network+addr], err)
+	delete(c.connectSpans, network+addr)
+}
+
+func (c *connectionTracer) tlsHandshakeStart() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.tlsSpan = c.startSpan(tlsHandshakeSpanName)
+}
+
+func (c *connectionTracer) tlsHandshakeDone(state tls.ConnectionState, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.tlsSpan == nil {
+		return
+	}
+	if err == nil {
+		c.tlsSpan.SetAttributes(
+			tlsProtocolVersionAttribute.String(getTlsVersionName(state.Version)),
+			tlsCipherAttribute.String(tls.CipherSuiteName(state.CipherSuite)),
+			tlsResumedAttribute.Bool(state.DidResume),
+			tlsNegotiatedProtocolAttribute.String(state.NegotiatedProtocol),
+		)
+	}
+	endSpan(c.tlsSpan, err)
+	c.tlsSpan = nil
+}
+
+func (c *connectionTracer) gotConn(info httptrace.GotConnInfo) {
+	trace.SpanFromContext(c.ctx).SetAttributes(
+		connectionReusedAttribute.Bool(info.Reused),
+		connectionWasIdleAttribute.Bool(info.WasIdle),
+	)
+}
+
+func (c *connectionTracer) wroteRequest(info httptrace.WroteRequestInfo) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if info.Err != nil {
+		return
+	}
+	c.ttfbSpan = c.startSpan(timeToFirstByteSpanName)
+}
+
+func (c *connectionTracer) gotFirstResponseByte() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	endSpan(c.ttfbSpan, nil)
+	c.ttfbSpan = nil
+}
+
+func getTlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "1.0"
+	case tls.VersionTLS11:
+		return "1.1"
+	case tls.VersionTLS12:
+		return "1.2"
+	case tls.VersionTLS13:
+		return "1.3"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,201 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// testTracerProvider records the spans started by its tracers
+type testTracerProvider struct {
+	noop.TracerProvider
+	mutex sync.Mutex
+	spans []*testSpan
+}
+
+type testTracer struct {
+	noop.Tracer
+	provider *testTracerProvider
+	name     string
+}
+
+type testSpan struct {
+	noop.Span
+	provider   *testTracerProvider
+	name       string
+	tracerName string
+	parent     *testSpan
+	links      []trace.Link
+	attributes map[attribute.Key]attribute.Value
+	events     []string
+	// the attributes of the events, in the same order as the events
+	eventAttributes [][]attribute.KeyValue
+	status          codes.Code
+	ended           bool
+	context         trace.SpanContext
+}
+
+// useTestTracerProvider sets a new test tracer provider as the global one until the end of the test
+func useTestTracerProvider(t *testing.T) *testTracerProvider {
+	provider := &testTracerProvider{}
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return provider
+}
+
+func (p *testTracerProvider) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
+	return &testTracer{provider: p, name: name}
+}
+
+// getSpans returns the spans with the given name
+func (p *testTracerProvider) getSpans(name string) []*testSpan {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	result := make([]*testSpan, 0)
+	for _, span := range p.spans {
+		if span.name == name {
+			result = append(result, span)
+		}
+	}
+	return result
+}
+
+func (t *testTracer) Start(ctx context.Context, spanName string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(options...)
+	t.provider.mutex.Lock()
+	defer t.provider.mutex.Unlock()
+	span := &testSpan{
+		provider:   t.provider,
+		name:       spanName,
+		tracerName: t.name,
+		links:      config.Links(),
+		attributes: make(map[attribute.Key]attribute.Value),
+		context: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{byte(len(t.provider.spans) + 1)},
+			TraceFlags: trace.FlagsSampled,
+		}),
+	}
+	if parent, ok := trace.SpanFromContext(ctx).(*testSpan); ok {
+		span.parent = parent
+	}
+	for _, kv := range config.Attributes() {
+		span.attributes[kv.Key] = kv.Value
+	}
+	t.provider.spans = append(t.provider.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func (s *testSpan) SpanContext() trace.SpanContext { return s.context }
+
+func (s *testSpan) IsRecording() bool { return true }
+
+func (s *testSpan) SetName(name string) {
+	s.provider.mutex.Lock()
+	defer s.provider.mutex.Unlock()
+	s.name = name
+}
+
+func (s *testSpan) SetAttributes(attributes ...attribute.KeyValue) {
+	s.provider.mutex.Lock()
+	defer s.provider.mutex.Unlock()
+	for _, kv := range attributes {
+		s.attributes[kv.Key] = kv.Value
+	}
+}
+
+func (s *testSpan) AddEvent(name string, options ...trace.EventOption) {
+	s.provider.mutex.Lock()
+	defer s.provider.mutex.Unlock()
+	s.events = append(s.events, name)
+	config := trace.NewEventConfig(options...)
+	s.eventAttributes = append(s.eventAttributes, config.Attributes())
+}
+
+func (s *testSpan) SetStatus(code codes.Code, description string) {
+	s.provider.mutex.Lock()
+	defer s.provider.mutex.Unlock()
+	s.status = code
+}
+
+func (s *testSpan) End(options ...trace.SpanEndOption) {
+	s.provider.mutex.Lock()
+	defer s.provider.mutex.Unlock()
+	s.ended = true
+}
+
+func (s *testSpan) getAttribute(key attribute.Key) (attribute.Value, bool) {
+	s.provider.mutex.Lock()
+	defer s.provider.mutex.Unlock()
+	value, ok := s.attributes[key]
+	return value, ok
+}
+
+func TestItCreatesConnectionSpans(t *testing.T) {
+	provider := useTestTracerProvider(t)
+	testServer := httptest.NewTLSServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	client := GetDefaultClient(&observabilityOptionsHandler{options: ObservabilityOptions{IncludeConnectionSpans: true}}, NewNetworkObservabilityHandler())
+	client.Transport.(*customTransport).middlewarePipeline.transport = testServer.Client().Transport
+
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	transportSpans := provider.getSpans("request_transport")
+	assert.Equal(t, 1, len(transportSpans))
+	for _, name := range []string{connectSpanName, tlsHandshakeSpanName, timeToFirstByteSpanName} {
+		spans := provider.getSpans(name)
+		if assert.Equal(t, 1, len(spans), name) {
+			assert.Equal(t, transportSpans[0], spans[0].parent)
+			assert.True(t, spans[0].ended)
+		}
+	}
+	version, _ := provider.getSpans(tlsHandshakeSpanName)[0].getAttribute(tlsProtocolVersionAttribute)
+	assert.Equal(t, "1.3", version.AsString())
+	reused, ok := transportSpans[0].getAttribute(connectionReusedAttribute)
+	assert.True(t, ok)
+	assert.False(t, reused.AsBool())
+}
+
+func TestItEndsConnectionSpansOnFailure(t *testing.T) {
+	provider := useTestTracerProvider(t)
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {}))
+	url := testServer.URL
+	testServer.Close()
+	client := GetDefaultClient(&observabilityOptionsHandler{options: ObservabilityOptions{IncludeConnectionSpans: true}}, NewNetworkObservabilityHandler())
+
+	_, err := client.Get(url)
+	assert.Error(t, err)
+	spans := provider.getSpans(connectSpanName)
+	if assert.Equal(t, 1, len(spans)) {
+		assert.True(t, spans[0].ended)
+		assert.Equal(t, codes.Error, spans[0].status)
+	}
+}
+
+func TestItDoesNotCreateConnectionSpansByDefault(t *testing.T) {
+	provider := useTestTracerProvider(t)
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	client := GetDefaultClient(&observabilityOptionsHandler{}, NewNetworkObservabilityHandler())
+
+	_, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(provider.getSpans("request_transport")))
+	assert.Empty(t, provider.getSpans(connectSpanName))
+}
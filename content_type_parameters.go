@@ -0,0 +1,67 @@
+package nethttplibrary
+
+import (
+	"mime"
+	"regexp"
+	"strings"
+
+	absser "github.com/microsoft/kiota-abstractions-go/serialization"
+)
+
+// ParameterizedParseNodeFactory is implemented by the parse node factories taking the parameters of the response media type into account
+// (e.g. odata.metadata or profile), the parameters are dropped when calling GetRootParseNode.
+type ParameterizedParseNodeFactory interface {
+	absser.ParseNodeFactory
+	// GetRootParseNodeWithParameters returns the root parse node of the content of the given media type and parameters
+	GetRootParseNodeWithParameters(mediaType string, parameters map[string]string, content []byte) (absser.ParseNode, error)
+}
+
+var vendorMediaTypeCleanupPattern = regexp.MustCompile(`[^/]+\+`)
+
+// parseContentType returns the lower case media type and the parameters of the content type
+func parseContentType(contentType string) (string, map[string]string) {
+	mediaType, parameters, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0])), nil
+	}
+	return mediaType, parameters
+}
+
+// getRootParseNodeWithParameters returns the root parse node of the content from the factory registered for the media type and its parameters.
+// The factories of a registry can be registered for a media type with parameters formatted by mime.FormatMediaType (e.g. "application/json; odata.metadata=full"),
+// they are preferred to the factories registered for the media type alone.
+func getRootParseNodeWithParameters(factory absser.ParseNodeFactory, mediaType string, parameters map[string]string, content []byte) (absser.ParseNode, error) {
+	if len(parameters) == 0 {
+		return factory.GetRootParseNode(mediaType, content)
+	}
+	if registry, ok := factory.(*absser.ParseNodeFactoryRegistry); ok {
+		if registered, registeredType := getRegisteredParseNodeFactory(registry, mediaType, parameters); registered != nil {
+			factory = registered
+			mediaType = registeredType
+		}
+	}
+	if parameterized, ok := factory.(ParameterizedParseNodeFactory); ok {
+		return parameterized.GetRootParseNodeWithParameters(mediaType, parameters, content)
+	}
+	return factory.GetRootParseNode(mediaType, content)
+}
+
+// getRegisteredParseNodeFactory returns the factory registered for the media type with its parameters, for the media type, or for the media type without vendor prefix
+func getRegisteredParseNodeFactory(registry *absser.ParseNodeFactoryRegistry, mediaType string, parameters map[string]string) (absser.ParseNodeFactory, string) {
+	registry.Lock()
+	defer registry.Unlock()
+	candidates := []string{mediaType, vendorMediaTypeCleanupPattern.ReplaceAllString(mediaType, "")}
+	for _, candidate := range candidates {
+		if withParameters := mime.FormatMediaType(candidate, parameters); withParameters != "" {
+			if factory, ok := registry.ContentTypeAssociatedFactories[withParameters]; ok {
+				return factory, candidate
+			}
+		}
+	}
+	for _, candidate := range candidates {
+		if factory, ok := registry.ContentTypeAssociatedFactories[candidate]; ok {
+			return factory, candidate
+		}
+	}
+	return nil, ""
+}
@@ -0,0 +1,67 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	absser "github.com/microsoft/kiota-abstractions-go/serialization"
+	"github.com/microsoft/kiota-http-go/testingutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type testParameterizedParseNodeFactory struct {
+	testingutil.MockParseNodeFactory
+	mediaType  string
+	parameters map[string]string
+}
+
+func (f *testParameterizedParseNodeFactory) GetRootParseNodeWithParameters(mediaType string, parameters map[string]string, content []byte) (absser.ParseNode, error) {
+	f.mediaType = mediaType
+	f.parameters = parameters
+	return &testingutil.MockParseNode{}, nil
+}
+
+func TestItPassesTheMediaTypeParametersToTheParseNodeFactory(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.Header().Set("Content-Type", "application/vnd.contoso+json; odata.metadata=full; charset=utf-8")
+		res.WriteHeader(200)
+		res.Write([]byte(`{}`))
+	}))
+	defer testServer.Close()
+	factory := &testParameterizedParseNodeFactory{}
+	registry := absser.NewParseNodeFactoryRegistry()
+	registry.ContentTypeAssociatedFactories["application/json"] = factory
+	adapter, err := NewNetHttpRequestAdapterWithParseNodeFactory(&absauth.AnonymousAuthenticationProvider{}, registry)
+	assert.Nil(t, err)
+	adapter.SetBaseUrl(testServer.URL)
+	requestInfo := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/items", map[string]string{})
+
+	result, err := adapter.Send(context.Background(), requestInfo, testingutil.MockEntityFactory, nil)
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, "application/json", factory.mediaType)
+	assert.Equal(t, map[string]string{"odata.metadata": "full", "charset": "utf-8"}, factory.parameters)
+}
+
+func TestItPrefersTheFactoriesRegisteredWithParameters(t *testing.T) {
+	registry := absser.NewParseNodeFactoryRegistry()
+	plain := &testParameterizedParseNodeFactory{}
+	full := &testParameterizedParseNodeFactory{}
+	registry.ContentTypeAssociatedFactories["application/json"] = plain
+	registry.ContentTypeAssociatedFactories["application/json; odata.metadata=full"] = full
+
+	mediaType, parameters := parseContentType("Application/JSON;odata.metadata=full")
+	_, err := getRootParseNodeWithParameters(registry, mediaType, parameters, []byte(`{}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "application/json", full.mediaType)
+	assert.Nil(t, plain.parameters)
+
+	mediaType, parameters = parseContentType("application/json; odata.metadata=minimal")
+	_, err = getRootParseNodeWithParameters(registry, mediaType, parameters, []byte(`{}`))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"odata.metadata": "minimal"}, plain.parameters)
+}
@@ -0,0 +1,109 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+)
+
+// ContextPipeline is the pipeline contract for context aware middlewares
+type ContextPipeline interface {
+	// Next moves the request object through the next middlewares in the pipeline with the given context.
+	// It returns the context returned by the next context aware middleware, or the given context when there is none.
+	Next(ctx context.Context, req *nethttp.Request, middlewareIndex int) (context.Context, *nethttp.Response, error)
+}
+
+// ContextMiddleware is a middleware receiving the context of the request explicitly.
+// The context it returns is handed back to the previous context aware middlewares, e.g. to expose values computed while sending the request.
+// Use NewMiddlewareFromContextMiddleware to add it to a middleware pipeline.
+type ContextMiddleware interface {
+	// Intercept intercepts the request and returns the response. The implementer MUST call pipeline.Next()
+	Intercept(ctx context.Context, pipeline ContextPipeline, middlewareIndex int, req *nethttp.Request) (context.Context, *nethttp.Response, error)
+}
+
+// ContextMiddlewareFunc is an adapter to use a function as a ContextMiddleware
+type ContextMiddlewareFunc func(ctx context.Context, pipeline ContextPipeline, middlewareIndex int, req *nethttp.Request) (context.Context, *nethttp.Response, error)
+
+// Intercept calls the function
+func (f ContextMiddlewareFunc) Intercept(ctx context.Context, pipeline ContextPipeline, middlewareIndex int, req *nethttp.Request) (context.Context, *nethttp.Response, error) {
+	return f(ctx, pipeline, middlewareIndex, req)
+}
+
+// returnedContextHolder carries the context returned by a context aware middleware through the middlewares which aren't
+type returnedContextHolder struct {
+	ctx context.Context
+}
+
+type returnedContextHolderKey struct{}
+
+// contextPipeline adapts a Pipeline to the ContextPipeline contract
+type contextPipeline struct {
+	pipeline Pipeline
+}
+
+// Next sets the context on the request and moves it through the next middlewares
+func (p *contextPipeline) Next(ctx context.Context, req *nethttp.Request, middlewareIndex int) (context.Context, *nethttp.Response, error) {
+	if ctx == nil {
+		ctx = req.Context()
+	}
+	holder := &returnedContextHolder{ctx: ctx}
+	resp, err := p.pipeline.Next(req.WithContext(context.WithValue(ctx, returnedContextHolderKey{}, holder)), middlewareIndex)
+	return holder.ctx, resp, err
+}
+
+// contextMiddlewareAdapter adapts a ContextMiddleware to the Middleware contract
+type contextMiddlewareAdapter struct {
+	middleware ContextMiddleware
+}
+
+// NewMiddlewareFromContextMiddleware adapts a context aware middleware so it can be added to a middleware pipeline
+func NewMiddlewareFromContextMiddleware(middleware ContextMiddleware) Middleware {
+	if adapter, ok := middleware.(*middlewareContextAdapter); ok {
+		return adapter.middleware
+	}
+	return &contextMiddlewareAdapter{middleware: middleware}
+}
+
+// Intercept implements the interface and calls the context aware middleware with the context of the request
+func (adapter *contextMiddlewareAdapter) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	ctx, resp, err := adapter.middleware.Intercept(req.Context(), &contextPipeline{pipeline: pipeline}, middlewareIndex, req)
+	if holder, ok := req.Context().Value(returnedContextHolderKey{}).(*returnedContextHolder); ok && ctx != nil {
+		holder.ctx = ctx
+	}
+	return resp, err
+}
+
+// middlewareContextAdapter adapts a Middleware to the ContextMiddleware contract
+type middlewareContextAdapter struct {
+	middleware Middleware
+}
+
+// NewContextMiddlewareFromMiddleware adapts a middleware to the context aware contract, the context of the request is the one given to Intercept
+func NewContextMiddlewareFromMiddleware(middleware Middleware) ContextMiddleware {
+	if adapter, ok := middleware.(*contextMiddlewareAdapter); ok {
+		return adapter.middleware
+	}
+	return &middlewareContextAdapter{middleware: middleware}
+}
+
+// pipelineFromContextPipeline adapts a ContextPipeline to the Pipeline contract
+type pipelineFromContextPipeline struct {
+	pipeline ContextPipeline
+	ctx      context.Context
+}
+
+// Next moves the request object through the next middlewares with the context of the request
+func (p *pipelineFromContextPipeline) Next(req *nethttp.Request, middlewareIndex int) (*nethttp.Response, error) {
+	ctx, resp, err := p.pipeline.Next(req.Context(), req, middlewareIndex)
+	p.ctx = ctx
+	return resp, err
+}
+
+// Intercept implements the interface and calls the middleware with the request bound to the given context
+func (adapter *middlewareContextAdapter) Intercept(ctx context.Context, pipeline ContextPipeline, middlewareIndex int, req *nethttp.Request) (context.Context, *nethttp.Response, error) {
+	if ctx != nil && ctx != req.Context() {
+		req = req.WithContext(ctx)
+	}
+	legacyPipeline := &pipelineFromContextPipeline{pipeline: pipeline, ctx: req.Context()}
+	resp, err := adapter.middleware.Intercept(legacyPipeline, middlewareIndex, req)
+	return legacyPipeline.ctx, resp, err
+}
@@ -0,0 +1,55 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testContextKey struct {
+	name string
+}
+
+func TestContextMiddlewaresShareContexts(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.Header().Set("X-Tenant", req.Header.Get("X-Tenant"))
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	var returnedEndpoint interface{}
+	outer := ContextMiddlewareFunc(func(ctx context.Context, pipeline ContextPipeline, middlewareIndex int, req *nethttp.Request) (context.Context, *nethttp.Response, error) {
+		ctx = context.WithValue(ctx, testContextKey{"tenant"}, "contoso")
+		ctx, resp, err := pipeline.Next(ctx, req, middlewareIndex)
+		returnedEndpoint = ctx.Value(testContextKey{"endpoint"})
+		return ctx, resp, err
+	})
+	inner := ContextMiddlewareFunc(func(ctx context.Context, pipeline ContextPipeline, middlewareIndex int, req *nethttp.Request) (context.Context, *nethttp.Response, error) {
+		req.Header.Set("X-Tenant", ctx.Value(testContextKey{"tenant"}).(string))
+		ctx, resp, err := pipeline.Next(ctx, req, middlewareIndex)
+		return context.WithValue(ctx, testContextKey{"endpoint"}, req.URL.Host), resp, err
+	})
+	client := GetDefaultClient(
+		NewMiddlewareFromContextMiddleware(outer),
+		NewHeadersInspectionHandler(),
+		NewMiddlewareFromContextMiddleware(inner),
+	)
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, "contoso", resp.Header.Get("X-Tenant"))
+	assert.Equal(t, resp.Request.URL.Host, returnedEndpoint)
+}
+
+func TestItAdaptsMiddlewaresToTheContextContract(t *testing.T) {
+	handler := NewHeadersInspectionHandler()
+	contextMiddleware := NewContextMiddlewareFromMiddleware(handler)
+	assert.Equal(t, handler, NewMiddlewareFromContextMiddleware(contextMiddleware))
+
+	req, _ := nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com", nil)
+	ctx := context.WithValue(context.Background(), testContextKey{"tenant"}, "contoso")
+	spy := newSpyPipeline()
+	_, _, _ = contextMiddleware.Intercept(ctx, &contextPipeline{pipeline: spy}, 0, req)
+	assert.Equal(t, "contoso", spy.GetReceivedRequest().Context().Value(testContextKey{"tenant"}))
+}
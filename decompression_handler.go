@@ -0,0 +1,276 @@
+package nethttplibrary
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"strings"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DecompressionHandler takes over the transparent decompression of the responses from the transport, advertising the accepted encodings,
+// and aborts the read of the responses exceeding the maximum decompressed size or compression ratio,
+// protecting the client from decompression bombs sent by malicious or misconfigured servers.
+// The requests carrying their own Accept-Encoding or Range header are sent untouched, like the transport does.
+type DecompressionHandler struct {
+	options DecompressionHandlerOptions
+}
+
+// DecompressionHandlerOptions to use when decompressing the responses.
+type DecompressionHandlerOptions struct {
+	// Enabled defines whether the handler decompresses the responses
+	Enabled bool
+	// MaxDecompressedSize is the maximum size in bytes of a decompressed response body, zero or less for no limit
+	MaxDecompressedSize int64
+	// MaxCompressionRatio is the maximum ratio between the decompressed and the compressed sizes of a response body, zero or less for no limit
+	MaxCompressionRatio float64
+	// AcceptedEncodings are the encodings advertised in the Accept-Encoding header in order of preference, gzip when empty.
+	// Only the encodings with a decoder are advertised, identity alone disables the compression (e.g. for streaming endpoints).
+	AcceptedEncodings []string
+	// ContentDecoders are the decoders of the encodings not supported out of the box (gzip and deflate), e.g. br or zstd
+	ContentDecoders map[string]ContentDecoder
+}
+
+// ContentDecoder returns a reader decoding the body of a response encoded with the encoding it is registered for
+type ContentDecoder func(body io.Reader) (io.ReadCloser, error)
+
+const defaultMaxDecompressedSize = 100 * 1024 * 1024
+const defaultMaxCompressionRatio = 100
+
+// minimumDecompressedSizeForRatio is the decompressed size from which the compression ratio is checked, small payloads can legitimately have very high ratios
+const minimumDecompressedSizeForRatio = 1024 * 1024
+const acceptEncodingHeader = "Accept-Encoding"
+const contentEncodingHeader = "Content-Encoding"
+const gzipEncoding = "gzip"
+const deflateEncoding = "deflate"
+const identityEncoding = "identity"
+
+// defaultContentDecoders are the decoders of the encodings supported out of the box
+var defaultContentDecoders = map[string]ContentDecoder{
+	gzipEncoding: func(body io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(body)
+	},
+	deflateEncoding: func(body io.Reader) (io.ReadCloser, error) {
+		return zlib.NewReader(body)
+	},
+}
+
+var decompressionKeyValue = abs.RequestOptionKey{
+	Key: "DecompressionHandler",
+}
+
+type decompressionHandlerOptionsInt interface {
+	abs.RequestOption
+	GetEnabled() bool
+	GetMaxDecompressedSize() int64
+	GetMaxCompressionRatio() float64
+	GetAcceptedEncodings() []string
+	GetContentDecoder(encoding string) ContentDecoder
+}
+
+// NewDecompressionHandlerOptions creates a new DecompressionHandlerOptions with the default limits
+func NewDecompressionHandlerOptions() *DecompressionHandlerOptions {
+	return &DecompressionHandlerOptions{
+		Enabled:             true,
+		MaxDecompressedSize: defaultMaxDecompressedSize,
+		MaxCompressionRatio: defaultMaxCompressionRatio,
+	}
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *DecompressionHandlerOptions) GetKey() abs.RequestOptionKey {
+	return decompressionKeyValue
+}
+
+// GetEnabled returns whether the handler decompresses the responses
+func (options *DecompressionHandlerOptions) GetEnabled() bool {
+	return options.Enabled
+}
+
+// GetMaxDecompressedSize returns the maximum size in bytes of a decompressed response body, zero for no limit
+func (options *DecompressionHandlerOptions) GetMaxDecompressedSize() int64 {
+	if options.MaxDecompressedSize < 0 {
+		return 0
+	}
+	return options.MaxDecompressedSize
+}
+
+// GetMaxCompressionRatio returns the maximum ratio between the decompressed and the compressed sizes of a response body, zero for no limit
+func (options *DecompressionHandlerOptions) GetMaxCompressionRatio() float64 {
+	if options.MaxCompressionRatio < 0 {
+		return 0
+	}
+	return options.MaxCompressionRatio
+}
+
+// GetAcceptedEncodings returns the encodings advertised in the Accept-Encoding header in order of preference, the encodings without decoder are left out
+func (options *DecompressionHandlerOptions) GetAcceptedEncodings() []string {
+	if len(options.AcceptedEncodings) == 0 {
+		return []string{gzipEncoding}
+	}
+	result := make([]string, 0, len(options.AcceptedEncodings))
+	for _, encoding := range options.AcceptedEncodings {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if encoding == identityEncoding || options.GetContentDecoder(encoding) != nil {
+			result = append(result, encoding)
+		}
+	}
+	return result
+}
+
+// GetContentDecoder returns the decoder of the encoding, nil when the encoding is not supported
+func (options *DecompressionHandlerOptions) GetContentDecoder(encoding string) ContentDecoder {
+	encoding = strings.ToLower(encoding)
+	for key, decoder := range options.ContentDecoders {
+		if strings.EqualFold(key, encoding) && decoder != nil {
+			return decoder
+		}
+	}
+	return defaultContentDecoders[encoding]
+}
+
+// DecompressionLimitExceededError is returned when reading a response body exceeding the limits of the DecompressionHandler
+type DecompressionLimitExceededError struct {
+	// Url is the url of the request
+	Url string
+	// CompressedSize is the number of compressed bytes read when the limit was exceeded
+	CompressedSize int64
+	// DecompressedSize is the number of decompressed bytes read when the limit was exceeded
+	DecompressedSize int64
+	// RatioExceeded is true when the compression ratio was exceeded, false when the decompressed size was
+	RatioExceeded bool
+}
+
+// Error returns the error message
+func (e *DecompressionLimitExceededError) Error() string {
+	if e.RatioExceeded {
+		return fmt.Sprintf("the response of %s exceeded the maximum compression ratio, %d bytes were decompressed from %d bytes", e.Url, e.DecompressedSize, e.CompressedSize)
+	}
+	return fmt.Sprintf("the response of %s exceeded the maximum decompressed size of %d bytes", e.Url, e.DecompressedSize)
+}
+
+// NewDecompressionHandler creates a new DecompressionHandler with the default limits
+func NewDecompressionHandler() *DecompressionHandler {
+	return NewDecompressionHandlerWithOptions(*NewDecompressionHandlerOptions())
+}
+
+// NewDecompressionHandlerWithOptions creates a new DecompressionHandler with the given options
+func NewDecompressionHandlerWithOptions(options DecompressionHandlerOptions) *DecompressionHandler {
+	return &DecompressionHandler{options: options}
+}
+
+// Intercept implements the interface and decompresses the responses within the limits of the options.
+func (middleware DecompressionHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(decompressionKeyValue).(decompressionHandlerOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+	if !reqOption.GetEnabled() || req.Header.Get(acceptEncodingHeader) != "" || req.Header.Get("Range") != "" || req.Method == nethttp.MethodHead {
+		return pipeline.Next(req, middlewareIndex)
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	if obsOptions != nil {
+		ctx, span := getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(req.Context(), "DecompressionHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.decompression.enable", true))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	encodings := reqOption.GetAcceptedEncodings()
+	if len(encodings) == 0 {
+		encodings = []string{identityEncoding}
+	}
+	// setting the header prevents the transport from decompressing the response itself
+	req = req.Clone(req.Context())
+	req.Header.Set(acceptEncodingHeader, strings.Join(encodings, ", "))
+	response, err := pipeline.Next(req, middlewareIndex)
+	if err != nil || response == nil || response.Body == nil {
+		return response, err
+	}
+	encoding := strings.ToLower(strings.TrimSpace(response.Header.Get(contentEncodingHeader)))
+	if encoding == "" || encoding == identityEncoding {
+		return response, nil
+	}
+	decoder := reqOption.GetContentDecoder(encoding)
+	if decoder == nil {
+		// the body is left encoded for the caller, with its Content-Encoding header
+		return response, nil
+	}
+	response.Body = &limitedDecompressionBody{
+		body:                response.Body,
+		compressed:          &countingReader{reader: response.Body},
+		decoder:             decoder,
+		url:                 req.URL.String(),
+		maxDecompressedSize: reqOption.GetMaxDecompressedSize(),
+		maxCompressionRatio: reqOption.GetMaxCompressionRatio(),
+	}
+	response.Header.Del(contentEncodingHeader)
+	response.Header.Del("Content-Length")
+	response.ContentLength = -1
+	response.Uncompressed = true
+	return response, nil
+}
+
+// countingReader counts the bytes read from the underlying reader
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// limitedDecompressionBody decompresses the response body and fails once the limits are exceeded
+type limitedDecompressionBody struct {
+	body                io.ReadCloser
+	compressed          *countingReader
+	decoder             ContentDecoder
+	reader              io.ReadCloser
+	decompressedSize    int64
+	url                 string
+	maxDecompressedSize int64
+	maxCompressionRatio float64
+	err                 error
+}
+
+func (b *limitedDecompressionBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.reader == nil {
+		// the decoder is created lazily as it can read the header of the encoding from the body
+		reader, err := b.decoder(b.compressed)
+		if err != nil {
+			b.err = err
+			return 0, err
+		}
+		b.reader = reader
+	}
+	n, err := b.reader.Read(p)
+	b.decompressedSize += int64(n)
+	if b.maxDecompressedSize > 0 && b.decompressedSize > b.maxDecompressedSize {
+		b.err = &DecompressionLimitExceededError{Url: b.url, CompressedSize: b.compressed.count, DecompressedSize: b.decompressedSize}
+		return n, b.err
+	}
+	if b.maxCompressionRatio > 0 && b.decompressedSize >= minimumDecompressedSizeForRatio && float64(b.decompressedSize) > b.maxCompressionRatio*float64(b.compressed.count) {
+		b.err = &DecompressionLimitExceededError{Url: b.url, CompressedSize: b.compressed.count, DecompressedSize: b.decompressedSize, RatioExceeded: true}
+		return n, b.err
+	}
+	if err != nil {
+		b.err = err
+	}
+	return n, err
+}
+
+func (b *limitedDecompressionBody) Close() error {
+	if b.reader != nil {
+		b.reader.Close()
+	}
+	return b.body.Close()
+}
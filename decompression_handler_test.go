@@ -1,11 +1,16 @@
 package nethttplibrary
 
 import (
+	"bytes"
 	"compress/gzip"
+	"compress/zlib"
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	nethttp "net/http"
 	httptest "net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -35,3 +40,132 @@ func TestTransportDecompressesResponse(t *testing.T) {
 	assert.True(t, resp.Uncompressed)
 	assert.Equal(t, string(respBody), `{"email":"Test@Test.com","name":"Test"}`)
 }
+
+func newGzipTestServer(body []byte, acceptEncodings *[]string) *httptest.Server {
+	return httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		*acceptEncodings = append(*acceptEncodings, req.Header.Get("Accept-Encoding"))
+		res.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(res)
+		defer gz.Close()
+		gz.Write(body)
+	}))
+}
+
+func TestDecompressionHandlerDecompressesResponse(t *testing.T) {
+	var acceptEncodings []string
+	testServer := newGzipTestServer([]byte("body"), &acceptEncodings)
+	defer testServer.Close()
+	client := getDefaultClientWithoutMiddleware()
+	client.Transport = NewCustomTransport(NewDecompressionHandler())
+
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	respBody, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, "body", string(respBody))
+	assert.True(t, resp.Uncompressed)
+	assert.Equal(t, "", resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, []string{"gzip"}, acceptEncodings)
+}
+
+func TestDecompressionHandlerEnforcesTheMaximumSize(t *testing.T) {
+	var acceptEncodings []string
+	testServer := newGzipTestServer(bytes.Repeat([]byte("a"), 100), &acceptEncodings)
+	defer testServer.Close()
+	client := getDefaultClientWithoutMiddleware()
+	client.Transport = NewCustomTransport(NewDecompressionHandlerWithOptions(DecompressionHandlerOptions{Enabled: true, MaxDecompressedSize: 10}))
+
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	_, err = io.ReadAll(resp.Body)
+	var limitErr *DecompressionLimitExceededError
+	if assert.True(t, errors.As(err, &limitErr)) {
+		assert.False(t, limitErr.RatioExceeded)
+		assert.Greater(t, limitErr.DecompressedSize, int64(10))
+	}
+}
+
+func TestDecompressionHandlerEnforcesTheMaximumRatio(t *testing.T) {
+	var acceptEncodings []string
+	testServer := newGzipTestServer(make([]byte, 4*1024*1024), &acceptEncodings)
+	defer testServer.Close()
+	client := getDefaultClientWithoutMiddleware()
+	client.Transport = NewCustomTransport(NewDecompressionHandler())
+
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	_, err = io.ReadAll(resp.Body)
+	var limitErr *DecompressionLimitExceededError
+	if assert.True(t, errors.As(err, &limitErr)) {
+		assert.True(t, limitErr.RatioExceeded)
+	}
+}
+
+func TestDecompressionHandlerLeavesRequestsWithAcceptEncoding(t *testing.T) {
+	var acceptEncodings []string
+	testServer := newGzipTestServer([]byte("body"), &acceptEncodings)
+	defer testServer.Close()
+	client := getDefaultClientWithoutMiddleware()
+	client.Transport = NewCustomTransport(NewDecompressionHandler())
+
+	req, _ := nethttp.NewRequest(nethttp.MethodGet, testServer.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.False(t, resp.Uncompressed)
+}
+
+func TestDecompressionHandlerAdvertisesTheAcceptedEncodings(t *testing.T) {
+	var acceptEncodings []string
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		acceptEncodings = append(acceptEncodings, req.Header.Get("Accept-Encoding"))
+		switch {
+		case strings.HasPrefix(req.Header.Get("Accept-Encoding"), "x-upper"):
+			res.Header().Set("Content-Encoding", "x-upper")
+			res.Write([]byte("body"))
+		case strings.HasPrefix(req.Header.Get("Accept-Encoding"), "deflate"):
+			res.Header().Set("Content-Encoding", "deflate")
+			writer := zlib.NewWriter(res)
+			defer writer.Close()
+			writer.Write([]byte("body"))
+		default:
+			res.Write([]byte("body"))
+		}
+	}))
+	defer testServer.Close()
+	options := NewDecompressionHandlerOptions()
+	options.AcceptedEncodings = []string{"deflate", "br", "gzip"}
+	client := getDefaultClientWithoutMiddleware()
+	client.Transport = NewCustomTransport(NewDecompressionHandlerWithOptions(*options))
+
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	respBody, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "body", string(respBody))
+	assert.True(t, resp.Uncompressed)
+
+	identity := NewDecompressionHandlerOptions()
+	identity.AcceptedEncodings = []string{"identity"}
+	req, _ := nethttp.NewRequestWithContext(context.WithValue(context.Background(), decompressionKeyValue, identity), nethttp.MethodGet, testServer.URL, nil)
+	resp, err = client.Do(req)
+	assert.Nil(t, err)
+	respBody, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, "body", string(respBody))
+	assert.False(t, resp.Uncompressed)
+
+	custom := NewDecompressionHandlerOptions()
+	custom.AcceptedEncodings = []string{"x-upper"}
+	custom.ContentDecoders = map[string]ContentDecoder{
+		"x-upper": func(body io.Reader) (io.ReadCloser, error) {
+			content, err := io.ReadAll(body)
+			return io.NopCloser(strings.NewReader(strings.ToUpper(string(content)))), err
+		},
+	}
+	req, _ = nethttp.NewRequestWithContext(context.WithValue(context.Background(), decompressionKeyValue, custom), nethttp.MethodGet, testServer.URL, nil)
+	resp, err = client.Do(req)
+	assert.Nil(t, err)
+	respBody, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, "BODY", string(respBody))
+	assert.Equal(t, []string{"deflate, gzip", "identity", "x-upper"}, acceptEncodings)
+}
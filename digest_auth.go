@@ -0,0 +1,208 @@
+package nethttplibrary
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+)
+
+// digestChallenge is a Digest authentication challenge (RFC 7616) received in a WWW-Authenticate or Proxy-Authenticate header
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+	stale     bool
+}
+
+// parseAuthParams parses a comma separated list of auth-params, the values can be quoted strings containing commas
+func parseAuthParams(value string) map[string]string {
+	params := make(map[string]string)
+	for len(value) > 0 {
+		value = strings.TrimLeft(value, " \t,")
+		equalIndex := strings.Index(value, "=")
+		if equalIndex < 0 {
+			break
+		}
+		name := strings.ToLower(strings.TrimSpace(value[:equalIndex]))
+		value = strings.TrimLeft(value[equalIndex+1:], " \t")
+		var paramValue string
+		if strings.HasPrefix(value, "\"") {
+			var builder strings.Builder
+			i := 1
+			for ; i < len(value) && value[i] != '"'; i++ {
+				if value[i] == '\\' && i+1 < len(value) {
+					i++
+				}
+				builder.WriteByte(value[i])
+			}
+			paramValue = builder.String()
+			if i < len(value) {
+				i++
+			}
+			value = value[i:]
+		} else {
+			commaIndex := strings.Index(value, ",")
+			if commaIndex < 0 {
+				commaIndex = len(value)
+			}
+			paramValue = strings.TrimSpace(value[:commaIndex])
+			value = value[commaIndex:]
+		}
+		params[name] = paramValue
+	}
+	return params
+}
+
+// parseDigestChallenge returns the first Digest challenge of the given header values
+func parseDigestChallenge(headerValues []string) (*digestChallenge, bool) {
+	for _, headerValue := range headerValues {
+		if len(headerValue) < 7 || !strings.EqualFold(headerValue[:7], "digest ") {
+			continue
+		}
+		params := parseAuthParams(headerValue[7:])
+		if params["nonce"] == "" {
+			continue
+		}
+		challenge := &digestChallenge{
+			realm:     params["realm"],
+			nonce:     params["nonce"],
+			opaque:    params["opaque"],
+			algorithm: params["algorithm"],
+			stale:     strings.EqualFold(params["stale"], "true"),
+		}
+		for _, qop := range strings.Split(params["qop"], ",") {
+			if strings.TrimSpace(qop) == "auth" {
+				challenge.qop = "auth"
+			}
+		}
+		return challenge, true
+	}
+	return nil, false
+}
+
+func (challenge *digestChallenge) getHash() (func() hash.Hash, bool, error) {
+	algorithm := strings.ToUpper(challenge.algorithm)
+	session := strings.HasSuffix(algorithm, "-SESS")
+	switch strings.TrimSuffix(algorithm, "-SESS") {
+	case "", "MD5":
+		return md5.New, session, nil
+	case "SHA-256":
+		return sha256.New, session, nil
+	default:
+		return nil, false, fmt.Errorf("unsupported digest algorithm %s", challenge.algorithm)
+	}
+}
+
+func hashHex(newHash func() hash.Hash, value string) string {
+	h := newHash()
+	h.Write([]byte(value))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func newDigestClientNonce() (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(nonce), nil
+}
+
+// authorize computes the value of the Authorization or Proxy-Authorization header answering the challenge
+func (challenge *digestChallenge) authorize(method string, uri string, username string, password string, nonceCount uint32, clientNonce string) (string, error) {
+	newHash, session, err := challenge.getHash()
+	if err != nil {
+		return "", err
+	}
+	ha1 := hashHex(newHash, username+":"+challenge.realm+":"+password)
+	if session {
+		ha1 = hashHex(newHash, ha1+":"+challenge.nonce+":"+clientNonce)
+	}
+	ha2 := hashHex(newHash, method+":"+uri)
+	nc := fmt.Sprintf("%08x", nonceCount)
+	var response string
+	if challenge.qop == "" {
+		response = hashHex(newHash, ha1+":"+challenge.nonce+":"+ha2)
+	} else {
+		response = hashHex(newHash, ha1+":"+challenge.nonce+":"+nc+":"+clientNonce+":"+challenge.qop+":"+ha2)
+	}
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Digest username=%q, realm=%q, nonce=%q, uri=%q", username, challenge.realm, challenge.nonce, uri)
+	if challenge.algorithm != "" {
+		fmt.Fprintf(&builder, ", algorithm=%s", challenge.algorithm)
+	}
+	if challenge.qop != "" {
+		fmt.Fprintf(&builder, ", qop=%s, nc=%s, cnonce=%q", challenge.qop, nc, clientNonce)
+	}
+	fmt.Fprintf(&builder, ", response=%q", response)
+	if challenge.opaque != "" {
+		fmt.Fprintf(&builder, ", opaque=%q", challenge.opaque)
+	}
+	return builder.String(), nil
+}
+
+// digestSession holds the last challenge received and the nonce count so subsequent requests authenticate pre-emptively
+type digestSession struct {
+	mutex      sync.Mutex
+	challenge  *digestChallenge
+	nonceCount uint32
+}
+
+// setChallenge replaces the challenge of the session, it returns false when the challenge was already answered and is not stale
+func (session *digestSession) setChallenge(challenge *digestChallenge, answered bool) bool {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	if answered && !challenge.stale && session.challenge != nil && session.challenge.nonce == challenge.nonce {
+		return false
+	}
+	session.challenge = challenge
+	session.nonceCount = 0
+	return true
+}
+
+// setNextNonce updates the nonce from the nextnonce parameter of an Authentication-Info or Proxy-Authentication-Info header
+func (session *digestSession) setNextNonce(authenticationInfo string) {
+	nextNonce := parseAuthParams(authenticationInfo)["nextnonce"]
+	if nextNonce == "" {
+		return
+	}
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	if session.challenge != nil && session.challenge.nonce != nextNonce {
+		challenge := *session.challenge
+		challenge.nonce = nextNonce
+		session.challenge = &challenge
+		session.nonceCount = 0
+	}
+}
+
+// hasChallenge returns whether a challenge was received
+func (session *digestSession) hasChallenge() bool {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	return session.challenge != nil
+}
+
+// authorize returns the header value for the request, or an empty string when no challenge was received yet
+func (session *digestSession) authorize(method string, uri string, username string, password string) (string, error) {
+	session.mutex.Lock()
+	if session.challenge == nil {
+		session.mutex.Unlock()
+		return "", nil
+	}
+	session.nonceCount++
+	challenge, nonceCount := session.challenge, session.nonceCount
+	session.mutex.Unlock()
+	clientNonce, err := newDigestClientNonce()
+	if err != nil {
+		return "", errors.New("failed to generate the digest client nonce: " + err.Error())
+	}
+	return challenge.authorize(method, uri, username, password, nonceCount, clientNonce)
+}
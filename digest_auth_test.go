@@ -0,0 +1,51 @@
+package nethttplibrary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItParsesAuthParams(t *testing.T) {
+	params := parseAuthParams(`realm="a, \"quoted\" realm", qop="auth,auth-int", algorithm=MD5, stale=TRUE`)
+	assert.Equal(t, `a, "quoted" realm`, params["realm"])
+	assert.Equal(t, "auth,auth-int", params["qop"])
+	assert.Equal(t, "MD5", params["algorithm"])
+	assert.Equal(t, "TRUE", params["stale"])
+}
+
+func TestItComputesTheDigestResponse(t *testing.T) {
+	challenge, ok := parseDigestChallenge([]string{
+		`Basic realm="testrealm@host.com"`,
+		`Digest realm="testrealm@host.com", qop="auth,auth-int", nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", opaque="5ccc069c403ebaf9f0171e9517f40e41"`,
+	})
+	assert.True(t, ok)
+	authorization, err := challenge.authorize("GET", "/dir/index.html", "Mufasa", "Circle Of Life", 1, "0a4f113b")
+	assert.Nil(t, err)
+	assert.Equal(t, `Digest username="Mufasa", realm="testrealm@host.com", nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", uri="/dir/index.html", qop=auth, nc=00000001, cnonce="0a4f113b", response="6629fae49393a05397450978507c4ef1", opaque="5ccc069c403ebaf9f0171e9517f40e41"`, authorization)
+}
+
+func TestItRejectsUnsupportedDigestAlgorithms(t *testing.T) {
+	challenge, ok := parseDigestChallenge([]string{`Digest realm="r", nonce="n", algorithm=SHA-512-256`})
+	assert.True(t, ok)
+	_, err := challenge.authorize("GET", "/", "user", "pass", 1, "cnonce")
+	assert.Error(t, err)
+}
+
+func TestDigestSessionIncrementsTheNonceCount(t *testing.T) {
+	session := digestSession{}
+	authorization, err := session.authorize("GET", "/", "user", "pass")
+	assert.Nil(t, err)
+	assert.Empty(t, authorization)
+	challenge, _ := parseDigestChallenge([]string{`Digest realm="r", nonce="n1", qop="auth", algorithm=SHA-256`})
+	assert.True(t, session.setChallenge(challenge, false))
+	authorization, _ = session.authorize("GET", "/", "user", "pass")
+	assert.Contains(t, authorization, "nc=00000001")
+	authorization, _ = session.authorize("GET", "/", "user", "pass")
+	assert.Contains(t, authorization, "nc=00000002")
+	assert.False(t, session.setChallenge(challenge, true))
+	session.setNextNonce(`nextnonce="n2"`)
+	authorization, _ = session.authorize("GET", "/", "user", "pass")
+	assert.Contains(t, authorization, `nonce="n2"`)
+	assert.Contains(t, authorization, "nc=00000001")
+}
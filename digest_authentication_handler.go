@@ -0,0 +1,168 @@
+package nethttplibrary
+
+import (
+	"errors"
+	"io"
+	nethttp "net/http"
+	"strings"
+	"sync"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DigestAuthenticationHandler answers the Digest challenges (RFC 7616) of origin servers (401 responses with a WWW-Authenticate header).
+// The challenges are remembered per origin so the following requests to the same origin authenticate pre-emptively with an incremented nonce count.
+// ProxyAuthenticationHandler answers the challenges of the proxies.
+type DigestAuthenticationHandler struct {
+	options  DigestAuthenticationHandlerOptions
+	mutex    sync.Mutex
+	sessions map[string]*digestSession
+}
+
+// DigestAuthenticationHandlerOptions to use when authenticating to origin servers with Digest authentication.
+type DigestAuthenticationHandlerOptions struct {
+	// Enabled defines whether the challenges should be answered
+	Enabled bool
+	// Username is the user name to authenticate with
+	Username string
+	// Password is the password to authenticate with
+	Password string
+	// AllowedHosts are the hosts whose challenges are answered (e.g. legacy.contoso.com or *.contoso.com), all the hosts when empty
+	AllowedHosts []string
+}
+
+const wwwAuthenticateHeader = "WWW-Authenticate"
+const authorizationHeader = "Authorization"
+const authenticationInfoHeader = "Authentication-Info"
+
+var digestAuthenticationKeyValue = abs.RequestOptionKey{
+	Key: "DigestAuthenticationHandler",
+}
+
+type digestAuthenticationHandlerOptionsInt interface {
+	abs.RequestOption
+	GetEnabled() bool
+	GetUsername() string
+	GetPassword() string
+	GetAllowedHosts() []string
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *DigestAuthenticationHandlerOptions) GetKey() abs.RequestOptionKey {
+	return digestAuthenticationKeyValue
+}
+
+// GetEnabled returns whether the challenges should be answered
+func (options *DigestAuthenticationHandlerOptions) GetEnabled() bool {
+	return options.Enabled
+}
+
+// GetUsername returns the user name to authenticate with
+func (options *DigestAuthenticationHandlerOptions) GetUsername() string {
+	return options.Username
+}
+
+// GetPassword returns the password to authenticate with
+func (options *DigestAuthenticationHandlerOptions) GetPassword() string {
+	return options.Password
+}
+
+// GetAllowedHosts returns the hosts whose challenges are answered
+func (options *DigestAuthenticationHandlerOptions) GetAllowedHosts() []string {
+	return options.AllowedHosts
+}
+
+// NewDigestAuthenticationHandler creates a new DigestAuthenticationHandler with the given credentials, answering the challenges of the given hosts or of all the hosts when none is given
+func NewDigestAuthenticationHandler(username string, password string, allowedHosts ...string) (*DigestAuthenticationHandler, error) {
+	return NewDigestAuthenticationHandlerWithOptions(DigestAuthenticationHandlerOptions{
+		Enabled:      true,
+		Username:     username,
+		Password:     password,
+		AllowedHosts: allowedHosts,
+	})
+}
+
+// NewDigestAuthenticationHandlerWithOptions creates a new DigestAuthenticationHandler with the given options
+func NewDigestAuthenticationHandlerWithOptions(options DigestAuthenticationHandlerOptions) (*DigestAuthenticationHandler, error) {
+	if options.Username == "" {
+		return nil, errors.New("username cannot be empty")
+	}
+	return &DigestAuthenticationHandler{
+		options:  options,
+		sessions: make(map[string]*digestSession),
+	}, nil
+}
+
+// getSession returns the session of the origin of the request
+func (middleware *DigestAuthenticationHandler) getSession(req *nethttp.Request) *digestSession {
+	origin := strings.ToLower(req.URL.Scheme + "://" + req.URL.Host)
+	middleware.mutex.Lock()
+	defer middleware.mutex.Unlock()
+	if middleware.sessions == nil {
+		middleware.sessions = make(map[string]*digestSession)
+	}
+	session, ok := middleware.sessions[origin]
+	if !ok {
+		session = &digestSession{}
+		middleware.sessions[origin] = session
+	}
+	return session
+}
+
+// Intercept implements the interface and answers the Digest challenges of the origin servers.
+func (middleware *DigestAuthenticationHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(digestAuthenticationKeyValue).(digestAuthenticationHandlerOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	if obsOptions != nil {
+		ctx, span := getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(req.Context(), "DigestAuthenticationHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.digest_authentication.enable", reqOption.GetEnabled()))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	if !reqOption.GetEnabled() || !isHostAllowed(reqOption.GetAllowedHosts(), req.URL.Hostname()) {
+		return pipeline.Next(req, middlewareIndex)
+	}
+	session := middleware.getSession(req)
+	uri := req.URL.RequestURI()
+	authorization, err := session.authorize(req.Method, uri, reqOption.GetUsername(), reqOption.GetPassword())
+	if err != nil {
+		return nil, err
+	}
+	answered := authorization != ""
+	if answered {
+		req.Header.Set(authorizationHeader, authorization)
+	}
+	resp, err := pipeline.Next(req, middlewareIndex)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode != nethttp.StatusUnauthorized {
+		session.setNextNonce(resp.Header.Get(authenticationInfoHeader))
+		return resp, nil
+	}
+	challenge, ok := parseDigestChallenge(resp.Header.Values(wwwAuthenticateHeader))
+	if !ok || !session.setChallenge(challenge, answered) {
+		return resp, nil
+	}
+	authorization, err = session.authorize(req.Method, uri, reqOption.GetUsername(), reqOption.GetPassword())
+	if err != nil {
+		return nil, err
+	}
+	if !rewindRequestBody(req) {
+		return resp, nil
+	}
+	if resp.Body != nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+	req.Header.Set(authorizationHeader, authorization)
+	resp, err = pipeline.Next(req, middlewareIndex)
+	if err == nil && resp.StatusCode != nethttp.StatusUnauthorized {
+		session.setNextNonce(resp.Header.Get(authenticationInfoHeader))
+	}
+	return resp, err
+}
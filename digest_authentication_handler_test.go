@@ -0,0 +1,64 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItAnswersDigestOriginChallenges(t *testing.T) {
+	challengeCount := 0
+	var authorizations []string
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		authorization := req.Header.Get("Authorization")
+		if authorization == "" {
+			challengeCount++
+			res.Header().Add("WWW-Authenticate", `Digest realm="legacy", qop="auth", nonce="abc", algorithm=SHA-256`)
+			res.WriteHeader(401)
+			return
+		}
+		authorizations = append(authorizations, authorization)
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	handler, err := NewDigestAuthenticationHandler("user", "pass")
+	assert.Nil(t, err)
+	client := GetDefaultClient(handler)
+
+	resp, err := client.Post(testServer.URL+"/api/items?top=1", "text/plain", strings.NewReader("content"))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	resp, err = client.Get(testServer.URL + "/api/items?top=1")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	assert.Equal(t, 1, challengeCount)
+	if assert.Equal(t, 2, len(authorizations)) {
+		assert.True(t, strings.HasPrefix(authorizations[0], `Digest username="user", realm="legacy", nonce="abc", uri="/api/items?top=1", algorithm=SHA-256, qop=auth, nc=00000001`))
+		assert.Contains(t, authorizations[1], "nc=00000002")
+	}
+}
+
+func TestItOnlyAnswersTheDigestChallengesOfTheAllowedHosts(t *testing.T) {
+	challengeCount := 0
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		challengeCount++
+		res.Header().Add("WWW-Authenticate", `Digest realm="legacy", qop="auth", nonce="abc"`)
+		res.WriteHeader(401)
+	}))
+	defer testServer.Close()
+	handler, err := NewDigestAuthenticationHandler("user", "pass", "legacy.contoso.com")
+	assert.Nil(t, err)
+	client := GetDefaultClient(handler)
+
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+	assert.Equal(t, 1, challengeCount)
+
+	_, err = NewDigestAuthenticationHandler("", "pass")
+	assert.Error(t, err)
+}
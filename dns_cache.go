@@ -0,0 +1,123 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+const defaultDnsCacheTTL = 30 * time.Second
+
+// DnsCacheOptions to use when caching the host name lookups.
+type DnsCacheOptions struct {
+	// TTL is the duration the addresses of a host are cached for, defaults to 30 seconds.
+	// The Go resolver doesn't expose the TTL of the DNS records, it should be set below the TTL of the records of the hosts.
+	TTL time.Duration
+	// Resolver is the resolver used to look up the hosts, defaults to net.DefaultResolver
+	Resolver *net.Resolver
+}
+
+type dnsCacheEntry struct {
+	addresses []string
+	expiresAt time.Time
+}
+
+// CachingResolver caches the addresses of the hosts so lookups don't hit the DNS resolvers for every new connection.
+// Failed lookups are not cached.
+type CachingResolver struct {
+	ttl        time.Duration
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+	mutex      sync.Mutex
+	entries    map[string]dnsCacheEntry
+}
+
+// NewCachingResolver creates a new CachingResolver with the given options
+func NewCachingResolver(options DnsCacheOptions) (*CachingResolver, error) {
+	if options.TTL < 0 {
+		return nil, errors.New("ttl cannot be negative")
+	}
+	ttl := options.TTL
+	if ttl == 0 {
+		ttl = defaultDnsCacheTTL
+	}
+	resolver := options.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &CachingResolver{
+		ttl:        ttl,
+		lookupHost: resolver.LookupHost,
+		entries:    make(map[string]dnsCacheEntry),
+	}, nil
+}
+
+// LookupHost returns the addresses of the host, from the cache when they haven't expired
+func (r *CachingResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.mutex.Lock()
+	entry, ok := r.entries[host]
+	r.mutex.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.addresses, nil
+	}
+	addresses, err := r.lookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	r.mutex.Lock()
+	r.entries[host] = dnsCacheEntry{
+		addresses: addresses,
+		expiresAt: time.Now().Add(r.ttl),
+	}
+	r.mutex.Unlock()
+	return addresses, nil
+}
+
+// Clear removes all the cached addresses
+func (r *CachingResolver) Clear() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.entries = make(map[string]dnsCacheEntry)
+}
+
+// DialContext returns a function to use as http.Transport.DialContext which resolves the hosts with the cache
+// and dials the addresses in turn with the dialer until a connection is established.
+func (r *CachingResolver) DialContext(dialer *net.Dialer) func(ctx context.Context, network string, address string) (net.Conn, error) {
+	if dialer == nil {
+		dialer = newDefaultDialer()
+	}
+	return func(ctx context.Context, network string, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, address)
+		}
+		addresses, err := r.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var lastErr error
+		for _, ip := range addresses {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		if lastErr == nil {
+			lastErr = &net.DNSError{Err: "no addresses found", Name: host, IsNotFound: true}
+		}
+		return nil, lastErr
+	}
+}
+
+// newDefaultDialer returns a dialer with the settings of http.DefaultTransport
+func newDefaultDialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+}
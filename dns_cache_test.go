@@ -0,0 +1,84 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+	"net"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCountingResolver(t *testing.T, ttl time.Duration, addresses ...string) (*CachingResolver, *int) {
+	resolver, err := NewCachingResolver(DnsCacheOptions{TTL: ttl})
+	assert.Nil(t, err)
+	lookupCount := 0
+	resolver.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		lookupCount++
+		if host == "unknown.contoso.com" {
+			return nil, errors.New("no such host")
+		}
+		return addresses, nil
+	}
+	return resolver, &lookupCount
+}
+
+func TestCachingResolverCachesTheLookups(t *testing.T) {
+	resolver, lookupCount := newCountingResolver(t, time.Minute, "127.0.0.1")
+	for i := 0; i < 3; i++ {
+		addresses, err := resolver.LookupHost(context.Background(), "graph.contoso.com")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"127.0.0.1"}, addresses)
+	}
+	assert.Equal(t, 1, *lookupCount)
+	for i := 0; i < 2; i++ {
+		_, err := resolver.LookupHost(context.Background(), "unknown.contoso.com")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 3, *lookupCount)
+	resolver.Clear()
+	_, _ = resolver.LookupHost(context.Background(), "graph.contoso.com")
+	assert.Equal(t, 4, *lookupCount)
+}
+
+func TestCachingResolverExpiresTheEntries(t *testing.T) {
+	resolver, lookupCount := newCountingResolver(t, time.Millisecond, "127.0.0.1")
+	_, _ = resolver.LookupHost(context.Background(), "graph.contoso.com")
+	time.Sleep(5 * time.Millisecond)
+	_, _ = resolver.LookupHost(context.Background(), "graph.contoso.com")
+	assert.Equal(t, 2, *lookupCount)
+}
+
+func TestCachingResolverDialsTheCachedAddresses(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	serverUrl, _ := url.Parse(testServer.URL)
+	// the test server only listens on 127.0.0.1, dialing the first address fails and the next one should be dialed
+	resolver, lookupCount := newCountingResolver(t, time.Minute, "127.0.0.2", "127.0.0.1")
+	client, err := NewKiotaClientBuilder().
+		WithDialContext(resolver.DialContext(nil)).
+		WithMiddleware(NewHeadersInspectionHandler()).
+		Build()
+	assert.Nil(t, err)
+	resp, err := client.Get("http://graph.contoso.com:" + serverUrl.Port())
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 1, *lookupCount)
+
+	_, err = resolver.DialContext(nil)(context.Background(), "tcp", "unknown.contoso.com:80")
+	assert.Error(t, err)
+}
+
+func TestItBuildsAClientWithADnsCache(t *testing.T) {
+	client, err := NewKiotaClientBuilder().WithResolver(&net.Resolver{PreferGo: true}).WithDnsCache(0).Build()
+	assert.Nil(t, err)
+	assert.NotNil(t, client.Transport.(*customTransport).middlewarePipeline.transport.(*nethttp.Transport).DialContext)
+	_, err = NewKiotaClientBuilder().WithDnsCache(-1).Build()
+	assert.Error(t, err)
+}
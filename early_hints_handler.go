@@ -0,0 +1,102 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+	"net/http/httptrace"
+	"net/textproto"
+	"sync"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// InformationalResponse is a 1xx response received before the final response
+type InformationalResponse struct {
+	// StatusCode is the status code of the informational response
+	StatusCode int
+	// Header are the headers of the informational response
+	Header nethttp.Header
+}
+
+// EarlyHintsInspectionOptions is the option to use to inspect the informational (1xx) responses, like 103 Early Hints, received for a request
+type EarlyHintsInspectionOptions struct {
+	mutex     sync.Mutex
+	responses []InformationalResponse
+}
+
+// NewEarlyHintsInspectionOptions creates a new EarlyHintsInspectionOptions
+func NewEarlyHintsInspectionOptions() *EarlyHintsInspectionOptions {
+	return &EarlyHintsInspectionOptions{}
+}
+
+var earlyHintsInspectionKeyValue = abs.RequestOptionKey{
+	Key: "nethttplibrary.EarlyHintsInspectionOptions",
+}
+
+type earlyHintsInspectionOptionsInt interface {
+	abs.RequestOption
+	AddInformationalResponse(statusCode int, header nethttp.Header)
+}
+
+// GetKey returns the key for the EarlyHintsInspectionOptions
+func (o *EarlyHintsInspectionOptions) GetKey() abs.RequestOptionKey {
+	return earlyHintsInspectionKeyValue
+}
+
+// AddInformationalResponse records an informational response
+func (o *EarlyHintsInspectionOptions) AddInformationalResponse(statusCode int, header nethttp.Header) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.responses = append(o.responses, InformationalResponse{StatusCode: statusCode, Header: header.Clone()})
+}
+
+// GetInformationalResponses returns the informational responses received in order
+func (o *EarlyHintsInspectionOptions) GetInformationalResponses() []InformationalResponse {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	result := make([]InformationalResponse, len(o.responses))
+	copy(result, o.responses)
+	return result
+}
+
+// GetEarlyHintsLinks returns the Link header values of the 103 Early Hints responses received
+func (o *EarlyHintsInspectionOptions) GetEarlyHintsLinks() []string {
+	links := make([]string, 0)
+	for _, response := range o.GetInformationalResponses() {
+		if response.StatusCode == nethttp.StatusEarlyHints {
+			links = append(links, response.Header.Values("Link")...)
+		}
+	}
+	return links
+}
+
+// EarlyHintsHandler records the informational responses received for the requests carrying an EarlyHintsInspectionOptions instead of silently dropping them
+type EarlyHintsHandler struct {
+}
+
+// NewEarlyHintsHandler creates a new EarlyHintsHandler
+func NewEarlyHintsHandler() *EarlyHintsHandler {
+	return &EarlyHintsHandler{}
+}
+
+// Intercept implements the interface and registers a trace recording the informational responses.
+func (middleware EarlyHintsHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(earlyHintsInspectionKeyValue).(earlyHintsInspectionOptionsInt)
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	if obsOptions != nil {
+		ctx, span := getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(req.Context(), "EarlyHintsHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.early_hints.enable", ok))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	if ok {
+		trace := &httptrace.ClientTrace{
+			Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+				reqOption.AddInformationalResponse(code, nethttp.Header(header))
+				return nil
+			},
+		}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	}
+	return pipeline.Next(req, middlewareIndex)
+}
@@ -0,0 +1,34 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItRecordsEarlyHints(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.Header().Add("Link", "</style.css>; rel=preload; as=style")
+		res.WriteHeader(nethttp.StatusEarlyHints)
+		res.Header().Del("Link")
+		res.WriteHeader(200)
+		res.Write([]byte("body"))
+	}))
+	defer func() { testServer.Close() }()
+	options := NewEarlyHintsInspectionOptions()
+	ctx := context.WithValue(context.Background(), options.GetKey(), options)
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, testServer.URL, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	resp, err := NewEarlyHintsHandler().Intercept(newNoopPipeline(), 0, req)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, []string{"</style.css>; rel=preload; as=style"}, options.GetEarlyHintsLinks())
+	assert.Equal(t, 1, len(options.GetInformationalResponses()))
+}
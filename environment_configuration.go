@@ -0,0 +1,170 @@
+package nethttplibrary
+
+import (
+	"errors"
+	nethttp "net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+)
+
+const (
+	// TimeoutEnvironmentVariable is the timeout of the clients, a duration (e.g. 30s) or a number of seconds
+	TimeoutEnvironmentVariable = "KIOTA_HTTP_TIMEOUT"
+	// MaxRetriesEnvironmentVariable is the maximum number of times the retry handler retries a request
+	MaxRetriesEnvironmentVariable = "KIOTA_HTTP_MAX_RETRIES"
+	// ProxyEnvironmentVariable is the url of the proxy the requests are sent through
+	ProxyEnvironmentVariable = "KIOTA_HTTP_PROXY"
+	// CompressionEnvironmentVariable enables or disables the compression of the request bodies (true or false)
+	CompressionEnvironmentVariable = "KIOTA_HTTP_COMPRESSION"
+	// ObservabilityLevelEnvironmentVariable is the span granularity of the request adapters (detailed, basic or off)
+	ObservabilityLevelEnvironmentVariable = "KIOTA_HTTP_OBSERVABILITY_LEVEL"
+)
+
+// EnvironmentConfiguration is the client configuration read from the KIOTA_HTTP_* environment variables, the settings are nil when their variable isn't set
+type EnvironmentConfiguration struct {
+	// Timeout is the timeout of the clients
+	Timeout *time.Duration
+	// MaxRetries is the maximum number of times the retry handler retries a request
+	MaxRetries *int
+	// ProxyUrl is the url of the proxy the requests are sent through
+	ProxyUrl *url.URL
+	// Compression enables or disables the compression of the request bodies
+	Compression *bool
+	// SpanGranularity is the span granularity of the request adapters
+	SpanGranularity *SpanGranularity
+}
+
+// EnvironmentVariableError is returned when the value of a KIOTA_HTTP_* environment variable is invalid
+type EnvironmentVariableError struct {
+	// Name is the name of the environment variable
+	Name string
+	// Value is the invalid value
+	Value string
+	// Err describes why the value is invalid
+	Err error
+}
+
+func (e *EnvironmentVariableError) Error() string {
+	return "invalid value " + strconv.Quote(e.Value) + " for the environment variable " + e.Name + ": " + e.Err.Error()
+}
+
+func (e *EnvironmentVariableError) Unwrap() error {
+	return e.Err
+}
+
+// GetEnvironmentConfiguration reads the client configuration from the KIOTA_HTTP_* environment variables
+func GetEnvironmentConfiguration() (*EnvironmentConfiguration, error) {
+	configuration := &EnvironmentConfiguration{}
+	if value, ok := lookupEnvironmentVariable(TimeoutEnvironmentVariable); ok {
+		timeout, err := parseEnvironmentTimeout(value)
+		if err != nil {
+			return nil, &EnvironmentVariableError{Name: TimeoutEnvironmentVariable, Value: value, Err: err}
+		}
+		configuration.Timeout = &timeout
+	}
+	if value, ok := lookupEnvironmentVariable(MaxRetriesEnvironmentVariable); ok {
+		maxRetries, err := strconv.Atoi(value)
+		if err == nil && maxRetries < 0 {
+			err = errors.New("the maximum number of retries cannot be negative")
+		}
+		if err != nil {
+			return nil, &EnvironmentVariableError{Name: MaxRetriesEnvironmentVariable, Value: value, Err: err}
+		}
+		configuration.MaxRetries = &maxRetries
+	}
+	if value, ok := lookupEnvironmentVariable(ProxyEnvironmentVariable); ok {
+		proxyUrl, err := url.Parse(value)
+		if err == nil && proxyUrl.Host == "" {
+			err = errors.New("the proxy url must be absolute")
+		}
+		if err != nil {
+			return nil, &EnvironmentVariableError{Name: ProxyEnvironmentVariable, Value: value, Err: err}
+		}
+		configuration.ProxyUrl = proxyUrl
+	}
+	if value, ok := lookupEnvironmentVariable(CompressionEnvironmentVariable); ok {
+		compression, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, &EnvironmentVariableError{Name: CompressionEnvironmentVariable, Value: value, Err: err}
+		}
+		configuration.Compression = &compression
+	}
+	if value, ok := lookupEnvironmentVariable(ObservabilityLevelEnvironmentVariable); ok {
+		granularity, err := parseSpanGranularity(value)
+		if err != nil {
+			return nil, &EnvironmentVariableError{Name: ObservabilityLevelEnvironmentVariable, Value: value, Err: err}
+		}
+		configuration.SpanGranularity = &granularity
+	}
+	return configuration, nil
+}
+
+// lookupEnvironmentVariable returns the trimmed value of the environment variable, false when it's not set or empty
+func lookupEnvironmentVariable(name string) (string, bool) {
+	value := strings.TrimSpace(os.Getenv(name))
+	return value, value != ""
+}
+
+// parseEnvironmentTimeout parses a duration (e.g. 30s) or a number of seconds
+func parseEnvironmentTimeout(value string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, parseErr := strconv.ParseFloat(value, 64)
+		if parseErr != nil {
+			return 0, err
+		}
+		timeout = time.Duration(seconds * float64(time.Second))
+	}
+	if timeout < 0 {
+		return 0, errors.New("the timeout cannot be negative")
+	}
+	return timeout, nil
+}
+
+// parseSpanGranularity parses the name of a span granularity
+func parseSpanGranularity(value string) (SpanGranularity, error) {
+	switch strings.ToLower(value) {
+	case "detailed":
+		return DetailedSpanGranularity, nil
+	case "basic":
+		return BasicSpanGranularity, nil
+	case "off":
+		return OffSpanGranularity, nil
+	}
+	return DetailedSpanGranularity, errors.New("the observability level must be detailed, basic or off")
+}
+
+// getMiddlewareOptions returns the options of the default middlewares configured by the environment which aren't already in the given options
+func (configuration *EnvironmentConfiguration) getMiddlewareOptions(requestOptions []abs.RequestOption) []abs.RequestOption {
+	result := make([]abs.RequestOption, 0, 2)
+	if configuration.MaxRetries != nil && !containsRequestOption(requestOptions, retryKeyValue) {
+		maxRetries := *configuration.MaxRetries
+		result = append(result, &RetryHandlerOptions{
+			MaxRetries: maxRetries,
+			// the retry handler uses its default maximum when the maximum is 0, so the retries are disabled with the callback instead
+			ShouldRetry: func(delay time.Duration, executionCount int, request *nethttp.Request, response *nethttp.Response) bool {
+				return maxRetries > 0
+			},
+		})
+	}
+	if configuration.Compression != nil && !containsRequestOption(requestOptions, compressKey) {
+		compression := NewCompressionOptions(*configuration.Compression)
+		result = append(result, &compression)
+	}
+	return result
+}
+
+// containsRequestOption returns whether the options contain an option with the given key
+func containsRequestOption(requestOptions []abs.RequestOption, key abs.RequestOptionKey) bool {
+	for _, option := range requestOptions {
+		if option != nil && option.GetKey() == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package nethttplibrary
+
+import (
+	"errors"
+	nethttp "net/http"
+	"testing"
+	"time"
+
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItReadsTheConfigurationFromTheEnvironment(t *testing.T) {
+	t.Setenv(TimeoutEnvironmentVariable, "30")
+	t.Setenv(MaxRetriesEnvironmentVariable, "5")
+	t.Setenv(ProxyEnvironmentVariable, "http://proxy.contoso.com:8080")
+	t.Setenv(CompressionEnvironmentVariable, "false")
+	t.Setenv(ObservabilityLevelEnvironmentVariable, "Basic")
+
+	configuration, err := GetEnvironmentConfiguration()
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Second, *configuration.Timeout)
+	assert.Equal(t, 5, *configuration.MaxRetries)
+	assert.Equal(t, "proxy.contoso.com:8080", configuration.ProxyUrl.Host)
+	assert.False(t, *configuration.Compression)
+	assert.Equal(t, BasicSpanGranularity, *configuration.SpanGranularity)
+
+	t.Setenv(TimeoutEnvironmentVariable, "1m30s")
+	configuration, err = GetEnvironmentConfiguration()
+	assert.Nil(t, err)
+	assert.Equal(t, 90*time.Second, *configuration.Timeout)
+}
+
+func TestItReturnsAnErrorForInvalidEnvironmentVariables(t *testing.T) {
+	t.Setenv(MaxRetriesEnvironmentVariable, "many")
+	_, err := GetEnvironmentConfiguration()
+	var variableErr *EnvironmentVariableError
+	if assert.True(t, errors.As(err, &variableErr)) {
+		assert.Equal(t, MaxRetriesEnvironmentVariable, variableErr.Name)
+		assert.Equal(t, "many", variableErr.Value)
+	}
+
+	t.Setenv(MaxRetriesEnvironmentVariable, "")
+	t.Setenv(ObservabilityLevelEnvironmentVariable, "verbose")
+	_, err = NewKiotaClientBuilder().WithEnvironmentConfiguration().Build()
+	assert.True(t, errors.As(err, &variableErr))
+}
+
+func TestItBuildsAClientConfiguredByTheEnvironment(t *testing.T) {
+	t.Setenv(TimeoutEnvironmentVariable, "30s")
+	t.Setenv(MaxRetriesEnvironmentVariable, "0")
+	t.Setenv(ProxyEnvironmentVariable, "http://proxy.contoso.com:8080")
+	t.Setenv(CompressionEnvironmentVariable, "false")
+	t.Setenv(ObservabilityLevelEnvironmentVariable, "off")
+
+	client, err := GetDefaultClientFromEnvironment()
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Second, client.Timeout)
+	transport := client.Transport.(*customTransport)
+	req, _ := nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com", nil)
+	proxyUrl, err := transport.middlewarePipeline.transport.(*nethttp.Transport).Proxy(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "http://proxy.contoso.com:8080", proxyUrl.String())
+	for _, middleware := range transport.middlewarePipeline.getMiddlewares() {
+		switch handler := middleware.(type) {
+		case *RetryHandler:
+			assert.False(t, handler.options.GetShouldRetry()(0, 0, req, nil))
+		case *CompressionHandler:
+			assert.False(t, handler.options.ShouldCompress())
+		}
+	}
+
+	client, err = NewKiotaClientBuilder().WithEnvironmentConfiguration().WithTimeout(10 * time.Second).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, 10*time.Second, client.Timeout)
+
+	adapter, err := NewKiotaClientBuilder().WithEnvironmentConfiguration().BuildRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.Nil(t, err)
+	assert.Equal(t, OffSpanGranularity, adapter.getObservabilityOptions().GetSpanGranularity())
+}
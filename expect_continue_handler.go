@@ -0,0 +1,95 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ExpectContinueHandler adds the "Expect: 100-continue" header to large uploads so the body is only transmitted once the server accepted the headers.
+// This saves bandwidth when the request is rejected, e.g. on authentication failures.
+// The parent transport must have a non zero ExpectContinueTimeout, which is the case of the default transport.
+type ExpectContinueHandler struct {
+	options ExpectContinueOptions
+}
+
+// ExpectContinueOptions to use when deciding whether to add the "Expect: 100-continue" header.
+type ExpectContinueOptions struct {
+	// Enabled defines whether the header should be added
+	Enabled bool
+	// MinimumBodySize is the body size in bytes from which the header is added
+	MinimumBodySize int64
+}
+
+const defaultExpectContinueMinimumBodySize = 1024 * 1024
+const expectHeader = "Expect"
+const expectContinueValue = "100-continue"
+
+var expectContinueKeyValue = abs.RequestOptionKey{
+	Key: "ExpectContinueHandler",
+}
+
+type expectContinueOptionsInt interface {
+	abs.RequestOption
+	GetEnabled() bool
+	GetMinimumBodySize() int64
+}
+
+// NewExpectContinueOptions creates a new ExpectContinueOptions with the default values
+func NewExpectContinueOptions() *ExpectContinueOptions {
+	return &ExpectContinueOptions{
+		Enabled:         true,
+		MinimumBodySize: defaultExpectContinueMinimumBodySize,
+	}
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *ExpectContinueOptions) GetKey() abs.RequestOptionKey {
+	return expectContinueKeyValue
+}
+
+// GetEnabled returns whether the header should be added
+func (options *ExpectContinueOptions) GetEnabled() bool {
+	return options.Enabled
+}
+
+// GetMinimumBodySize returns the body size in bytes from which the header is added
+func (options *ExpectContinueOptions) GetMinimumBodySize() int64 {
+	if options.MinimumBodySize < 0 {
+		return 0
+	}
+	return options.MinimumBodySize
+}
+
+// NewExpectContinueHandler creates a new ExpectContinueHandler with the default options
+func NewExpectContinueHandler() *ExpectContinueHandler {
+	return NewExpectContinueHandlerWithOptions(*NewExpectContinueOptions())
+}
+
+// NewExpectContinueHandlerWithOptions creates a new ExpectContinueHandler with the given options
+func NewExpectContinueHandlerWithOptions(options ExpectContinueOptions) *ExpectContinueHandler {
+	return &ExpectContinueHandler{options: options}
+}
+
+// Intercept implements the interface and adds the "Expect: 100-continue" header to large uploads.
+func (middleware ExpectContinueHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(expectContinueKeyValue).(expectContinueOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	if obsOptions != nil {
+		ctx, span := getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(req.Context(), "ExpectContinueHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.expect_continue.enable", reqOption.GetEnabled()))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	if reqOption.GetEnabled() &&
+		req.Body != nil && req.Body != nethttp.NoBody &&
+		req.ContentLength >= reqOption.GetMinimumBodySize() &&
+		req.Header.Get(expectHeader) == "" {
+		req.Header.Set(expectHeader, expectContinueValue)
+	}
+	return pipeline.Next(req, middlewareIndex)
+}
@@ -0,0 +1,41 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItAddsTheExpectContinueHeaderToLargeUploads(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(401)
+	}))
+	defer func() { testServer.Close() }()
+	options := NewExpectContinueOptions()
+	options.MinimumBodySize = 4
+	client := GetDefaultClient(NewExpectContinueHandlerWithOptions(*options))
+	resp, err := client.Post(testServer.URL, "text/plain", strings.NewReader("content"))
+	assert.Nil(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+	assert.Equal(t, "100-continue", resp.Request.Header.Get("Expect"))
+}
+
+func TestItSkipsExpectContinueForSmallBodies(t *testing.T) {
+	handler := NewExpectContinueHandler()
+	req, err := nethttp.NewRequest(nethttp.MethodPost, "https://example.com", strings.NewReader("content"))
+	if err != nil {
+		t.Error(err)
+	}
+	pipeline := newSpyPipeline()
+	_, _ = handler.Intercept(pipeline, 0, req)
+	assert.Empty(t, pipeline.GetReceivedRequest().Header.Get("Expect"))
+
+	options := &ExpectContinueOptions{Enabled: true}
+	req = req.WithContext(context.WithValue(req.Context(), expectContinueKeyValue, options))
+	_, _ = handler.Intercept(pipeline, 0, req)
+	assert.Equal(t, "100-continue", pipeline.GetReceivedRequest().Header.Get("Expect"))
+}
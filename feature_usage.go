@@ -0,0 +1,129 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	"strconv"
+	"sync"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// FeatureUsageFlag is a bit flag describing a feature of the client in use for a request
+type FeatureUsageFlag int64
+
+const (
+	// NoneFeatureUsageFlag indicates no feature is in use
+	NoneFeatureUsageFlag FeatureUsageFlag = 0
+	// RetryHandlerEnabledFeatureUsageFlag indicates the retry handler is in use
+	RetryHandlerEnabledFeatureUsageFlag FeatureUsageFlag = 1
+	// RedirectHandlerEnabledFeatureUsageFlag indicates the redirect handler is in use
+	RedirectHandlerEnabledFeatureUsageFlag FeatureUsageFlag = 2
+	// CompressionHandlerEnabledFeatureUsageFlag indicates the compression handler is in use
+	CompressionHandlerEnabledFeatureUsageFlag FeatureUsageFlag = 4
+	// ProxyEnabledFeatureUsageFlag indicates the client is configured with a proxy
+	ProxyEnabledFeatureUsageFlag FeatureUsageFlag = 8
+)
+
+type featureUsageContextKey struct{}
+
+// featureUsage holds the feature flags registered for a request, it is shared by all the middlewares of the pipeline
+type featureUsage struct {
+	mutex sync.Mutex
+	flags FeatureUsageFlag
+	// whether the flags should be added to the user agent header before the request is sent
+	userAgentTokenEnabled bool
+}
+
+func (f *featureUsage) add(flag FeatureUsageFlag) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.flags |= flag
+}
+
+func (f *featureUsage) get() FeatureUsageFlag {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.flags
+}
+
+func getFeatureUsageHolder(ctx context.Context) *featureUsage {
+	if usage, ok := ctx.Value(featureUsageContextKey{}).(*featureUsage); ok {
+		return usage
+	}
+	return nil
+}
+
+func enableFeatureUsageUserAgentToken(req *nethttp.Request) *nethttp.Request {
+	req = RegisterFeatureUsage(req, NoneFeatureUsageFlag)
+	usage := getFeatureUsageHolder(req.Context())
+	usage.mutex.Lock()
+	defer usage.mutex.Unlock()
+	usage.userAgentTokenEnabled = true
+	return req
+}
+
+func isFeatureUsageUserAgentTokenEnabled(ctx context.Context) bool {
+	usage := getFeatureUsageHolder(ctx)
+	if usage == nil {
+		return false
+	}
+	usage.mutex.Lock()
+	defer usage.mutex.Unlock()
+	return usage.userAgentTokenEnabled
+}
+
+// RegisterFeatureUsage registers the given feature flag for the request.
+// The returned request must be used in place of the given one as the flags are stored in the request context.
+func RegisterFeatureUsage(req *nethttp.Request, flag FeatureUsageFlag) *nethttp.Request {
+	if usage := getFeatureUsageHolder(req.Context()); usage != nil {
+		usage.add(flag)
+		return req
+	}
+	usage := &featureUsage{flags: flag}
+	return req.WithContext(context.WithValue(req.Context(), featureUsageContextKey{}, usage))
+}
+
+// GetFeatureUsage returns the feature flags registered in the given context
+func GetFeatureUsage(ctx context.Context) FeatureUsageFlag {
+	if usage := getFeatureUsageHolder(ctx); usage != nil {
+		return usage.get()
+	}
+	return NoneFeatureUsageFlag
+}
+
+// String returns the hexadecimal representation of the flags
+func (f FeatureUsageFlag) String() string {
+	return strconv.FormatInt(int64(f), 16)
+}
+
+// FeatureUsageHandler writes the feature flags registered for the request to the user agent header when the user agent handler includes the feature usage.
+// It follows the handlers registering flags so the header sent lists all of them.
+type FeatureUsageHandler struct {
+}
+
+var featureUsageKeyValue = abs.RequestOptionKey{
+	Key: "FeatureUsageHandler",
+}
+
+// NewFeatureUsageHandler creates a new FeatureUsageHandler
+func NewFeatureUsageHandler() *FeatureUsageHandler {
+	return &FeatureUsageHandler{}
+}
+
+// Intercept implements the interface and writes the feature usage token of the user agent header.
+func (middleware FeatureUsageHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	enabled := isFeatureUsageUserAgentTokenEnabled(req.Context())
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	if obsOptions != nil {
+		ctx, span := getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(req.Context(), "FeatureUsageHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.feature_usage.enable", enabled))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	if enabled {
+		setFeatureUsageUserAgentToken(req)
+	}
+	return pipeline.Next(req, middlewareIndex)
+}
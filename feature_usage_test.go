@@ -0,0 +1,21 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItRegistersFeatureUsage(t *testing.T) {
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://example.com", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, NoneFeatureUsageFlag, GetFeatureUsage(req.Context()))
+	req = RegisterFeatureUsage(req, RetryHandlerEnabledFeatureUsageFlag)
+	sameReq := RegisterFeatureUsage(req, CompressionHandlerEnabledFeatureUsageFlag)
+	assert.Same(t, req, sameReq)
+	assert.Equal(t, RetryHandlerEnabledFeatureUsageFlag|CompressionHandlerEnabledFeatureUsageFlag, GetFeatureUsage(req.Context()))
+	assert.Equal(t, "5", GetFeatureUsage(req.Context()).String())
+}
@@ -7,6 +7,7 @@ require (
 	github.com/microsoft/kiota-abstractions-go v1.6.0
 	github.com/stretchr/testify v1.9.0
 	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/metric v1.24.0
 	go.opentelemetry.io/otel/trace v1.24.0
 )
 
@@ -18,6 +19,5 @@ require (
 	github.com/kr/text v0.2.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/std-uritemplate/std-uritemplate/go v0.0.55 // indirect
-	go.opentelemetry.io/otel/metric v1.24.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
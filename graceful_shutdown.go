@@ -0,0 +1,49 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRequestAdapterShutdown is returned by the send methods of a request adapter once Shutdown was called
+var ErrRequestAdapterShutdown = errors.New("the request adapter is shut down")
+
+// startSend registers a send in flight, it fails once the request adapter is shut down
+func (a *NetHttpRequestAdapter) startSend() error {
+	a.shutdownMutex.Lock()
+	defer a.shutdownMutex.Unlock()
+	if a.shutdown {
+		return ErrRequestAdapterShutdown
+	}
+	a.sends.Add(1)
+	return nil
+}
+
+// endSend unregisters a send in flight
+func (a *NetHttpRequestAdapter) endSend() {
+	a.sends.Done()
+}
+
+// Shutdown stops accepting new sends, waits for the sends in flight to complete and closes the idle connections of the client.
+// It returns the error of the context when it's done before the sends in flight complete, the idle connections are closed in any case.
+func (a *NetHttpRequestAdapter) Shutdown(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	a.shutdownMutex.Lock()
+	a.shutdown = true
+	a.shutdownMutex.Unlock()
+	drained := make(chan struct{})
+	go func() {
+		a.sends.Wait()
+		close(drained)
+	}()
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	a.httpClient.CloseIdleConnections()
+	return err
+}
@@ -0,0 +1,58 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+	"time"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/stretchr/testify/assert"
+)
+
+type idleConnectionsTransport struct {
+	nethttp.RoundTripper
+	closed int
+}
+
+func (transport *idleConnectionsTransport) CloseIdleConnections() {
+	transport.closed++
+}
+
+func TestItDrainsTheSendsInFlightOnShutdown(t *testing.T) {
+	release := make(chan struct{})
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		<-release
+		res.WriteHeader(204)
+	}))
+	defer testServer.Close()
+	transport := &idleConnectionsTransport{RoundTripper: nethttp.DefaultTransport}
+	client := GetDefaultClient(NewRetryHandler())
+	client.Transport.(*customTransport).middlewarePipeline.transport = transport
+	adapter, err := NewNetHttpRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(&absauth.AnonymousAuthenticationProvider{}, nil, nil, client)
+	assert.Nil(t, err)
+	adapter.SetBaseUrl(testServer.URL)
+	newRequest := func() *abs.RequestInformation {
+		return abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/items", map[string]string{})
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- adapter.SendNoContent(context.Background(), newRequest(), nil)
+	}()
+	assert.Eventually(t, func() bool {
+		return adapter.GetActiveRequestCount() == 1
+	}, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, adapter.Shutdown(ctx), context.DeadlineExceeded)
+	assert.ErrorIs(t, adapter.SendNoContent(context.Background(), newRequest(), nil), ErrRequestAdapterShutdown)
+
+	close(release)
+	assert.Nil(t, adapter.Shutdown(context.Background()))
+	assert.Nil(t, <-done)
+	assert.Equal(t, 2, transport.closed)
+}
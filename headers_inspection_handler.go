@@ -1,27 +1,65 @@
 package nethttplibrary
 
 import (
+	"context"
+	"io"
 	nethttp "net/http"
+	"sync"
 
 	abstractions "github.com/microsoft/kiota-abstractions-go"
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
 
-// HeadersInspectionHandlerOptions is the options to use when inspecting headers
+// HeadersInspectionHandlerOptions is the options to use when inspecting headers.
+// When the options are passed as a request option, the headers of that request only are captured, which is the recommended way to inspect concurrent requests.
+// When the options are set on the handler, the headers of all the requests sent with the client are merged in the same containers:
+// the writes are synchronized, but the containers should only be read once the requests completed.
 type HeadersInspectionOptions struct {
 	InspectRequestHeaders  bool
 	InspectResponseHeaders bool
 	RequestHeaders         *abstractions.RequestHeaders
 	ResponseHeaders        *abstractions.ResponseHeaders
+	// InspectIntermediateResponses captures the headers of every request sent over the network and of its response (redirects, retries, continuous access evaluation reattempts).
+	// It is only honored when the options are passed as a request option, the captured hops of all the requests of a client would grow without bounds otherwise.
+	InspectIntermediateResponses bool
+	// InspectResponseTrailers captures the trailer fields of the response, they are only available once the response body was read entirely
+	InspectResponseTrailers bool
+	// ResponseTrailers are the trailer fields of the response
+	ResponseTrailers *abstractions.ResponseHeaders
+	state            *headersInspectionState
 }
 
+// HeadersInspectionHop holds the headers of a request sent over the network and of its response
+type HeadersInspectionHop struct {
+	// Method is the method of the request
+	Method string
+	// Url is the URL of the request
+	Url string
+	// StatusCode is the status code of the response
+	StatusCode int
+	// RequestHeaders are the headers of the request as sent over the network
+	RequestHeaders *abstractions.RequestHeaders
+	// ResponseHeaders are the headers of the response
+	ResponseHeaders *abstractions.ResponseHeaders
+}
+
+// headersInspectionState synchronizes the writes to the headers containers and holds the captured hops
+type headersInspectionState struct {
+	mutex sync.Mutex
+	hops  []HeadersInspectionHop
+}
+
+// headersInspectionStateMutex guards the lazy initialization of the state of the options created without NewHeadersInspectionOptions
+var headersInspectionStateMutex sync.Mutex
+
 // NewHeadersInspectionOptions creates a new HeadersInspectionOptions with default options
 func NewHeadersInspectionOptions() *HeadersInspectionOptions {
 	return &HeadersInspectionOptions{
-		RequestHeaders:  abstractions.NewRequestHeaders(),
-		ResponseHeaders: abstractions.NewResponseHeaders(),
+		RequestHeaders:   abstractions.NewRequestHeaders(),
+		ResponseHeaders:  abstractions.NewResponseHeaders(),
+		ResponseTrailers: abstractions.NewResponseHeaders(),
+		state:            &headersInspectionState{},
 	}
 }
 
@@ -31,6 +69,13 @@ type headersInspectionOptionsInt interface {
 	GetInspectResponseHeaders() bool
 	GetRequestHeaders() *abstractions.RequestHeaders
 	GetResponseHeaders() *abstractions.ResponseHeaders
+	GetInspectIntermediateResponses() bool
+	GetInspectResponseTrailers() bool
+	GetResponseTrailers() *abstractions.ResponseHeaders
+	addRequestHeaders(headers *abstractions.RequestHeaders)
+	addResponseHeaders(headers *abstractions.ResponseHeaders)
+	addResponseTrailers(trailers *abstractions.ResponseHeaders)
+	addIntermediateResponse(hop HeadersInspectionHop)
 }
 
 var headersInspectionKeyValue = abstractions.RequestOptionKey{
@@ -57,6 +102,75 @@ func (o *HeadersInspectionOptions) GetResponseHeaders() *abstractions.ResponseHe
 	return o.ResponseHeaders
 }
 
+// GetInspectIntermediateResponses returns true if the headers of every request sent over the network and of its response should be captured
+func (o *HeadersInspectionOptions) GetInspectIntermediateResponses() bool {
+	return o.InspectIntermediateResponses
+}
+
+// GetInspectResponseTrailers returns true if the trailer fields of the response should be inspected
+func (o *HeadersInspectionOptions) GetInspectResponseTrailers() bool {
+	return o.InspectResponseTrailers
+}
+
+// GetResponseTrailers returns the trailer fields of the response, they are only populated once the response body was read entirely
+func (o *HeadersInspectionOptions) GetResponseTrailers() *abstractions.ResponseHeaders {
+	return o.ResponseTrailers
+}
+
+// GetIntermediateResponses returns the headers of every request sent over the network and of its response, in order
+func (o *HeadersInspectionOptions) GetIntermediateResponses() []HeadersInspectionHop {
+	state := o.getState()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	return append([]HeadersInspectionHop(nil), state.hops...)
+}
+
+func (o *HeadersInspectionOptions) getState() *headersInspectionState {
+	headersInspectionStateMutex.Lock()
+	defer headersInspectionStateMutex.Unlock()
+	if o.state == nil {
+		o.state = &headersInspectionState{}
+	}
+	return o.state
+}
+
+func (o *HeadersInspectionOptions) addRequestHeaders(headers *abstractions.RequestHeaders) {
+	state := o.getState()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if o.RequestHeaders == nil {
+		o.RequestHeaders = abstractions.NewRequestHeaders()
+	}
+	o.RequestHeaders.AddAll(headers)
+}
+
+func (o *HeadersInspectionOptions) addResponseHeaders(headers *abstractions.ResponseHeaders) {
+	state := o.getState()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if o.ResponseHeaders == nil {
+		o.ResponseHeaders = abstractions.NewResponseHeaders()
+	}
+	o.ResponseHeaders.AddAll(headers)
+}
+
+func (o *HeadersInspectionOptions) addResponseTrailers(trailers *abstractions.ResponseHeaders) {
+	state := o.getState()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if o.ResponseTrailers == nil {
+		o.ResponseTrailers = abstractions.NewResponseHeaders()
+	}
+	o.ResponseTrailers.AddAll(trailers)
+}
+
+func (o *HeadersInspectionOptions) addIntermediateResponse(hop HeadersInspectionHop) {
+	state := o.getState()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	state.hops = append(state.hops, hop)
+}
+
 // GetKey returns the key for the HeadersInspectionOptions
 func (o *HeadersInspectionOptions) GetKey() abstractions.RequestOptionKey {
 	return headersInspectionKeyValue
@@ -74,6 +188,7 @@ func NewHeadersInspectionHandler() *HeadersInspectionHandler {
 
 // NewHeadersInspectionHandlerWithOptions creates a new HeadersInspectionHandler with the given options
 func NewHeadersInspectionHandlerWithOptions(options HeadersInspectionOptions) *HeadersInspectionHandler {
+	options.getState()
 	return &HeadersInspectionHandler{options: options}
 }
 
@@ -85,36 +200,100 @@ func (middleware HeadersInspectionHandler) Intercept(pipeline Pipeline, middlewa
 	var observabilityName string
 	if obsOptions != nil {
 		observabilityName = obsOptions.GetTracerInstrumentationName()
-		ctx, span = otel.GetTracerProvider().Tracer(observabilityName).Start(ctx, "HeadersInspectionHandler_Intercept")
+		ctx, span = getTracerProvider(req.Context()).Tracer(observabilityName).Start(ctx, "HeadersInspectionHandler_Intercept")
 		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.headersInspection.enable", true))
 		defer span.End()
 		req = req.WithContext(ctx)
 	}
-	reqOption, ok := req.Context().Value(headersInspectionKeyValue).(headersInspectionOptionsInt)
-	if !ok {
+	reqOption, isRequestOption := req.Context().Value(headersInspectionKeyValue).(headersInspectionOptionsInt)
+	if !isRequestOption {
 		reqOption = &middleware.options
 	}
 	if reqOption.GetInspectRequestHeaders() {
-		for k, v := range req.Header {
-			if len(v) == 1 {
-				reqOption.GetRequestHeaders().Add(k, v[0])
-			} else {
-				reqOption.GetRequestHeaders().Add(k, v[0], v[1:]...)
-			}
-		}
+		reqOption.addRequestHeaders(getInspectedRequestHeaders(req.Header))
+	}
+	if isRequestOption && reqOption.GetInspectIntermediateResponses() {
+		req = req.WithContext(context.WithValue(req.Context(), headersInspectionHopsKey{}, reqOption))
 	}
 	response, err := pipeline.Next(req, middlewareIndex)
 	if err != nil {
 		return response, err
 	}
 	if reqOption.GetInspectResponseHeaders() {
-		for k, v := range response.Header {
-			if len(v) == 1 {
-				reqOption.GetResponseHeaders().Add(k, v[0])
-			} else {
-				reqOption.GetResponseHeaders().Add(k, v[0], v[1:]...)
-			}
-		}
+		reqOption.addResponseHeaders(getInspectedResponseHeaders(response.Header))
+	}
+	if reqOption.GetInspectResponseTrailers() && response != nil && response.Body != nil {
+		response.Body = &trailersInspectionBody{ReadCloser: response.Body, response: response, options: reqOption}
 	}
 	return response, err
 }
+
+// trailersInspectionBody captures the trailer fields of the response once its body was read entirely or closed
+type trailersInspectionBody struct {
+	io.ReadCloser
+	response *nethttp.Response
+	options  headersInspectionOptionsInt
+	once     sync.Once
+}
+
+func (b *trailersInspectionBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.captureTrailers()
+	}
+	return n, err
+}
+
+func (b *trailersInspectionBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.captureTrailers()
+	return err
+}
+
+func (b *trailersInspectionBody) captureTrailers() {
+	b.once.Do(func() {
+		b.options.addResponseTrailers(getInspectedResponseHeaders(b.response.Trailer))
+	})
+}
+
+type headersInspectionHopsKey struct{}
+
+// inspectHop captures the headers of the request sent over the network and of its response when the headers inspection handler requested it
+func inspectHop(req *nethttp.Request, resp *nethttp.Response) {
+	options, ok := req.Context().Value(headersInspectionHopsKey{}).(headersInspectionOptionsInt)
+	if !ok {
+		return
+	}
+	hop := HeadersInspectionHop{
+		Method:          req.Method,
+		StatusCode:      resp.StatusCode,
+		RequestHeaders:  getInspectedRequestHeaders(req.Header),
+		ResponseHeaders: getInspectedResponseHeaders(resp.Header),
+	}
+	if req.URL != nil {
+		hop.Url = req.URL.String()
+	}
+	options.addIntermediateResponse(hop)
+}
+
+// getInspectedRequestHeaders copies the native headers in a fresh container owned by the request being inspected
+func getInspectedRequestHeaders(header nethttp.Header) *abstractions.RequestHeaders {
+	result := abstractions.NewRequestHeaders()
+	for k, v := range header {
+		if len(v) > 0 {
+			result.Add(k, v[0], v[1:]...)
+		}
+	}
+	return result
+}
+
+// getInspectedResponseHeaders copies the native headers in a fresh container owned by the response being inspected
+func getInspectedResponseHeaders(header nethttp.Header) *abstractions.ResponseHeaders {
+	result := abstractions.NewResponseHeaders()
+	for k, v := range header {
+		if len(v) > 0 {
+			result.Add(k, v[0], v[1:]...)
+		}
+	}
+	return result
+}
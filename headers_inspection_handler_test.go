@@ -1,8 +1,12 @@
 package nethttplibrary
 
 import (
+	"context"
+	"io"
 	nethttp "net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync"
 	"testing"
 
 	abs "github.com/microsoft/kiota-abstractions-go"
@@ -73,3 +77,141 @@ func TestItGetsResponseHeaders(t *testing.T) {
 	assert.Equal(t, "test", options.GetResponseHeaders().Get("test")[0])
 	assert.Empty(t, options.GetRequestHeaders().ListKeys())
 }
+
+func TestItCapturesTheIntermediateResponses(t *testing.T) {
+	callCount := 0
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		callCount++
+		res.Header().Set("X-Call", strconv.Itoa(callCount))
+		if req.URL.Path == "/a" {
+			nethttp.Redirect(res, req, "/b", nethttp.StatusFound)
+			return
+		}
+		if callCount == 2 {
+			res.Header().Set("Retry-After", "0")
+			res.WriteHeader(503)
+			return
+		}
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	client := GetDefaultClient(NewHeadersInspectionHandler(), NewRedirectHandler(), NewRetryHandler(), NewNetworkObservabilityHandler())
+	options := NewHeadersInspectionOptions()
+	options.InspectIntermediateResponses = true
+	options.InspectResponseHeaders = true
+	req, _ := nethttp.NewRequestWithContext(context.WithValue(context.Background(), headersInspectionKeyValue, options), nethttp.MethodGet, testServer.URL+"/a", nil)
+
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	hops := options.GetIntermediateResponses()
+	if assert.Equal(t, 3, len(hops)) {
+		assert.Equal(t, []int{302, 503, 200}, []int{hops[0].StatusCode, hops[1].StatusCode, hops[2].StatusCode})
+		assert.Equal(t, testServer.URL+"/a", hops[0].Url)
+		assert.Equal(t, testServer.URL+"/b", hops[1].Url)
+		assert.Equal(t, []string{"1"}, hops[0].ResponseHeaders.Get("X-Call"))
+		assert.Equal(t, []string{"1"}, hops[2].RequestHeaders.Get("Retry-Attempt"))
+		assert.Equal(t, "GET", hops[2].Method)
+	}
+	assert.Equal(t, []string{"3"}, options.GetResponseHeaders().Get("X-Call"))
+}
+
+func TestItDoesNotCaptureTheIntermediateResponsesByDefault(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	options := &HeadersInspectionOptions{InspectResponseHeaders: true, ResponseHeaders: abs.NewResponseHeaders(), RequestHeaders: abs.NewRequestHeaders()}
+	client := GetDefaultClient(NewHeadersInspectionHandlerWithOptions(*options))
+
+	_, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Empty(t, options.GetIntermediateResponses())
+}
+
+func TestItOnlyCapturesTheIntermediateResponsesOfRequestOptions(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	options := NewHeadersInspectionOptions()
+	options.InspectIntermediateResponses = true
+	client := GetDefaultClient(NewHeadersInspectionHandlerWithOptions(*options), NewNetworkObservabilityHandler())
+
+	for i := 0; i < 3; i++ {
+		_, err := client.Get(testServer.URL)
+		assert.Nil(t, err)
+	}
+	assert.Empty(t, options.GetIntermediateResponses())
+}
+
+func TestItInspectsConcurrentRequests(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.Header().Set("X-Request", req.Header.Get("X-Request"))
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	sharedOptions := NewHeadersInspectionOptions()
+	sharedOptions.InspectRequestHeaders = true
+	sharedOptions.InspectResponseHeaders = true
+	client := GetDefaultClient(NewHeadersInspectionHandlerWithOptions(*sharedOptions))
+
+	const requestCount = 20
+	requestOptions := make([]*HeadersInspectionOptions, requestCount)
+	var wg sync.WaitGroup
+	for i := 0; i < requestCount; i++ {
+		requestOptions[i] = NewHeadersInspectionOptions()
+		requestOptions[i].InspectResponseHeaders = true
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			req, _ := nethttp.NewRequest(nethttp.MethodGet, testServer.URL, nil)
+			req.Header.Set("X-Request", strconv.Itoa(i))
+			_, err := client.Do(req)
+			assert.Nil(t, err)
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			req, _ := nethttp.NewRequestWithContext(context.WithValue(context.Background(), headersInspectionKeyValue, requestOptions[i]), nethttp.MethodGet, testServer.URL, nil)
+			req.Header.Set("X-Request", "request-"+strconv.Itoa(i))
+			_, err := client.Do(req)
+			assert.Nil(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, requestCount, len(sharedOptions.GetRequestHeaders().Get("X-Request")))
+	assert.Equal(t, requestCount, len(sharedOptions.GetResponseHeaders().Get("X-Request")))
+	for i, options := range requestOptions {
+		assert.Equal(t, []string{"request-" + strconv.Itoa(i)}, options.GetResponseHeaders().Get("X-Request"))
+	}
+}
+
+func TestItGetsResponseTrailers(t *testing.T) {
+	options := NewHeadersInspectionOptions()
+	options.InspectResponseTrailers = true
+	handler := NewHeadersInspectionHandlerWithOptions(*options)
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.Header().Set("Trailer", "X-Checksum")
+		res.WriteHeader(200)
+		res.Write([]byte("body"))
+		res.Header().Set("X-Checksum", "abc")
+	}))
+	defer func() { testServer.Close() }()
+	req, err := nethttp.NewRequest(nethttp.MethodGet, testServer.URL, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	resp, err := handler.Intercept(newNoopPipeline(), 0, req)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Empty(t, options.GetResponseTrailers().ListKeys())
+
+	body, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, "body", string(body))
+	resp.Body.Close()
+	assert.Equal(t, []string{"abc"}, options.GetResponseTrailers().Get("X-Checksum"))
+	assert.Empty(t, options.GetResponseHeaders().ListKeys())
+}
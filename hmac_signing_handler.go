@@ -0,0 +1,207 @@
+package nethttplibrary
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HmacSigningHandler signs outgoing requests with a shared secret and adds the signature to a configurable header.
+// The signed payload is composed of the request method, path and query, a unix timestamp and the hex encoded SHA-256 hash of the body, separated by new lines.
+type HmacSigningHandler struct {
+	options HmacSigningHandlerOptions
+}
+
+// HmacSigningHandlerOptions to use when signing the requests.
+type HmacSigningHandlerOptions struct {
+	// Enabled defines whether requests should be signed
+	Enabled bool
+	// Secret is the shared secret used as the HMAC key
+	Secret []byte
+	// HeaderName is the name of the header the signature is written to
+	HeaderName string
+	// HeaderTemplate is the template used to build the header value. {signature} and {timestamp} are replaced by their values.
+	HeaderTemplate string
+	// TimestampHeaderName is the name of an optional header the timestamp is written to
+	TimestampHeaderName string
+	// HashFunc is the hash function used to compute the HMAC, SHA-256 when nil
+	HashFunc func() hash.Hash
+}
+
+const defaultHmacSignatureHeaderName = "X-Signature"
+const defaultHmacSignatureHeaderTemplate = "{signature}"
+const hmacSignatureTemplatePlaceholder = "{signature}"
+const hmacTimestampTemplatePlaceholder = "{timestamp}"
+
+var hmacSigningKeyValue = abs.RequestOptionKey{
+	Key: "HmacSigningHandler",
+}
+
+type hmacSigningHandlerOptionsInt interface {
+	abs.RequestOption
+	GetEnabled() bool
+	GetSecret() []byte
+	GetHeaderName() string
+	GetHeaderTemplate() string
+	GetTimestampHeaderName() string
+	GetHashFunc() func() hash.Hash
+}
+
+// NewHmacSigningHandlerOptions creates a new HmacSigningHandlerOptions with the given secret and the default values.
+func NewHmacSigningHandlerOptions(secret []byte) *HmacSigningHandlerOptions {
+	return &HmacSigningHandlerOptions{
+		Enabled:        true,
+		Secret:         secret,
+		HeaderName:     defaultHmacSignatureHeaderName,
+		HeaderTemplate: defaultHmacSignatureHeaderTemplate,
+		HashFunc:       sha256.New,
+	}
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *HmacSigningHandlerOptions) GetKey() abs.RequestOptionKey {
+	return hmacSigningKeyValue
+}
+
+// GetEnabled returns whether requests should be signed
+func (options *HmacSigningHandlerOptions) GetEnabled() bool {
+	return options.Enabled
+}
+
+// GetSecret returns the shared secret used as the HMAC key
+func (options *HmacSigningHandlerOptions) GetSecret() []byte {
+	return options.Secret
+}
+
+// GetHeaderName returns the name of the header the signature is written to
+func (options *HmacSigningHandlerOptions) GetHeaderName() string {
+	if options.HeaderName == "" {
+		return defaultHmacSignatureHeaderName
+	}
+	return options.HeaderName
+}
+
+// GetHeaderTemplate returns the template used to build the signature header value
+func (options *HmacSigningHandlerOptions) GetHeaderTemplate() string {
+	if options.HeaderTemplate == "" {
+		return defaultHmacSignatureHeaderTemplate
+	}
+	return options.HeaderTemplate
+}
+
+// GetTimestampHeaderName returns the name of the header the timestamp is written to
+func (options *HmacSigningHandlerOptions) GetTimestampHeaderName() string {
+	return options.TimestampHeaderName
+}
+
+// GetHashFunc returns the hash function used to compute the HMAC
+func (options *HmacSigningHandlerOptions) GetHashFunc() func() hash.Hash {
+	if options.HashFunc == nil {
+		return sha256.New
+	}
+	return options.HashFunc
+}
+
+// NewHmacSigningHandler creates a new HmacSigningHandler signing requests with the given secret
+func NewHmacSigningHandler(secret []byte) (*HmacSigningHandler, error) {
+	return NewHmacSigningHandlerWithOptions(*NewHmacSigningHandlerOptions(secret))
+}
+
+// NewHmacSigningHandlerWithOptions creates a new HmacSigningHandler with the given options
+func NewHmacSigningHandlerWithOptions(options HmacSigningHandlerOptions) (*HmacSigningHandler, error) {
+	if options.Enabled && len(options.Secret) == 0 {
+		return nil, errors.New("secret cannot be empty")
+	}
+	return &HmacSigningHandler{options: options}, nil
+}
+
+// Intercept implements the interface and signs the request before moving it through the pipeline.
+func (middleware HmacSigningHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(hmacSigningKeyValue).(hmacSigningHandlerOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	ctx := req.Context()
+	var span trace.Span
+	if obsOptions != nil {
+		ctx, span = getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "HmacSigningHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.hmac_signing.enable", reqOption.GetEnabled()))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	if !reqOption.GetEnabled() {
+		return pipeline.Next(req, middlewareIndex)
+	}
+	if len(reqOption.GetSecret()) == 0 {
+		err := errors.New("secret cannot be empty")
+		if span != nil {
+			span.RecordError(err)
+		}
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature, err := computeHmacSignature(req, timestamp, reqOption.GetSecret(), reqOption.GetHashFunc())
+	if err != nil {
+		if span != nil {
+			span.RecordError(err)
+		}
+		return nil, err
+	}
+	headerValue := strings.ReplaceAll(reqOption.GetHeaderTemplate(), hmacSignatureTemplatePlaceholder, signature)
+	headerValue = strings.ReplaceAll(headerValue, hmacTimestampTemplatePlaceholder, timestamp)
+	req.Header.Set(reqOption.GetHeaderName(), headerValue)
+	if reqOption.GetTimestampHeaderName() != "" {
+		req.Header.Set(reqOption.GetTimestampHeaderName(), timestamp)
+	}
+	return pipeline.Next(req, middlewareIndex)
+}
+
+// computeHmacSignature returns the base64 encoded signature of the request, restoring the body so it can still be sent
+func computeHmacSignature(req *nethttp.Request, timestamp string, secret []byte, hashFunc func() hash.Hash) (string, error) {
+	body, err := readAndRestoreRequestBody(req)
+	if err != nil {
+		return "", err
+	}
+	bodyHash := sha256.Sum256(body)
+	payload := strings.Join([]string{
+		strings.ToUpper(req.Method),
+		req.URL.RequestURI(),
+		timestamp,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+	mac := hmac.New(hashFunc, secret)
+	mac.Write([]byte(payload))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// readAndRestoreRequestBody reads the request body and rewinds or replaces it so it can be read again
+func readAndRestoreRequestBody(req *nethttp.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == nethttp.NoBody {
+		return []byte{}, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := req.Body.(io.Seeker); ok {
+		if _, err := s.Seek(0, io.SeekStart); err == nil {
+			return body, nil
+		}
+	}
+	req.Body = NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,71 @@
+package nethttplibrary
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItRequiresASecretForHmacSigning(t *testing.T) {
+	_, err := NewHmacSigningHandler(nil)
+	assert.Error(t, err)
+}
+
+func TestItSignsTheRequest(t *testing.T) {
+	secret := []byte("secret")
+	var signatureHeader, timestampHeader, receivedBody string
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		signatureHeader = req.Header.Get("X-Signature")
+		timestampHeader = req.Header.Get("X-Timestamp")
+		body, _ := io.ReadAll(req.Body)
+		receivedBody = string(body)
+		res.WriteHeader(200)
+		res.Write([]byte("body"))
+	}))
+	defer func() { testServer.Close() }()
+	options := NewHmacSigningHandlerOptions(secret)
+	options.HeaderTemplate = "HMAC {signature}"
+	options.TimestampHeaderName = "X-Timestamp"
+	handler, err := NewHmacSigningHandlerWithOptions(*options)
+	assert.Nil(t, err)
+	req, err := nethttp.NewRequest(nethttp.MethodPost, testServer.URL+"/users?top=1", strings.NewReader("content"))
+	if err != nil {
+		t.Error(err)
+	}
+	resp, err := handler.Intercept(newNoopPipeline(), 0, req)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.NotNil(t, resp)
+	assert.Equal(t, "content", receivedBody)
+	assert.NotEmpty(t, timestampHeader)
+
+	bodyHash := sha256.Sum256([]byte("content"))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("POST\n/users?top=1\n" + timestampHeader + "\n" + hex.EncodeToString(bodyHash[:])))
+	assert.Equal(t, "HMAC "+base64.StdEncoding.EncodeToString(mac.Sum(nil)), signatureHeader)
+}
+
+func TestItDoesNotSignTheRequestWhenDisabled(t *testing.T) {
+	handler, err := NewHmacSigningHandler([]byte("secret"))
+	assert.Nil(t, err)
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://example.com", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	options := NewHmacSigningHandlerOptions([]byte("secret"))
+	options.Enabled = false
+	req = req.WithContext(context.WithValue(req.Context(), hmacSigningKeyValue, options))
+	pipeline := newSpyPipeline()
+	_, _ = handler.Intercept(pipeline, 0, req)
+	assert.Empty(t, pipeline.GetReceivedRequest().Header.Get("X-Signature"))
+}
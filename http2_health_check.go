@@ -0,0 +1,43 @@
+package nethttplibrary
+
+import (
+	"errors"
+	nethttp "net/http"
+	"time"
+)
+
+const defaultHttp2PingTimeout = 15 * time.Second
+
+// Http2HealthCheckOptions to use when detecting the stalled HTTP/2 connections, e.g. dropped by a NAT.
+type Http2HealthCheckOptions struct {
+	// ReadIdleTimeout is the duration after which a ping is sent when no frame was received on a connection
+	ReadIdleTimeout time.Duration
+	// PingTimeout is the duration after which the connection is closed when the ping isn't answered, defaults to 15 seconds
+	PingTimeout time.Duration
+}
+
+func (options Http2HealthCheckOptions) validate() (Http2HealthCheckOptions, error) {
+	if options.ReadIdleTimeout <= 0 {
+		return options, errors.New("read idle timeout must be positive")
+	}
+	if options.PingTimeout < 0 {
+		return options, errors.New("ping timeout cannot be negative")
+	}
+	if options.PingTimeout == 0 {
+		options.PingTimeout = defaultHttp2PingTimeout
+	}
+	return options, nil
+}
+
+// ConfigureHttp2HealthCheck configures the transport to ping the idle HTTP/2 connections and recycle the ones not answering.
+// It requires go 1.24 or later, an error is returned otherwise.
+func ConfigureHttp2HealthCheck(transport *nethttp.Transport, options Http2HealthCheckOptions) error {
+	if transport == nil {
+		return errors.New("transport cannot be nil")
+	}
+	options, err := options.validate()
+	if err != nil {
+		return err
+	}
+	return configureHttp2HealthCheck(transport, options)
+}
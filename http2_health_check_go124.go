@@ -0,0 +1,16 @@
+//go:build go1.24
+
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+)
+
+func configureHttp2HealthCheck(transport *nethttp.Transport, options Http2HealthCheckOptions) error {
+	if transport.HTTP2 == nil {
+		transport.HTTP2 = &nethttp.HTTP2Config{}
+	}
+	transport.HTTP2.SendPingTimeout = options.ReadIdleTimeout
+	transport.HTTP2.PingTimeout = options.PingTimeout
+	return nil
+}
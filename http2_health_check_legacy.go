@@ -0,0 +1,12 @@
+//go:build !go1.24
+
+package nethttplibrary
+
+import (
+	"errors"
+	nethttp "net/http"
+)
+
+func configureHttp2HealthCheck(transport *nethttp.Transport, options Http2HealthCheckOptions) error {
+	return errors.New("the HTTP/2 health check requires go 1.24 or later")
+}
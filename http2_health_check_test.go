@@ -0,0 +1,33 @@
+//go:build go1.24
+
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItConfiguresTheHttp2HealthCheck(t *testing.T) {
+	transport := &nethttp.Transport{}
+	err := ConfigureHttp2HealthCheck(transport, Http2HealthCheckOptions{ReadIdleTimeout: 30 * time.Second})
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Second, transport.HTTP2.SendPingTimeout)
+	assert.Equal(t, 15*time.Second, transport.HTTP2.PingTimeout)
+
+	client, err := NewKiotaClientBuilder().WithHttp2HealthCheck(10*time.Second, 5*time.Second).Build()
+	assert.Nil(t, err)
+	parent := client.Transport.(*customTransport).middlewarePipeline.transport.(*nethttp.Transport)
+	assert.Equal(t, 10*time.Second, parent.HTTP2.SendPingTimeout)
+	assert.Equal(t, 5*time.Second, parent.HTTP2.PingTimeout)
+	assert.True(t, parent.ForceAttemptHTTP2)
+}
+
+func TestItValidatesTheHttp2HealthCheckOptions(t *testing.T) {
+	assert.Error(t, ConfigureHttp2HealthCheck(nil, Http2HealthCheckOptions{ReadIdleTimeout: time.Second}))
+	assert.Error(t, ConfigureHttp2HealthCheck(&nethttp.Transport{}, Http2HealthCheckOptions{}))
+	_, err := NewKiotaClientBuilder().WithHttp2HealthCheck(time.Second, -1).Build()
+	assert.Error(t, err)
+}
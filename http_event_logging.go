@@ -0,0 +1,143 @@
+package nethttplibrary
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogSeverity is the severity of a log record, the values match the OpenTelemetry log data model severity numbers
+type LogSeverity int
+
+const (
+	// DebugLogSeverity is used for the request start events
+	DebugLogSeverity LogSeverity = 5
+	// InfoLogSeverity is used for the request finish and redirect events
+	InfoLogSeverity LogSeverity = 9
+	// WarnLogSeverity is used for the retry events
+	WarnLogSeverity LogSeverity = 13
+	// ErrorLogSeverity is used for the request errors
+	ErrorLogSeverity LogSeverity = 17
+)
+
+const (
+	// RequestStartLogEventName is the name of the event emitted before a request is sent over the network
+	RequestStartLogEventName = "com.microsoft.kiota.http.request.start"
+	// RequestFinishLogEventName is the name of the event emitted when the response of a request is received
+	RequestFinishLogEventName = "com.microsoft.kiota.http.request.finish"
+	// RequestErrorLogEventName is the name of the event emitted when a request fails without response
+	RequestErrorLogEventName = "com.microsoft.kiota.http.request.error"
+	// RequestRetryLogEventName is the name of the event emitted when the retry handler retries a request
+	RequestRetryLogEventName = "com.microsoft.kiota.http.request.retry"
+	// RequestRedirectLogEventName is the name of the event emitted when the redirect handler follows a redirect
+	RequestRedirectLogEventName = "com.microsoft.kiota.http.request.redirect"
+	// InsecureSkipVerifyLogEventName is the name of the warning emitted when a client not verifying the TLS certificates is built
+	InsecureSkipVerifyLogEventName = "com.microsoft.kiota.http.client.insecure_skip_verify"
+)
+
+var (
+	requestDurationAttribute  = attribute.Key("com.microsoft.kiota.request.duration_ms")
+	errorTypeAttribute        = attribute.Key("error.type")
+	exceptionMessageAttribute = attribute.Key("exception.message")
+	redirectLocationAttribute = attribute.Key("com.microsoft.kiota.handler.redirect.location")
+)
+
+// LogRecord is a structured log record describing an HTTP event
+type LogRecord struct {
+	// Timestamp is the time the event occurred at
+	Timestamp time.Time
+	// Severity is the severity of the event
+	Severity LogSeverity
+	// EventName is the name of the event (e.g. com.microsoft.kiota.http.request.finish)
+	EventName string
+	// Body is a human readable description of the event
+	Body string
+	// Attributes are the attributes describing the event, using the same keys as the spans
+	Attributes []attribute.KeyValue
+	// SpanContext is the context of the active span, to correlate the logs with the traces
+	SpanContext trace.SpanContext
+}
+
+// Logger emits the log records, it can be bridged to an OpenTelemetry logger or any logging library
+type Logger interface {
+	// Emit emits the log record
+	Emit(ctx context.Context, record LogRecord)
+}
+
+// LoggerProvider provides the loggers used to emit the HTTP events
+type LoggerProvider interface {
+	// Logger returns the logger for the given instrumentation name
+	Logger(name string) Logger
+}
+
+// LoggerFunc is a function implementing the Logger interface
+type LoggerFunc func(ctx context.Context, record LogRecord)
+
+// Emit calls the function
+func (f LoggerFunc) Emit(ctx context.Context, record LogRecord) {
+	f(ctx, record)
+}
+
+// LoggerProviderFunc is a function implementing the LoggerProvider interface
+type LoggerProviderFunc func(name string) Logger
+
+// Logger calls the function
+func (f LoggerProviderFunc) Logger(name string) Logger {
+	return f(name)
+}
+
+// loggerProviderOptionsInt is implemented by the observability options which can provide a logger provider
+type loggerProviderOptionsInt interface {
+	GetLoggerProvider() LoggerProvider
+}
+
+// getHttpEventLogger returns the logger configured in the observability options of the request or nil
+func getHttpEventLogger(req *nethttp.Request) Logger {
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	if obsOptions == nil {
+		return nil
+	}
+	providerOptions, ok := obsOptions.(loggerProviderOptionsInt)
+	if !ok || providerOptions.GetLoggerProvider() == nil {
+		return nil
+	}
+	return providerOptions.GetLoggerProvider().Logger(obsOptions.GetTracerInstrumentationName())
+}
+
+// emitHttpEvent emits a log record for the request when a logger provider is configured in the observability options
+func emitHttpEvent(req *nethttp.Request, severity LogSeverity, eventName string, body string, attributes ...attribute.KeyValue) {
+	logger := getHttpEventLogger(req)
+	if logger == nil {
+		return
+	}
+	ctx := req.Context()
+	logger.Emit(ctx, LogRecord{
+		Timestamp:   time.Now(),
+		Severity:    severity,
+		EventName:   eventName,
+		Body:        body,
+		Attributes:  append(getRequestLogAttributes(req), attributes...),
+		SpanContext: trace.SpanContextFromContext(ctx),
+	})
+}
+
+func getRequestLogAttributes(req *nethttp.Request) []attribute.KeyValue {
+	attributes := []attribute.KeyValue{httpRequestMethodAttribute.String(req.Method)}
+	if req.URL == nil {
+		return attributes
+	}
+	attributes = append(attributes, serverAddressAttribute.String(req.URL.Hostname()))
+	if obsOptions := GetObservabilityOptionsFromRequest(req); obsOptions != nil && obsOptions.GetIncludeEUIIAttributes() {
+		attributes = append(attributes, urlFullAttribute.String(getRedactor(req).RedactUrl(req.URL)))
+	}
+	return attributes
+}
+
+// getErrorType returns the type of the error (e.g. *net.OpError) for the error.type attribute
+func getErrorType(err error) string {
+	return fmt.Sprintf("%T", err)
+}
@@ -0,0 +1,88 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLoggerProvider struct {
+	mutex   sync.Mutex
+	records []LogRecord
+}
+
+func (p *testLoggerProvider) Logger(name string) Logger {
+	return LoggerFunc(func(ctx context.Context, record LogRecord) {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		p.records = append(p.records, record)
+	})
+}
+
+func (p *testLoggerProvider) getEventNames() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	names := make([]string, len(p.records))
+	for i, record := range p.records {
+		names[i] = record.EventName
+	}
+	return names
+}
+
+func TestItEmitsLogRecordsForTheHttpEvents(t *testing.T) {
+	callCount := 0
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		callCount++
+		switch {
+		case req.URL.Path == "/redirect":
+			nethttp.Redirect(res, req, "/target", nethttp.StatusFound)
+		case callCount == 2:
+			res.Header().Set("Retry-After", "0")
+			res.WriteHeader(503)
+		default:
+			res.WriteHeader(200)
+		}
+	}))
+	defer testServer.Close()
+	loggerProvider := &testLoggerProvider{}
+	client := GetDefaultClient(&observabilityOptionsHandler{options: ObservabilityOptions{LoggerProvider: loggerProvider}}, NewRetryHandler(), NewRedirectHandler(), NewNetworkObservabilityHandler())
+
+	resp, err := client.Get(testServer.URL + "/redirect")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, []string{
+		RequestStartLogEventName,
+		RequestFinishLogEventName,
+		RequestRedirectLogEventName,
+		RequestStartLogEventName,
+		RequestFinishLogEventName,
+		RequestRetryLogEventName,
+		RequestStartLogEventName,
+		RequestFinishLogEventName,
+		RequestRedirectLogEventName,
+		RequestStartLogEventName,
+		RequestFinishLogEventName,
+	}, loggerProvider.getEventNames())
+	finish := loggerProvider.records[1]
+	assert.Equal(t, InfoLogSeverity, finish.Severity)
+	assert.Contains(t, finish.Attributes, httpResponseStatusCodeAttribute.Int(302))
+	assert.Contains(t, finish.Attributes, httpRequestMethodAttribute.String("GET"))
+	assert.Equal(t, WarnLogSeverity, loggerProvider.records[5].Severity)
+}
+
+func TestItEmitsALogRecordForRequestErrors(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {}))
+	url := testServer.URL
+	testServer.Close()
+	loggerProvider := &testLoggerProvider{}
+	client := GetDefaultClient(&observabilityOptionsHandler{options: ObservabilityOptions{LoggerProvider: loggerProvider}}, NewNetworkObservabilityHandler())
+
+	_, err := client.Get(url)
+	assert.Error(t, err)
+	assert.Equal(t, []string{RequestStartLogEventName, RequestErrorLogEventName}, loggerProvider.getEventNames())
+	assert.Equal(t, ErrorLogSeverity, loggerProvider.records[1].Severity)
+}
@@ -0,0 +1,564 @@
+package nethttplibrary
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"net/url"
+	"os"
+	"time"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+)
+
+// KiotaClientBuilder builds net/http clients and request adapters configured for Kiota with chained methods.
+// Errors are accumulated and returned by Build.
+type KiotaClientBuilder struct {
+	timeout                *time.Duration
+	proxyUrl               *url.URL
+	proxyFunc              func(*nethttp.Request) (*url.URL, error)
+	proxyAutoConfig        ProxyAutoConfigEvaluator
+	transport              nethttp.RoundTripper
+	bareTransport          bool
+	noEnvProxy             bool
+	tlsConfigurators       []func(*tls.Config)
+	insecure               bool
+	loggerProvider         LoggerProvider
+	transportConfigurators []func(*nethttp.Transport)
+	dialContext            func(ctx context.Context, network string, address string) (net.Conn, error)
+	resolver               *net.Resolver
+	dnsCache               *DnsCacheOptions
+	connectionLimits       *ConnectionLimitsOptions
+	http2HealthCheck       *Http2HealthCheckOptions
+	nativeRedirects        bool
+	maxRedirects           int
+	cookieJar              nethttp.CookieJar
+	middlewares            []Middleware
+	middlewareOptions      []abs.RequestOption
+	environment            *EnvironmentConfiguration
+	err                    error
+}
+
+// NewKiotaClientBuilder creates a new KiotaClientBuilder
+func NewKiotaClientBuilder() *KiotaClientBuilder {
+	return &KiotaClientBuilder{}
+}
+
+func (b *KiotaClientBuilder) setError(err error) *KiotaClientBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// WithTimeout sets the timeout of the client, 0 means no timeout
+func (b *KiotaClientBuilder) WithTimeout(timeout time.Duration) *KiotaClientBuilder {
+	if timeout < 0 {
+		return b.setError(errors.New("timeout cannot be negative"))
+	}
+	b.timeout = &timeout
+	return b
+}
+
+// WithProxy sets the url of the proxy the requests are sent through
+func (b *KiotaClientBuilder) WithProxy(proxyUrlStr string) *KiotaClientBuilder {
+	proxyUrl, err := url.Parse(proxyUrlStr)
+	if err != nil {
+		return b.setError(err)
+	}
+	b.proxyUrl = proxyUrl
+	return b
+}
+
+// WithAuthenticatedProxy sets the url and the credentials of the proxy the requests are sent through
+func (b *KiotaClientBuilder) WithAuthenticatedProxy(proxyUrlStr string, username string, password string) *KiotaClientBuilder {
+	b.WithProxy(proxyUrlStr)
+	if b.proxyUrl != nil {
+		b.proxyUrl.User = url.UserPassword(username, password)
+	}
+	return b
+}
+
+// WithProxyAutoConfig selects the proxy of each request by evaluating a proxy auto-configuration (PAC) script,
+// the entries of the result are tried in order when the connection to a proxy fails
+func (b *KiotaClientBuilder) WithProxyAutoConfig(evaluator ProxyAutoConfigEvaluator) *KiotaClientBuilder {
+	if evaluator == nil {
+		return b.setError(errors.New("evaluator cannot be nil"))
+	}
+	b.proxyFunc = NewProxyAutoConfigProxyFunc(evaluator)
+	b.proxyAutoConfig = evaluator
+	return b
+}
+
+// WithTransport sets the parent transport the middleware pipeline relies on to send the requests
+func (b *KiotaClientBuilder) WithTransport(transport nethttp.RoundTripper) *KiotaClientBuilder {
+	if transport == nil {
+		return b.setError(errors.New("transport cannot be nil"))
+	}
+	b.transport = transport
+	return b
+}
+
+// WithBareTransport opts out of the default transport settings (HTTP/2, proxy from environment, dial timeouts...) derived from http.DefaultTransport
+// and relies on a bare http.Transport instead. It is ignored when a transport is set with WithTransport.
+func (b *KiotaClientBuilder) WithBareTransport() *KiotaClientBuilder {
+	b.bareTransport = true
+	return b
+}
+
+// WithoutEnvironmentProxy stops reading the proxy from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+// It applies to the default transport and to transports set with WithTransport when they are a *http.Transport.
+func (b *KiotaClientBuilder) WithoutEnvironmentProxy() *KiotaClientBuilder {
+	b.noEnvProxy = true
+	return b
+}
+
+// WithUnixSocket sends all the requests over the Unix domain socket at the given path, whatever the host of their url (e.g. http://unix/v1/items).
+// No proxy is used for these requests.
+func (b *KiotaClientBuilder) WithUnixSocket(socketPath string) *KiotaClientBuilder {
+	if socketPath == "" {
+		return b.setError(errors.New("socketPath cannot be empty"))
+	}
+	b.noEnvProxy = true
+	b.transportConfigurators = append(b.transportConfigurators, func(transport *nethttp.Transport) {
+		configureUnixSocket(transport, socketPath)
+	})
+	return b
+}
+
+// WithDialContext sets the function used to establish the connections to the servers
+func (b *KiotaClientBuilder) WithDialContext(dialContext func(ctx context.Context, network string, address string) (net.Conn, error)) *KiotaClientBuilder {
+	if dialContext == nil {
+		return b.setError(errors.New("dialContext cannot be nil"))
+	}
+	b.dialContext = dialContext
+	return b
+}
+
+// WithResolver sets the resolver used to look up the hosts, e.g. to honor split-horizon DNS setups. It is ignored when WithDialContext is used.
+func (b *KiotaClientBuilder) WithResolver(resolver *net.Resolver) *KiotaClientBuilder {
+	if resolver == nil {
+		return b.setError(errors.New("resolver cannot be nil"))
+	}
+	b.resolver = resolver
+	return b
+}
+
+// WithDnsCache caches the host name lookups for the given duration, 0 meaning the default 30 seconds. It is ignored when WithDialContext is used.
+func (b *KiotaClientBuilder) WithDnsCache(ttl time.Duration) *KiotaClientBuilder {
+	if ttl < 0 {
+		return b.setError(errors.New("ttl cannot be negative"))
+	}
+	b.dnsCache = &DnsCacheOptions{TTL: ttl}
+	return b
+}
+
+// getDialContext returns the dial function configured on the builder, nil when none is
+func (b *KiotaClientBuilder) getDialContext() (func(ctx context.Context, network string, address string) (net.Conn, error), error) {
+	if b.dialContext != nil {
+		return b.dialContext, nil
+	}
+	if b.dnsCache != nil {
+		resolver, err := NewCachingResolver(DnsCacheOptions{TTL: b.dnsCache.TTL, Resolver: b.resolver})
+		if err != nil {
+			return nil, err
+		}
+		return resolver.DialContext(nil), nil
+	}
+	if b.resolver != nil {
+		dialer := newDefaultDialer()
+		dialer.Resolver = b.resolver
+		return dialer.DialContext, nil
+	}
+	return nil, nil
+}
+
+// WithMaxConnsPerHost limits the total number of connections per host, 0 means no limit
+func (b *KiotaClientBuilder) WithMaxConnsPerHost(maxConns int) *KiotaClientBuilder {
+	if maxConns < 0 {
+		return b.setError(errors.New("maxConns cannot be negative"))
+	}
+	b.getConnectionLimits().MaxConnsPerHost = maxConns
+	return b
+}
+
+// WithMaxConnsForHost overrides the maximum number of connections for the given host name (without port), its connections are pooled separately
+func (b *KiotaClientBuilder) WithMaxConnsForHost(host string, maxConns int) *KiotaClientBuilder {
+	if host == "" || maxConns < 0 {
+		return b.setError(errors.New("host cannot be empty and maxConns cannot be negative"))
+	}
+	limits := b.getConnectionLimits()
+	if limits.PerHost == nil {
+		limits.PerHost = make(map[string]int)
+	}
+	limits.PerHost[host] = maxConns
+	return b
+}
+
+func (b *KiotaClientBuilder) getConnectionLimits() *ConnectionLimitsOptions {
+	if b.connectionLimits == nil {
+		b.connectionLimits = &ConnectionLimitsOptions{}
+	}
+	return b.connectionLimits
+}
+
+// WithHttp2HealthCheck pings the HTTP/2 connections which didn't receive any frame for readIdleTimeout and closes them when the ping isn't answered within pingTimeout.
+// It requires go 1.24 or later.
+func (b *KiotaClientBuilder) WithHttp2HealthCheck(readIdleTimeout time.Duration, pingTimeout time.Duration) *KiotaClientBuilder {
+	options, err := Http2HealthCheckOptions{ReadIdleTimeout: readIdleTimeout, PingTimeout: pingTimeout}.validate()
+	if err != nil {
+		return b.setError(err)
+	}
+	b.http2HealthCheck = &options
+	return b
+}
+
+// WithClientCertificate sets the client certificate presented to the servers requiring mutual TLS authentication
+func (b *KiotaClientBuilder) WithClientCertificate(certificate tls.Certificate) *KiotaClientBuilder {
+	b.tlsConfigurators = append(b.tlsConfigurators, func(tlsConfig *tls.Config) {
+		tlsConfig.Certificates = append(tlsConfig.Certificates, certificate)
+	})
+	return b
+}
+
+// WithClientCertificateFromFiles loads the client certificate presented to the servers requiring mutual TLS authentication from PEM encoded files
+func (b *KiotaClientBuilder) WithClientCertificateFromFiles(certFile string, keyFile string) *KiotaClientBuilder {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return b.setError(err)
+	}
+	return b.WithClientCertificate(certificate)
+}
+
+// WithRootCAs sets the certificate authorities trusted to verify the certificates of the servers in place of the system ones
+func (b *KiotaClientBuilder) WithRootCAs(rootCAs *x509.CertPool) *KiotaClientBuilder {
+	if rootCAs == nil {
+		return b.setError(errors.New("rootCAs cannot be nil"))
+	}
+	b.tlsConfigurators = append(b.tlsConfigurators, func(tlsConfig *tls.Config) {
+		tlsConfig.RootCAs = rootCAs
+	})
+	return b
+}
+
+// WithCAFile trusts the certificate authorities of the given PEM encoded file in addition to the root CAs of the transport, or the system ones
+func (b *KiotaClientBuilder) WithCAFile(caFile string) *KiotaClientBuilder {
+	content, err := os.ReadFile(caFile)
+	if err != nil {
+		return b.setError(err)
+	}
+	certificates, err := parsePEMCertificates(content)
+	if err != nil {
+		return b.setError(err)
+	}
+	b.tlsConfigurators = append(b.tlsConfigurators, func(tlsConfig *tls.Config) {
+		if tlsConfig.RootCAs == nil {
+			systemCAs, err := x509.SystemCertPool()
+			if err != nil {
+				systemCAs = x509.NewCertPool()
+			}
+			tlsConfig.RootCAs = systemCAs
+		}
+		for _, certificate := range certificates {
+			tlsConfig.RootCAs.AddCert(certificate)
+		}
+	})
+	return b
+}
+
+// WithCertificatePins validates the certificate chain of the host against the given SPKI pins, see CertificatePinningOptions
+func (b *KiotaClientBuilder) WithCertificatePins(host string, pins ...string) *KiotaClientBuilder {
+	if host == "" || len(pins) == 0 {
+		return b.setError(errors.New("host and pins cannot be empty"))
+	}
+	b.tlsConfigurators = append(b.tlsConfigurators, func(tlsConfig *tls.Config) {
+		configureCertificatePinning(tlsConfig, CertificatePinningOptions{
+			Pins: map[string][]string{host: pins},
+		})
+	})
+	return b
+}
+
+// WithInsecureSkipVerify disables the verification of the certificates of the servers.
+// This is meant for local development against self-signed endpoints only, as it exposes the requests to man-in-the-middle attacks.
+// A warning is emitted every time a client is built with this option, see WithLoggerProvider.
+func (b *KiotaClientBuilder) WithInsecureSkipVerify() *KiotaClientBuilder {
+	b.insecure = true
+	b.tlsConfigurators = append(b.tlsConfigurators, func(tlsConfig *tls.Config) {
+		tlsConfig.InsecureSkipVerify = true
+	})
+	return b
+}
+
+// parsePEMCertificates parses the certificates of PEM encoded content
+func parsePEMCertificates(content []byte) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, errors.New("no certificate found in the PEM content")
+	}
+	return certificates, nil
+}
+
+// WithNativeRedirects relies on the redirect support of the net/http client in place of the RedirectHandler middleware, e.g. to integrate with a cookie jar.
+// Up to maxRedirects redirects are followed (defaults to 5 when lower than 1, 20 at most) and redirects from https to http are not followed.
+// The two mechanisms are mutually exclusive, Build fails when a RedirectHandler or RedirectHandlerOptions is provided.
+func (b *KiotaClientBuilder) WithNativeRedirects(maxRedirects int) *KiotaClientBuilder {
+	b.nativeRedirects = true
+	b.maxRedirects = (&RedirectHandlerOptions{MaxRedirects: maxRedirects}).GetMaxRedirect()
+	return b
+}
+
+// WithCookieJar sets the cookie jar of the client
+func (b *KiotaClientBuilder) WithCookieJar(jar nethttp.CookieJar) *KiotaClientBuilder {
+	if jar == nil {
+		return b.setError(errors.New("jar cannot be nil"))
+	}
+	b.cookieJar = jar
+	return b
+}
+
+// getNativeRedirectPolicy returns the CheckRedirect function of the client when native redirects are enabled
+func getNativeRedirectPolicy(maxRedirects int) func(req *nethttp.Request, via []*nethttp.Request) error {
+	return func(req *nethttp.Request, via []*nethttp.Request) error {
+		if len(via) > maxRedirects {
+			return nethttp.ErrUseLastResponse
+		}
+		if len(via) > 0 && via[len(via)-1].URL.Scheme == "https" && req.URL.Scheme == "http" {
+			return nethttp.ErrUseLastResponse
+		}
+		return nil
+	}
+}
+
+func isRedirectHandler(middleware Middleware) bool {
+	switch middleware.(type) {
+	case *RedirectHandler, RedirectHandler:
+		return true
+	}
+	return false
+}
+
+// WithLoggerProvider sets the provider of the logger the warnings of the builder are emitted with.
+// The LoggerProvider of the ObservabilityOptions of the default middleware options is used otherwise, and the warnings are written to the standard error without any.
+func (b *KiotaClientBuilder) WithLoggerProvider(loggerProvider LoggerProvider) *KiotaClientBuilder {
+	if loggerProvider == nil {
+		return b.setError(errors.New("loggerProvider cannot be nil"))
+	}
+	b.loggerProvider = loggerProvider
+	return b
+}
+
+// emitWarning emits a warning with the configured logger provider, or writes it to the standard error
+func (b *KiotaClientBuilder) emitWarning(eventName string, body string) {
+	loggerProvider, instrumentationName := b.loggerProvider, defaultTracerInstrumentationName
+	for _, option := range b.middlewareOptions {
+		if obsOptions, ok := option.(*ObservabilityOptions); ok {
+			instrumentationName = obsOptions.GetTracerInstrumentationName()
+			if loggerProvider == nil {
+				loggerProvider = obsOptions.GetLoggerProvider()
+			}
+		}
+	}
+	if loggerProvider == nil {
+		fmt.Fprintln(os.Stderr, "WARNING: "+body)
+		return
+	}
+	if logger := loggerProvider.Logger(instrumentationName); logger != nil {
+		logger.Emit(context.Background(), LogRecord{
+			Timestamp: time.Now(),
+			Severity:  WarnLogSeverity,
+			EventName: eventName,
+			Body:      body,
+		})
+	}
+}
+
+// WithMiddleware sets the middlewares of the client in place of the default ones
+func (b *KiotaClientBuilder) WithMiddleware(middlewares ...Middleware) *KiotaClientBuilder {
+	b.middlewares = append(b.middlewares, middlewares...)
+	return b
+}
+
+// WithDefaultMiddlewareOptions sets the options used to configure the default middlewares
+func (b *KiotaClientBuilder) WithDefaultMiddlewareOptions(requestOptions ...abs.RequestOption) *KiotaClientBuilder {
+	b.middlewareOptions = append(b.middlewareOptions, requestOptions...)
+	return b
+}
+
+// WithEnvironmentConfiguration applies the configuration read from the KIOTA_HTTP_* environment variables (see GetEnvironmentConfiguration)
+// to the settings which aren't configured on the builder. The retries and compression settings only apply to the default middlewares.
+func (b *KiotaClientBuilder) WithEnvironmentConfiguration() *KiotaClientBuilder {
+	environment, err := GetEnvironmentConfiguration()
+	if err != nil {
+		return b.setError(err)
+	}
+	b.environment = environment
+	return b
+}
+
+// getProxy returns the proxy function configured on the builder, nil when none is
+func (b *KiotaClientBuilder) getProxy() func(*nethttp.Request) (*url.URL, error) {
+	if b.proxyUrl != nil {
+		return nethttp.ProxyURL(b.proxyUrl)
+	}
+	if b.proxyFunc == nil && b.environment != nil && b.environment.ProxyUrl != nil {
+		return nethttp.ProxyURL(b.environment.ProxyUrl)
+	}
+	return b.proxyFunc
+}
+
+// getParentTransport returns the configured parent transport, or a clone of the default one
+func (b *KiotaClientBuilder) getParentTransport() (nethttp.RoundTripper, error) {
+	transport := b.transport
+	if transport == nil && b.bareTransport {
+		transport = &nethttp.Transport{}
+	} else if transport == nil {
+		transport = GetDefaultTransport()
+	}
+	proxy := b.getProxy()
+	dialContext, err := b.getDialContext()
+	if err != nil {
+		return nil, err
+	}
+	hasTransportSettings := proxy != nil || dialContext != nil || b.connectionLimits != nil || b.http2HealthCheck != nil || len(b.tlsConfigurators) > 0 || len(b.transportConfigurators) > 0
+	if !hasTransportSettings && !b.noEnvProxy {
+		return transport, nil
+	}
+	httpTransport, ok := transport.(*nethttp.Transport)
+	if !ok {
+		if !hasTransportSettings {
+			return transport, nil
+		}
+		return nil, errors.New("a proxy, connection or TLS settings can only be configured with a *http.Transport")
+	}
+	httpTransport = httpTransport.Clone()
+	if proxy != nil || b.noEnvProxy {
+		httpTransport.Proxy = proxy
+	}
+	if dialContext != nil {
+		httpTransport.DialContext = dialContext
+	}
+	for _, configure := range b.transportConfigurators {
+		configure(httpTransport)
+	}
+	if len(b.tlsConfigurators) > 0 {
+		tlsConfig := &tls.Config{}
+		if httpTransport.TLSClientConfig != nil {
+			tlsConfig = httpTransport.TLSClientConfig.Clone()
+		}
+		for _, configure := range b.tlsConfigurators {
+			configure(tlsConfig)
+		}
+		httpTransport.TLSClientConfig = tlsConfig
+	}
+	if b.http2HealthCheck != nil {
+		if err := configureHttp2HealthCheck(httpTransport, *b.http2HealthCheck); err != nil {
+			return nil, err
+		}
+	}
+	if b.connectionLimits != nil {
+		return NewConnectionLimitedTransport(httpTransport, *b.connectionLimits)
+	}
+	return httpTransport, nil
+}
+
+// Build builds the net/http client
+func (b *KiotaClientBuilder) Build() (*nethttp.Client, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.middlewares) > 0 && len(b.middlewareOptions) > 0 {
+		return nil, errors.New("default middleware options cannot be combined with custom middlewares")
+	}
+	middlewares := b.middlewares
+	if len(middlewares) == 0 {
+		middlewareOptions := b.middlewareOptions
+		if b.environment != nil {
+			middlewareOptions = append(b.environment.getMiddlewareOptions(middlewareOptions), middlewareOptions...)
+		}
+		defaultMiddlewares, err := GetDefaultMiddlewaresWithOptions(middlewareOptions...)
+		if err != nil {
+			return nil, err
+		}
+		middlewares = defaultMiddlewares
+		if b.nativeRedirects {
+			for _, option := range b.middlewareOptions {
+				if _, ok := option.(*RedirectHandlerOptions); ok {
+					return nil, errors.New("redirect handler options cannot be combined with native redirects")
+				}
+			}
+			middlewares = make([]Middleware, 0, len(defaultMiddlewares))
+			for _, middleware := range defaultMiddlewares {
+				if !isRedirectHandler(middleware) {
+					middlewares = append(middlewares, middleware)
+				}
+			}
+		}
+	} else if b.nativeRedirects {
+		for _, middleware := range middlewares {
+			if isRedirectHandler(middleware) {
+				return nil, errors.New("a redirect handler cannot be combined with native redirects")
+			}
+		}
+	}
+	parentTransport, err := b.getParentTransport()
+	if err != nil {
+		return nil, err
+	}
+	if b.insecure {
+		b.emitWarning(InsecureSkipVerifyLogEventName, "the TLS certificates of the servers are not verified (WithInsecureSkipVerify), this must only be used for local development")
+	}
+	if b.proxyAutoConfig != nil && b.proxyUrl == nil {
+		parentTransport = newProxyAutoConfigTransport(parentTransport, b.proxyAutoConfig)
+	}
+	client := getDefaultClientWithoutMiddleware()
+	if b.timeout != nil {
+		client.Timeout = *b.timeout
+	} else if b.environment != nil && b.environment.Timeout != nil {
+		client.Timeout = *b.environment.Timeout
+	}
+	if b.nativeRedirects {
+		client.CheckRedirect = getNativeRedirectPolicy(b.maxRedirects)
+	}
+	client.Jar = b.cookieJar
+	transport := NewCustomTransportWithParentTransport(parentTransport, middlewares...)
+	if b.getProxy() != nil {
+		transport.featureUsage |= ProxyEnabledFeatureUsageFlag
+	}
+	client.Transport = transport
+	return client, nil
+}
+
+// BuildRequestAdapter builds a request adapter relying on the net/http client built with the configuration
+func (b *KiotaClientBuilder) BuildRequestAdapter(authenticationProvider absauth.AuthenticationProvider) (*NetHttpRequestAdapter, error) {
+	client, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	observabilityOptions := ObservabilityOptions{}
+	if b.environment != nil && b.environment.SpanGranularity != nil {
+		observabilityOptions.SetSpanGranularity(*b.environment.SpanGranularity)
+	}
+	return NewNetHttpRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClientAndObservabilityOptions(authenticationProvider, nil, nil, client, observabilityOptions)
+}
@@ -3,8 +3,11 @@
 package nethttplibrary
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	abs "github.com/microsoft/kiota-abstractions-go"
+	"net"
 	nethttp "net/http"
 	"net/url"
 	"time"
@@ -15,7 +18,7 @@ import (
 func GetClientWithProxySettings(proxyUrlStr string, middleware ...Middleware) (*nethttp.Client, error) {
 	client := getDefaultClientWithoutMiddleware()
 
-	transport, err := getTransportWithProxy(proxyUrlStr, nil, middleware...)
+	transport, err := getTransportWithProxy(proxyUrlStr, nil, nil, middleware...)
 	if err != nil {
 		return nil, err
 	}
@@ -29,7 +32,7 @@ func GetClientWithAuthenticatedProxySettings(proxyUrlStr string, username string
 	client := getDefaultClientWithoutMiddleware()
 
 	user := url.UserPassword(username, password)
-	transport, err := getTransportWithProxy(proxyUrlStr, user, middleware...)
+	transport, err := getTransportWithProxy(proxyUrlStr, user, nil, middleware...)
 	if err != nil {
 		return nil, err
 	}
@@ -37,25 +40,88 @@ func GetClientWithAuthenticatedProxySettings(proxyUrlStr string, username string
 	return client, nil
 }
 
-func getTransportWithProxy(proxyUrlStr string, user *url.Userinfo, middlewares ...Middleware) (nethttp.RoundTripper, error) {
+// GetClientWithProxySettingsAndTLSConfig creates a new default net/http client with a proxy url, the TLS configuration of the transport and default middleware.
+// The TLS configuration can set the root CAs to trust when the proxy intercepts TLS connections.
+// Not providing any middleware would result in having default middleware provided
+func GetClientWithProxySettingsAndTLSConfig(proxyUrlStr string, tlsConfig *tls.Config, middleware ...Middleware) (*nethttp.Client, error) {
+	client := getDefaultClientWithoutMiddleware()
+
+	transport, err := getTransportWithProxy(proxyUrlStr, nil, tlsConfig, middleware...)
+	if err != nil {
+		return nil, err
+	}
+	client.Transport = transport
+	return client, nil
+}
+
+// GetClientWithAuthenticatedProxySettingsAndTLSConfig creates a new default net/http client with a proxy url and credentials, the TLS configuration of the transport and default middleware.
+// The TLS configuration can set the root CAs to trust when the proxy intercepts TLS connections.
+// Not providing any middleware would result in having default middleware provided
+func GetClientWithAuthenticatedProxySettingsAndTLSConfig(proxyUrlStr string, username string, password string, tlsConfig *tls.Config, middleware ...Middleware) (*nethttp.Client, error) {
+	client := getDefaultClientWithoutMiddleware()
+
+	user := url.UserPassword(username, password)
+	transport, err := getTransportWithProxy(proxyUrlStr, user, tlsConfig, middleware...)
+	if err != nil {
+		return nil, err
+	}
+	client.Transport = transport
+	return client, nil
+}
+
+func getTransportWithProxy(proxyUrlStr string, user *url.Userinfo, tlsConfig *tls.Config, middlewares ...Middleware) (nethttp.RoundTripper, error) {
 	proxyURL, err := url.Parse(proxyUrlStr)
 	if err != nil {
 		return nil, err
 	}
+	if proxyURL.Host == "" {
+		return nil, errors.New("proxy url must be absolute: " + proxyUrlStr)
+	}
 
 	if user != nil {
 		proxyURL.User = user
 	}
 
-	transport := &nethttp.Transport{
-		Proxy: nethttp.ProxyURL(proxyURL),
+	transport := getDefaultHttpTransport()
+	transport.Proxy = nethttp.ProxyURL(proxyURL)
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig.Clone()
 	}
 
 	if len(middlewares) == 0 {
 		middlewares = GetDefaultMiddlewares()
 	}
+	for _, middleware := range middlewares {
+		if proxyAuthenticationHandler, ok := middleware.(*ProxyAuthenticationHandler); ok {
+			proxyAuthenticationHandler.ConfigureTransport(transport)
+		}
+	}
+
+	customTransport := NewCustomTransportWithParentTransport(transport, middlewares...)
+	customTransport.featureUsage |= ProxyEnabledFeatureUsageFlag
+	return customTransport, nil
+}
+
+// GetClientWithUnixSocket creates a new default net/http client sending the requests over the Unix domain socket at the given path and default middleware.
+// The host of the request urls is ignored (e.g. http://unix/v1/items), no proxy is used.
+// Not providing any middleware would result in having default middleware provided
+func GetClientWithUnixSocket(socketPath string, middleware ...Middleware) (*nethttp.Client, error) {
+	if socketPath == "" {
+		return nil, errors.New("socketPath cannot be empty")
+	}
+	transport := getDefaultHttpTransport()
+	configureUnixSocket(transport, socketPath)
+	client := getDefaultClientWithoutMiddleware()
+	client.Transport = NewCustomTransportWithParentTransport(transport, middleware...)
+	return client, nil
+}
 
-	return NewCustomTransportWithParentTransport(transport, middlewares...), nil
+func configureUnixSocket(transport *nethttp.Transport, socketPath string) {
+	dialer := &net.Dialer{}
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", socketPath)
+	}
 }
 
 // GetDefaultClient creates a new default net/http client with the options configured for the Kiota request adapter
@@ -65,6 +131,12 @@ func GetDefaultClient(middleware ...Middleware) *nethttp.Client {
 	return client
 }
 
+// GetDefaultClientFromEnvironment creates a new default net/http client configured with the KIOTA_HTTP_* environment variables (see GetEnvironmentConfiguration)
+// Not providing any middleware would result in having default middleware provided, with the retries and compression configured by the environment variables
+func GetDefaultClientFromEnvironment(middleware ...Middleware) (*nethttp.Client, error) {
+	return NewKiotaClientBuilder().WithEnvironmentConfiguration().WithMiddleware(middleware...).Build()
+}
+
 // used for internal unit testing
 func getDefaultClientWithoutMiddleware() *nethttp.Client {
 	// the default client doesn't come with any other settings than making a new one does, and using the default client impacts behavior for non-kiota requests
@@ -89,8 +161,12 @@ func GetDefaultMiddlewaresWithOptions(requestOptions ...abs.RequestOption) ([]Mi
 
 	// map of middleware options
 	middlewareMap := make(map[abs.RequestOptionKey]Middleware)
+	// keys of the middlewares of the registered factories, in the order of their options
+	var registeredKeys []abs.RequestOptionKey
+	var observabilityHandler Middleware
 
 	for _, element := range requestOptions {
+		var err error
 		switch v := element.(type) {
 		case *RetryHandlerOptions:
 			middlewareMap[retryKeyValue] = NewRetryHandlerWithOptions(*v)
@@ -98,24 +174,147 @@ func GetDefaultMiddlewaresWithOptions(requestOptions ...abs.RequestOption) ([]Mi
 			middlewareMap[redirectKeyValue] = NewRedirectHandlerWithOptions(*v)
 		case *CompressionOptions:
 			middlewareMap[compressKey] = NewCompressionHandlerWithOptions(*v)
+		case *ConnectionReuseOptions:
+			middlewareMap[connectionReuseKeyValue] = NewConnectionReuseHandlerWithOptions(*v)
 		case *ParametersNameDecodingOptions:
 			middlewareMap[parametersNameDecodingKeyValue] = NewParametersNameDecodingHandlerWithOptions(*v)
 		case *UserAgentHandlerOptions:
 			middlewareMap[userAgentKeyValue] = NewUserAgentHandlerWithOptions(v)
 		case *HeadersInspectionOptions:
 			middlewareMap[headersInspectionKeyValue] = NewHeadersInspectionHandlerWithOptions(*v)
+		case *ChaosHandlerOptions:
+			middlewareMap[chaosHandlerKey], err = NewChaosHandlerWithOptions(v)
+		case *UrlReplaceOptions:
+			middlewareMap[urlReplaceOptionKey] = NewUrlReplaceHandler(v.Enabled, v.ReplacementPairs)
+		case *ObservabilityOptions:
+			observabilityHandler = &observabilityOptionsHandler{options: *v}
+		case *AllowedHostsOptions:
+			middlewareMap[allowedHostsKeyValue] = NewAllowedHostsHandlerWithOptions(*v)
+		case *BaggageHandlerOptions:
+			middlewareMap[baggageKeyValue] = NewBaggageHandlerWithOptions(*v)
+		case *ClientRequestIdHandlerOptions:
+			middlewareMap[clientRequestIdKeyValue] = NewClientRequestIdHandlerWithOptions(*v)
+		case *DigestAuthenticationHandlerOptions:
+			middlewareMap[digestAuthenticationKeyValue], err = NewDigestAuthenticationHandlerWithOptions(*v)
+		case *DecompressionHandlerOptions:
+			middlewareMap[decompressionKeyValue] = NewDecompressionHandlerWithOptions(*v)
+		case *EarlyHintsInspectionOptions:
+			middlewareMap[earlyHintsInspectionKeyValue] = NewEarlyHintsHandler()
+		case *ExpectContinueOptions:
+			middlewareMap[expectContinueKeyValue] = NewExpectContinueHandlerWithOptions(*v)
+		case *HmacSigningHandlerOptions:
+			middlewareMap[hmacSigningKeyValue], err = NewHmacSigningHandlerWithOptions(*v)
+		case *LoadBalancingHandlerOptions:
+			middlewareMap[loadBalancingKeyValue], err = NewLoadBalancingHandlerWithOptions(*v)
+		case *NetworkObservabilityHandlerOptions:
+			middlewareMap[networkObservabilityKeyValue] = NewNetworkObservabilityHandlerWithOptions(*v)
+		case *OfflineQueueHandlerOptions:
+			middlewareMap[offlineQueueKeyValue], err = NewOfflineQueueHandlerWithOptions(*v)
+		case *OptimisticConcurrencyHandlerOptions:
+			middlewareMap[optimisticConcurrencyKeyValue] = NewOptimisticConcurrencyHandlerWithOptions(*v)
+		case *ProxyAuthenticationHandlerOptions:
+			middlewareMap[proxyAuthenticationKeyValue], err = NewProxyAuthenticationHandlerWithOptions(*v)
+		case *RateLimitingHandlerOptions:
+			middlewareMap[rateLimitingKeyValue], err = NewRateLimitingHandlerWithOptions(*v)
+		case *RewriteOptions:
+			middlewareMap[rewriteOptionKey] = NewRewriteHandlerWithOptions(*v)
+		case *SchemaValidationOptions:
+			middlewareMap[schemaValidationKey] = NewSchemaValidationHandlerWithOptions(*v)
+		case *StubHandlerOptions:
+			middlewareMap[stubHandlerKey] = NewStubHandlerWithOptions(*v)
+		case *TelemetryHandlerOptions:
+			middlewareMap[telemetryKeyValue] = NewTelemetryHandlerWithOptions(*v)
 		default:
-			// none of the above types
-			return nil, errors.New("unsupported option type")
+			// none of the above types, looking for a registered factory
+			factory, ok := getMiddlewareFactory(element)
+			if !ok {
+				return nil, errors.New("unsupported option type")
+			}
+			if _, ok := middlewareMap[element.GetKey()]; !ok {
+				registeredKeys = append(registeredKeys, element.GetKey())
+			}
+			middlewareMap[element.GetKey()], err = factory(element)
+		}
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	middleware := getDefaultMiddleWare(middlewareMap)
+	middleware := getDefaultMiddleWare(middlewareMap, registeredKeys...)
+	if observabilityHandler != nil {
+		// the observability options need to be set before any other middleware runs
+		middleware = append([]Middleware{observabilityHandler}, middleware...)
+	}
 	return middleware, nil
 }
 
-// getDefaultMiddleWare creates a new default set of middlewares for the Kiota request adapter
-func getDefaultMiddleWare(middlewareMap map[abs.RequestOptionKey]Middleware) []Middleware {
+// isBuiltInMiddlewareOption returns whether GetDefaultMiddlewaresWithOptions handles the option without a registered factory
+func isBuiltInMiddlewareOption(option interface{}) bool {
+	switch option.(type) {
+	case *RetryHandlerOptions, *RedirectHandlerOptions, *CompressionOptions, *ParametersNameDecodingOptions, *UserAgentHandlerOptions,
+		*HeadersInspectionOptions, *ChaosHandlerOptions, *UrlReplaceOptions, *ObservabilityOptions, *AllowedHostsOptions,
+		*BaggageHandlerOptions, *ClientRequestIdHandlerOptions, *ConnectionReuseOptions, *DecompressionHandlerOptions, *DigestAuthenticationHandlerOptions, *EarlyHintsInspectionOptions, *ExpectContinueOptions,
+		*HmacSigningHandlerOptions, *LoadBalancingHandlerOptions, *NetworkObservabilityHandlerOptions, *OfflineQueueHandlerOptions, *OptimisticConcurrencyHandlerOptions, *ProxyAuthenticationHandlerOptions,
+		*RateLimitingHandlerOptions, *RewriteOptions, *SchemaValidationOptions, *StubHandlerOptions, *TelemetryHandlerOptions:
+		return true
+	}
+	return false
+}
+
+// middlewareOrder is the order of the handlers shipped with the library in the chain, from the first to see the request to the last.
+//   - the allowed hosts are checked on the url of the caller, before the url replace and rewrite handlers change it
+//   - the headers describing the client and the request are added once, before the requests are queued, balanced or retried
+//   - the rate limiting, authentication challenges, compression and signing apply to every attempt of the retry and redirect handlers
+//   - the HMAC signature is computed on the compressed body which is sent
+//   - the response body is decompressed before its schema is validated
+//   - the headers inspection sees the headers which are sent, signature included
+//
+// The middlewares of the registered factories follow, in the order of their options, then the chaos and stub handlers,
+// so the other handlers, the retry handler included, process their simulated responses like real ones.
+// The handlers of transportMiddlewareOrder come last, they act on the requests actually sent over the network.
+var middlewareOrder = []abs.RequestOptionKey{
+	allowedHostsKeyValue,
+	urlReplaceOptionKey,
+	rewriteOptionKey,
+	parametersNameDecodingKeyValue,
+	userAgentKeyValue,
+	telemetryKeyValue,
+	clientRequestIdKeyValue,
+	baggageKeyValue,
+	optimisticConcurrencyKeyValue,
+	offlineQueueKeyValue,
+	loadBalancingKeyValue,
+	retryKeyValue,
+	redirectKeyValue,
+	rateLimitingKeyValue,
+	digestAuthenticationKeyValue,
+	proxyAuthenticationKeyValue,
+	expectContinueKeyValue,
+	compressKey,
+	hmacSigningKeyValue,
+	decompressionKeyValue,
+	schemaValidationKey,
+	earlyHintsInspectionKeyValue,
+	headersInspectionKeyValue,
+}
+
+// simulationMiddlewareOrder is the order of the handlers simulating responses, at the end of the chain
+var simulationMiddlewareOrder = []abs.RequestOptionKey{
+	chaosHandlerKey,
+	stubHandlerKey,
+}
+
+// transportMiddlewareOrder is the order of the handlers of the requests sent over the network, after all the others
+//   - the feature usage token lists the flags registered by all the handlers before it
+//   - the network observability handler observes the request as sent, connection header included
+var transportMiddlewareOrder = []abs.RequestOptionKey{
+	featureUsageKeyValue,
+	connectionReuseKeyValue,
+	networkObservabilityKeyValue,
+}
+
+// getDefaultMiddleWare creates a new default set of middlewares for the Kiota request adapter, ordered by middlewareOrder
+func getDefaultMiddleWare(middlewareMap map[abs.RequestOptionKey]Middleware, registeredKeys ...abs.RequestOptionKey) []Middleware {
 	middlewareSource := map[abs.RequestOptionKey]func() Middleware{
 		retryKeyValue: func() Middleware {
 			return NewRetryHandler()
@@ -135,18 +334,31 @@ func getDefaultMiddleWare(middlewareMap map[abs.RequestOptionKey]Middleware) []M
 		headersInspectionKeyValue: func() Middleware {
 			return NewHeadersInspectionHandler()
 		},
+		featureUsageKeyValue: func() Middleware {
+			return NewFeatureUsageHandler()
+		},
+		connectionReuseKeyValue: func() Middleware {
+			return NewConnectionReuseHandler()
+		},
+		networkObservabilityKeyValue: func() Middleware {
+			return NewNetworkObservabilityHandler()
+		},
 	}
 
-	// loop over middlewareSource and add any middleware that wasn't provided in the requestOptions
+	// add any middleware that wasn't provided in the requestOptions
 	for key, value := range middlewareSource {
 		if _, ok := middlewareMap[key]; !ok {
 			middlewareMap[key] = value()
 		}
 	}
 
-	var middleware []Middleware
-	for _, value := range middlewareMap {
-		middleware = append(middleware, value)
+	middleware := make([]Middleware, 0, len(middlewareMap))
+	for _, keys := range [][]abs.RequestOptionKey{middlewareOrder, registeredKeys, simulationMiddlewareOrder, transportMiddlewareOrder} {
+		for _, key := range keys {
+			if value, ok := middlewareMap[key]; ok && value != nil {
+				middleware = append(middleware, value)
+			}
+		}
 	}
 
 	return middleware
@@ -0,0 +1,177 @@
+package nethttplibrary
+
+import (
+	"errors"
+	nethttp "net/http"
+	"net/url"
+	"sync"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LoadBalancingStrategy defines how the endpoint is selected for a request
+type LoadBalancingStrategy int
+
+const (
+	// RoundRobin selects the endpoints in turn
+	RoundRobin LoadBalancingStrategy = iota
+	// LeastOutstanding selects the endpoint with the fewest requests in flight
+	LeastOutstanding
+)
+
+// LoadBalancingHandlerOptions to use when distributing the requests across endpoints.
+type LoadBalancingHandlerOptions struct {
+	// Enabled defines whether the requests should be distributed
+	Enabled bool
+	// Endpoints is the list of equivalent endpoints (scheme and host, e.g. https://westeurope.contoso.com) to distribute the requests across
+	Endpoints []string
+	// Strategy is the strategy used to select the endpoint
+	Strategy LoadBalancingStrategy
+}
+
+var loadBalancingKeyValue = abs.RequestOptionKey{
+	Key: "LoadBalancingHandler",
+}
+
+type loadBalancingHandlerOptionsInt interface {
+	abs.RequestOption
+	GetEnabled() bool
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *LoadBalancingHandlerOptions) GetKey() abs.RequestOptionKey {
+	return loadBalancingKeyValue
+}
+
+// GetEnabled returns whether the requests should be distributed
+func (options *LoadBalancingHandlerOptions) GetEnabled() bool {
+	return options.Enabled
+}
+
+// EndpointStats holds the metrics recorded for an endpoint by the LoadBalancingHandler
+type EndpointStats struct {
+	// Endpoint is the endpoint the metrics are recorded for
+	Endpoint string
+	// Requests is the number of requests sent to the endpoint
+	Requests int64
+	// Failures is the number of requests which failed or received a server error
+	Failures int64
+	// Outstanding is the number of requests currently in flight
+	Outstanding int64
+}
+
+// LoadBalancingHandler distributes the requests across a set of equivalent endpoints.
+type LoadBalancingHandler struct {
+	options   LoadBalancingHandlerOptions
+	endpoints []*url.URL
+	mutex     sync.Mutex
+	next      int
+	stats     []EndpointStats
+}
+
+// LoadBalancingEndpointAttributeName is the span attribute name used to record the selected endpoint
+const LoadBalancingEndpointAttributeName = "com.microsoft.kiota.handler.load_balancing.endpoint"
+
+// NewLoadBalancingHandler creates a new LoadBalancingHandler distributing the requests across the endpoints in a round-robin fashion
+func NewLoadBalancingHandler(endpoints ...string) (*LoadBalancingHandler, error) {
+	return NewLoadBalancingHandlerWithOptions(LoadBalancingHandlerOptions{
+		Enabled:   true,
+		Endpoints: endpoints,
+		Strategy:  RoundRobin,
+	})
+}
+
+// NewLoadBalancingHandlerWithOptions creates a new LoadBalancingHandler with the given options
+func NewLoadBalancingHandlerWithOptions(options LoadBalancingHandlerOptions) (*LoadBalancingHandler, error) {
+	if len(options.Endpoints) == 0 {
+		return nil, errors.New("at least one endpoint is required")
+	}
+	handler := &LoadBalancingHandler{
+		options:   options,
+		endpoints: make([]*url.URL, 0, len(options.Endpoints)),
+		stats:     make([]EndpointStats, 0, len(options.Endpoints)),
+	}
+	for _, endpoint := range options.Endpoints {
+		parsed, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if parsed.Scheme == "" || parsed.Host == "" {
+			return nil, errors.New("endpoint " + endpoint + " must be an absolute URL")
+		}
+		handler.endpoints = append(handler.endpoints, parsed)
+		handler.stats = append(handler.stats, EndpointStats{Endpoint: endpoint})
+	}
+	return handler, nil
+}
+
+// GetEndpointStats returns a snapshot of the metrics recorded for each endpoint
+func (middleware *LoadBalancingHandler) GetEndpointStats() []EndpointStats {
+	middleware.mutex.Lock()
+	defer middleware.mutex.Unlock()
+	result := make([]EndpointStats, len(middleware.stats))
+	copy(result, middleware.stats)
+	return result
+}
+
+func (middleware *LoadBalancingHandler) acquireEndpoint() int {
+	middleware.mutex.Lock()
+	defer middleware.mutex.Unlock()
+	index := 0
+	if middleware.options.Strategy == LeastOutstanding {
+		for i := range middleware.stats {
+			if middleware.stats[i].Outstanding < middleware.stats[index].Outstanding {
+				index = i
+			}
+		}
+	} else {
+		index = middleware.next
+		middleware.next = (middleware.next + 1) % len(middleware.endpoints)
+	}
+	middleware.stats[index].Requests++
+	middleware.stats[index].Outstanding++
+	return index
+}
+
+func (middleware *LoadBalancingHandler) releaseEndpoint(index int, failed bool) {
+	middleware.mutex.Lock()
+	defer middleware.mutex.Unlock()
+	middleware.stats[index].Outstanding--
+	if failed {
+		middleware.stats[index].Failures++
+	}
+}
+
+// Intercept implements the interface and sends the request to the selected endpoint.
+func (middleware *LoadBalancingHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(loadBalancingKeyValue).(loadBalancingHandlerOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+	if !reqOption.GetEnabled() {
+		return pipeline.Next(req, middlewareIndex)
+	}
+	index := middleware.acquireEndpoint()
+	endpoint := middleware.endpoints[index]
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	ctx := req.Context()
+	var span trace.Span
+	if obsOptions != nil {
+		ctx, span = getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "LoadBalancingHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.load_balancing.enable", true),
+			attribute.String(LoadBalancingEndpointAttributeName, endpoint.Host))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	targetUrl := *req.URL
+	targetUrl.Scheme = endpoint.Scheme
+	targetUrl.Host = endpoint.Host
+	req.URL = &targetUrl
+	req.Host = endpoint.Host
+
+	response, err := pipeline.Next(req, middlewareIndex)
+	middleware.releaseEndpoint(index, err != nil || (response != nil && response.StatusCode >= 500))
+	return response, err
+}
@@ -0,0 +1,53 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItRequiresEndpointsForLoadBalancing(t *testing.T) {
+	_, err := NewLoadBalancingHandler()
+	assert.Error(t, err)
+	_, err = NewLoadBalancingHandler("contoso.com")
+	assert.Error(t, err)
+}
+
+func TestItDistributesRequestsInRoundRobin(t *testing.T) {
+	handler, err := NewLoadBalancingHandler("https://eu.contoso.com", "https://us.contoso.com")
+	assert.Nil(t, err)
+	hosts := make([]string, 0)
+	for i := 0; i < 3; i++ {
+		req, err := nethttp.NewRequest(nethttp.MethodGet, "https://contoso.com/users", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		pipeline := newSpyPipeline()
+		_, _ = handler.Intercept(pipeline, 0, req)
+		hosts = append(hosts, pipeline.GetReceivedRequest().URL.Host)
+		assert.Equal(t, "/users", pipeline.GetReceivedRequest().URL.Path)
+	}
+	assert.Equal(t, []string{"eu.contoso.com", "us.contoso.com", "eu.contoso.com"}, hosts)
+
+	stats := handler.GetEndpointStats()
+	assert.Equal(t, int64(2), stats[0].Requests)
+	assert.Equal(t, int64(2), stats[0].Failures)
+	assert.Equal(t, int64(0), stats[0].Outstanding)
+	assert.Equal(t, int64(1), stats[1].Requests)
+}
+
+func TestItSelectsTheLeastOutstandingEndpoint(t *testing.T) {
+	handler, err := NewLoadBalancingHandlerWithOptions(LoadBalancingHandlerOptions{
+		Enabled:   true,
+		Endpoints: []string{"https://eu.contoso.com", "https://us.contoso.com"},
+		Strategy:  LeastOutstanding,
+	})
+	assert.Nil(t, err)
+	first := handler.acquireEndpoint()
+	second := handler.acquireEndpoint()
+	assert.Equal(t, 0, first)
+	assert.Equal(t, 1, second)
+	handler.releaseEndpoint(second, false)
+	assert.Equal(t, 1, handler.acquireEndpoint())
+}
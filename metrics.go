@@ -0,0 +1,98 @@
+package nethttplibrary
+
+import (
+	"context"
+	"mime"
+	nethttp "net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const requestBodySizeMetricName = "http.client.request.body.size"
+const responseBodySizeMetricName = "http.client.response.body.size"
+const compressionRatioMetricName = "com.microsoft.kiota.handler.compression.ratio"
+
+// payloadInstruments holds the instruments recording the payload metrics for a meter
+type payloadInstruments struct {
+	requestBodySize  metric.Int64Histogram
+	responseBodySize metric.Int64Histogram
+	compressionRatio metric.Float64Histogram
+}
+
+type instrumentsKey struct {
+	provider  metric.MeterProvider
+	meterName string
+}
+
+var payloadInstrumentsByMeter sync.Map
+
+// getInstruments returns the instruments of the meter with the given name from the cache, creating them on first use.
+// The cache is keyed by meter provider so the instruments are created again when the global meter provider changes.
+func getInstruments[T any](cache *sync.Map, meterName string, create func(meter metric.Meter) *T) *T {
+	provider := otel.GetMeterProvider()
+	key := instrumentsKey{provider: provider, meterName: meterName}
+	if instruments, ok := cache.Load(key); ok {
+		return instruments.(*T)
+	}
+	actual, _ := cache.LoadOrStore(key, create(provider.Meter(meterName)))
+	return actual.(*T)
+}
+
+// getPayloadInstruments returns the payload instruments of the meter with the given name
+func getPayloadInstruments(meterName string) *payloadInstruments {
+	return getInstruments(&payloadInstrumentsByMeter, meterName, func(meter metric.Meter) *payloadInstruments {
+		instruments := &payloadInstruments{}
+		// the instruments are no-ops when they fail to be created
+		instruments.requestBodySize, _ = meter.Int64Histogram(requestBodySizeMetricName,
+			metric.WithUnit("By"),
+			metric.WithDescription("Size of the HTTP request bodies"))
+		instruments.responseBodySize, _ = meter.Int64Histogram(responseBodySizeMetricName,
+			metric.WithUnit("By"),
+			metric.WithDescription("Size of the HTTP response bodies"))
+		instruments.compressionRatio, _ = meter.Float64Histogram(compressionRatioMetricName,
+			metric.WithUnit("1"),
+			metric.WithDescription("Ratio between the uncompressed and the compressed size of the request bodies compressed by the compression handler"))
+		return instruments
+	})
+}
+
+// getMediaType returns the media type of the content type header without its parameters, to keep the cardinality of the metrics low
+func getMediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+// recordPayloadMetrics records the sizes of the request and response bodies tagged by method and content type
+func recordPayloadMetrics(ctx context.Context, meterName string, req *nethttp.Request, resp *nethttp.Response) {
+	instruments := getPayloadInstruments(meterName)
+	method := httpRequestMethodAttribute.String(req.Method)
+	if req.ContentLength > 0 && instruments.requestBodySize != nil {
+		instruments.requestBodySize.Record(ctx, req.ContentLength, metric.WithAttributes(method,
+			httpRequestHeaderContentTypeAttribute.String(getMediaType(req.Header.Get("Content-Type")))))
+	}
+	if resp != nil && resp.ContentLength >= 0 && instruments.responseBodySize != nil {
+		instruments.responseBodySize.Record(ctx, resp.ContentLength, metric.WithAttributes(method,
+			httpResponseHeaderContentTypeAttribute.String(getMediaType(resp.Header.Get("Content-Type"))),
+			httpResponseStatusCodeAttribute.Int(resp.StatusCode)))
+	}
+}
+
+// recordCompressionRatio records the ratio achieved when compressing a request body tagged by content type
+func recordCompressionRatio(ctx context.Context, meterName string, req *nethttp.Request, uncompressedSize int, compressedSize int) {
+	instruments := getPayloadInstruments(meterName)
+	if compressedSize <= 0 || instruments.compressionRatio == nil {
+		return
+	}
+	instruments.compressionRatio.Record(ctx, float64(uncompressedSize)/float64(compressedSize), metric.WithAttributes(
+		httpRequestHeaderContentTypeAttribute.String(getMediaType(req.Header.Get("Content-Type"))),
+		attribute.String("http.request.header.content-encoding", "gzip")))
+}
@@ -0,0 +1,174 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+type testMeasurement struct {
+	value      float64
+	attributes attribute.Set
+}
+
+// testMeterProvider records the measurements of the synchronous histograms and counters by instrument name
+type testMeterProvider struct {
+	noop.MeterProvider
+	meter *testMeter
+}
+
+type testMeter struct {
+	noop.Meter
+	mutex        sync.Mutex
+	measurements map[string][]testMeasurement
+}
+
+func newTestMeterProvider() *testMeterProvider {
+	return &testMeterProvider{meter: &testMeter{measurements: make(map[string][]testMeasurement)}}
+}
+
+// useTestMeterProvider sets a new test meter provider as the global one until the end of the test
+func useTestMeterProvider(t *testing.T) *testMeterProvider {
+	provider := newTestMeterProvider()
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(provider)
+	t.Cleanup(func() { otel.SetMeterProvider(previous) })
+	return provider
+}
+
+func (p *testMeterProvider) Meter(name string, options ...metric.MeterOption) metric.Meter {
+	return p.meter
+}
+
+func (p *testMeterProvider) getMeasurements(name string) []testMeasurement {
+	p.meter.mutex.Lock()
+	defer p.meter.mutex.Unlock()
+	return append([]testMeasurement(nil), p.meter.measurements[name]...)
+}
+
+func (m *testMeter) record(name string, value float64, attributes attribute.Set) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.measurements[name] = append(m.measurements[name], testMeasurement{value: value, attributes: attributes})
+}
+
+func (m *testMeter) Int64Histogram(name string, options ...metric.Int64HistogramOption) (metric.Int64Histogram, error) {
+	return &testInt64Histogram{name: name, meter: m}, nil
+}
+
+func (m *testMeter) Float64Histogram(name string, options ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return &testFloat64Histogram{name: name, meter: m}, nil
+}
+
+func (m *testMeter) Int64Counter(name string, options ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return &testInt64Counter{name: name, meter: m}, nil
+}
+
+func (m *testMeter) Float64Counter(name string, options ...metric.Float64CounterOption) (metric.Float64Counter, error) {
+	return &testFloat64Counter{name: name, meter: m}, nil
+}
+
+func (m *testMeter) Int64UpDownCounter(name string, options ...metric.Int64UpDownCounterOption) (metric.Int64UpDownCounter, error) {
+	return &testInt64UpDownCounter{name: name, meter: m}, nil
+}
+
+type testInt64Histogram struct {
+	noop.Int64Histogram
+	name  string
+	meter *testMeter
+}
+
+func (h *testInt64Histogram) Record(ctx context.Context, value int64, options ...metric.RecordOption) {
+	h.meter.record(h.name, float64(value), metric.NewRecordConfig(options).Attributes())
+}
+
+type testFloat64Histogram struct {
+	noop.Float64Histogram
+	name  string
+	meter *testMeter
+}
+
+func (h *testFloat64Histogram) Record(ctx context.Context, value float64, options ...metric.RecordOption) {
+	h.meter.record(h.name, value, metric.NewRecordConfig(options).Attributes())
+}
+
+type testInt64Counter struct {
+	noop.Int64Counter
+	name  string
+	meter *testMeter
+}
+
+func (c *testInt64Counter) Add(ctx context.Context, value int64, options ...metric.AddOption) {
+	c.meter.record(c.name, float64(value), metric.NewAddConfig(options).Attributes())
+}
+
+type testFloat64Counter struct {
+	noop.Float64Counter
+	name  string
+	meter *testMeter
+}
+
+func (c *testFloat64Counter) Add(ctx context.Context, value float64, options ...metric.AddOption) {
+	c.meter.record(c.name, value, metric.NewAddConfig(options).Attributes())
+}
+
+type testInt64UpDownCounter struct {
+	noop.Int64UpDownCounter
+	name  string
+	meter *testMeter
+}
+
+func (c *testInt64UpDownCounter) Add(ctx context.Context, value int64, options ...metric.AddOption) {
+	c.meter.record(c.name, float64(value), metric.NewAddConfig(options).Attributes())
+}
+
+func TestItRecordsPayloadAndCompressionMetrics(t *testing.T) {
+	provider := useTestMeterProvider(t)
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.Header().Set("Content-Type", "application/json; charset=utf-8")
+		res.WriteHeader(200)
+		res.Write([]byte(`{"id":"1"}`))
+	}))
+	defer testServer.Close()
+	client := GetDefaultClient(&observabilityOptionsHandler{}, NewCompressionHandler(), NewNetworkObservabilityHandler())
+	resp, err := client.Post(testServer.URL, "application/json", strings.NewReader(strings.Repeat(`{"name":"value"}`, 100)))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	requestSizes := provider.getMeasurements(requestBodySizeMetricName)
+	assert.Equal(t, 1, len(requestSizes))
+	assert.Less(t, requestSizes[0].value, float64(1600))
+	contentType, _ := requestSizes[0].attributes.Value(httpRequestHeaderContentTypeAttribute)
+	assert.Equal(t, "application/json", contentType.AsString())
+
+	responseSizes := provider.getMeasurements(responseBodySizeMetricName)
+	assert.Equal(t, 1, len(responseSizes))
+	assert.Equal(t, float64(10), responseSizes[0].value)
+	contentType, _ = responseSizes[0].attributes.Value(httpResponseHeaderContentTypeAttribute)
+	assert.Equal(t, "application/json", contentType.AsString())
+
+	ratios := provider.getMeasurements(compressionRatioMetricName)
+	assert.Equal(t, 1, len(ratios))
+	assert.Greater(t, ratios[0].value, float64(10))
+}
+
+func TestItDoesNotRecordMetricsWithoutObservabilityOptions(t *testing.T) {
+	provider := useTestMeterProvider(t)
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	client := GetDefaultClient(NewCompressionHandler(), NewNetworkObservabilityHandler())
+	_, err := client.Post(testServer.URL, "application/json", strings.NewReader(`{}`))
+	assert.Nil(t, err)
+	assert.Empty(t, provider.getMeasurements(requestBodySizeMetricName))
+}
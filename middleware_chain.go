@@ -0,0 +1,298 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+	"reflect"
+)
+
+// getMiddlewareName returns the name of the type of the middleware, without the pointer indirection (e.g. RetryHandler)
+func getMiddlewareName(middleware Middleware) string {
+	if middleware == nil {
+		return ""
+	}
+	middlewareType := reflect.TypeOf(middleware)
+	for middlewareType.Kind() == reflect.Ptr {
+		middlewareType = middlewareType.Elem()
+	}
+	return middlewareType.Name()
+}
+
+func (pipeline *middlewarePipeline) getMiddlewares() []Middleware {
+	pipeline.mutex.RLock()
+	defer pipeline.mutex.RUnlock()
+	return pipeline.middlewares
+}
+
+// mutate replaces the middlewares with the result of the given function, the slice passed to the function is a copy
+func (pipeline *middlewarePipeline) mutate(mutation func(middlewares []Middleware) ([]Middleware, bool)) bool {
+	pipeline.mutex.Lock()
+	defer pipeline.mutex.Unlock()
+	current := make([]Middleware, len(pipeline.middlewares))
+	copy(current, pipeline.middlewares)
+	result, changed := mutation(current)
+	if changed {
+		pipeline.middlewares = result
+	}
+	return changed
+}
+
+func indexOfMiddleware(middlewares []Middleware, match func(Middleware) bool) int {
+	for i, middleware := range middlewares {
+		if match(middleware) {
+			return i
+		}
+	}
+	return -1
+}
+
+func matchMiddlewareName(name string) func(Middleware) bool {
+	return func(middleware Middleware) bool {
+		return getMiddlewareName(middleware) == name
+	}
+}
+
+func insertMiddlewares(middlewares []Middleware, index int, inserted ...Middleware) []Middleware {
+	result := make([]Middleware, 0, len(middlewares)+len(inserted))
+	result = append(result, middlewares[:index]...)
+	result = append(result, inserted...)
+	return append(result, middlewares[index:]...)
+}
+
+// Use appends the given middlewares at the end of the chain, right before the request is sent.
+// The chain should be mutated before sending requests.
+func (transport *customTransport) Use(middlewares ...Middleware) {
+	transport.middlewarePipeline.mutate(func(current []Middleware) ([]Middleware, bool) {
+		return append(current, middlewares...), len(middlewares) > 0
+	})
+}
+
+// InsertBefore inserts the given middlewares before the first middleware of the chain with the given type name (e.g. RetryHandler).
+// It returns false when no middleware with that name is present.
+func (transport *customTransport) InsertBefore(name string, middlewares ...Middleware) bool {
+	return transport.middlewarePipeline.mutate(func(current []Middleware) ([]Middleware, bool) {
+		index := indexOfMiddleware(current, matchMiddlewareName(name))
+		if index < 0 {
+			return current, false
+		}
+		return insertMiddlewares(current, index, middlewares...), true
+	})
+}
+
+// InsertAfter inserts the given middlewares after the first middleware of the chain with the given type name (e.g. RetryHandler).
+// It returns false when no middleware with that name is present.
+func (transport *customTransport) InsertAfter(name string, middlewares ...Middleware) bool {
+	return transport.middlewarePipeline.mutate(func(current []Middleware) ([]Middleware, bool) {
+		index := indexOfMiddleware(current, matchMiddlewareName(name))
+		if index < 0 {
+			return current, false
+		}
+		return insertMiddlewares(current, index+1, middlewares...), true
+	})
+}
+
+// Replace replaces the first middleware of the chain with the given type name (e.g. RetryHandler) by the given middleware.
+// It returns false when no middleware with that name is present.
+func (transport *customTransport) Replace(name string, middleware Middleware) bool {
+	return transport.middlewarePipeline.mutate(func(current []Middleware) ([]Middleware, bool) {
+		index := indexOfMiddleware(current, matchMiddlewareName(name))
+		if index < 0 || middleware == nil {
+			return current, false
+		}
+		current[index] = middleware
+		return current, true
+	})
+}
+
+// Remove removes all the middlewares of the chain with the given type name (e.g. RetryHandler).
+// It returns false when no middleware with that name is present.
+func (transport *customTransport) Remove(name string) bool {
+	return transport.middlewarePipeline.mutate(func(current []Middleware) ([]Middleware, bool) {
+		result := make([]Middleware, 0, len(current))
+		for _, middleware := range current {
+			if getMiddlewareName(middleware) != name {
+				result = append(result, middleware)
+			}
+		}
+		return result, len(result) != len(current)
+	})
+}
+
+// RemoveMiddleware removes all the middlewares of type T (e.g. *RetryHandler) from the chain of the transport.
+// It returns false when no middleware of that type is present.
+func RemoveMiddleware[T Middleware](transport *customTransport) bool {
+	return transport.middlewarePipeline.mutate(func(current []Middleware) ([]Middleware, bool) {
+		result := make([]Middleware, 0, len(current))
+		for _, middleware := range current {
+			if _, ok := middleware.(T); !ok {
+				result = append(result, middleware)
+			}
+		}
+		return result, len(result) != len(current)
+	})
+}
+
+// ReplaceMiddleware replaces the first middleware of type T (e.g. *RetryHandler) of the chain of the transport by the given middleware.
+// It returns false when no middleware of that type is present.
+func ReplaceMiddleware[T Middleware](transport *customTransport, middleware Middleware) bool {
+	return transport.middlewarePipeline.mutate(func(current []Middleware) ([]Middleware, bool) {
+		index := indexOfMiddleware(current, func(candidate Middleware) bool {
+			_, ok := candidate.(T)
+			return ok
+		})
+		if index < 0 || middleware == nil {
+			return current, false
+		}
+		current[index] = middleware
+		return current, true
+	})
+}
+
+// MiddlewareDescriptor describes a middleware of the chain of a transport
+type MiddlewareDescriptor struct {
+	// Index is the position of the middleware in the chain, the first middleware intercepts the requests first
+	Index int
+	// Name is the name of the type of the middleware (e.g. RetryHandler)
+	Name string
+	// Options is a pointer to a copy of the options the middleware was configured with (e.g. *RetryHandlerOptions), nil when the middleware doesn't expose any.
+	// The options added to a request take precedence over them for that request.
+	Options any
+	// Middleware is the middleware itself
+	Middleware Middleware
+}
+
+// MiddlewareOptionsProvider is implemented by the custom middlewares exposing the options they were configured with to the middleware chain introspection
+type MiddlewareOptionsProvider interface {
+	// GetMiddlewareOptions returns the options the middleware was configured with
+	GetMiddlewareOptions() any
+}
+
+// getMiddlewareOptions returns a copy of the options the middleware was configured with, nil when they are not known
+func getMiddlewareOptions(middleware Middleware) any {
+	if value := reflect.ValueOf(middleware); value.IsValid() && value.Kind() != reflect.Ptr {
+		// the handlers added by value are described like the ones added by pointer
+		pointer := reflect.New(value.Type())
+		pointer.Elem().Set(value)
+		if pointerMiddleware, ok := pointer.Interface().(Middleware); ok {
+			middleware = pointerMiddleware
+		}
+	}
+	switch m := middleware.(type) {
+	case MiddlewareOptionsProvider:
+		return m.GetMiddlewareOptions()
+	case *AllowedHostsHandler:
+		options := m.options
+		return &options
+	case *BaggageHandler:
+		options := m.options
+		return &options
+	case *ChaosHandler:
+		return m.GetOptions()
+	case *ClientRequestIdHandler:
+		options := m.options
+		return &options
+	case *CompressionHandler:
+		options := m.options
+		return &options
+	case *ConnectionReuseHandler:
+		options := m.options
+		return &options
+	case *DecompressionHandler:
+		options := m.options
+		return &options
+	case *DigestAuthenticationHandler:
+		options := m.options
+		return &options
+	case *EarlyHintsHandler:
+		// the handler has no settings, it is configured with the options of the request
+		return NewEarlyHintsInspectionOptions()
+	case *ExpectContinueHandler:
+		options := m.options
+		return &options
+	case *HeadersInspectionHandler:
+		options := m.options
+		return &options
+	case *HmacSigningHandler:
+		options := m.options
+		return &options
+	case *LoadBalancingHandler:
+		options := m.options
+		return &options
+	case *NetworkObservabilityHandler:
+		options := m.options
+		return &options
+	case *observabilityOptionsHandler:
+		options := m.options
+		return &options
+	case *OfflineQueueHandler:
+		options := m.options
+		return &options
+	case *OptimisticConcurrencyHandler:
+		options := m.options
+		return &options
+	case *ParametersNameDecodingHandler:
+		options := m.options
+		return &options
+	case *ProxyAuthenticationHandler:
+		options := m.options
+		return &options
+	case *RateLimitingHandler:
+		options := m.options
+		return &options
+	case *RedirectHandler:
+		options := m.options
+		return &options
+	case *RetryHandler:
+		options := m.options
+		return &options
+	case *RewriteHandler:
+		options := m.options
+		return &options
+	case *SchedulerHandler:
+		options := m.options
+		return &options
+	case *SchemaValidationHandler:
+		options := m.options
+		return &options
+	case *StubHandler:
+		options := m.options
+		return &options
+	case *TelemetryHandler:
+		options := m.options
+		return &options
+	case *UrlReplaceHandler:
+		options := m.options
+		return &options
+	case *UserAgentHandler:
+		options := m.options
+		return &options
+	}
+	return nil
+}
+
+// GetMiddlewareDescriptors returns the descriptors of the middlewares of the chain, in the order they intercept the requests
+func (transport *customTransport) GetMiddlewareDescriptors() []MiddlewareDescriptor {
+	middlewares := transport.middlewarePipeline.getMiddlewares()
+	result := make([]MiddlewareDescriptor, 0, len(middlewares))
+	for i, middleware := range middlewares {
+		result = append(result, MiddlewareDescriptor{
+			Index:      i,
+			Name:       getMiddlewareName(middleware),
+			Options:    getMiddlewareOptions(middleware),
+			Middleware: middleware,
+		})
+	}
+	return result
+}
+
+// GetClientMiddlewareDescriptors returns the descriptors of the middlewares of the client, in the order they intercept the requests.
+// It returns false when the transport of the client is not a transport created by this library.
+func GetClientMiddlewareDescriptors(client *nethttp.Client) ([]MiddlewareDescriptor, bool) {
+	if client == nil {
+		return nil, false
+	}
+	transport, ok := client.Transport.(*customTransport)
+	if !ok || transport == nil {
+		return nil, false
+	}
+	return transport.GetMiddlewareDescriptors(), true
+}
@@ -0,0 +1,147 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	"reflect"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func getMiddlewareNames(transport *customTransport) []string {
+	names := make([]string, 0)
+	for _, middleware := range transport.middlewarePipeline.getMiddlewares() {
+		names = append(names, getMiddlewareName(middleware))
+	}
+	return names
+}
+
+func TestItMutatesTheMiddlewareChain(t *testing.T) {
+	transport := NewCustomTransport(NewRetryHandler(), NewRedirectHandler())
+	transport.Use(NewUserAgentHandler())
+	assert.Equal(t, []string{"RetryHandler", "RedirectHandler", "UserAgentHandler"}, getMiddlewareNames(transport))
+
+	assert.True(t, transport.InsertBefore("RedirectHandler", NewCompressionHandler()))
+	assert.True(t, transport.InsertAfter("RedirectHandler", NewHeadersInspectionHandler()))
+	assert.False(t, transport.InsertAfter("ChaosHandler", NewChaosHandler()))
+	assert.Equal(t, []string{"RetryHandler", "CompressionHandler", "RedirectHandler", "HeadersInspectionHandler", "UserAgentHandler"}, getMiddlewareNames(transport))
+
+	assert.True(t, transport.Remove("CompressionHandler"))
+	assert.True(t, RemoveMiddleware[*HeadersInspectionHandler](transport))
+	assert.False(t, RemoveMiddleware[*ChaosHandler](transport))
+	assert.Equal(t, []string{"RetryHandler", "RedirectHandler", "UserAgentHandler"}, getMiddlewareNames(transport))
+
+	assert.True(t, ReplaceMiddleware[*RetryHandler](transport, NewParametersNameDecodingHandler()))
+	assert.True(t, transport.Replace("UserAgentHandler", NewUrlReplaceHandler(false, nil)))
+	assert.Equal(t, []string{"ParametersNameDecodingHandler", "RedirectHandler", "UrlReplaceHandler"}, getMiddlewareNames(transport))
+}
+
+func TestItKeepsTheMiddlewaresOfTheRequestsInFlight(t *testing.T) {
+	var transport *customTransport
+	firstCount := 0
+	lastCount := 0
+	first := NewMiddlewareFromContextMiddleware(ContextMiddlewareFunc(func(ctx context.Context, pipeline ContextPipeline, middlewareIndex int, req *nethttp.Request) (context.Context, *nethttp.Response, error) {
+		firstCount++
+		transport.InsertBefore("contextMiddlewareAdapter", NewUserAgentHandler())
+		return pipeline.Next(ctx, req, middlewareIndex)
+	}))
+	last := NewMiddlewareFromContextMiddleware(ContextMiddlewareFunc(func(ctx context.Context, pipeline ContextPipeline, middlewareIndex int, req *nethttp.Request) (context.Context, *nethttp.Response, error) {
+		lastCount++
+		assert.Empty(t, req.Header.Get("User-Agent"))
+		return pipeline.Next(ctx, req, middlewareIndex)
+	}))
+	transport = NewCustomTransportWithParentTransport(roundTripperFunc(func(req *nethttp.Request) (*nethttp.Response, error) {
+		return &nethttp.Response{StatusCode: 200, Header: make(nethttp.Header), Body: nethttp.NoBody, Request: req}, nil
+	}), first, NewHeadersInspectionHandler(), last)
+	req, _ := nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com/v1.0/me", nil)
+	resp, err := transport.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 1, firstCount)
+	assert.Equal(t, 1, lastCount)
+	assert.Equal(t, []string{"UserAgentHandler", "contextMiddlewareAdapter", "HeadersInspectionHandler", "contextMiddlewareAdapter"}, getMiddlewareNames(transport))
+}
+
+func TestItDescribesTheMiddlewareChain(t *testing.T) {
+	transport := NewCustomTransport(NewRetryHandlerWithOptions(RetryHandlerOptions{MaxRetries: 5}), NewEarlyHintsHandler())
+	descriptors := transport.GetMiddlewareDescriptors()
+	if assert.Equal(t, 2, len(descriptors)) {
+		assert.Equal(t, 0, descriptors[0].Index)
+		assert.Equal(t, "RetryHandler", descriptors[0].Name)
+		options, ok := descriptors[0].Options.(*RetryHandlerOptions)
+		if assert.True(t, ok) {
+			assert.Equal(t, 5, options.GetMaxRetries())
+		}
+		assert.Equal(t, 1, descriptors[1].Index)
+		assert.Equal(t, "EarlyHintsHandler", descriptors[1].Name)
+		assert.IsType(t, &EarlyHintsInspectionOptions{}, descriptors[1].Options)
+	}
+
+	client := GetDefaultClient(NewRedirectHandler())
+	clientDescriptors, ok := GetClientMiddlewareDescriptors(client)
+	assert.True(t, ok)
+	if assert.Equal(t, 1, len(clientDescriptors)) {
+		assert.Equal(t, "RedirectHandler", clientDescriptors[0].Name)
+		assert.IsType(t, &RedirectHandlerOptions{}, clientDescriptors[0].Options)
+	}
+	_, ok = GetClientMiddlewareDescriptors(&nethttp.Client{})
+	assert.False(t, ok)
+}
+
+func TestItDescribesTheOptionsOfEveryBuiltInHandler(t *testing.T) {
+	options := []abs.RequestOption{
+		&AllowedHostsOptions{},
+		&BaggageHandlerOptions{},
+		&ChaosHandlerOptions{ChaosPercentage: 10, ChaosStrategy: Random},
+		&ClientRequestIdHandlerOptions{},
+		&CompressionOptions{},
+		&ConnectionReuseOptions{},
+		&DecompressionHandlerOptions{},
+		&DigestAuthenticationHandlerOptions{Username: "user"},
+		&EarlyHintsInspectionOptions{},
+		&ExpectContinueOptions{},
+		&HeadersInspectionOptions{},
+		&HmacSigningHandlerOptions{Enabled: true, Secret: []byte("secret")},
+		&LoadBalancingHandlerOptions{Endpoints: []string{"https://graph.microsoft.com"}},
+		&NetworkObservabilityHandlerOptions{},
+		&ObservabilityOptions{},
+		&OfflineQueueHandlerOptions{Store: NewInMemoryOfflineRequestStore()},
+		&OptimisticConcurrencyHandlerOptions{},
+		&ParametersNameDecodingOptions{},
+		&ProxyAuthenticationHandlerOptions{Username: "user"},
+		&RateLimitingHandlerOptions{RequestsPerSecond: 1, Burst: 1},
+		&RedirectHandlerOptions{},
+		&RetryHandlerOptions{},
+		&RewriteOptions{},
+		&SchemaValidationOptions{},
+		&StubHandlerOptions{},
+		&TelemetryHandlerOptions{},
+		&UrlReplaceOptions{},
+		&UserAgentHandlerOptions{},
+	}
+	for _, option := range options {
+		assert.True(t, isBuiltInMiddlewareOption(option), reflect.TypeOf(option).String())
+	}
+	middlewares, err := GetDefaultMiddlewaresWithOptions(options...)
+	assert.Nil(t, err)
+	schedulerHandler, err := NewSchedulerHandler(1)
+	assert.Nil(t, err)
+	middlewares = append(middlewares, schedulerHandler)
+	// the options built by the factory plus the feature usage and scheduler handlers
+	assert.Equal(t, len(options)+2, len(middlewares))
+	for _, middleware := range middlewares {
+		name := getMiddlewareName(middleware)
+		if name == "FeatureUsageHandler" {
+			// the handler has no options, the user agent handler options enable the feature usage
+			assert.Nil(t, getMiddlewareOptions(middleware))
+			continue
+		}
+		described := getMiddlewareOptions(middleware)
+		assert.NotNil(t, described, name)
+		if value := reflect.ValueOf(middleware).Elem(); value.Type().Implements(reflect.TypeOf((*Middleware)(nil)).Elem()) {
+			assert.IsType(t, described, getMiddlewareOptions(value.Interface().(Middleware)), name)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package nethttplibrary
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+)
+
+// MiddlewareFactory creates a middleware configured with the given request option
+type MiddlewareFactory func(option abs.RequestOption) (Middleware, error)
+
+var middlewareFactories = struct {
+	sync.RWMutex
+	factories map[reflect.Type]MiddlewareFactory
+}{
+	factories: make(map[reflect.Type]MiddlewareFactory),
+}
+
+// RegisterMiddlewareFactory registers the factory creating the middleware configured with request options of type T (e.g. *MyHandlerOptions),
+// so third-party handlers can be added to the default middlewares by GetDefaultMiddlewaresWithOptions and the client builder.
+// The options of the handlers shipped with the library cannot be registered, registering a factory for the same type again replaces it.
+func RegisterMiddlewareFactory[T abs.RequestOption](factory func(option T) (Middleware, error)) error {
+	if factory == nil {
+		return errors.New("factory cannot be nil")
+	}
+	optionType := reflect.TypeOf((*T)(nil)).Elem()
+	if optionType.Kind() == reflect.Interface {
+		return errors.New("the option type must be a concrete type")
+	}
+	if isBuiltInMiddlewareOption(reflect.New(optionType).Elem().Interface()) {
+		return errors.New("the options of the handlers shipped with the library cannot be registered")
+	}
+	middlewareFactories.Lock()
+	defer middlewareFactories.Unlock()
+	middlewareFactories.factories[optionType] = func(option abs.RequestOption) (Middleware, error) {
+		middleware, err := factory(option.(T))
+		if err == nil && middleware == nil {
+			return nil, errors.New("the factory returned a nil middleware")
+		}
+		return middleware, err
+	}
+	return nil
+}
+
+// UnregisterMiddlewareFactory removes the factory registered for request options of type T, it returns false when none was registered
+func UnregisterMiddlewareFactory[T abs.RequestOption]() bool {
+	optionType := reflect.TypeOf((*T)(nil)).Elem()
+	middlewareFactories.Lock()
+	defer middlewareFactories.Unlock()
+	_, ok := middlewareFactories.factories[optionType]
+	delete(middlewareFactories.factories, optionType)
+	return ok
+}
+
+// getMiddlewareFactory returns the factory registered for the type of the option
+func getMiddlewareFactory(option abs.RequestOption) (MiddlewareFactory, bool) {
+	middlewareFactories.RLock()
+	defer middlewareFactories.RUnlock()
+	factory, ok := middlewareFactories.factories[reflect.TypeOf(option)]
+	return factory, ok
+}
@@ -0,0 +1,70 @@
+package nethttplibrary
+
+import (
+	"errors"
+	nethttp "net/http"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type testThirdPartyOptions struct {
+	HeaderValue string
+}
+
+func (o *testThirdPartyOptions) GetKey() abs.RequestOptionKey {
+	return abs.RequestOptionKey{Key: "TestThirdPartyHandler"}
+}
+
+type testThirdPartyHandler struct {
+	options testThirdPartyOptions
+}
+
+func (middleware *testThirdPartyHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	req.Header.Set("X-Third-Party", middleware.options.HeaderValue)
+	return pipeline.Next(req, middlewareIndex)
+}
+
+func TestItCreatesRegisteredMiddlewares(t *testing.T) {
+	_, err := GetDefaultMiddlewaresWithOptions(&testThirdPartyOptions{})
+	assert.Equal(t, "unsupported option type", err.Error())
+
+	err = RegisterMiddlewareFactory(func(options *testThirdPartyOptions) (Middleware, error) {
+		if options.HeaderValue == "" {
+			return nil, errors.New("header value cannot be empty")
+		}
+		return &testThirdPartyHandler{options: *options}, nil
+	})
+	assert.Nil(t, err)
+	defer UnregisterMiddlewareFactory[*testThirdPartyOptions]()
+
+	middlewares, err := GetDefaultMiddlewaresWithOptions(&testThirdPartyOptions{HeaderValue: "value"})
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(middlewares))
+	_, err = GetDefaultMiddlewaresWithOptions(&testThirdPartyOptions{})
+	assert.Error(t, err)
+
+	client, err := NewKiotaClientBuilder().WithDefaultMiddlewareOptions(&testThirdPartyOptions{HeaderValue: "value"}).Build()
+	assert.Nil(t, err)
+	found := false
+	for _, middleware := range client.Transport.(*customTransport).middlewarePipeline.getMiddlewares() {
+		if _, ok := middleware.(*testThirdPartyHandler); ok {
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	assert.True(t, UnregisterMiddlewareFactory[*testThirdPartyOptions]())
+	assert.False(t, UnregisterMiddlewareFactory[*testThirdPartyOptions]())
+}
+
+func TestItRejectsInvalidMiddlewareFactories(t *testing.T) {
+	assert.Error(t, RegisterMiddlewareFactory[*testThirdPartyOptions](nil))
+	assert.Error(t, RegisterMiddlewareFactory(func(options *RetryHandlerOptions) (Middleware, error) {
+		return NewRetryHandler(), nil
+	}))
+	assert.Error(t, RegisterMiddlewareFactory(func(options abs.RequestOption) (Middleware, error) {
+		return NewRetryHandler(), nil
+	}))
+}
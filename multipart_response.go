@@ -0,0 +1,233 @@
+package nethttplibrary
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	nethttp "net/http"
+	"strings"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absser "github.com/microsoft/kiota-abstractions-go/serialization"
+)
+
+const multipartMixedContentType = "multipart/mixed"
+const httpMessageContentType = "application/http"
+
+// MultipartResponsePart is a part of a multipart response
+type MultipartResponsePart struct {
+	// ContentId is the Content-ID header of the part without the angle brackets, empty when the part doesn't have one
+	ContentId string
+	// StatusCode is the status code of the HTTP response embedded in the part (application/http), zero when the part doesn't embed a response
+	StatusCode int
+	// Headers are the headers of the embedded HTTP response, or the headers of the part when it doesn't embed a response.
+	// The values of multi-valued headers are comma separated.
+	Headers map[string]string
+	// Body is the content of the part, or of the embedded HTTP response
+	Body []byte
+}
+
+// toBatchResponseItem converts the part so it can be deserialized like the responses of a batch
+func (part MultipartResponsePart) toBatchResponseItem() BatchResponseItem {
+	return BatchResponseItem{
+		Id:         part.ContentId,
+		StatusCode: part.StatusCode,
+		Headers:    part.Headers,
+		Body:       part.Body,
+	}
+}
+
+// MultipartResponse holds the parts of a multipart response (e.g. multipart/mixed), the parts of nested multipart parts are flattened in order
+type MultipartResponse struct {
+	parts            []MultipartResponsePart
+	parseNodeFactory absser.ParseNodeFactory
+}
+
+// NewMultipartResponse reads the parts of the multipart content, the parse node factory deserializes the bodies of the parts
+func NewMultipartResponse(contentType string, content io.Reader, parseNodeFactory absser.ParseNodeFactory) (*MultipartResponse, error) {
+	if content == nil {
+		return nil, errors.New("content cannot be nil")
+	}
+	if parseNodeFactory == nil {
+		parseNodeFactory = absser.DefaultParseNodeFactoryInstance
+	}
+	parts, err := readMultipartParts(contentType, content)
+	if err != nil {
+		return nil, err
+	}
+	return &MultipartResponse{
+		parts:            parts,
+		parseNodeFactory: parseNodeFactory,
+	}, nil
+}
+
+// readMultipartParts reads the parts of the multipart content, flattening the nested multipart parts
+func readMultipartParts(contentType string, content io.Reader) ([]MultipartResponsePart, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, fmt.Errorf("the content type %s is not a multipart content type", mediaType)
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, errors.New("the multipart content type doesn't have a boundary")
+	}
+	reader := multipart.NewReader(content, boundary)
+	var result []MultipartResponsePart
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return result, nil
+		} else if err != nil {
+			return nil, err
+		}
+		partContentType := part.Header.Get("Content-Type")
+		partMediaType := getMediaType(partContentType)
+		if strings.HasPrefix(partMediaType, "multipart/") {
+			nestedParts, err := readMultipartParts(partContentType, part)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, nestedParts...)
+			continue
+		}
+		item := MultipartResponsePart{
+			ContentId: strings.Trim(part.Header.Get("Content-ID"), "<>"),
+		}
+		if partMediaType == httpMessageContentType {
+			response, err := nethttp.ReadResponse(bufio.NewReader(part), nil)
+			if err != nil {
+				return nil, err
+			}
+			item.StatusCode = response.StatusCode
+			item.Headers = getMultipartHeaders(response.Header)
+			item.Body, err = io.ReadAll(response.Body)
+			response.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			item.Headers = getMultipartHeaders(nethttp.Header(part.Header))
+			item.Body, err = io.ReadAll(part)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(item.Body) == 0 {
+			item.Body = nil
+		}
+		result = append(result, item)
+	}
+}
+
+// getMultipartHeaders flattens the headers, joining the values of multi-valued headers with commas
+func getMultipartHeaders(header nethttp.Header) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(header))
+	for key, values := range header {
+		result[key] = strings.Join(values, ", ")
+	}
+	return result
+}
+
+// GetParts returns the parts of the response, in order
+func (r *MultipartResponse) GetParts() []MultipartResponsePart {
+	result := make([]MultipartResponsePart, len(r.parts))
+	copy(result, r.parts)
+	return result
+}
+
+// GetPartByContentId returns the first part with the given Content-ID
+func (r *MultipartResponse) GetPartByContentId(contentId string) (MultipartResponsePart, bool) {
+	for _, part := range r.parts {
+		if part.ContentId == contentId {
+			return part, true
+		}
+	}
+	return MultipartResponsePart{}, false
+}
+
+// getPart returns the part at the given index
+func (r *MultipartResponse) getPart(index int) (MultipartResponsePart, error) {
+	if index < 0 || index >= len(r.parts) {
+		return MultipartResponsePart{}, fmt.Errorf("the multipart response doesn't contain a part at the index %d", index)
+	}
+	return r.parts[index], nil
+}
+
+// GetError returns the error of the HTTP response embedded in the part at the given index, nil when it succeeded or the part doesn't embed a response.
+// The error is deserialized with the factory mapped to the status code, an ApiError is returned when none is mapped.
+func (r *MultipartResponse) GetError(index int, errorMappings abs.ErrorMappings) error {
+	part, err := r.getPart(index)
+	if err != nil {
+		return err
+	}
+	return getBatchResponseItemError(part.toBatchResponseItem(), r.parseNodeFactory, errorMappings)
+}
+
+// GetMultipartResponseValue returns the deserialized body of the part at the given index.
+// The error of the embedded HTTP response is returned when it failed, see MultipartResponse.GetError.
+func GetMultipartResponseValue[T absser.Parsable](response *MultipartResponse, index int, factory absser.ParsableFactory, errorMappings abs.ErrorMappings) (T, error) {
+	var result T
+	if response == nil {
+		return result, errors.New("response cannot be nil")
+	}
+	if err := response.GetError(index, errorMappings); err != nil {
+		return result, err
+	}
+	value, err := getBatchResponseItemValue(response.parts[index].toBatchResponseItem(), response.parseNodeFactory, factory)
+	if err != nil || value == nil {
+		return result, err
+	}
+	result, ok := value.(T)
+	if !ok {
+		return result, fmt.Errorf("the part is a %T where a %T was expected", value, result)
+	}
+	return result, nil
+}
+
+// SendMultipart sends the request and reads the parts of the multipart response, nil is returned when the response doesn't have a body.
+// The Accept header is set to multipart/mixed unless the request already has one.
+func (a *NetHttpRequestAdapter) SendMultipart(ctx context.Context, requestInfo *abs.RequestInformation, errorMappings abs.ErrorMappings) (*MultipartResponse, error) {
+	if requestInfo == nil {
+		return nil, errors.New("requestInfo cannot be nil")
+	}
+	requestInfo.Headers.TryAdd("Accept", multipartMixedContentType)
+	if err := a.startSend(); err != nil {
+		return nil, err
+	}
+	defer a.endSend()
+	ctx = a.prepareContext(ctx, requestInfo)
+	ctx, span := a.startTracingSpan(ctx, requestInfo, "SendMultipart")
+	defer span.End()
+	response, err := a.getHttpResponseMessage(ctx, requestInfo, "", span)
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		return nil, errors.New("response is nil")
+	}
+	defer a.purge(response)
+	err = a.throwIfFailedResponse(ctx, response, errorMappings, span)
+	if err != nil {
+		return nil, err
+	}
+	if a.shouldReturnNil(response) {
+		return nil, nil
+	}
+	defer startTimingPhase(ctx, deserializationTimingPhase)()
+	result, err := NewMultipartResponse(response.Header.Get("Content-Type"), response.Body, a.getParseNodeFactory())
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return result, nil
+}
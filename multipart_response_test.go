@@ -0,0 +1,96 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"strings"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/stretchr/testify/assert"
+)
+
+const testMultipartContent = "--batch\r\n" +
+	"Content-Type: application/http\r\n" +
+	"Content-ID: <1>\r\n" +
+	"\r\n" +
+	"HTTP/1.1 200 OK\r\n" +
+	"Content-Type: application/json\r\n" +
+	"\r\n" +
+	"{\"value\":[\"a\",\"b\"]}\r\n" +
+	"--batch\r\n" +
+	"Content-Type: multipart/mixed; boundary=changeset\r\n" +
+	"\r\n" +
+	"--changeset\r\n" +
+	"Content-Type: application/http\r\n" +
+	"Content-ID: 2\r\n" +
+	"\r\n" +
+	"HTTP/1.1 404 Not Found\r\n" +
+	"Content-Type: application/json\r\n" +
+	"\r\n" +
+	"{\"error\":{\"code\":\"itemNotFound\"}}\r\n" +
+	"--changeset--\r\n" +
+	"\r\n" +
+	"--batch\r\n" +
+	"Content-Type: text/csv\r\n" +
+	"\r\n" +
+	"id,name\r\n" +
+	"--batch--\r\n"
+
+func TestItReadsTheMultipartResponseParts(t *testing.T) {
+	response, err := NewMultipartResponse("multipart/mixed; boundary=batch", strings.NewReader(testMultipartContent), &testContentParseNodeFactory{})
+	assert.Nil(t, err)
+	parts := response.GetParts()
+	if assert.Equal(t, 3, len(parts)) {
+		assert.Equal(t, "1", parts[0].ContentId)
+		assert.Equal(t, 200, parts[0].StatusCode)
+		assert.Equal(t, "application/json", parts[0].Headers["Content-Type"])
+		assert.Equal(t, 404, parts[1].StatusCode)
+		assert.Equal(t, 0, parts[2].StatusCode)
+		assert.Equal(t, "text/csv", parts[2].Headers["Content-Type"])
+		assert.Equal(t, "id,name", string(parts[2].Body))
+	}
+	part, ok := response.GetPartByContentId("2")
+	assert.True(t, ok)
+	assert.Equal(t, 404, part.StatusCode)
+
+	page, err := GetMultipartResponseValue[*testPage](response, 0, testBatchPageFactory, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, page.items)
+
+	_, err = GetMultipartResponseValue[*testPage](response, 1, testBatchPageFactory, abs.ErrorMappings{"4XX": testBatchErrorFactory})
+	var batchErr *testBatchError
+	if assert.True(t, errors.As(err, &batchErr)) {
+		assert.Equal(t, "itemNotFound", batchErr.code)
+	}
+	assert.Nil(t, response.GetError(2, nil))
+	assert.Error(t, response.GetError(3, nil))
+
+	_, err = NewMultipartResponse("application/json", strings.NewReader(testMultipartContent), nil)
+	assert.Error(t, err)
+}
+
+func TestItSendsMultipartRequests(t *testing.T) {
+	var receivedAccept string
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		receivedAccept = req.Header.Get("Accept")
+		res.Header().Set("Content-Type", "multipart/mixed; boundary=batch")
+		res.WriteHeader(200)
+		res.Write([]byte(testMultipartContent))
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapterWithParseNodeFactory(&absauth.AnonymousAuthenticationProvider{}, &testContentParseNodeFactory{})
+	assert.Nil(t, err)
+	adapter.SetBaseUrl(testServer.URL)
+	requestInfo := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/export", map[string]string{})
+
+	response, err := adapter.SendMultipart(context.Background(), requestInfo, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "multipart/mixed", receivedAccept)
+	if assert.NotNil(t, response) {
+		assert.Equal(t, 3, len(response.GetParts()))
+	}
+}
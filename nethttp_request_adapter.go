@@ -10,12 +10,13 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	abs "github.com/microsoft/kiota-abstractions-go"
 	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
 	absser "github.com/microsoft/kiota-abstractions-go/serialization"
 	"github.com/microsoft/kiota-abstractions-go/store"
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
@@ -36,6 +37,8 @@ func (nopCloser) Close() error { return nil }
 
 // NetHttpRequestAdapter implements the RequestAdapter interface using net/http
 type NetHttpRequestAdapter struct {
+	// activeRequests is the number of requests in flight, first so it's 64-bit aligned for the atomic operations
+	activeRequests int64
 	// serializationWriterFactory is the factory used to create serialization writers
 	serializationWriterFactory absser.SerializationWriterFactory
 	// parseNodeFactory is the factory used to create parse nodes
@@ -46,8 +49,24 @@ type NetHttpRequestAdapter struct {
 	authenticationProvider absauth.AuthenticationProvider
 	// The base url for every request.
 	baseUrl string
+	// baseUrlResolution resolves the base url of the requests instead of baseUrl when set
+	baseUrlResolution *baseUrlResolution
+	// baseUrlMutex guards baseUrlResolution
+	baseUrlMutex sync.Mutex
 	// The observation options for the request adapter.
-	observabilityOptions ObservabilityOptions
+	observabilityOptions *ObservabilityOptions
+	// observabilityMutex guards observabilityOptions, which is replaced instead of modified as the requests in flight hold it
+	observabilityMutex sync.RWMutex
+	// backingStoreEnabled defines whether the parse nodes and serialization writers are wrapped with the backing store proxies
+	backingStoreEnabled bool
+	// factoriesMutex guards the factories and backingStoreEnabled
+	factoriesMutex sync.RWMutex
+	// sends are the sends in flight, waited for on shutdown
+	sends sync.WaitGroup
+	// shutdown defines whether the request adapter stopped accepting new sends
+	shutdown bool
+	// shutdownMutex guards shutdown and the additions to sends
+	shutdownMutex sync.Mutex
 }
 
 // NewNetHttpRequestAdapter creates a new NetHttpRequestAdapter with the given parameters
@@ -81,7 +100,7 @@ func NewNetHttpRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAn
 		httpClient:                 httpClient,
 		authenticationProvider:     authenticationProvider,
 		baseUrl:                    "",
-		observabilityOptions:       observabilityOptions,
+		observabilityOptions:       &observabilityOptions,
 	}
 	if result.httpClient == nil {
 		defaultClient := GetDefaultClient()
@@ -98,52 +117,142 @@ func NewNetHttpRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAn
 
 // GetSerializationWriterFactory returns the serialization writer factory currently in use for the request adapter service.
 func (a *NetHttpRequestAdapter) GetSerializationWriterFactory() absser.SerializationWriterFactory {
-	return a.serializationWriterFactory
+	a.factoriesMutex.RLock()
+	factory, backingStoreEnabled := a.serializationWriterFactory, a.backingStoreEnabled
+	a.factoriesMutex.RUnlock()
+	if registry, ok := factory.(*absser.SerializationWriterFactoryRegistry); ok && backingStoreEnabled {
+		// the factories registered since the backing store was enabled need to be wrapped too, the registry is wrapped in place under its own lock
+		abs.EnableBackingStoreForSerializationWriterFactory(registry)
+	}
+	return factory
+}
+
+// getParseNodeFactory returns the parse node factory currently in use for the request adapter service.
+func (a *NetHttpRequestAdapter) getParseNodeFactory() absser.ParseNodeFactory {
+	a.factoriesMutex.RLock()
+	factory, backingStoreEnabled := a.parseNodeFactory, a.backingStoreEnabled
+	a.factoriesMutex.RUnlock()
+	if registry, ok := factory.(*absser.ParseNodeFactoryRegistry); ok && backingStoreEnabled {
+		// the factories registered since the backing store was enabled need to be wrapped too, the registry is wrapped in place under its own lock
+		abs.EnableBackingStoreForParseNodeFactory(registry)
+	}
+	return factory
 }
 
 // EnableBackingStore enables the backing store proxies for the SerializationWriters and ParseNodes in use.
 func (a *NetHttpRequestAdapter) EnableBackingStore(factory store.BackingStoreFactory) {
-	a.parseNodeFactory = abs.EnableBackingStoreForParseNodeFactory(a.parseNodeFactory)
-	a.serializationWriterFactory = abs.EnableBackingStoreForSerializationWriterFactory(a.serializationWriterFactory)
+	a.factoriesMutex.Lock()
+	a.backingStoreEnabled = true
+	a.parseNodeFactory = enableBackingStoreForParseNodeFactory(a.parseNodeFactory)
+	a.serializationWriterFactory = enableBackingStoreForSerializationWriterFactory(a.serializationWriterFactory)
+	a.factoriesMutex.Unlock()
 	if factory != nil {
 		store.BackingStoreFactoryInstance = factory
 	}
 }
 
+// enableBackingStoreForParseNodeFactory wraps the factory with the backing store proxy unless it is already wrapped, the factories of registries are wrapped in place
+func enableBackingStoreForParseNodeFactory(factory absser.ParseNodeFactory) absser.ParseNodeFactory {
+	if _, ok := factory.(*store.BackingStoreParseNodeFactory); ok {
+		return factory
+	}
+	return abs.EnableBackingStoreForParseNodeFactory(factory)
+}
+
+// enableBackingStoreForSerializationWriterFactory wraps the factory with the backing store proxy unless it is already wrapped, the factories of registries are wrapped in place
+func enableBackingStoreForSerializationWriterFactory(factory absser.SerializationWriterFactory) absser.SerializationWriterFactory {
+	if _, ok := factory.(*store.BackingStoreSerializationWriterProxyFactory); ok {
+		return factory
+	}
+	return abs.EnableBackingStoreForSerializationWriterFactory(factory)
+}
+
 // SetBaseUrl sets the base url for every request.
 func (a *NetHttpRequestAdapter) SetBaseUrl(baseUrl string) {
 	a.baseUrl = baseUrl
 }
 
+// SetObservabilityName sets the name of the instrumentation scope the spans of the requests sent afterwards are attributed to,
+// the default name is used when empty. ObservabilityNameOptions overrides it for a request.
+func (a *NetHttpRequestAdapter) SetObservabilityName(observabilityName string) {
+	a.observabilityMutex.Lock()
+	defer a.observabilityMutex.Unlock()
+	observabilityOptions := *a.observabilityOptions
+	observabilityOptions.SetTracerInstrumentationName(observabilityName)
+	a.observabilityOptions = &observabilityOptions
+}
+
+// GetObservabilityName returns the name of the instrumentation scope the spans of the requests are attributed to
+func (a *NetHttpRequestAdapter) GetObservabilityName() string {
+	return a.getObservabilityOptions().GetTracerInstrumentationName()
+}
+
+// getObservabilityOptions returns a snapshot of the observability options of the request adapter, it must not be modified
+func (a *NetHttpRequestAdapter) getObservabilityOptions() *ObservabilityOptions {
+	a.observabilityMutex.RLock()
+	defer a.observabilityMutex.RUnlock()
+	return a.observabilityOptions
+}
+
 // GetBaseUrl gets the base url for every request.
 func (a *NetHttpRequestAdapter) GetBaseUrl() string {
 	return a.baseUrl
 }
 
+// CloneWithBaseUrl returns a new request adapter sending the requests to the given base url.
+// The clone shares the http client and its middleware pipeline and connection pool, the factories and the authentication provider with the request adapter,
+// it gets a copy of the observability options and counts its active requests separately.
+func (a *NetHttpRequestAdapter) CloneWithBaseUrl(baseUrl string) *NetHttpRequestAdapter {
+	observabilityOptions := *a.getObservabilityOptions()
+	a.factoriesMutex.RLock()
+	defer a.factoriesMutex.RUnlock()
+	return &NetHttpRequestAdapter{
+		serializationWriterFactory: a.serializationWriterFactory,
+		parseNodeFactory:           a.parseNodeFactory,
+		httpClient:                 a.httpClient,
+		authenticationProvider:     a.authenticationProvider,
+		baseUrl:                    baseUrl,
+		observabilityOptions:       &observabilityOptions,
+		backingStoreEnabled:        a.backingStoreEnabled,
+	}
+}
+
 func (a *NetHttpRequestAdapter) getHttpResponseMessage(ctx context.Context, requestInfo *abs.RequestInformation, claims string, spanForAttributes trace.Span) (*nethttp.Response, error) {
-	ctx, span := otel.GetTracerProvider().Tracer(a.observabilityOptions.GetTracerInstrumentationName()).Start(ctx, "getHttpResponseMessage")
+	ctx, span := a.startSpan(ctx, DetailedSpanGranularity, "getHttpResponseMessage", getAttemptSpanOptions(ctx, getPreviousAttempt(ctx))...)
 	defer span.End()
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	a.setBaseUrlForRequestInformation(requestInfo)
+	if err := a.setBaseUrlForRequestInformation(ctx, requestInfo); err != nil {
+		return nil, err
+	}
 	additionalContext := make(map[string]any)
 	if claims != "" {
 		additionalContext[claimsKey] = claims
 	}
+	stopAuthenticationTiming := startTimingPhase(ctx, authenticationTimingPhase)
 	err := a.authenticationProvider.AuthenticateRequest(ctx, requestInfo, additionalContext)
+	stopAuthenticationTiming()
 	if err != nil {
 		return nil, err
 	}
+	stopSerializationTiming := startTimingPhase(ctx, serializationTimingPhase)
 	request, err := a.getRequestFromRequestInformation(ctx, requestInfo, spanForAttributes)
+	stopSerializationTiming()
 	if err != nil {
 		return nil, err
 	}
-	response, err := (*a.httpClient).Do(request)
+	a.setCustomSpanAttributes(spanForAttributes, request, nil)
+	stopPipelineTiming := startPipelineTiming(ctx)
+	endActiveRequest := a.startActiveRequest(ctx, request)
+	response, err := a.getHttpClient(ctx).Do(request)
+	endActiveRequest(response, err)
+	stopPipelineTiming()
 	if err != nil {
 		spanForAttributes.RecordError(err)
 		return nil, err
 	}
+	a.setCustomSpanAttributes(spanForAttributes, request, response)
 	if response != nil {
 		contentLenHeader := response.Header.Get("Content-Length")
 		if contentLenHeader != "" {
@@ -156,7 +265,8 @@ func (a *NetHttpRequestAdapter) getHttpResponseMessage(ctx context.Context, requ
 		}
 		spanForAttributes.SetAttributes(
 			httpResponseStatusCodeAttribute.Int(response.StatusCode),
-			networkProtocolNameAttribute.String(response.Proto),
+			networkProtocolNameAttribute.String("http"),
+			networkProtocolVersionAttribute.String(getProtocolVersion(response.Proto)),
 		)
 	}
 	return a.retryCAEResponseIfRequired(ctx, response, requestInfo, claims, spanForAttributes)
@@ -170,26 +280,38 @@ var reClaims = regexp.MustCompile(`\"([^\"]*)\"`)
 const AuthenticateChallengedEventKey = "com.microsoft.kiota.authenticate_challenge_received"
 
 func (a *NetHttpRequestAdapter) retryCAEResponseIfRequired(ctx context.Context, response *nethttp.Response, requestInfo *abs.RequestInformation, claims string, spanForAttributes trace.Span) (*nethttp.Response, error) {
-	ctx, span := otel.GetTracerProvider().Tracer(a.observabilityOptions.GetTracerInstrumentationName()).Start(ctx, "retryCAEResponseIfRequired")
+	previousAttempt := trace.SpanContextFromContext(ctx)
+	ctx, span := a.startSpan(ctx, DetailedSpanGranularity, "retryCAEResponseIfRequired")
 	defer span.End()
-	if response.StatusCode == 401 &&
-		claims == "" { //avoid infinite loop, we only retry once
+	if response.StatusCode == 401 {
 		authenticateHeaderVal := response.Header.Get("WWW-Authenticate")
-		if authenticateHeaderVal != "" && reBearer.Match([]byte(authenticateHeaderVal)) {
-			span.AddEvent(AuthenticateChallengedEventKey)
-			spanForAttributes.SetAttributes(httpRequestResendCountAttribute.Int(1))
+		if authenticateHeaderVal != "" {
+			isBearer := reBearer.Match([]byte(authenticateHeaderVal))
 			responseClaims := ""
-			parametersRaw := string(reBearer.ReplaceAll([]byte(authenticateHeaderVal), []byte("")))
-			parameters := strings.Split(parametersRaw, ",")
-			for _, parameter := range parameters {
-				if strings.HasPrefix(strings.Trim(parameter, " "), claimsKey) {
-					responseClaims = reClaims.FindStringSubmatch(parameter)[1]
-					break
+			if isBearer {
+				parametersRaw := string(reBearer.ReplaceAll([]byte(authenticateHeaderVal), []byte("")))
+				parameters := strings.Split(parametersRaw, ",")
+				for _, parameter := range parameters {
+					if strings.HasPrefix(strings.Trim(parameter, " "), claimsKey) {
+						if match := reClaims.FindStringSubmatch(parameter); len(match) > 1 {
+							responseClaims = match[1]
+						}
+						break
+					}
 				}
 			}
-			if responseClaims != "" {
+			//avoid infinite loop, we only retry once
+			retry := isBearer && claims == "" && responseClaims != ""
+			a.recordAuthenticationChallenge(ctx, span, response, authenticateHeaderVal, responseClaims != "", retry)
+			if isBearer && claims == "" {
+				spanForAttributes.SetAttributes(httpRequestResendCountAttribute.Int(1))
+			}
+			if retry {
 				defer a.purge(response)
-				return a.getHttpResponseMessage(ctx, requestInfo, responseClaims, spanForAttributes)
+				start := time.Now()
+				retryResponse, err := a.getHttpResponseMessage(withPreviousAttempt(ctx, previousAttempt), requestInfo, responseClaims, spanForAttributes)
+				a.recordContinuousAccessEvaluationRetry(ctx, span, response, responseClaims, retryResponse, err, time.Since(start))
+				return retryResponse, err
 			}
 		}
 	}
@@ -205,8 +327,22 @@ func (a *NetHttpRequestAdapter) getResponsePrimaryContentType(response *nethttp.
 	return strings.ToLower(splat[0])
 }
 
-func (a *NetHttpRequestAdapter) setBaseUrlForRequestInformation(requestInfo *abs.RequestInformation) {
-	requestInfo.PathParameters["baseurl"] = a.GetBaseUrl()
+// getResponseContentTypeParameters returns the parameters of the response media type (e.g. odata.metadata or profile)
+func (a *NetHttpRequestAdapter) getResponseContentTypeParameters(response *nethttp.Response) map[string]string {
+	if response.Header == nil {
+		return nil
+	}
+	_, parameters := parseContentType(response.Header.Get("Content-Type"))
+	return parameters
+}
+
+func (a *NetHttpRequestAdapter) setBaseUrlForRequestInformation(ctx context.Context, requestInfo *abs.RequestInformation) error {
+	baseUrl, err := a.resolveBaseUrl(ctx)
+	if err != nil {
+		return err
+	}
+	requestInfo.PathParameters["baseurl"] = baseUrl
+	return nil
 }
 
 func (a *NetHttpRequestAdapter) prepareContext(ctx context.Context, requestInfo *abs.RequestInformation) context.Context {
@@ -219,9 +355,7 @@ func (a *NetHttpRequestAdapter) prepareContext(ctx context.Context, requestInfo
 		ctx, _ = context.WithTimeout(ctx, a.httpClient.Timeout)
 	}
 
-	for _, value := range requestInfo.GetRequestOptions() {
-		ctx = context.WithValue(ctx, value.GetKey(), value)
-	}
+	ctx = WithRequestOptions(ctx, requestInfo.GetRequestOptions()...)
 	obsOptionsSet := false
 	if reqObsOpt := ctx.Value(observabilityOptionsKeyValue); reqObsOpt != nil {
 		if _, ok := reqObsOpt.(ObservabilityOptionsInt); ok {
@@ -229,9 +363,9 @@ func (a *NetHttpRequestAdapter) prepareContext(ctx context.Context, requestInfo
 		}
 	}
 	if !obsOptionsSet {
-		ctx = context.WithValue(ctx, observabilityOptionsKeyValue, &a.observabilityOptions)
+		ctx = context.WithValue(ctx, observabilityOptionsKeyValue, a.getObservabilityOptions())
 	}
-	return ctx
+	return withObservabilityName(ctx)
 }
 
 // ConvertToNativeRequest converts the given RequestInformation into a native HTTP request.
@@ -248,7 +382,7 @@ func (a *NetHttpRequestAdapter) ConvertToNativeRequest(context context.Context,
 }
 
 func (a *NetHttpRequestAdapter) getRequestFromRequestInformation(ctx context.Context, requestInfo *abs.RequestInformation, spanForAttributes trace.Span) (*nethttp.Request, error) {
-	ctx, span := otel.GetTracerProvider().Tracer(a.observabilityOptions.GetTracerInstrumentationName()).Start(ctx, "getRequestFromRequestInformation")
+	ctx, span := a.startSpan(ctx, DetailedSpanGranularity, "getRequestFromRequestInformation")
 	defer span.End()
 	if spanForAttributes == nil {
 		spanForAttributes = span
@@ -260,12 +394,17 @@ func (a *NetHttpRequestAdapter) getRequestFromRequestInformation(ctx context.Con
 		return nil, err
 	}
 	spanForAttributes.SetAttributes(
-		serverAddressAttribute.String(uri.Scheme),
-		urlSchemeAttribute.String(uri.Host),
+		serverAddressAttribute.String(uri.Hostname()),
+		urlSchemeAttribute.String(uri.Scheme),
 	)
+	if port := uri.Port(); port != "" {
+		if portValue, err := strconv.Atoi(port); err == nil {
+			spanForAttributes.SetAttributes(serverPortAttribute.Int(portValue))
+		}
+	}
 
-	if a.observabilityOptions.IncludeEUIIAttributes {
-		spanForAttributes.SetAttributes(urlFullAttribute.String(uri.String()))
+	if a.getObservabilityOptions().IncludeEUIIAttributes {
+		spanForAttributes.SetAttributes(urlFullAttribute.String(a.getRedactor().RedactUrl(uri)))
 	}
 
 	request, err := nethttp.NewRequestWithContext(ctx, requestInfo.Method.String(), uri.String(), nil)
@@ -275,19 +414,19 @@ func (a *NetHttpRequestAdapter) getRequestFromRequestInformation(ctx context.Con
 		return nil, err
 	}
 	if len(requestInfo.Content) > 0 {
-		reader := bytes.NewReader(requestInfo.Content)
-		request.Body = NopCloser(reader)
+		content := requestInfo.Content
+		request.Body = NopCloser(bytes.NewReader(content))
+		request.ContentLength = int64(len(content))
+		// lets the standard library and the middlewares rewind the body when replaying the request
+		request.GetBody = func() (io.ReadCloser, error) {
+			return NopCloser(bytes.NewReader(content)), nil
+		}
 	}
 	if request.Header == nil {
 		request.Header = make(nethttp.Header)
 	}
 	if requestInfo.Headers != nil {
-		for _, key := range requestInfo.Headers.ListKeys() {
-			values := requestInfo.Headers.Get(key)
-			for _, v := range values {
-				request.Header.Add(key, v)
-			}
-		}
+		setRequestHeaders(request, requestInfo.Headers)
 		if request.Header.Get("Content-Type") != "" {
 			spanForAttributes.SetAttributes(
 				httpRequestHeaderContentTypeAttribute.String(request.Header.Get("Content-Type")),
@@ -309,12 +448,52 @@ const EventResponseHandlerInvokedKey = "com.microsoft.kiota.response_handler_inv
 
 var queryParametersCleanupRegex = regexp.MustCompile(`\{\?[^\}]+}`)
 
+// setCustomSpanAttributes sets the attributes returned by the span attributes callback of the observability options on the span
+func (a *NetHttpRequestAdapter) setCustomSpanAttributes(span trace.Span, request *nethttp.Request, response *nethttp.Response) {
+	callback := a.getObservabilityOptions().GetSpanAttributesCallback()
+	if callback == nil || span == nil {
+		return
+	}
+	if attributes := callback(request, response); len(attributes) > 0 {
+		span.SetAttributes(attributes...)
+	}
+}
+
+// getRedactor returns the redactor of the observability options of the adapter or the default one
+func (a *NetHttpRequestAdapter) getRedactor() *Redactor {
+	if a.getObservabilityOptions().GetRedactor() != nil {
+		return a.getObservabilityOptions().GetRedactor()
+	}
+	return defaultRedactor
+}
+
+// getObservabilityName returns the observability name of the request, the one of the request adapter when the request doesn't override it
+func (a *NetHttpRequestAdapter) getObservabilityName(ctx context.Context) string {
+	if _, ok := ctx.Value(observabilityNameKeyValue).(*ObservabilityNameOptions); ok {
+		if options, ok := ctx.Value(observabilityOptionsKeyValue).(ObservabilityOptionsInt); ok {
+			return options.GetTracerInstrumentationName()
+		}
+	}
+	return a.getObservabilityOptions().GetTracerInstrumentationName()
+}
+
+// startSpan starts a span when the span granularity of the observability options includes the given level,
+// otherwise it returns the context unchanged with a non-recording span
+func (a *NetHttpRequestAdapter) startSpan(ctx context.Context, level SpanGranularity, spanName string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if a.getObservabilityOptions().GetSpanGranularity() > level {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	ctx, span := getTracerProvider(ctx).Tracer(a.getObservabilityName(ctx)).Start(ctx, spanName, options...)
+	return ctx, withLegacyAttributes(span, a.getObservabilityOptions())
+}
+
 func (a *NetHttpRequestAdapter) startTracingSpan(ctx context.Context, requestInfo *abs.RequestInformation, methodName string) (context.Context, trace.Span) {
-	decodedUriTemplate := decodeUriEncodedString(requestInfo.UrlTemplate, []byte{'-', '.', '~', '$'})
+	decodedUriTemplate := decodeUriEncodedString(requestInfo.UrlTemplate, "-.~$")
 	telemetryPathValue := queryParametersCleanupRegex.ReplaceAll([]byte(decodedUriTemplate), []byte(""))
-	ctx, span := otel.GetTracerProvider().Tracer(a.observabilityOptions.GetTracerInstrumentationName()).Start(ctx, methodName+" - "+string(telemetryPathValue))
-	span.SetAttributes(urlUriTemplateAttribute.String(decodedUriTemplate))
-	return ctx, span
+	ctx, groupId := withAttemptGroup(ctx)
+	ctx, span := a.startSpan(ctx, BasicSpanGranularity, methodName+" - "+string(telemetryPathValue))
+	span.SetAttributes(urlUriTemplateAttribute.String(decodedUriTemplate), attemptGroupIdAttribute.String(groupId))
+	return withUriTemplate(withOperationSpan(ctx, span), decodedUriTemplate), span
 }
 
 // Send executes the HTTP request specified by the given RequestInformation and returns the deserialized response model.
@@ -322,6 +501,10 @@ func (a *NetHttpRequestAdapter) Send(ctx context.Context, requestInfo *abs.Reque
 	if requestInfo == nil {
 		return nil, errors.New("requestInfo cannot be nil")
 	}
+	if err := a.startSend(); err != nil {
+		return nil, err
+	}
+	defer a.endSend()
 	ctx = a.prepareContext(ctx, requestInfo)
 	ctx, span := a.startTracingSpan(ctx, requestInfo, "Send")
 	defer span.End()
@@ -329,6 +512,7 @@ func (a *NetHttpRequestAdapter) Send(ctx context.Context, requestInfo *abs.Reque
 	if err != nil {
 		return nil, err
 	}
+	defer startTimingPhase(ctx, deserializationTimingPhase)()
 
 	responseHandler := getResponseHandler(ctx)
 	if responseHandler != nil {
@@ -341,7 +525,12 @@ func (a *NetHttpRequestAdapter) Send(ctx context.Context, requestInfo *abs.Reque
 		if result == nil {
 			return nil, nil
 		}
-		return result.(absser.Parsable), nil
+		typed, err := getResponseHandlerResult[absser.Parsable](result)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		return typed, nil
 	} else if response != nil {
 		defer a.purge(response)
 		err = a.throwIfFailedResponse(ctx, response, errorMappings, span)
@@ -358,7 +547,7 @@ func (a *NetHttpRequestAdapter) Send(ctx context.Context, requestInfo *abs.Reque
 		if parseNode == nil {
 			return nil, nil
 		}
-		_, deserializeSpan := otel.GetTracerProvider().Tracer(a.observabilityOptions.GetTracerInstrumentationName()).Start(ctx, "GetObjectValue")
+		_, deserializeSpan := a.startSpan(ctx, DetailedSpanGranularity, "GetObjectValue")
 		defer deserializeSpan.End()
 		result, err := parseNode.GetObjectValue(constructor)
 		a.setResponseType(result, span)
@@ -382,6 +571,10 @@ func (a *NetHttpRequestAdapter) SendEnum(ctx context.Context, requestInfo *abs.R
 	if requestInfo == nil {
 		return nil, errors.New("requestInfo cannot be nil")
 	}
+	if err := a.startSend(); err != nil {
+		return nil, err
+	}
+	defer a.endSend()
 	ctx = a.prepareContext(ctx, requestInfo)
 	ctx, span := a.startTracingSpan(ctx, requestInfo, "SendEnum")
 	defer span.End()
@@ -389,6 +582,7 @@ func (a *NetHttpRequestAdapter) SendEnum(ctx context.Context, requestInfo *abs.R
 	if err != nil {
 		return nil, err
 	}
+	defer startTimingPhase(ctx, deserializationTimingPhase)()
 
 	responseHandler := getResponseHandler(ctx)
 	if responseHandler != nil {
@@ -401,7 +595,7 @@ func (a *NetHttpRequestAdapter) SendEnum(ctx context.Context, requestInfo *abs.R
 		if result == nil {
 			return nil, nil
 		}
-		return result.(absser.Parsable), nil
+		return result, nil
 	} else if response != nil {
 		defer a.purge(response)
 		err = a.throwIfFailedResponse(ctx, response, errorMappings, span)
@@ -418,7 +612,7 @@ func (a *NetHttpRequestAdapter) SendEnum(ctx context.Context, requestInfo *abs.R
 		if parseNode == nil {
 			return nil, nil
 		}
-		_, deserializeSpan := otel.GetTracerProvider().Tracer(a.observabilityOptions.GetTracerInstrumentationName()).Start(ctx, "GetEnumValue")
+		_, deserializeSpan := a.startSpan(ctx, DetailedSpanGranularity, "GetEnumValue")
 		defer deserializeSpan.End()
 		result, err := parseNode.GetEnumValue(parser)
 		a.setResponseType(result, span)
@@ -436,6 +630,10 @@ func (a *NetHttpRequestAdapter) SendCollection(ctx context.Context, requestInfo
 	if requestInfo == nil {
 		return nil, errors.New("requestInfo cannot be nil")
 	}
+	if err := a.startSend(); err != nil {
+		return nil, err
+	}
+	defer a.endSend()
 	ctx = a.prepareContext(ctx, requestInfo)
 	ctx, span := a.startTracingSpan(ctx, requestInfo, "SendCollection")
 	defer span.End()
@@ -443,6 +641,7 @@ func (a *NetHttpRequestAdapter) SendCollection(ctx context.Context, requestInfo
 	if err != nil {
 		return nil, err
 	}
+	defer startTimingPhase(ctx, deserializationTimingPhase)()
 
 	responseHandler := getResponseHandler(ctx)
 	if responseHandler != nil {
@@ -455,7 +654,12 @@ func (a *NetHttpRequestAdapter) SendCollection(ctx context.Context, requestInfo
 		if result == nil {
 			return nil, nil
 		}
-		return result.([]absser.Parsable), nil
+		typed, err := getResponseHandlerResult[[]absser.Parsable](result)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		return typed, nil
 	} else if response != nil {
 		defer a.purge(response)
 		err = a.throwIfFailedResponse(ctx, response, errorMappings, span)
@@ -472,7 +676,7 @@ func (a *NetHttpRequestAdapter) SendCollection(ctx context.Context, requestInfo
 		if parseNode == nil {
 			return nil, nil
 		}
-		_, deserializeSpan := otel.GetTracerProvider().Tracer(a.observabilityOptions.GetTracerInstrumentationName()).Start(ctx, "GetCollectionOfObjectValues")
+		_, deserializeSpan := a.startSpan(ctx, DetailedSpanGranularity, "GetCollectionOfObjectValues")
 		defer deserializeSpan.End()
 		result, err := parseNode.GetCollectionOfObjectValues(constructor)
 		a.setResponseType(result, span)
@@ -490,6 +694,10 @@ func (a *NetHttpRequestAdapter) SendEnumCollection(ctx context.Context, requestI
 	if requestInfo == nil {
 		return nil, errors.New("requestInfo cannot be nil")
 	}
+	if err := a.startSend(); err != nil {
+		return nil, err
+	}
+	defer a.endSend()
 	ctx = a.prepareContext(ctx, requestInfo)
 	ctx, span := a.startTracingSpan(ctx, requestInfo, "SendEnumCollection")
 	defer span.End()
@@ -497,6 +705,7 @@ func (a *NetHttpRequestAdapter) SendEnumCollection(ctx context.Context, requestI
 	if err != nil {
 		return nil, err
 	}
+	defer startTimingPhase(ctx, deserializationTimingPhase)()
 
 	responseHandler := getResponseHandler(ctx)
 	if responseHandler != nil {
@@ -509,7 +718,12 @@ func (a *NetHttpRequestAdapter) SendEnumCollection(ctx context.Context, requestI
 		if result == nil {
 			return nil, nil
 		}
-		return result.([]any), nil
+		typed, err := getResponseHandlerResult[[]any](result)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		return typed, nil
 	} else if response != nil {
 		defer a.purge(response)
 		err = a.throwIfFailedResponse(ctx, response, errorMappings, span)
@@ -526,7 +740,7 @@ func (a *NetHttpRequestAdapter) SendEnumCollection(ctx context.Context, requestI
 		if parseNode == nil {
 			return nil, nil
 		}
-		_, deserializeSpan := otel.GetTracerProvider().Tracer(a.observabilityOptions.GetTracerInstrumentationName()).Start(ctx, "GetCollectionOfEnumValues")
+		_, deserializeSpan := a.startSpan(ctx, DetailedSpanGranularity, "GetCollectionOfEnumValues")
 		defer deserializeSpan.End()
 		result, err := parseNode.GetCollectionOfEnumValues(parser)
 		a.setResponseType(result, span)
@@ -552,6 +766,10 @@ func (a *NetHttpRequestAdapter) SendPrimitive(ctx context.Context, requestInfo *
 	if requestInfo == nil {
 		return nil, errors.New("requestInfo cannot be nil")
 	}
+	if err := a.startSend(); err != nil {
+		return nil, err
+	}
+	defer a.endSend()
 	ctx = a.prepareContext(ctx, requestInfo)
 	ctx, span := a.startTracingSpan(ctx, requestInfo, "SendPrimitive")
 	defer span.End()
@@ -559,6 +777,7 @@ func (a *NetHttpRequestAdapter) SendPrimitive(ctx context.Context, requestInfo *
 	if err != nil {
 		return nil, err
 	}
+	defer startTimingPhase(ctx, deserializationTimingPhase)()
 
 	responseHandler := getResponseHandler(ctx)
 	if responseHandler != nil {
@@ -571,7 +790,7 @@ func (a *NetHttpRequestAdapter) SendPrimitive(ctx context.Context, requestInfo *
 		if result == nil {
 			return nil, nil
 		}
-		return result.(absser.Parsable), nil
+		return result, nil
 	} else if response != nil {
 		defer a.purge(response)
 		err = a.throwIfFailedResponse(ctx, response, errorMappings, span)
@@ -598,7 +817,7 @@ func (a *NetHttpRequestAdapter) SendPrimitive(ctx context.Context, requestInfo *
 		if parseNode == nil {
 			return nil, nil
 		}
-		_, deserializeSpan := otel.GetTracerProvider().Tracer(a.observabilityOptions.GetTracerInstrumentationName()).Start(ctx, "Get"+typeName+"Value")
+		_, deserializeSpan := a.startSpan(ctx, DetailedSpanGranularity, "Get"+typeName+"Value")
 		defer deserializeSpan.End()
 		var result any
 		switch typeName {
@@ -636,6 +855,10 @@ func (a *NetHttpRequestAdapter) SendPrimitiveCollection(ctx context.Context, req
 	if requestInfo == nil {
 		return nil, errors.New("requestInfo cannot be nil")
 	}
+	if err := a.startSend(); err != nil {
+		return nil, err
+	}
+	defer a.endSend()
 	ctx = a.prepareContext(ctx, requestInfo)
 	ctx, span := a.startTracingSpan(ctx, requestInfo, "SendPrimitiveCollection")
 	defer span.End()
@@ -643,6 +866,7 @@ func (a *NetHttpRequestAdapter) SendPrimitiveCollection(ctx context.Context, req
 	if err != nil {
 		return nil, err
 	}
+	defer startTimingPhase(ctx, deserializationTimingPhase)()
 
 	responseHandler := getResponseHandler(ctx)
 	if responseHandler != nil {
@@ -655,7 +879,12 @@ func (a *NetHttpRequestAdapter) SendPrimitiveCollection(ctx context.Context, req
 		if result == nil {
 			return nil, nil
 		}
-		return result.([]any), nil
+		typed, err := getResponseHandlerResult[[]any](result)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		return typed, nil
 	} else if response != nil {
 		defer a.purge(response)
 		err = a.throwIfFailedResponse(ctx, response, errorMappings, span)
@@ -672,7 +901,7 @@ func (a *NetHttpRequestAdapter) SendPrimitiveCollection(ctx context.Context, req
 		if parseNode == nil {
 			return nil, nil
 		}
-		_, deserializeSpan := otel.GetTracerProvider().Tracer(a.observabilityOptions.GetTracerInstrumentationName()).Start(ctx, "GetCollectionOfPrimitiveValues")
+		_, deserializeSpan := a.startSpan(ctx, DetailedSpanGranularity, "GetCollectionOfPrimitiveValues")
 		defer deserializeSpan.End()
 		result, err := parseNode.GetCollectionOfPrimitiveValues(typeName)
 		a.setResponseType(result, span)
@@ -690,6 +919,10 @@ func (a *NetHttpRequestAdapter) SendNoContent(ctx context.Context, requestInfo *
 	if requestInfo == nil {
 		return errors.New("requestInfo cannot be nil")
 	}
+	if err := a.startSend(); err != nil {
+		return err
+	}
+	defer a.endSend()
 	ctx = a.prepareContext(ctx, requestInfo)
 	ctx, span := a.startTracingSpan(ctx, requestInfo, "SendNoContent")
 	defer span.End()
@@ -697,6 +930,7 @@ func (a *NetHttpRequestAdapter) SendNoContent(ctx context.Context, requestInfo *
 	if err != nil {
 		return err
 	}
+	defer startTimingPhase(ctx, deserializationTimingPhase)()
 
 	responseHandler := getResponseHandler(ctx)
 	if responseHandler != nil {
@@ -718,8 +952,32 @@ func (a *NetHttpRequestAdapter) SendNoContent(ctx context.Context, requestInfo *
 	}
 }
 
+// SendHeaders executes the HTTP request specified by the given RequestInformation, typically a HEAD request, and returns the status code and the headers of the response.
+// The body of the response is discarded and no error is returned for failed status codes, which makes it suitable for existence checks and metadata probes.
+func (a *NetHttpRequestAdapter) SendHeaders(ctx context.Context, requestInfo *abs.RequestInformation) (int, *abs.ResponseHeaders, error) {
+	if requestInfo == nil {
+		return 0, nil, errors.New("requestInfo cannot be nil")
+	}
+	if err := a.startSend(); err != nil {
+		return 0, nil, err
+	}
+	defer a.endSend()
+	ctx = a.prepareContext(ctx, requestInfo)
+	ctx, span := a.startTracingSpan(ctx, requestInfo, "SendHeaders")
+	defer span.End()
+	response, err := a.getHttpResponseMessage(ctx, requestInfo, "", span)
+	if err != nil {
+		return 0, nil, err
+	}
+	if response == nil {
+		return 0, nil, errors.New("response is nil")
+	}
+	defer a.purge(response)
+	return response.StatusCode, getInspectedResponseHeaders(response.Header), nil
+}
+
 func (a *NetHttpRequestAdapter) getRootParseNode(ctx context.Context, response *nethttp.Response, spanForAttributes trace.Span) (absser.ParseNode, context.Context, error) {
-	ctx, span := otel.GetTracerProvider().Tracer(a.observabilityOptions.GetTracerInstrumentationName()).Start(ctx, "getRootParseNode")
+	ctx, span := a.startSpan(ctx, DetailedSpanGranularity, "getRootParseNode")
 	defer span.End()
 
 	if response.ContentLength == 0 {
@@ -735,7 +993,7 @@ func (a *NetHttpRequestAdapter) getRootParseNode(ctx context.Context, response *
 	if contentType == "" {
 		return nil, ctx, nil
 	}
-	rootNode, err := a.parseNodeFactory.GetRootParseNode(contentType, body)
+	rootNode, err := getRootParseNodeWithParameters(a.getParseNodeFactory(), contentType, a.getResponseContentTypeParameters(response), body)
 	if err != nil {
 		spanForAttributes.RecordError(err)
 	}
@@ -759,8 +1017,25 @@ const ErrorMappingFoundAttributeName = "com.microsoft.kiota.error.mapping_found"
 // ErrorBodyFoundAttributeName is the attribute name used to indicate whether the error response contained a body
 const ErrorBodyFoundAttributeName = "com.microsoft.kiota.error.body_found"
 
+// getErrorFactory returns the factory of the error mapped to the status code, looking up the exact code, then the 4XX or 5XX classes and finally XXX
+func getErrorFactory(errorMappings abs.ErrorMappings, statusCode int) absser.ParsableFactory {
+	if len(errorMappings) == 0 {
+		return nil
+	}
+	if errorCtor := errorMappings[strconv.Itoa(statusCode)]; errorCtor != nil {
+		return errorCtor
+	} else if statusCode >= 400 && statusCode < 500 && errorMappings["4XX"] != nil {
+		return errorMappings["4XX"]
+	} else if statusCode >= 500 && statusCode < 600 && errorMappings["5XX"] != nil {
+		return errorMappings["5XX"]
+	} else if statusCode >= 400 && statusCode < 600 {
+		return errorMappings["XXX"]
+	}
+	return nil
+}
+
 func (a *NetHttpRequestAdapter) throwIfFailedResponse(ctx context.Context, response *nethttp.Response, errorMappings abs.ErrorMappings, spanForAttributes trace.Span) error {
-	ctx, span := otel.GetTracerProvider().Tracer(a.observabilityOptions.GetTracerInstrumentationName()).Start(ctx, "throwIfFailedResponse")
+	ctx, span := a.startSpan(ctx, DetailedSpanGranularity, "throwIfFailedResponse")
 	defer span.End()
 	if response.StatusCode < 400 {
 		return nil
@@ -774,18 +1049,7 @@ func (a *NetHttpRequestAdapter) throwIfFailedResponse(ctx context.Context, respo
 			responseHeaders.Add(key, values[i])
 		}
 	}
-	var errorCtor absser.ParsableFactory = nil
-	if len(errorMappings) != 0 {
-		if errorMappings[statusAsString] != nil {
-			errorCtor = errorMappings[statusAsString]
-		} else if response.StatusCode >= 400 && response.StatusCode < 500 && errorMappings["4XX"] != nil {
-			errorCtor = errorMappings["4XX"]
-		} else if response.StatusCode >= 500 && response.StatusCode < 600 && errorMappings["5XX"] != nil {
-			errorCtor = errorMappings["5XX"]
-		} else if errorMappings["XXX"] != nil && response.StatusCode >= 400 && response.StatusCode < 600 {
-			errorCtor = errorMappings["XXX"]
-		}
-	}
+	errorCtor := getErrorFactory(errorMappings, response.StatusCode)
 
 	if errorCtor == nil {
 		spanForAttributes.SetAttributes(attribute.Bool(ErrorMappingFoundAttributeName, false))
@@ -816,7 +1080,7 @@ func (a *NetHttpRequestAdapter) throwIfFailedResponse(ctx context.Context, respo
 	}
 	spanForAttributes.SetAttributes(attribute.Bool(ErrorBodyFoundAttributeName, true))
 
-	_, deserializeSpan := otel.GetTracerProvider().Tracer(a.observabilityOptions.GetTracerInstrumentationName()).Start(ctx, "GetObjectValue")
+	_, deserializeSpan := a.startSpan(ctx, DetailedSpanGranularity, "GetObjectValue")
 	defer deserializeSpan.End()
 	errValue, err := rootNode.GetObjectValue(errorCtor)
 	if err != nil {
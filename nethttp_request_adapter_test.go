@@ -3,15 +3,17 @@ package nethttplibrary
 import (
 	"context"
 	"github.com/microsoft/kiota-abstractions-go/serialization"
+	"io"
 	nethttp "net/http"
 	httptest "net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
 
 	abs "github.com/microsoft/kiota-abstractions-go"
 	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
 	absstore "github.com/microsoft/kiota-abstractions-go/store"
-	"github.com/microsoft/kiota-http-go/internal"
+	"github.com/microsoft/kiota-http-go/testingutil"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -83,7 +85,7 @@ func TestGenericError(t *testing.T) {
 	}))
 	defer func() { testServer.Close() }()
 	authProvider := &absauth.AnonymousAuthenticationProvider{}
-	adapter, err := NewNetHttpRequestAdapterWithParseNodeFactory(authProvider, &internal.MockParseNodeFactory{})
+	adapter, err := NewNetHttpRequestAdapterWithParseNodeFactory(authProvider, &testingutil.MockParseNodeFactory{})
 	assert.Nil(t, err)
 	assert.NotNil(t, adapter)
 
@@ -244,7 +246,7 @@ func TestSendReturnNilOnNoContent(t *testing.T) {
 		request.SetUri(*uri)
 		request.Method = abs.GET
 
-		res, err2 := adapter.Send(context.TODO(), request, internal.MockEntityFactory, nil)
+		res, err2 := adapter.Send(context.TODO(), request, testingutil.MockEntityFactory, nil)
 		assert.Nil(t, err2)
 		assert.Nil(t, res)
 	}
@@ -273,7 +275,7 @@ func TestSendReturnErrOnNoContent(t *testing.T) {
 		request.SetUri(*uri)
 		request.Method = abs.GET
 
-		res, err2 := adapter.Send(context.TODO(), request, internal.MockEntityFactory, nil)
+		res, err2 := adapter.Send(context.TODO(), request, testingutil.MockEntityFactory, nil)
 		assert.Error(t, err2)
 		assert.Nil(t, res)
 	}
@@ -289,7 +291,7 @@ func TestSendReturnsObjectOnContent(t *testing.T) {
 		}))
 		defer func() { testServer.Close() }()
 		authProvider := &absauth.AnonymousAuthenticationProvider{}
-		adapter, err := NewNetHttpRequestAdapterWithParseNodeFactory(authProvider, &internal.MockParseNodeFactory{})
+		adapter, err := NewNetHttpRequestAdapterWithParseNodeFactory(authProvider, &testingutil.MockParseNodeFactory{})
 		assert.Nil(t, err)
 		assert.NotNil(t, adapter)
 
@@ -300,7 +302,7 @@ func TestSendReturnsObjectOnContent(t *testing.T) {
 		request.SetUri(*uri)
 		request.Method = abs.GET
 
-		res, err2 := adapter.Send(context.TODO(), request, internal.MockEntityFactory, nil)
+		res, err2 := adapter.Send(context.TODO(), request, testingutil.MockEntityFactory, nil)
 		assert.Nil(t, err2)
 		assert.Nil(t, res)
 	}
@@ -352,3 +354,129 @@ func TestNetHttpRequestAdapter_EnableBackingStore(t *testing.T) {
 	adapter.EnableBackingStore(store)
 	assert.Equal(t, absstore.BackingStoreFactoryInstance(), store())
 }
+
+func TestNetHttpRequestAdapter_EnableBackingStoreWrapsTheFactories(t *testing.T) {
+	authProvider := &absauth.AnonymousAuthenticationProvider{}
+	parseNodeRegistry := serialization.NewParseNodeFactoryRegistry()
+	adapter, err := NewNetHttpRequestAdapterWithParseNodeFactoryAndSerializationWriterFactory(authProvider, parseNodeRegistry, serialization.NewSerializationWriterFactoryRegistry())
+	assert.NoError(t, err)
+
+	adapter.EnableBackingStore(nil)
+	parseNodeRegistry.Lock()
+	parseNodeRegistry.ContentTypeAssociatedFactories["application/json"] = &testingutil.MockParseNodeFactory{}
+	parseNodeRegistry.Unlock()
+	assert.Equal(t, parseNodeRegistry, adapter.getParseNodeFactory())
+	_, ok := parseNodeRegistry.ContentTypeAssociatedFactories["application/json"].(*absstore.BackingStoreParseNodeFactory)
+	assert.True(t, ok)
+
+	adapter, err = NewNetHttpRequestAdapterWithParseNodeFactory(authProvider, &testingutil.MockParseNodeFactory{})
+	assert.NoError(t, err)
+	adapter.EnableBackingStore(nil)
+	wrapped := adapter.getParseNodeFactory()
+	_, ok = wrapped.(*absstore.BackingStoreParseNodeFactory)
+	assert.True(t, ok)
+	adapter.EnableBackingStore(nil)
+	assert.Same(t, wrapped, adapter.getParseNodeFactory())
+}
+
+func TestNetHttpRequestAdapter_EnableBackingStoreWhileSending(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(204)
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapterWithParseNodeFactoryAndSerializationWriterFactory(&absauth.AnonymousAuthenticationProvider{}, serialization.NewParseNodeFactoryRegistry(), serialization.NewSerializationWriterFactoryRegistry())
+	assert.NoError(t, err)
+	adapter.SetBaseUrl(testServer.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i == 0 {
+				adapter.EnableBackingStore(nil)
+			}
+			request := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/items", map[string]string{})
+			assert.NoError(t, adapter.SendNoContent(context.Background(), request, nil))
+			assert.NotNil(t, adapter.GetSerializationWriterFactory())
+			assert.NotNil(t, adapter.getParseNodeFactory())
+			assert.NotNil(t, adapter.CloneWithBaseUrl(testServer.URL))
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestItSetsGetBodyOnRequestsWithContent(t *testing.T) {
+	adapter, err := NewNetHttpRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.NoError(t, err)
+	requestInfo := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.POST, "{+baseurl}/items", map[string]string{"baseurl": "https://localhost"})
+	requestInfo.SetStreamContentAndContentType([]byte("payload"), "text/plain")
+
+	result, err := adapter.ConvertToNativeRequest(context.Background(), requestInfo)
+	assert.NoError(t, err)
+	request := result.(*nethttp.Request)
+	assert.Equal(t, int64(7), request.ContentLength)
+	body, _ := io.ReadAll(request.Body)
+	assert.Equal(t, "payload", string(body))
+	if assert.NotNil(t, request.GetBody) {
+		replayed, err := request.GetBody()
+		assert.NoError(t, err)
+		body, _ = io.ReadAll(replayed)
+		assert.Equal(t, "payload", string(body))
+	}
+}
+
+func TestSendHeadersReturnsTheStatusCodeAndHeaders(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		if req.URL.Path == "/missing" {
+			res.WriteHeader(404)
+			return
+		}
+		res.Header().Set("ETag", "\"1\"")
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.NoError(t, err)
+	adapter.SetBaseUrl(testServer.URL)
+
+	request := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.HEAD, "{+baseurl}/exists", map[string]string{})
+	statusCode, headers, err := adapter.SendHeaders(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, statusCode)
+	assert.Equal(t, []string{"\"1\""}, headers.Get("ETag"))
+
+	request = abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.HEAD, "{+baseurl}/missing", map[string]string{})
+	statusCode, _, err = adapter.SendHeaders(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, statusCode)
+}
+
+func TestItClonesTheAdapterWithAnotherBaseUrl(t *testing.T) {
+	var receivedHosts []string
+	handler := nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		receivedHosts = append(receivedHosts, req.Host)
+		res.WriteHeader(204)
+	})
+	primary := httptest.NewServer(handler)
+	defer primary.Close()
+	secondary := httptest.NewServer(handler)
+	defer secondary.Close()
+	adapter, err := NewNetHttpRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.Nil(t, err)
+	adapter.SetBaseUrl(primary.URL)
+	adapter.SetObservabilityName("contoso-sdk")
+
+	clone := adapter.CloneWithBaseUrl(secondary.URL)
+	assert.Equal(t, secondary.URL, clone.GetBaseUrl())
+	assert.Equal(t, primary.URL, adapter.GetBaseUrl())
+	assert.Same(t, adapter.httpClient, clone.httpClient)
+	assert.Equal(t, adapter.GetSerializationWriterFactory(), clone.GetSerializationWriterFactory())
+	assert.Equal(t, "contoso-sdk", clone.GetObservabilityName())
+
+	for _, a := range []*NetHttpRequestAdapter{adapter, clone} {
+		request := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/items", map[string]string{})
+		assert.Nil(t, a.SendNoContent(context.Background(), request, nil))
+	}
+	assert.Equal(t, []string{primary.Listener.Addr().String(), secondary.Listener.Addr().String()}, receivedHosts)
+}
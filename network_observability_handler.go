@@ -0,0 +1,112 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	"time"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NetworkObservabilityHandler observes the requests sent over the network: it starts the request_transport span and its connection spans,
+// measures the network timing, emits the request log events, captures the hops, protocols and rate limits requested by the inspection options
+// and records the payload and throttling metrics. It is the last handler of the default chain so every attempt of the retry and redirect handlers is observed.
+type NetworkObservabilityHandler struct {
+	options NetworkObservabilityHandlerOptions
+}
+
+// NetworkObservabilityHandlerOptions to use when observing the requests sent over the network.
+type NetworkObservabilityHandlerOptions struct {
+	// Enabled defines whether the requests sent over the network should be observed
+	Enabled bool
+}
+
+var networkObservabilityKeyValue = abs.RequestOptionKey{
+	Key: "NetworkObservabilityHandler",
+}
+
+type networkObservabilityHandlerOptionsInt interface {
+	abs.RequestOption
+	GetEnabled() bool
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *NetworkObservabilityHandlerOptions) GetKey() abs.RequestOptionKey {
+	return networkObservabilityKeyValue
+}
+
+// GetEnabled returns whether the requests sent over the network should be observed
+func (options *NetworkObservabilityHandlerOptions) GetEnabled() bool {
+	return options.Enabled
+}
+
+// NewNetworkObservabilityHandler creates a new NetworkObservabilityHandler with the default options
+func NewNetworkObservabilityHandler() *NetworkObservabilityHandler {
+	return NewNetworkObservabilityHandlerWithOptions(NetworkObservabilityHandlerOptions{Enabled: true})
+}
+
+// NewNetworkObservabilityHandlerWithOptions creates a new NetworkObservabilityHandler with the given options
+func NewNetworkObservabilityHandlerWithOptions(options NetworkObservabilityHandlerOptions) *NetworkObservabilityHandler {
+	return &NetworkObservabilityHandler{options: options}
+}
+
+// networkObservabilityContextKey marks the requests observed by a NetworkObservabilityHandler, the last hop of the pipeline doesn't start their request_transport span
+type networkObservabilityContextKey struct{}
+
+// startRequestTransportSpan starts the span of a request sent over the network
+func startRequestTransportSpan(ctx context.Context, obsOptions ObservabilityOptionsInt) (context.Context, trace.Span) {
+	ctx, span := getTracerProvider(ctx).Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "request_transport")
+	return ctx, withLegacyAttributes(span, obsOptions)
+}
+
+// Intercept implements the interface and observes the request sent over the network.
+func (middleware NetworkObservabilityHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(networkObservabilityKeyValue).(networkObservabilityHandlerOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+	if !reqOption.GetEnabled() {
+		return pipeline.Next(req, middlewareIndex)
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	ctx := context.WithValue(req.Context(), networkObservabilityContextKey{}, true)
+	var span trace.Span
+	var observabilityName string
+	var endConnectionTrace func(err error)
+	if obsOptions != nil {
+		observabilityName = obsOptions.GetTracerInstrumentationName()
+		ctx, span = startRequestTransportSpan(ctx, obsOptions)
+		defer span.End()
+		if shouldIncludeConnectionSpans(obsOptions) {
+			ctx, endConnectionTrace = withConnectionTrace(ctx, getTracerProvider(ctx).Tracer(observabilityName))
+		}
+	}
+	req = req.WithContext(ctx)
+	stopNetworkTiming := startTimingPhase(ctx, networkTimingPhase)
+	emitHttpEvent(req, DebugLogSeverity, RequestStartLogEventName, "Sending request")
+	start := time.Now()
+	resp, err := pipeline.Next(req, middlewareIndex)
+	stopNetworkTiming()
+	if err != nil {
+		emitHttpEvent(req, ErrorLogSeverity, RequestErrorLogEventName, "Request failed",
+			requestDurationAttribute.Float64(float64(time.Since(start))/float64(time.Millisecond)),
+			errorTypeAttribute.String(getErrorType(err)),
+			exceptionMessageAttribute.String(getRedactor(req).RedactError(err)))
+	} else {
+		inspectHop(req, resp)
+		inspectProtocol(ctx, span, resp)
+		inspectRateLimit(ctx, resp)
+		emitHttpEvent(req, InfoLogSeverity, RequestFinishLogEventName, "Response received",
+			requestDurationAttribute.Float64(float64(time.Since(start))/float64(time.Millisecond)),
+			httpResponseStatusCodeAttribute.Int(resp.StatusCode))
+	}
+	if endConnectionTrace != nil {
+		endConnectionTrace(err)
+	}
+	if obsOptions != nil && err == nil {
+		recordPayloadMetrics(ctx, observabilityName, req, resp)
+		recordThrottling(ctx, observabilityName, span, req, resp)
+	}
+	return resp, err
+}
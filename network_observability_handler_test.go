@@ -0,0 +1,40 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItObservesTheRequestsUnlessTheHandlerIsDisabledOrRemoved(t *testing.T) {
+	provider := useTestTracerProvider(t)
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	loggerProvider := &testLoggerProvider{}
+	middlewares, err := GetDefaultMiddlewaresWithOptions(&ObservabilityOptions{LoggerProvider: loggerProvider})
+	assert.Nil(t, err)
+	transport := NewCustomTransport(middlewares...)
+	client := getDefaultClientWithoutMiddleware()
+	client.Transport = transport
+
+	_, err = client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{RequestStartLogEventName, RequestFinishLogEventName}, loggerProvider.getEventNames())
+
+	req, _ := nethttp.NewRequestWithContext(context.WithValue(context.Background(), networkObservabilityKeyValue, &NetworkObservabilityHandlerOptions{}), nethttp.MethodGet, testServer.URL, nil)
+	_, err = client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(loggerProvider.getEventNames()))
+
+	assert.True(t, transport.Remove("NetworkObservabilityHandler"))
+	_, err = client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(loggerProvider.getEventNames()))
+	// the last hop of the pipeline still traces the requests which weren't observed
+	assert.Equal(t, 3, len(provider.getSpans("request_transport")))
+}
@@ -1,20 +1,59 @@
 package nethttplibrary
 
 import (
+	"context"
 	nethttp "net/http"
 
 	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ObservabilityOptions holds the tracing, metrics and logging configuration for the request adapter
 type ObservabilityOptions struct {
 	// Whether to include attributes which could contains EUII information like URLs
 	IncludeEUIIAttributes bool
+	// Whether to create child spans for the DNS lookup, TCP connect, TLS handshake and time to first byte of the requests
+	IncludeConnectionSpans bool
+	// The spans created by the request adapter, defaults to DetailedSpanGranularity
+	SpanGranularity SpanGranularity
+	// The provider of the loggers emitting structured log records for the HTTP events (request start and finish, retries, redirects and errors)
+	LoggerProvider LoggerProvider
+	// The redactor removing the sensitive data from the diagnostics, defaults to NewRedactor()
+	Redactor *Redactor
+	// Whether to also emit the attribute names used before the HTTP semantic conventions were stable (e.g. http.status_code, http.url)
+	EmitLegacyAttributes bool
+	// The callback returning extra attributes to set on the span of the request adapter operation (e.g. tenant id, operation name),
+	// invoked with the request before it's sent, then with the request and the response once it's received
+	SpanAttributesCallback func(req *nethttp.Request, resp *nethttp.Response) []attribute.KeyValue
+	// The name of the instrumentation scope of the tracers and meters, defaults to github.com/microsoft/kiota-http-go
+	TracerInstrumentationName string
 }
 
+const defaultTracerInstrumentationName = "github.com/microsoft/kiota-http-go"
+
+// SpanGranularity defines which spans are created by the request adapter
+type SpanGranularity int
+
+const (
+	// DetailedSpanGranularity creates the operation span and the internal spans of the request adapter (e.g. getHttpResponseMessage, getRootParseNode, GetObjectValue)
+	DetailedSpanGranularity SpanGranularity = iota
+	// BasicSpanGranularity only creates the operation span (e.g. Send - /users/{user-id})
+	BasicSpanGranularity
+	// OffSpanGranularity doesn't create any span in the request adapter, the middlewares still trace the requests
+	OffSpanGranularity
+)
+
 // GetTracerInstrumentationName returns the observability name to use for the tracer
 func (o *ObservabilityOptions) GetTracerInstrumentationName() string {
-	return "github.com/microsoft/kiota-http-go"
+	if o.TracerInstrumentationName != "" {
+		return o.TracerInstrumentationName
+	}
+	return defaultTracerInstrumentationName
+}
+
+// SetTracerInstrumentationName sets the observability name to use for the tracer, the default name is used when empty
+func (o *ObservabilityOptions) SetTracerInstrumentationName(value string) {
+	o.TracerInstrumentationName = value
 }
 
 // GetIncludeEUIIAttributes returns whether to include attributes which could contains EUII information
@@ -27,6 +66,66 @@ func (o *ObservabilityOptions) SetIncludeEUIIAttributes(value bool) {
 	o.IncludeEUIIAttributes = value
 }
 
+// GetIncludeConnectionSpans returns whether to create child spans for the connection phases of the requests
+func (o *ObservabilityOptions) GetIncludeConnectionSpans() bool {
+	return o.IncludeConnectionSpans
+}
+
+// SetIncludeConnectionSpans set whether to create child spans for the connection phases of the requests
+func (o *ObservabilityOptions) SetIncludeConnectionSpans(value bool) {
+	o.IncludeConnectionSpans = value
+}
+
+// GetSpanGranularity returns the spans created by the request adapter
+func (o *ObservabilityOptions) GetSpanGranularity() SpanGranularity {
+	return o.SpanGranularity
+}
+
+// SetSpanGranularity sets the spans created by the request adapter
+func (o *ObservabilityOptions) SetSpanGranularity(value SpanGranularity) {
+	o.SpanGranularity = value
+}
+
+// GetLoggerProvider returns the provider of the loggers emitting the HTTP events
+func (o *ObservabilityOptions) GetLoggerProvider() LoggerProvider {
+	return o.LoggerProvider
+}
+
+// SetLoggerProvider sets the provider of the loggers emitting the HTTP events
+func (o *ObservabilityOptions) SetLoggerProvider(value LoggerProvider) {
+	o.LoggerProvider = value
+}
+
+// GetRedactor returns the redactor removing the sensitive data from the diagnostics
+func (o *ObservabilityOptions) GetRedactor() *Redactor {
+	return o.Redactor
+}
+
+// SetRedactor sets the redactor removing the sensitive data from the diagnostics
+func (o *ObservabilityOptions) SetRedactor(value *Redactor) {
+	o.Redactor = value
+}
+
+// GetEmitLegacyAttributes returns whether to also emit the legacy attribute names
+func (o *ObservabilityOptions) GetEmitLegacyAttributes() bool {
+	return o.EmitLegacyAttributes
+}
+
+// SetEmitLegacyAttributes sets whether to also emit the legacy attribute names
+func (o *ObservabilityOptions) SetEmitLegacyAttributes(value bool) {
+	o.EmitLegacyAttributes = value
+}
+
+// GetSpanAttributesCallback returns the callback returning extra attributes to set on the span of the request adapter operation
+func (o *ObservabilityOptions) GetSpanAttributesCallback() func(req *nethttp.Request, resp *nethttp.Response) []attribute.KeyValue {
+	return o.SpanAttributesCallback
+}
+
+// SetSpanAttributesCallback sets the callback returning extra attributes to set on the span of the request adapter operation
+func (o *ObservabilityOptions) SetSpanAttributesCallback(value func(req *nethttp.Request, resp *nethttp.Response) []attribute.KeyValue) {
+	o.SpanAttributesCallback = value
+}
+
 // ObservabilityOptionsInt defines the options contract for handlers
 type ObservabilityOptionsInt interface {
 	abs.RequestOption
@@ -39,10 +138,77 @@ func (*ObservabilityOptions) GetKey() abs.RequestOptionKey {
 	return observabilityOptionsKeyValue
 }
 
+// connectionSpansOptionsInt is implemented by the observability options which can enable the connection spans
+type connectionSpansOptionsInt interface {
+	GetIncludeConnectionSpans() bool
+}
+
+// shouldIncludeConnectionSpans returns whether the given observability options enable the connection spans
+func shouldIncludeConnectionSpans(options ObservabilityOptionsInt) bool {
+	connectionOptions, ok := options.(connectionSpansOptionsInt)
+	return ok && connectionOptions.GetIncludeConnectionSpans()
+}
+
 var observabilityOptionsKeyValue = abs.RequestOptionKey{
 	Key: "ObservabilityOptions",
 }
 
+// ObservabilityNameOptions is a request option overriding the observability name the spans of the request are attributed to,
+// so hosts running several SDKs over the same request adapter can attribute the spans to the right instrumentation scope
+type ObservabilityNameOptions struct {
+	// ObservabilityName is the name of the instrumentation scope of the spans of the request
+	ObservabilityName string
+}
+
+var observabilityNameKeyValue = abs.RequestOptionKey{
+	Key: "ObservabilityNameOptions",
+}
+
+// NewObservabilityNameOptions creates a new ObservabilityNameOptions with the given observability name
+func NewObservabilityNameOptions(observabilityName string) *ObservabilityNameOptions {
+	return &ObservabilityNameOptions{ObservabilityName: observabilityName}
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (o *ObservabilityNameOptions) GetKey() abs.RequestOptionKey {
+	return observabilityNameKeyValue
+}
+
+// GetObservabilityName returns the name of the instrumentation scope of the spans of the request
+func (o *ObservabilityNameOptions) GetObservabilityName() string {
+	return o.ObservabilityName
+}
+
+// withObservabilityName returns a context carrying a copy of the observability options with the observability name of the request, if it overrides it
+func withObservabilityName(ctx context.Context) context.Context {
+	nameOptions, ok := ctx.Value(observabilityNameKeyValue).(*ObservabilityNameOptions)
+	if !ok || nameOptions == nil || nameOptions.GetObservabilityName() == "" {
+		return ctx
+	}
+	options, ok := ctx.Value(observabilityOptionsKeyValue).(*ObservabilityOptions)
+	if !ok || options == nil {
+		return ctx
+	}
+	overridden := *options
+	overridden.TracerInstrumentationName = nameOptions.GetObservabilityName()
+	return context.WithValue(ctx, observabilityOptionsKeyValue, &overridden)
+}
+
+// observabilityOptionsHandler adds the observability options to the requests which don't carry any,
+// so the middlewares trace the requests sent with the client without going through the request adapter
+type observabilityOptionsHandler struct {
+	options ObservabilityOptions
+}
+
+// Intercept implements the interface and adds the observability options to the request context
+func (middleware *observabilityOptionsHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	if GetObservabilityOptionsFromRequest(req) == nil {
+		options := middleware.options
+		req = req.WithContext(context.WithValue(req.Context(), observabilityOptionsKeyValue, &options))
+	}
+	return pipeline.Next(req, middlewareIndex)
+}
+
 // GetObservabilityOptionsFromRequest returns the observability options from the request context
 func GetObservabilityOptionsFromRequest(req *nethttp.Request) ObservabilityOptionsInt {
 	if options, ok := req.Context().Value(observabilityOptionsKeyValue).(ObservabilityOptionsInt); ok {
@@ -0,0 +1,396 @@
+package nethttplibrary
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	nethttp "net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueuedRequest is a request which could not be sent and is stored to be replayed later
+type QueuedRequest struct {
+	// Id is the unique id of the queued request
+	Id string
+	// IdempotencyKey is the key sent with the request so the service can detect duplicates
+	IdempotencyKey string
+	// Method is the HTTP method of the request
+	Method string
+	// Url is the full URL of the request
+	Url string
+	// Header are the headers of the request, without the credentials
+	Header nethttp.Header
+	// Body is the body of the request
+	Body []byte
+	// QueuedAt is the time the request was queued
+	QueuedAt time.Time
+}
+
+// OfflineRequestStore is a persistent store for the requests queued by the OfflineQueueHandler
+type OfflineRequestStore interface {
+	// Enqueue stores the request, requests with an idempotency key already stored must be ignored
+	Enqueue(request QueuedRequest) error
+	// List returns the stored requests in the order they were queued
+	List() ([]QueuedRequest, error)
+	// Remove removes the request with the given id
+	Remove(id string) error
+}
+
+// InMemoryOfflineRequestStore is an OfflineRequestStore keeping the requests in memory
+type InMemoryOfflineRequestStore struct {
+	mutex    sync.Mutex
+	requests []QueuedRequest
+}
+
+// NewInMemoryOfflineRequestStore creates a new InMemoryOfflineRequestStore
+func NewInMemoryOfflineRequestStore() *InMemoryOfflineRequestStore {
+	return &InMemoryOfflineRequestStore{}
+}
+
+// Enqueue stores the request unless a request with the same idempotency key is already stored
+func (s *InMemoryOfflineRequestStore) Enqueue(request QueuedRequest) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, existing := range s.requests {
+		if existing.IdempotencyKey == request.IdempotencyKey {
+			return nil
+		}
+	}
+	s.requests = append(s.requests, request)
+	return nil
+}
+
+// List returns the stored requests in the order they were queued
+func (s *InMemoryOfflineRequestStore) List() ([]QueuedRequest, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	result := make([]QueuedRequest, len(s.requests))
+	copy(result, s.requests)
+	return result, nil
+}
+
+// Remove removes the request with the given id
+func (s *InMemoryOfflineRequestStore) Remove(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, existing := range s.requests {
+		if existing.Id == id {
+			s.requests = append(s.requests[:i], s.requests[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// RequestQueuedError is returned when a request could not be sent and was queued to be replayed later
+type RequestQueuedError struct {
+	// Id is the id of the queued request
+	Id string
+	// Err is the error which occurred while sending the request
+	Err error
+}
+
+// Error returns the error message
+func (e *RequestQueuedError) Error() string {
+	return "the request could not be sent and was queued with id " + e.Id + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *RequestQueuedError) Unwrap() error {
+	return e.Err
+}
+
+// OfflineQueueHandlerOptions to use when queuing the requests which could not be sent.
+type OfflineQueueHandlerOptions struct {
+	// Enabled defines whether the requests should be queued
+	Enabled bool
+	// Store is the store the requests are queued to
+	Store OfflineRequestStore
+	// IdempotencyKeyHeaderName is the name of the header the idempotency key is written to
+	IdempotencyKeyHeaderName string
+	// ShouldQueue is the callback deciding whether a request should be queued given the error, network errors are queued when nil
+	ShouldQueue func(req *nethttp.Request, err error) bool
+	// ReplayOnReconnect defines whether queued requests are replayed in the background once a request succeeds again
+	ReplayOnReconnect bool
+	// AuthenticateReplay adds the credentials to the replayed requests, as the credential headers aren't stored with the queued requests
+	AuthenticateReplay func(req *nethttp.Request) error
+}
+
+const defaultIdempotencyKeyHeaderName = "Idempotency-Key"
+
+var offlineQueueKeyValue = abs.RequestOptionKey{
+	Key: "OfflineQueueHandler",
+}
+
+type offlineQueueHandlerOptionsInt interface {
+	abs.RequestOption
+	GetEnabled() bool
+	GetStore() OfflineRequestStore
+	GetIdempotencyKeyHeaderName() string
+	GetShouldQueue() func(req *nethttp.Request, err error) bool
+	GetReplayOnReconnect() bool
+	GetAuthenticateReplay() func(req *nethttp.Request) error
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *OfflineQueueHandlerOptions) GetKey() abs.RequestOptionKey {
+	return offlineQueueKeyValue
+}
+
+// GetEnabled returns whether the requests should be queued
+func (options *OfflineQueueHandlerOptions) GetEnabled() bool {
+	return options.Enabled
+}
+
+// GetStore returns the store the requests are queued to
+func (options *OfflineQueueHandlerOptions) GetStore() OfflineRequestStore {
+	return options.Store
+}
+
+// GetIdempotencyKeyHeaderName returns the name of the header the idempotency key is written to
+func (options *OfflineQueueHandlerOptions) GetIdempotencyKeyHeaderName() string {
+	if options.IdempotencyKeyHeaderName == "" {
+		return defaultIdempotencyKeyHeaderName
+	}
+	return options.IdempotencyKeyHeaderName
+}
+
+// GetShouldQueue returns the callback deciding whether a request should be queued
+func (options *OfflineQueueHandlerOptions) GetShouldQueue() func(req *nethttp.Request, err error) bool {
+	if options.ShouldQueue == nil {
+		return isNetworkUnreachableError
+	}
+	return options.ShouldQueue
+}
+
+// GetReplayOnReconnect returns whether queued requests are replayed once a request succeeds again
+func (options *OfflineQueueHandlerOptions) GetReplayOnReconnect() bool {
+	return options.ReplayOnReconnect
+}
+
+// GetAuthenticateReplay returns the callback adding the credentials to the replayed requests
+func (options *OfflineQueueHandlerOptions) GetAuthenticateReplay() func(req *nethttp.Request) error {
+	return options.AuthenticateReplay
+}
+
+// NewOfflineReplayAuthenticator returns a callback for AuthenticateReplay authenticating the replayed requests with the authentication provider
+func NewOfflineReplayAuthenticator(authenticationProvider absauth.AuthenticationProvider) func(req *nethttp.Request) error {
+	return func(req *nethttp.Request) error {
+		requestInfo := abs.NewRequestInformation()
+		requestInfo.SetUri(*req.URL)
+		if err := authenticationProvider.AuthenticateRequest(req.Context(), requestInfo, nil); err != nil {
+			return err
+		}
+		for _, key := range requestInfo.Headers.ListKeys() {
+			for _, value := range requestInfo.Headers.Get(key) {
+				req.Header.Add(key, value)
+			}
+		}
+		return nil
+	}
+}
+
+// OfflineQueueHandler queues the requests which failed because the network is unreachable to a store and replays them when connectivity returns.
+// Only the requests whose body can be read again are queued, they carry an idempotency key so the service can detect duplicates.
+// The credential headers are removed from the queued requests, set AuthenticateReplay to authenticate them again when they are replayed.
+type OfflineQueueHandler struct {
+	options   OfflineQueueHandlerOptions
+	mutex     sync.Mutex
+	replaying bool
+}
+
+// NewOfflineQueueHandler creates a new OfflineQueueHandler queuing the requests to the given store
+func NewOfflineQueueHandler(store OfflineRequestStore) (*OfflineQueueHandler, error) {
+	return NewOfflineQueueHandlerWithOptions(OfflineQueueHandlerOptions{
+		Enabled:           true,
+		Store:             store,
+		ReplayOnReconnect: true,
+	})
+}
+
+// NewOfflineQueueHandlerWithOptions creates a new OfflineQueueHandler with the given options
+func NewOfflineQueueHandlerWithOptions(options OfflineQueueHandlerOptions) (*OfflineQueueHandler, error) {
+	if options.Store == nil {
+		return nil, errors.New("store cannot be nil")
+	}
+	return &OfflineQueueHandler{options: options}, nil
+}
+
+func isNetworkUnreachableError(req *nethttp.Request, err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// Intercept implements the interface and queues the request when it could not be sent.
+func (middleware *OfflineQueueHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(offlineQueueKeyValue).(offlineQueueHandlerOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+	if !reqOption.GetEnabled() || reqOption.GetStore() == nil {
+		return pipeline.Next(req, middlewareIndex)
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	ctx := req.Context()
+	var span trace.Span
+	var observabilityName string
+	if obsOptions != nil {
+		observabilityName = obsOptions.GetTracerInstrumentationName()
+		ctx, span = getTracerProvider(req.Context()).Tracer(observabilityName).Start(ctx, "OfflineQueueHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.offline_queue.enable", true))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	if !isQueueableRequest(req) {
+		return pipeline.Next(req, middlewareIndex)
+	}
+	headerName := reqOption.GetIdempotencyKeyHeaderName()
+	if req.Header.Get(headerName) == "" {
+		req.Header.Set(headerName, uuid.NewString())
+	}
+	response, err := pipeline.Next(req, middlewareIndex)
+	if err != nil {
+		if !reqOption.GetShouldQueue()(req, err) {
+			return response, err
+		}
+		body, bodyErr := getQueuedRequestBody(req)
+		if bodyErr != nil {
+			return response, err
+		}
+		queued := QueuedRequest{
+			Id:             uuid.NewString(),
+			IdempotencyKey: req.Header.Get(headerName),
+			Method:         req.Method,
+			Url:            req.URL.String(),
+			Header:         removeCredentialHeaders(req.Header),
+			Body:           body,
+			QueuedAt:       time.Now(),
+		}
+		if storeErr := reqOption.GetStore().Enqueue(queued); storeErr != nil {
+			return response, err
+		}
+		if span != nil {
+			span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.offline_queue.queued", true))
+		}
+		return response, &RequestQueuedError{Id: queued.Id, Err: err}
+	}
+	if reqOption.GetReplayOnReconnect() && middleware.startReplay() {
+		// a single replay runs at a time, the requests succeeding meanwhile don't start another one
+		go func() {
+			defer middleware.endReplay()
+			_, _ = middleware.replay(context.Background(), reqOption.GetStore(), reqOption.GetAuthenticateReplay(), func(replayReq *nethttp.Request) (*nethttp.Response, error) {
+				return pipeline.Next(replayReq, middlewareIndex)
+			})
+		}()
+	}
+	return response, nil
+}
+
+// isQueueableRequest returns whether the body of the request can be read again to be queued
+func isQueueableRequest(req *nethttp.Request) bool {
+	return req.Body == nil || req.Body == nethttp.NoBody || req.GetBody != nil
+}
+
+// getQueuedRequestBody reads a new copy of the body of the request
+func getQueuedRequestBody(req *nethttp.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == nethttp.NoBody {
+		return nil, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// removeCredentialHeaders returns a copy of the headers without the credential headers the Redactor redacts by default, so they aren't persisted
+func removeCredentialHeaders(header nethttp.Header) nethttp.Header {
+	result := header.Clone()
+	for _, name := range NewRedactor().DeniedHeaders {
+		result.Del(name)
+	}
+	return result
+}
+
+// ReplayQueuedRequests sends the queued requests with the given client and removes the ones which were delivered.
+// It returns the number of delivered requests, 0 when a replay is already running.
+func (middleware *OfflineQueueHandler) ReplayQueuedRequests(ctx context.Context, client *nethttp.Client) (int, error) {
+	if client == nil {
+		return 0, errors.New("client cannot be nil")
+	}
+	if !middleware.startReplay() {
+		return 0, nil
+	}
+	defer middleware.endReplay()
+	return middleware.replay(ctx, middleware.options.Store, middleware.options.AuthenticateReplay, client.Do)
+}
+
+// startReplay marks a replay as running, it returns false when one is already running
+func (middleware *OfflineQueueHandler) startReplay() bool {
+	middleware.mutex.Lock()
+	defer middleware.mutex.Unlock()
+	if middleware.replaying {
+		return false
+	}
+	middleware.replaying = true
+	return true
+}
+
+// endReplay marks the running replay as done
+func (middleware *OfflineQueueHandler) endReplay() {
+	middleware.mutex.Lock()
+	defer middleware.mutex.Unlock()
+	middleware.replaying = false
+}
+
+// replay sends the queued requests, the caller must have started the replay
+func (middleware *OfflineQueueHandler) replay(ctx context.Context, store OfflineRequestStore, authenticate func(req *nethttp.Request) error, send func(*nethttp.Request) (*nethttp.Response, error)) (int, error) {
+	queued, err := store.List()
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, queuedRequest := range queued {
+		req, err := nethttp.NewRequestWithContext(ctx, queuedRequest.Method, queuedRequest.Url, nil)
+		if err != nil {
+			return delivered, err
+		}
+		req.Header = queuedRequest.Header.Clone()
+		if len(queuedRequest.Body) > 0 {
+			req.Body = NopCloser(bytes.NewReader(queuedRequest.Body))
+			req.ContentLength = int64(len(queuedRequest.Body))
+		}
+		if authenticate != nil {
+			if err := authenticate(req); err != nil {
+				return delivered, err
+			}
+		}
+		response, err := send(req)
+		if err != nil {
+			// still offline, the remaining requests are kept for the next replay
+			return delivered, err
+		}
+		if response.Body != nil {
+			_, _ = io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+		}
+		if err := store.Remove(queuedRequest.Id); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
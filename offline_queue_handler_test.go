@@ -0,0 +1,121 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+	"io"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItQueuesRequestsWhenTheNetworkIsUnreachable(t *testing.T) {
+	var receivedBody, receivedKey, receivedAuthorization string
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		body, _ := io.ReadAll(req.Body)
+		receivedBody = string(body)
+		receivedKey = req.Header.Get("Idempotency-Key")
+		receivedAuthorization = req.Header.Get("Authorization")
+		res.WriteHeader(201)
+	}))
+	url := testServer.URL
+	testServer.Close()
+
+	store := NewInMemoryOfflineRequestStore()
+	handler, err := NewOfflineQueueHandlerWithOptions(OfflineQueueHandlerOptions{
+		Enabled: true,
+		Store:   store,
+		AuthenticateReplay: func(req *nethttp.Request) error {
+			req.Header.Set("Authorization", "Bearer refreshed")
+			return nil
+		},
+	})
+	assert.Nil(t, err)
+	req, err := nethttp.NewRequest(nethttp.MethodPost, url, strings.NewReader("content"))
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Cookie", "session=secret")
+	_, err = handler.Intercept(newNoopPipeline(), 0, req)
+	var queuedErr *RequestQueuedError
+	assert.True(t, errors.As(err, &queuedErr))
+	queued, _ := store.List()
+	assert.Equal(t, 1, len(queued))
+	assert.Equal(t, "content", string(queued[0].Body))
+	assert.Empty(t, queued[0].Header.Get("Authorization"))
+	assert.Empty(t, queued[0].Header.Get("Cookie"))
+
+	// requests with the same idempotency key are only queued once
+	assert.Nil(t, store.Enqueue(queued[0]))
+	queued, _ = store.List()
+	assert.Equal(t, 1, len(queued))
+
+	testServer = httptest.NewUnstartedServer(testServer.Config.Handler)
+	testServer.Start()
+	defer testServer.Close()
+	queued[0].Url = testServer.URL
+	_ = store.Remove(queued[0].Id)
+	_ = store.Enqueue(queued[0])
+
+	delivered, err := handler.ReplayQueuedRequests(context.Background(), getDefaultClientWithoutMiddleware())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, "content", receivedBody)
+	assert.Equal(t, queued[0].IdempotencyKey, receivedKey)
+	assert.Equal(t, "Bearer refreshed", receivedAuthorization)
+	queued, _ = store.List()
+	assert.Empty(t, queued)
+}
+
+func TestItRequiresAStoreForTheOfflineQueue(t *testing.T) {
+	_, err := NewOfflineQueueHandler(nil)
+	assert.Error(t, err)
+}
+
+func TestItDoesntQueueRequestsWhoseBodyCannotBeReadAgain(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {}))
+	url := testServer.URL
+	testServer.Close()
+
+	store := NewInMemoryOfflineRequestStore()
+	handler, err := NewOfflineQueueHandler(store)
+	assert.Nil(t, err)
+	req, err := nethttp.NewRequest(nethttp.MethodPost, url, io.NopCloser(strings.NewReader("content")))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = handler.Intercept(newNoopPipeline(), 0, req)
+	var queuedErr *RequestQueuedError
+	assert.False(t, errors.As(err, &queuedErr))
+	assert.Empty(t, req.Header.Get("Idempotency-Key"))
+	queued, _ := store.List()
+	assert.Empty(t, queued)
+}
+
+func TestItReplaysTheQueuedRequestsOneReplayAtATime(t *testing.T) {
+	store := NewInMemoryOfflineRequestStore()
+	handler, err := NewOfflineQueueHandler(store)
+	assert.Nil(t, err)
+	assert.True(t, handler.startReplay())
+	delivered, err := handler.ReplayQueuedRequests(context.Background(), getDefaultClientWithoutMiddleware())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, delivered)
+
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	req, err := nethttp.NewRequest(nethttp.MethodGet, testServer.URL, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = handler.Intercept(newNoopPipeline(), 0, req)
+	assert.Nil(t, err)
+	assert.True(t, handler.replaying)
+	handler.endReplay()
+	assert.True(t, handler.startReplay())
+}
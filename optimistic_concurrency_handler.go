@@ -0,0 +1,192 @@
+package nethttplibrary
+
+import (
+	"io"
+	nethttp "net/http"
+	"strings"
+	"sync"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ETagStore stores the entity tags of the resources fetched through the OptimisticConcurrencyHandler
+type ETagStore interface {
+	// Get returns the entity tag stored for the resource
+	Get(resource string) (string, bool)
+	// Set stores the entity tag of the resource
+	Set(resource string, etag string)
+	// Remove removes the entity tag stored for the resource
+	Remove(resource string)
+}
+
+// InMemoryETagStore is an ETagStore keeping the entity tags in memory
+type InMemoryETagStore struct {
+	mutex sync.RWMutex
+	etags map[string]string
+}
+
+// NewInMemoryETagStore creates a new InMemoryETagStore
+func NewInMemoryETagStore() *InMemoryETagStore {
+	return &InMemoryETagStore{etags: make(map[string]string)}
+}
+
+// Get returns the entity tag stored for the resource
+func (s *InMemoryETagStore) Get(resource string) (string, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	etag, ok := s.etags[resource]
+	return etag, ok
+}
+
+// Set stores the entity tag of the resource
+func (s *InMemoryETagStore) Set(resource string, etag string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.etags[resource] = etag
+}
+
+// Remove removes the entity tag stored for the resource
+func (s *InMemoryETagStore) Remove(resource string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.etags, resource)
+}
+
+// PreconditionFailedError is returned when the service rejects a request because the resource was modified since its entity tag was fetched
+type PreconditionFailedError struct {
+	// Method is the HTTP method of the rejected request
+	Method string
+	// Url is the url of the rejected request
+	Url string
+	// ETag is the entity tag sent in the If-Match header
+	ETag string
+}
+
+// Error returns the error message
+func (e *PreconditionFailedError) Error() string {
+	return "the resource " + e.Url + " was modified since the entity tag " + e.ETag + " was fetched, the " + e.Method + " request was rejected"
+}
+
+// OptimisticConcurrencyHandlerOptions to use when adding the If-Match header to the requests modifying resources.
+type OptimisticConcurrencyHandlerOptions struct {
+	// Enabled defines whether the If-Match header should be added
+	Enabled bool
+	// Store is the store the entity tags of the fetched resources are kept in, an in-memory store is used when nil
+	Store ETagStore
+}
+
+const ifMatchHeader = "If-Match"
+const etagHeader = "ETag"
+
+var optimisticConcurrencyKeyValue = abs.RequestOptionKey{
+	Key: "OptimisticConcurrencyHandler",
+}
+
+type optimisticConcurrencyHandlerOptionsInt interface {
+	abs.RequestOption
+	GetEnabled() bool
+	GetStore() ETagStore
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *OptimisticConcurrencyHandlerOptions) GetKey() abs.RequestOptionKey {
+	return optimisticConcurrencyKeyValue
+}
+
+// GetEnabled returns whether the If-Match header should be added
+func (options *OptimisticConcurrencyHandlerOptions) GetEnabled() bool {
+	return options.Enabled
+}
+
+// GetStore returns the store the entity tags are kept in
+func (options *OptimisticConcurrencyHandlerOptions) GetStore() ETagStore {
+	return options.Store
+}
+
+// OptimisticConcurrencyHandler remembers the entity tags of the fetched resources and adds the If-Match header
+// to the PATCH, PUT and DELETE requests to the same resources, so concurrent modifications are detected.
+type OptimisticConcurrencyHandler struct {
+	options OptimisticConcurrencyHandlerOptions
+}
+
+// NewOptimisticConcurrencyHandler creates a new OptimisticConcurrencyHandler keeping the entity tags in memory
+func NewOptimisticConcurrencyHandler() *OptimisticConcurrencyHandler {
+	return NewOptimisticConcurrencyHandlerWithOptions(OptimisticConcurrencyHandlerOptions{Enabled: true})
+}
+
+// NewOptimisticConcurrencyHandlerWithOptions creates a new OptimisticConcurrencyHandler with the given options
+func NewOptimisticConcurrencyHandlerWithOptions(options OptimisticConcurrencyHandlerOptions) *OptimisticConcurrencyHandler {
+	if options.Store == nil {
+		options.Store = NewInMemoryETagStore()
+	}
+	return &OptimisticConcurrencyHandler{options: options}
+}
+
+// getETagResource returns the key identifying the resource of the request in the store, the query parameters are ignored
+func getETagResource(req *nethttp.Request) string {
+	return req.URL.Scheme + "://" + req.URL.Host + req.URL.EscapedPath()
+}
+
+func isModifyingMethod(method string) bool {
+	return method == nethttp.MethodPatch || method == nethttp.MethodPut || method == nethttp.MethodDelete
+}
+
+// Intercept implements the interface and adds the If-Match header to the requests modifying fetched resources.
+func (middleware OptimisticConcurrencyHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(optimisticConcurrencyKeyValue).(optimisticConcurrencyHandlerOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+	store := reqOption.GetStore()
+	if store == nil {
+		store = middleware.options.Store
+	}
+	if !reqOption.GetEnabled() || store == nil {
+		return pipeline.Next(req, middlewareIndex)
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	if obsOptions != nil {
+		ctx, span := getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(req.Context(), "OptimisticConcurrencyHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.optimistic_concurrency.enable", true))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	resource := getETagResource(req)
+	if isModifyingMethod(req.Method) && req.Header.Get(ifMatchHeader) == "" {
+		if etag, ok := store.Get(resource); ok {
+			// the request of the caller is left untouched
+			req = req.Clone(req.Context())
+			req.Header.Set(ifMatchHeader, etag)
+		}
+	}
+	response, err := pipeline.Next(req, middlewareIndex)
+	if err != nil || response == nil {
+		return response, err
+	}
+	switch {
+	case response.StatusCode == nethttp.StatusPreconditionFailed && req.Header.Get(ifMatchHeader) != "":
+		store.Remove(resource)
+		if response.Body != nil {
+			_, _ = io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+		}
+		return nil, &PreconditionFailedError{Method: req.Method, Url: req.URL.String(), ETag: req.Header.Get(ifMatchHeader)}
+	case response.StatusCode < 200 || response.StatusCode >= 300:
+	case req.Method == nethttp.MethodDelete:
+		store.Remove(resource)
+	case req.Method == nethttp.MethodGet || req.Method == nethttp.MethodHead || isModifyingMethod(req.Method):
+		if etag := response.Header.Get(etagHeader); etag != "" && !isWeakETag(etag) {
+			store.Set(resource, etag)
+		} else if isModifyingMethod(req.Method) || etag != "" {
+			// the entity tag fetched before the modification is outdated, and weak entity tags cannot be used with If-Match
+			store.Remove(resource)
+		}
+	}
+	return response, nil
+}
+
+// isWeakETag returns whether the entity tag is weak, weak entity tags never match with the strong comparison of If-Match (RFC 9110)
+func isWeakETag(etag string) bool {
+	return strings.HasPrefix(etag, "W/")
+}
@@ -0,0 +1,100 @@
+package nethttplibrary
+
+import (
+	"errors"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItAddsTheIfMatchHeaderToModifyingRequests(t *testing.T) {
+	etag := `"1"`
+	receivedIfMatch := make([]string, 0)
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		receivedIfMatch = append(receivedIfMatch, req.Header.Get("If-Match"))
+		switch req.Method {
+		case nethttp.MethodGet:
+			res.Header().Set("ETag", etag)
+			res.WriteHeader(200)
+		case nethttp.MethodPatch:
+			if req.Header.Get("If-Match") != etag {
+				res.WriteHeader(412)
+				return
+			}
+			etag = `"2"`
+			res.Header().Set("ETag", etag)
+			res.WriteHeader(200)
+		default:
+			res.WriteHeader(204)
+		}
+	}))
+	defer testServer.Close()
+	store := NewInMemoryETagStore()
+	client := GetDefaultClient(NewOptimisticConcurrencyHandlerWithOptions(OptimisticConcurrencyHandlerOptions{Enabled: true, Store: store}))
+	send := func(method string, query string) (*nethttp.Response, error) {
+		req, _ := nethttp.NewRequest(method, testServer.URL+"/items/1"+query, strings.NewReader("{}"))
+		return client.Do(req)
+	}
+
+	_, err := send(nethttp.MethodPatch, "")
+	assert.Nil(t, err)
+	_, err = send(nethttp.MethodGet, "?$select=name")
+	assert.Nil(t, err)
+	resp, err := send(nethttp.MethodPatch, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	stored, _ := store.Get(testServer.URL + "/items/1")
+	assert.Equal(t, `"2"`, stored)
+	_, err = send(nethttp.MethodDelete, "")
+	assert.Nil(t, err)
+	_, ok := store.Get(testServer.URL + "/items/1")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"", "", `"1"`, `"2"`}, receivedIfMatch)
+}
+
+func TestItReturnsAPreconditionFailedError(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(412)
+	}))
+	defer testServer.Close()
+	store := NewInMemoryETagStore()
+	store.Set(testServer.URL+"/items/1", `"1"`)
+	client := GetDefaultClient(NewOptimisticConcurrencyHandlerWithOptions(OptimisticConcurrencyHandlerOptions{Enabled: true, Store: store}))
+	req, _ := nethttp.NewRequest(nethttp.MethodPut, testServer.URL+"/items/1", strings.NewReader("{}"))
+
+	_, err := client.Do(req)
+	var preconditionErr *PreconditionFailedError
+	if assert.True(t, errors.As(err, &preconditionErr)) {
+		assert.Equal(t, `"1"`, preconditionErr.ETag)
+		assert.Equal(t, nethttp.MethodPut, preconditionErr.Method)
+	}
+	_, ok := store.Get(testServer.URL + "/items/1")
+	assert.False(t, ok)
+}
+
+func TestItDoesntStoreWeakETags(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.Header().Set("ETag", req.URL.Query().Get("etag"))
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	store := NewInMemoryETagStore()
+	client := GetDefaultClient(NewOptimisticConcurrencyHandlerWithOptions(OptimisticConcurrencyHandlerOptions{Enabled: true, Store: store}))
+
+	_, err := client.Get(testServer.URL + "/items/1?etag=\"1\"")
+	assert.Nil(t, err)
+	req, _ := nethttp.NewRequest(nethttp.MethodPatch, testServer.URL+"/items/1?etag=W/\"2\"", strings.NewReader("{}"))
+	_, err = client.Do(req)
+	assert.Nil(t, err)
+	assert.Empty(t, req.Header.Get("If-Match"))
+	_, ok := store.Get(testServer.URL + "/items/1")
+	assert.False(t, ok)
+
+	_, err = client.Get(testServer.URL + "/items/1?etag=W/\"3\"")
+	assert.Nil(t, err)
+	_, ok = store.Get(testServer.URL + "/items/1")
+	assert.False(t, ok)
+}
@@ -0,0 +1,182 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"time"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absser "github.com/microsoft/kiota-abstractions-go/serialization"
+)
+
+const defaultPageIteratorMaxThrottledRetries = 3
+
+// PageIterator iterates over the pages of a collection paged with next links (e.g. @odata.nextLink).
+// The next page is only requested once the current one was processed by the callback.
+type PageIterator[T absser.Parsable] struct {
+	adapter             abs.RequestAdapter
+	factory             absser.ParsableFactory
+	getNextLink         func(page T) *string
+	errorMappings       abs.ErrorMappings
+	headers             *abs.RequestHeaders
+	options             []abs.RequestOption
+	maxThrottledRetries int
+	current             T
+	currentProcessed    bool
+}
+
+// NewPageIterator creates a new PageIterator starting with the given page.
+// The factory deserializes the next pages and getNextLink returns the link to the page following the given one, nil or empty on the last page.
+func NewPageIterator[T absser.Parsable](firstPage T, adapter abs.RequestAdapter, factory absser.ParsableFactory, getNextLink func(page T) *string) (*PageIterator[T], error) {
+	if adapter == nil {
+		return nil, errors.New("adapter cannot be nil")
+	}
+	if factory == nil {
+		return nil, errors.New("factory cannot be nil")
+	}
+	if getNextLink == nil {
+		return nil, errors.New("getNextLink cannot be nil")
+	}
+	return &PageIterator[T]{
+		adapter:             adapter,
+		factory:             factory,
+		getNextLink:         getNextLink,
+		maxThrottledRetries: defaultPageIteratorMaxThrottledRetries,
+		current:             firstPage,
+	}, nil
+}
+
+// SetErrorMappings sets the error mappings used to deserialize the failed responses of the next pages
+func (it *PageIterator[T]) SetErrorMappings(errorMappings abs.ErrorMappings) {
+	it.errorMappings = errorMappings
+}
+
+// SetHeaders sets the headers added to the requests of the next pages
+func (it *PageIterator[T]) SetHeaders(headers *abs.RequestHeaders) {
+	it.headers = headers
+}
+
+// SetRequestOptions sets the request options added to the requests of the next pages
+func (it *PageIterator[T]) SetRequestOptions(options ...abs.RequestOption) {
+	it.options = options
+}
+
+// SetMaxThrottledRetries sets how many times a page is requested again after a throttling error, 3 by default.
+// The throttling errors are the ones left once the retry handler gave up.
+func (it *PageIterator[T]) SetMaxThrottledRetries(maxThrottledRetries int) {
+	if maxThrottledRetries < 0 {
+		maxThrottledRetries = 0
+	}
+	it.maxThrottledRetries = maxThrottledRetries
+}
+
+// Iterate calls the callback for every page until the last page or until the callback returns false.
+// When stopped by the callback, calling Iterate again resumes with the next page.
+func (it *PageIterator[T]) Iterate(ctx context.Context, callback func(page T) bool) error {
+	if callback == nil {
+		return errors.New("callback cannot be nil")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !it.currentProcessed {
+			if isNilParsable(it.current) {
+				return nil
+			}
+			it.currentProcessed = true
+			if !callback(it.current) {
+				return nil
+			}
+		}
+		nextLink := it.getNextLink(it.current)
+		if nextLink == nil || *nextLink == "" {
+			return nil
+		}
+		page, err := it.getPage(ctx, *nextLink)
+		if err != nil {
+			return err
+		}
+		it.current = page
+		it.currentProcessed = false
+	}
+}
+
+// getPage requests the page at the given link, requesting it again after throttling errors
+func (it *PageIterator[T]) getPage(ctx context.Context, link string) (T, error) {
+	var page T
+	pageUrl, err := url.Parse(link)
+	if err != nil {
+		return page, err
+	}
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return page, err
+		}
+		requestInfo := abs.NewRequestInformation()
+		requestInfo.Method = abs.GET
+		requestInfo.SetUri(*pageUrl)
+		if it.headers != nil {
+			requestInfo.Headers.AddAll(it.headers)
+		}
+		requestInfo.AddRequestOptions(it.options)
+		result, err := it.adapter.Send(ctx, requestInfo, it.factory, it.errorMappings)
+		if err == nil {
+			if result == nil {
+				return page, nil
+			}
+			typedResult, ok := result.(T)
+			if !ok {
+				return page, fmt.Errorf("the page is a %T where a %T was expected", result, page)
+			}
+			return typedResult, nil
+		}
+		delay, throttled := getThrottlingDelay(err, time.Now())
+		if !throttled || attempt >= it.maxThrottledRetries {
+			return page, err
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return page, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// getThrottlingDelay returns how long to wait before sending the request again when the error is caused by a throttling response
+func getThrottlingDelay(err error, now time.Time) (time.Duration, bool) {
+	var apiErr *abs.ApiError
+	if !errors.As(err, &apiErr) {
+		return 0, false
+	}
+	var retryAfter string
+	if apiErr.ResponseHeaders != nil {
+		if values := apiErr.ResponseHeaders.Get(retryAfterHeader); len(values) > 0 {
+			retryAfter = values[0]
+		}
+	}
+	if apiErr.ResponseStatusCode != tooManyRequests && (apiErr.ResponseStatusCode != serviceUnavailable || retryAfter == "") {
+		return 0, false
+	}
+	if delay, ok := parseRetryAfter(retryAfter, now); ok {
+		return delay, true
+	}
+	return defaultDelaySeconds * time.Second, true
+}
+
+// isNilParsable returns whether the page is nil, including typed nil pointers
+func isNilParsable(page absser.Parsable) bool {
+	if page == nil {
+		return true
+	}
+	value := reflect.ValueOf(page)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
@@ -0,0 +1,142 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absser "github.com/microsoft/kiota-abstractions-go/serialization"
+	"github.com/microsoft/kiota-http-go/testingutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPage struct {
+	items    []string
+	nextLink *string
+}
+
+func (p *testPage) Serialize(writer absser.SerializationWriter) error {
+	return nil
+}
+
+func (p *testPage) GetFieldDeserializers() map[string]func(absser.ParseNode) error {
+	return make(map[string]func(absser.ParseNode) error)
+}
+
+func testPageFactory(parseNode absser.ParseNode) (absser.Parsable, error) {
+	return &testPage{}, nil
+}
+
+func getTestPageNextLink(page *testPage) *string {
+	return page.nextLink
+}
+
+func newTestPagesAdapter() *testingutil.FakeRequestAdapter {
+	secondLink := "https://graph.microsoft.com/v1.0/users?$skiptoken=3"
+	pages := map[string]*testPage{
+		"https://graph.microsoft.com/v1.0/users?$skiptoken=2": {items: []string{"c", "d"}, nextLink: &secondLink},
+		secondLink: {items: []string{"e"}},
+	}
+	return testingutil.NewFakeRequestAdapter(func(ctx context.Context, requestInfo *abs.RequestInformation) (any, error) {
+		uri, err := requestInfo.GetUri()
+		if err != nil {
+			return nil, err
+		}
+		return pages[uri.String()], nil
+	})
+}
+
+func TestItIteratesOverThePages(t *testing.T) {
+	adapter := newTestPagesAdapter()
+	firstLink := "https://graph.microsoft.com/v1.0/users?$skiptoken=2"
+	iterator, err := NewPageIterator(&testPage{items: []string{"a", "b"}, nextLink: &firstLink}, adapter, testPageFactory, getTestPageNextLink)
+	assert.Nil(t, err)
+	headers := abs.NewRequestHeaders()
+	headers.Add("ConsistencyLevel", "eventual")
+	iterator.SetHeaders(headers)
+
+	items := make([]string, 0)
+	err = iterator.Iterate(context.Background(), func(page *testPage) bool {
+		items = append(items, page.items...)
+		return true
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, items)
+	requests := adapter.GetReceivedRequests()
+	if assert.Equal(t, 2, len(requests)) {
+		assert.Equal(t, abs.GET, requests[0].Method)
+		assert.Equal(t, []string{"eventual"}, requests[0].Headers.Get("ConsistencyLevel"))
+	}
+}
+
+func TestItResumesTheIterationAfterTheCallbackStopped(t *testing.T) {
+	adapter := newTestPagesAdapter()
+	firstLink := "https://graph.microsoft.com/v1.0/users?$skiptoken=2"
+	iterator, _ := NewPageIterator(&testPage{items: []string{"a", "b"}, nextLink: &firstLink}, adapter, testPageFactory, getTestPageNextLink)
+
+	pages := 0
+	err := iterator.Iterate(context.Background(), func(page *testPage) bool {
+		pages++
+		return false
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, pages)
+	assert.Equal(t, 0, len(adapter.GetReceivedRequests()))
+
+	err = iterator.Iterate(context.Background(), func(page *testPage) bool {
+		pages++
+		return true
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, pages)
+}
+
+func TestItRequestsThePageAgainAfterThrottling(t *testing.T) {
+	attempts := 0
+	adapter := testingutil.NewFakeRequestAdapter(func(ctx context.Context, requestInfo *abs.RequestInformation) (any, error) {
+		attempts++
+		if attempts == 1 {
+			apiErr := abs.NewApiError()
+			apiErr.ResponseStatusCode = 429
+			apiErr.ResponseHeaders.Add("Retry-After", "0")
+			return nil, apiErr
+		}
+		return &testPage{items: []string{"b"}}, nil
+	})
+	nextLink := "https://graph.microsoft.com/v1.0/users?$skiptoken=2"
+	iterator, _ := NewPageIterator(&testPage{items: []string{"a"}, nextLink: &nextLink}, adapter, testPageFactory, getTestPageNextLink)
+
+	items := make([]string, 0)
+	err := iterator.Iterate(context.Background(), func(page *testPage) bool {
+		items = append(items, page.items...)
+		return true
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, items)
+	assert.Equal(t, 2, attempts)
+
+	attempts = 0
+	iterator, _ = NewPageIterator(&testPage{nextLink: &nextLink}, adapter, testPageFactory, getTestPageNextLink)
+	iterator.SetMaxThrottledRetries(0)
+	err = iterator.Iterate(context.Background(), func(page *testPage) bool { return true })
+	var apiErr *abs.ApiError
+	assert.True(t, errors.As(err, &apiErr))
+}
+
+func TestItStopsIteratingWhenTheContextIsCancelled(t *testing.T) {
+	adapter := newTestPagesAdapter()
+	firstLink := "https://graph.microsoft.com/v1.0/users?$skiptoken=2"
+	iterator, _ := NewPageIterator(&testPage{nextLink: &firstLink}, adapter, testPageFactory, getTestPageNextLink)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := iterator.Iterate(ctx, func(page *testPage) bool {
+		cancel()
+		return true
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 0, len(adapter.GetReceivedRequests()))
+
+	_, err = NewPageIterator[*testPage](nil, nil, testPageFactory, getTestPageNextLink)
+	assert.Error(t, err)
+}
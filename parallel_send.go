@@ -0,0 +1,91 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absser "github.com/microsoft/kiota-abstractions-go/serialization"
+)
+
+const defaultMaxParallelRequests = 4
+
+// ParallelRequest is a request sent by SendAll
+type ParallelRequest struct {
+	// RequestInfo is the request to send
+	RequestInfo *abs.RequestInformation
+	// Factory deserializes the response, the response isn't expected to have content when nil
+	Factory absser.ParsableFactory
+	// ErrorMappings deserialize the failed responses
+	ErrorMappings abs.ErrorMappings
+}
+
+// ParallelResult is the outcome of a request sent by SendAll
+type ParallelResult struct {
+	// Value is the deserialized response, nil when the request failed or the response doesn't have content
+	Value absser.Parsable
+	// Err is the error of the request, the error of the context when the request wasn't sent because the context was done
+	Err error
+}
+
+// SendAll sends the requests through the request adapter with at most maxConcurrency requests in flight (4 when lower than 1),
+// and returns their results in the order of the requests.
+// Every request goes through the middleware pipeline, so the retry and throttling handlers delay the requests they retry while holding their slot,
+// which slows the other requests down instead of sending them to a throttling service.
+// The requests which didn't start when the context is done get the error of the context.
+func SendAll(ctx context.Context, adapter abs.RequestAdapter, requests []ParallelRequest, maxConcurrency int) ([]ParallelResult, error) {
+	if adapter == nil {
+		return nil, errors.New("adapter cannot be nil")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if maxConcurrency < 1 {
+		maxConcurrency = defaultMaxParallelRequests
+	}
+	results := make([]ParallelResult, len(requests))
+	slots := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for i, request := range requests {
+		if !acquireParallelSlot(ctx, slots) {
+			for j := i; j < len(requests); j++ {
+				results[j].Err = ctx.Err()
+			}
+			break
+		}
+		wg.Add(1)
+		go func(i int, request ParallelRequest) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results[i] = sendParallelRequest(ctx, adapter, request)
+		}(i, request)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// acquireParallelSlot waits for a free slot, it returns false when the context is done first
+func acquireParallelSlot(ctx context.Context, slots chan struct{}) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sendParallelRequest sends a request of SendAll
+func sendParallelRequest(ctx context.Context, adapter abs.RequestAdapter, request ParallelRequest) ParallelResult {
+	if request.RequestInfo == nil {
+		return ParallelResult{Err: errors.New("requestInfo cannot be nil")}
+	}
+	if request.Factory == nil {
+		return ParallelResult{Err: adapter.SendNoContent(ctx, request.RequestInfo, request.ErrorMappings)}
+	}
+	value, err := adapter.Send(ctx, request.RequestInfo, request.Factory, request.ErrorMappings)
+	return ParallelResult{Value: value, Err: err}
+}
@@ -0,0 +1,87 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/microsoft/kiota-http-go/testingutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItSendsTheRequestsWithBoundedParallelism(t *testing.T) {
+	var mutex sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		mutex.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mutex.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mutex.Lock()
+		inFlight--
+		mutex.Unlock()
+		if req.URL.Path == "/items/3" {
+			res.WriteHeader(500)
+			return
+		}
+		res.WriteHeader(204)
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.Nil(t, err)
+	adapter.SetBaseUrl(testServer.URL)
+	requests := make([]ParallelRequest, 8)
+	for i := range requests {
+		requests[i].RequestInfo = abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/items/"+strconv.Itoa(i), map[string]string{})
+	}
+	requests[1].Factory = testingutil.MockEntityFactory
+	requests[5].RequestInfo = nil
+
+	results, err := SendAll(context.Background(), adapter, requests, 3)
+	assert.Nil(t, err)
+	assert.LessOrEqual(t, maxInFlight, 3)
+	assert.Greater(t, maxInFlight, 1)
+	if assert.Equal(t, len(requests), len(results)) {
+		for i, result := range results {
+			assert.Nil(t, result.Value)
+			switch i {
+			case 3:
+				var apiErr *abs.ApiError
+				if assert.True(t, errors.As(result.Err, &apiErr)) {
+					assert.Equal(t, 500, apiErr.ResponseStatusCode)
+				}
+			case 5:
+				assert.Error(t, result.Err)
+			default:
+				assert.Nil(t, result.Err)
+			}
+		}
+	}
+}
+
+func TestItDoesntStartTheRequestsOnceTheContextIsDone(t *testing.T) {
+	adapter, err := NewNetHttpRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	requests := []ParallelRequest{{RequestInfo: abs.NewRequestInformation()}, {RequestInfo: abs.NewRequestInformation()}}
+
+	results, err := SendAll(ctx, adapter, requests, 1)
+	assert.Nil(t, err)
+	for _, result := range results {
+		assert.ErrorIs(t, result.Err, context.Canceled)
+	}
+
+	_, err = SendAll(context.Background(), nil, requests, 1)
+	assert.Error(t, err)
+}
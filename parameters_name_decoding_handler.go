@@ -4,9 +4,9 @@ import (
 	nethttp "net/http"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	abs "github.com/microsoft/kiota-abstractions-go"
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 )
 
@@ -16,6 +16,8 @@ type ParametersNameDecodingOptions struct {
 	Enable bool
 	// ParametersToDecode defines the characters that should be decoded
 	ParametersToDecode []byte
+	// CharactersToDecode defines additional characters that should be decoded, including non-ASCII characters encoded as multiple percent-encoded bytes
+	CharactersToDecode string
 }
 
 // ParametersNameDecodingHandler decodes special characters in the request query parameters that had to be encoded due to RFC 6570 restrictions names before executing the request.
@@ -40,6 +42,7 @@ type parametersNameDecodingOptionsInt interface {
 	abs.RequestOption
 	GetEnable() bool
 	GetParametersToDecode() []byte
+	GetCharactersToDecode() string
 }
 
 var parametersNameDecodingKeyValue = abs.RequestOptionKey{
@@ -61,6 +64,21 @@ func (options *ParametersNameDecodingOptions) GetParametersToDecode() []byte {
 	return options.ParametersToDecode
 }
 
+// GetCharactersToDecode returns the charactersToDecode value from the option
+func (options *ParametersNameDecodingOptions) GetCharactersToDecode() string {
+	return options.CharactersToDecode
+}
+
+// getCharactersToDecode returns all the characters to decode configured in the option
+func getCharactersToDecode(options parametersNameDecodingOptionsInt) string {
+	var builder strings.Builder
+	for _, parameter := range options.GetParametersToDecode() {
+		builder.WriteRune(rune(parameter))
+	}
+	builder.WriteString(options.GetCharactersToDecode())
+	return builder.String()
+}
+
 // Intercept implements the RequestInterceptor interface and decodes the parameters name
 func (handler *ParametersNameDecodingHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
 	reqOption, ok := req.Context().Value(parametersNameDecodingKeyValue).(parametersNameDecodingOptionsInt)
@@ -70,25 +88,72 @@ func (handler *ParametersNameDecodingHandler) Intercept(pipeline Pipeline, middl
 	obsOptions := GetObservabilityOptionsFromRequest(req)
 	ctx := req.Context()
 	if obsOptions != nil {
-		ctx, span := otel.GetTracerProvider().Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "ParametersNameDecodingHandler_Intercept")
+		ctx, span := getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "ParametersNameDecodingHandler_Intercept")
 		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.parameters_name_decoding.enable", reqOption.GetEnable()))
 		req = req.WithContext(ctx)
 		defer span.End()
 	}
-	if reqOption.GetEnable() &&
-		len(reqOption.GetParametersToDecode()) != 0 &&
-		strings.Contains(req.URL.RawQuery, "%") {
-		req.URL.RawQuery = decodeUriEncodedString(req.URL.RawQuery, reqOption.GetParametersToDecode())
+	if reqOption.GetEnable() {
+		req.URL.RawQuery = decodeQueryParameterNames(req.URL.RawQuery, getCharactersToDecode(reqOption))
 	}
 	return pipeline.Next(req, middlewareIndex)
 }
 
-func decodeUriEncodedString(originalValue string, parametersToDecode []byte) string {
-	resultValue := originalValue
-	for _, parameter := range parametersToDecode {
-		valueToReplace := "%" + strconv.FormatInt(int64(parameter), 16)
-		replacementValue := string(parameter)
-		resultValue = strings.ReplaceAll(strings.ReplaceAll(resultValue, strings.ToUpper(valueToReplace), replacementValue), strings.ToLower(valueToReplace), replacementValue)
+// decodeQueryParameterNames decodes the characters to decode in the names of the query parameters, the values are kept byte-for-byte
+func decodeQueryParameterNames(rawQuery string, charactersToDecode string) string {
+	if charactersToDecode == "" || !strings.Contains(rawQuery, "%") {
+		return rawQuery
+	}
+	parameters := strings.Split(rawQuery, "&")
+	for i, parameter := range parameters {
+		name, value, hasValue := strings.Cut(parameter, "=")
+		name = decodeUriEncodedString(name, charactersToDecode)
+		if hasValue {
+			parameters[i] = name + "=" + value
+		} else {
+			parameters[i] = name
+		}
+	}
+	return strings.Join(parameters, "&")
+}
+
+// decodeUriEncodedString decodes the percent-encoded characters of the value which are part of the characters to decode
+func decodeUriEncodedString(originalValue string, charactersToDecode string) string {
+	if charactersToDecode == "" || !strings.Contains(originalValue, "%") {
+		return originalValue
+	}
+	var builder strings.Builder
+	for i := 0; i < len(originalValue); {
+		if originalValue[i] == '%' {
+			if character, size := decodePercentEncodedRune(originalValue[i:]); size > 0 && strings.ContainsRune(charactersToDecode, character) {
+				builder.WriteRune(character)
+				i += size
+				continue
+			}
+		}
+		builder.WriteByte(originalValue[i])
+		i++
+	}
+	return builder.String()
+}
+
+// decodePercentEncodedRune decodes the UTF-8 character percent-encoded at the start of the value (e.g. %C3%A9 for é)
+// and returns it with the length of its encoded form, or a length of 0 when the value does not start with a valid encoded character
+func decodePercentEncodedRune(value string) (rune, int) {
+	encoded := make([]byte, 0, utf8.UTFMax)
+	for i := 0; len(encoded) < utf8.UTFMax && i+3 <= len(value) && value[i] == '%'; i += 3 {
+		decoded, err := strconv.ParseUint(value[i+1:i+3], 16, 8)
+		if err != nil {
+			return utf8.RuneError, 0
+		}
+		encoded = append(encoded, byte(decoded))
+		if utf8.FullRune(encoded) {
+			character, size := utf8.DecodeRune(encoded)
+			if character == utf8.RuneError && size <= 1 {
+				return utf8.RuneError, 0
+			}
+			return character, i + 3
+		}
 	}
-	return resultValue
+	return utf8.RuneError, 0
 }
@@ -24,6 +24,8 @@ func TestItDecodesQueryParameterNames(t *testing.T) {
 		{"?%24select=diplayName&api%7Eversion=M%26A", "/?$select=diplayName&api~version=M%26A"}, //Values are not decoded but params are
 		{"?%24select=diplayName&api%2Eversion=M%26A", "/?$select=diplayName&api.version=M%26A"}, //Values are not decoded but params are
 		{"?%24select=diplayName&api%2Eversion=M%26A", "/?$select=diplayName&api.version=M%26A"}, //Values are not decoded but params are
+		{"?%24filter=price%20eq%20%2410", "/?$filter=price%20eq%20%2410"},                       //Values are not decoded but params are
+		{"?%24filter=a%2Db%3Dc&%24top", "/?$filter=a%2Db%3Dc&$top"},                             //Values are not decoded but params are
 	}
 	result := ""
 	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
@@ -48,3 +50,38 @@ func TestItDecodesQueryParameterNames(t *testing.T) {
 		assert.Equal(t, expected, result)
 	}
 }
+
+func TestItDecodesMultiByteQueryParameterNames(t *testing.T) {
+	result := ""
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		result = req.URL.RawQuery
+		res.WriteHeader(200)
+	}))
+	defer func() { testServer.Close() }()
+	handler := NewParametersNameDecodingHandlerWithOptions(ParametersNameDecodingOptions{
+		Enable:             true,
+		ParametersToDecode: []byte{'$'},
+		CharactersToDecode: "é€",
+	})
+	req, err := nethttp.NewRequest(nethttp.MethodGet, testServer.URL+"?%24select=name&caf%C3%a9=1&%E2%82%AC=2&%C3%A8=3&broken%C3=4", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = handler.Intercept(newNoopPipeline(), 0, req)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, "$select=name&café=1&€=2&%C3%A8=3&broken%C3=4", result)
+}
+
+func TestItDecodesPercentEncodedRunes(t *testing.T) {
+	character, size := decodePercentEncodedRune("%F0%9F%98%80rest")
+	assert.Equal(t, '😀', character)
+	assert.Equal(t, 12, size)
+	_, size = decodePercentEncodedRune("%ZZ")
+	assert.Equal(t, 0, size)
+	_, size = decodePercentEncodedRune("%C3")
+	assert.Equal(t, 0, size)
+	_, size = decodePercentEncodedRune("%C3%28")
+	assert.Equal(t, 0, size)
+}
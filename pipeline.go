@@ -2,9 +2,7 @@ package nethttplibrary
 
 import (
 	nethttp "net/http"
-
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/trace"
+	"sync"
 )
 
 // Pipeline contract for middleware infrastructure
@@ -17,6 +15,8 @@ type Pipeline interface {
 type customTransport struct {
 	// middleware pipeline in use for the client
 	middlewarePipeline *middlewarePipeline
+	// feature flags registered for every request going through the transport
+	featureUsage FeatureUsageFlag
 }
 
 // middleware pipeline implementation using a roundtripper from net/http
@@ -25,6 +25,10 @@ type middlewarePipeline struct {
 	transport nethttp.RoundTripper
 	// the middlewares to execute
 	middlewares []Middleware
+	// guards the middlewares when the chain is mutated
+	mutex sync.RWMutex
+	// the copies of the transport configured with the proxies of the ProxyOverrideOptions, by proxy url
+	proxyTransports sync.Map
 }
 
 func newMiddlewarePipeline(middlewares []Middleware, transport nethttp.RoundTripper) *middlewarePipeline {
@@ -34,42 +38,105 @@ func newMiddlewarePipeline(middlewares []Middleware, transport nethttp.RoundTrip
 	}
 }
 
-// Next moves the request object through middlewares in the pipeline
+// requestPipeline is the pipeline of a single request, the middlewares are read once when the request enters the transport
+// so mutating the chain while the request is in flight does not shift the index of the middlewares
+type requestPipeline struct {
+	// the pipeline of the transport
+	pipeline *middlewarePipeline
+	// the middlewares of the pipeline when the request entered the transport
+	middlewares []Middleware
+}
+
+// Next moves the request object through middlewares in the pipeline, with the middlewares in use when the request was sent
 func (pipeline *middlewarePipeline) Next(req *nethttp.Request, middlewareIndex int) (*nethttp.Response, error) {
+	return pipeline.forRequest().Next(req, middlewareIndex)
+}
+
+// forRequest returns a pipeline using the current middlewares for the whole request
+func (pipeline *middlewarePipeline) forRequest() *requestPipeline {
+	return &requestPipeline{
+		pipeline:    pipeline,
+		middlewares: pipeline.getMiddlewares(),
+	}
+}
+
+// Next moves the request object through middlewares in the pipeline
+func (pipeline *requestPipeline) Next(req *nethttp.Request, middlewareIndex int) (*nethttp.Response, error) {
 	if middlewareIndex < len(pipeline.middlewares) {
 		middleware := pipeline.middlewares[middlewareIndex]
 		return middleware.Intercept(pipeline, middlewareIndex+1, req)
 	}
-	obsOptions := GetObservabilityOptionsFromRequest(req)
-	ctx := req.Context()
-	var span trace.Span
-	var observabilityName string
-	if obsOptions != nil {
-		observabilityName = obsOptions.GetTracerInstrumentationName()
-		ctx, span = otel.GetTracerProvider().Tracer(observabilityName).Start(ctx, "request_transport")
-		defer span.End()
-		req = req.WithContext(ctx)
+	return pipeline.pipeline.send(req)
+}
+
+// send executes the request with the transport once it went through the middlewares
+func (pipeline *middlewarePipeline) send(req *nethttp.Request) (*nethttp.Response, error) {
+	if req.Context().Value(networkObservabilityContextKey{}) == nil {
+		if obsOptions := GetObservabilityOptionsFromRequest(req); obsOptions != nil {
+			ctx, span := startRequestTransportSpan(req.Context(), obsOptions)
+			defer span.End()
+			req = req.WithContext(ctx)
+		}
+	}
+	parentTransport, err := pipeline.getParentTransportForRequest(req)
+	if err != nil {
+		return nil, err
 	}
-	return pipeline.transport.RoundTrip(req)
+	return getTransportForRequest(req, parentTransport).RoundTrip(req)
+}
+
+// closeIdleConnectionsTransport is implemented by the transports which can close their idle connections
+type closeIdleConnectionsTransport interface {
+	CloseIdleConnections()
+}
+
+// CloseIdleConnections closes the idle connections of the transport sending the requests
+func (transport *customTransport) CloseIdleConnections() {
+	if closer, ok := transport.middlewarePipeline.transport.(closeIdleConnectionsTransport); ok {
+		closer.CloseIdleConnections()
+	}
+	// the copies of the transport configured with the proxy overrides
+	transport.middlewarePipeline.proxyTransports.Range(func(_, proxyTransport any) bool {
+		if closer, ok := proxyTransport.(closeIdleConnectionsTransport); ok {
+			closer.CloseIdleConnections()
+		}
+		return true
+	})
 }
 
 // RoundTrip executes the the next middleware and returns a response
 func (transport *customTransport) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
-	return transport.middlewarePipeline.Next(req, 0)
+	req = RegisterFeatureUsage(req, transport.featureUsage)
+	req = withPipelineValues(req)
+	return transport.middlewarePipeline.forRequest().Next(req, 0)
 }
 
 // GetDefaultTransport returns the default http transport used by the library
+// The proxy is read from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
 func GetDefaultTransport() nethttp.RoundTripper {
 	defaultTransport, ok := nethttp.DefaultTransport.(*nethttp.Transport)
 	if !ok {
 		return nethttp.DefaultTransport
 	}
 	defaultTransport = defaultTransport.Clone()
+	defaultTransport.Proxy = nethttp.ProxyFromEnvironment
 	defaultTransport.ForceAttemptHTTP2 = true
 	defaultTransport.DisableCompression = false
 	return defaultTransport
 }
 
+// getDefaultHttpTransport returns a clone of the default http transport (HTTP/2, proxy from environment, dial timeouts...)
+// or a bare transport when http.DefaultTransport was replaced by another implementation
+func getDefaultHttpTransport() *nethttp.Transport {
+	if defaultTransport, ok := GetDefaultTransport().(*nethttp.Transport); ok {
+		return defaultTransport
+	}
+	return &nethttp.Transport{
+		Proxy:             nethttp.ProxyFromEnvironment,
+		ForceAttemptHTTP2: true,
+	}
+}
+
 // NewCustomTransport creates a new custom transport for http client with the provided set of middleware
 func NewCustomTransport(middlewares ...Middleware) *customTransport {
 	return NewCustomTransportWithParentTransport(nil, middlewares...)
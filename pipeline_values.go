@@ -0,0 +1,87 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	"sync"
+)
+
+// PipelineKey is a typed key of a value shared by the middlewares of a pipeline, e.g. an attempt count or a resolved endpoint.
+// Keys are compared by identity, they should be created once with NewPipelineKey and stored in a package variable.
+type PipelineKey[T any] struct {
+	name string
+}
+
+// NewPipelineKey creates a new PipelineKey, the name is used for debugging purposes only
+func NewPipelineKey[T any](name string) *PipelineKey[T] {
+	return &PipelineKey[T]{name: name}
+}
+
+// String returns the name of the key
+func (key *PipelineKey[T]) String() string {
+	return key.name
+}
+
+type pipelineValuesContextKey struct{}
+
+// pipelineValues holds the values shared by the middlewares for a request, it is shared by all the middlewares of the pipeline
+type pipelineValues struct {
+	mutex  sync.RWMutex
+	values map[interface{}]interface{}
+}
+
+func getPipelineValuesHolder(ctx context.Context) *pipelineValues {
+	if values, ok := ctx.Value(pipelineValuesContextKey{}).(*pipelineValues); ok {
+		return values
+	}
+	return nil
+}
+
+// withPipelineValues adds the holder of the pipeline values to the request context if it's missing
+func withPipelineValues(req *nethttp.Request) *nethttp.Request {
+	if getPipelineValuesHolder(req.Context()) != nil {
+		return req
+	}
+	return req.WithContext(context.WithValue(req.Context(), pipelineValuesContextKey{}, &pipelineValues{
+		values: make(map[interface{}]interface{}),
+	}))
+}
+
+// SetPipelineValue sets the value of the key for the request, the value is visible to all the middlewares of the pipeline including the previous ones.
+// The returned request must be used in place of the given one when the request wasn't sent through a Kiota transport.
+func SetPipelineValue[T any](req *nethttp.Request, key *PipelineKey[T], value T) *nethttp.Request {
+	req = withPipelineValues(req)
+	holder := getPipelineValuesHolder(req.Context())
+	holder.mutex.Lock()
+	defer holder.mutex.Unlock()
+	holder.values[key] = value
+	return req
+}
+
+// GetPipelineValue returns the value of the key for the request context, false when it wasn't set
+func GetPipelineValue[T any](ctx context.Context, key *PipelineKey[T]) (T, bool) {
+	var result T
+	holder := getPipelineValuesHolder(ctx)
+	if holder == nil {
+		return result, false
+	}
+	holder.mutex.RLock()
+	defer holder.mutex.RUnlock()
+	value, ok := holder.values[key]
+	if !ok {
+		return result, false
+	}
+	result, ok = value.(T)
+	return result, ok
+}
+
+// DeletePipelineValue removes the value of the key for the request context
+func DeletePipelineValue[T any](ctx context.Context, key *PipelineKey[T]) {
+	holder := getPipelineValuesHolder(ctx)
+	if holder == nil {
+		return
+	}
+	holder.mutex.Lock()
+	defer holder.mutex.Unlock()
+	delete(holder.values, key)
+}
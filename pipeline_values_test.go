@@ -0,0 +1,68 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testAttemptsKey = NewPipelineKey[int]("attempts")
+var testEndpointKey = NewPipelineKey[string]("endpoint")
+
+type testPipelineValueHandler struct {
+	assertions func(req *nethttp.Request)
+}
+
+func (middleware *testPipelineValueHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	resp, err := pipeline.Next(req, middlewareIndex)
+	middleware.assertions(req)
+	return resp, err
+}
+
+type testPipelineValueSetter struct{}
+
+func (middleware *testPipelineValueSetter) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	attempts, _ := GetPipelineValue(req.Context(), testAttemptsKey)
+	req = SetPipelineValue(req, testAttemptsKey, attempts+1)
+	req = SetPipelineValue(req, testEndpointKey, req.URL.Host)
+	return pipeline.Next(req, middlewareIndex)
+}
+
+func TestMiddlewaresSharePipelineValues(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	assertionsCalled := false
+	client := GetDefaultClient(&testPipelineValueHandler{assertions: func(req *nethttp.Request) {
+		assertionsCalled = true
+		attempts, ok := GetPipelineValue(req.Context(), testAttemptsKey)
+		assert.True(t, ok)
+		assert.Equal(t, 1, attempts)
+		endpoint, _ := GetPipelineValue(req.Context(), testEndpointKey)
+		assert.Equal(t, req.URL.Host, endpoint)
+	}}, &testPipelineValueSetter{})
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.True(t, assertionsCalled)
+}
+
+func TestPipelineValuesAreTyped(t *testing.T) {
+	_, ok := GetPipelineValue(context.Background(), testAttemptsKey)
+	assert.False(t, ok)
+
+	req, _ := nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com", nil)
+	otherKey := NewPipelineKey[int]("attempts")
+	req = SetPipelineValue(req, testAttemptsKey, 2)
+	_, ok = GetPipelineValue(req.Context(), otherKey)
+	assert.False(t, ok)
+	assert.Equal(t, "attempts", otherKey.String())
+
+	DeletePipelineValue(req.Context(), testAttemptsKey)
+	_, ok = GetPipelineValue(req.Context(), testAttemptsKey)
+	assert.False(t, ok)
+}
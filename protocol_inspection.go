@@ -0,0 +1,97 @@
+package nethttplibrary
+
+import (
+	"context"
+	"crypto/tls"
+	nethttp "net/http"
+	"sync"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ProtocolInfo describes the protocol negotiated for a response
+type ProtocolInfo struct {
+	// Protocol is the protocol of the response (e.g. HTTP/2.0)
+	Protocol string
+	// NegotiatedProtocol is the protocol negotiated with ALPN during the TLS handshake (e.g. h2), empty when the server didn't negotiate any
+	NegotiatedProtocol string
+	// TLSVersion is the version of TLS used for the connection (e.g. 1.3), empty when the connection isn't encrypted
+	TLSVersion string
+	// CipherSuite is the name of the TLS cipher suite used for the connection
+	CipherSuite string
+	// DidResume is true when the TLS session was resumed from a previous connection
+	DidResume bool
+}
+
+// ProtocolInspectionOptions is a request option which is filled with the protocol negotiated for each response received for the request
+// (including retries and redirects), to help diagnosing protocol downgrades and handshake problems
+type ProtocolInspectionOptions struct {
+	mutex     sync.Mutex
+	responses []ProtocolInfo
+}
+
+// NewProtocolInspectionOptions creates a new ProtocolInspectionOptions
+func NewProtocolInspectionOptions() *ProtocolInspectionOptions {
+	return &ProtocolInspectionOptions{}
+}
+
+var protocolInspectionKeyValue = abs.RequestOptionKey{
+	Key: "nethttplibrary.ProtocolInspectionOptions",
+}
+
+// GetKey returns the key for the ProtocolInspectionOptions
+func (o *ProtocolInspectionOptions) GetKey() abs.RequestOptionKey {
+	return protocolInspectionKeyValue
+}
+
+// GetResponses returns the protocol information of the responses received, in order
+func (o *ProtocolInspectionOptions) GetResponses() []ProtocolInfo {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return append([]ProtocolInfo(nil), o.responses...)
+}
+
+func (o *ProtocolInspectionOptions) add(info ProtocolInfo) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.responses = append(o.responses, info)
+}
+
+// getProtocolInfo returns the protocol information of the response
+func getProtocolInfo(resp *nethttp.Response) ProtocolInfo {
+	info := ProtocolInfo{Protocol: resp.Proto}
+	if resp.TLS != nil {
+		info.NegotiatedProtocol = resp.TLS.NegotiatedProtocol
+		info.TLSVersion = getTlsVersionName(resp.TLS.Version)
+		info.CipherSuite = tls.CipherSuiteName(resp.TLS.CipherSuite)
+		info.DidResume = resp.TLS.DidResume
+	}
+	return info
+}
+
+// inspectProtocol records the protocol of the response in the protocol inspection options of the context and as attributes of the given span
+func inspectProtocol(ctx context.Context, span trace.Span, resp *nethttp.Response) {
+	options, ok := ctx.Value(protocolInspectionKeyValue).(*ProtocolInspectionOptions)
+	if !ok && span == nil {
+		return
+	}
+	info := getProtocolInfo(resp)
+	if ok {
+		options.add(info)
+	}
+	if span == nil {
+		return
+	}
+	if version := getProtocolVersion(info.Protocol); version != "" {
+		span.SetAttributes(networkProtocolVersionAttribute.String(version))
+	}
+	if resp.TLS != nil {
+		span.SetAttributes(
+			tlsProtocolVersionAttribute.String(info.TLSVersion),
+			tlsCipherAttribute.String(info.CipherSuite),
+			tlsResumedAttribute.Bool(info.DidResume),
+			tlsNegotiatedProtocolAttribute.String(info.NegotiatedProtocol),
+		)
+	}
+}
@@ -0,0 +1,56 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItInspectsTheNegotiatedProtocol(t *testing.T) {
+	provider := useTestTracerProvider(t)
+	testServer := httptest.NewUnstartedServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	testServer.EnableHTTP2 = true
+	testServer.StartTLS()
+	defer testServer.Close()
+	client := GetDefaultClient(&observabilityOptionsHandler{}, NewNetworkObservabilityHandler())
+	client.Transport.(*customTransport).middlewarePipeline.transport = testServer.Client().Transport
+	options := NewProtocolInspectionOptions()
+	req, _ := nethttp.NewRequestWithContext(context.WithValue(context.Background(), protocolInspectionKeyValue, options), nethttp.MethodGet, testServer.URL, nil)
+
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	responses := options.GetResponses()
+	if assert.Equal(t, 1, len(responses)) {
+		assert.Equal(t, "HTTP/2.0", responses[0].Protocol)
+		assert.Equal(t, "h2", responses[0].NegotiatedProtocol)
+		assert.Equal(t, "1.3", responses[0].TLSVersion)
+		assert.NotEmpty(t, responses[0].CipherSuite)
+	}
+	spans := provider.getSpans("request_transport")
+	if assert.Equal(t, 1, len(spans)) {
+		version, _ := spans[0].getAttribute(networkProtocolVersionAttribute)
+		assert.Equal(t, "2.0", version.AsString())
+		alpn, _ := spans[0].getAttribute(tlsNegotiatedProtocolAttribute)
+		assert.Equal(t, "h2", alpn.AsString())
+	}
+}
+
+func TestItInspectsUnencryptedResponses(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	client := GetDefaultClient(NewNetworkObservabilityHandler())
+	options := NewProtocolInspectionOptions()
+	req, _ := nethttp.NewRequestWithContext(context.WithValue(context.Background(), protocolInspectionKeyValue, options), nethttp.MethodGet, testServer.URL, nil)
+
+	_, err := client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, []ProtocolInfo{{Protocol: "HTTP/1.1"}}, options.GetResponses())
+}
@@ -0,0 +1,280 @@
+package nethttplibrary
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ProxyAuthenticationHandler answers the Basic and Digest challenges of proxies (407 responses with a Proxy-Authenticate header).
+// Digest challenges are remembered so the following requests authenticate pre-emptively with an incremented nonce count.
+// It applies to the requests sent in clear through the proxy, ConfigureTransport sets up the http.Transport so the tunnels of https requests
+// get authenticated as well.
+type ProxyAuthenticationHandler struct {
+	options ProxyAuthenticationHandlerOptions
+	session digestSession
+	// transport is the transport configured to authenticate its CONNECT requests, nil when none was configured
+	transport *nethttp.Transport
+	// connectBasic is set to 1 once the proxy challenged a CONNECT request with the Basic scheme
+	connectBasic int32
+}
+
+// ProxyAuthenticationHandlerOptions to use when authenticating to a proxy.
+type ProxyAuthenticationHandlerOptions struct {
+	// Enabled defines whether the challenges should be answered
+	Enabled bool
+	// Username is the user name to authenticate with
+	Username string
+	// Password is the password to authenticate with
+	Password string
+}
+
+const proxyAuthenticateHeader = "Proxy-Authenticate"
+const proxyAuthorizationHeader = "Proxy-Authorization"
+const proxyAuthenticationInfoHeader = "Proxy-Authentication-Info"
+
+var proxyAuthenticationKeyValue = abs.RequestOptionKey{
+	Key: "ProxyAuthenticationHandler",
+}
+
+type proxyAuthenticationHandlerOptionsInt interface {
+	abs.RequestOption
+	GetEnabled() bool
+	GetUsername() string
+	GetPassword() string
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *ProxyAuthenticationHandlerOptions) GetKey() abs.RequestOptionKey {
+	return proxyAuthenticationKeyValue
+}
+
+// GetEnabled returns whether the challenges should be answered
+func (options *ProxyAuthenticationHandlerOptions) GetEnabled() bool {
+	return options.Enabled
+}
+
+// GetUsername returns the user name to authenticate with
+func (options *ProxyAuthenticationHandlerOptions) GetUsername() string {
+	return options.Username
+}
+
+// GetPassword returns the password to authenticate with
+func (options *ProxyAuthenticationHandlerOptions) GetPassword() string {
+	return options.Password
+}
+
+// NewProxyAuthenticationHandler creates a new ProxyAuthenticationHandler with the given credentials
+func NewProxyAuthenticationHandler(username string, password string) (*ProxyAuthenticationHandler, error) {
+	return NewProxyAuthenticationHandlerWithOptions(ProxyAuthenticationHandlerOptions{
+		Enabled:  true,
+		Username: username,
+		Password: password,
+	})
+}
+
+// NewProxyAuthenticationHandlerWithOptions creates a new ProxyAuthenticationHandler with the given options
+func NewProxyAuthenticationHandlerWithOptions(options ProxyAuthenticationHandlerOptions) (*ProxyAuthenticationHandler, error) {
+	if options.Username == "" {
+		return nil, errors.New("username cannot be empty")
+	}
+	return &ProxyAuthenticationHandler{options: options}, nil
+}
+
+// GetProxyConnectHeader returns the Proxy-Authorization header of CONNECT requests once a Digest or Basic challenge was received.
+// It matches the signature of http.Transport.GetProxyConnectHeader.
+func (middleware *ProxyAuthenticationHandler) GetProxyConnectHeader(ctx context.Context, proxyURL *url.URL, target string) (nethttp.Header, error) {
+	authorization, err := middleware.session.authorize(nethttp.MethodConnect, target, middleware.options.Username, middleware.options.Password)
+	if err != nil {
+		return nil, err
+	}
+	if authorization == "" && atomic.LoadInt32(&middleware.connectBasic) == 1 {
+		authorization = getBasicAuthorization(middleware.options.Username, middleware.options.Password)
+	}
+	if authorization == "" {
+		return nil, nil
+	}
+	header := make(nethttp.Header)
+	header.Set(proxyAuthorizationHeader, authorization)
+	return header, nil
+}
+
+// ConfigureTransport sets the GetProxyConnectHeader of the transport so the tunnels of https requests get authenticated.
+// The transport reports a rejected CONNECT request as an error, the handler then fetches the challenge of the proxy and retries the request.
+// The clients created with the proxy settings of the library configure their transport when the handler is part of their middlewares.
+func (middleware *ProxyAuthenticationHandler) ConfigureTransport(transport *nethttp.Transport) {
+	transport.GetProxyConnectHeader = middleware.GetProxyConnectHeader
+	middleware.transport = transport
+}
+
+// isProxyConnectRejected returns whether the error is the one of the transport when the proxy answers the CONNECT request with a 407 status
+func isProxyConnectRejected(req *nethttp.Request, err error) bool {
+	return req.URL.Scheme == "https" && strings.HasSuffix(err.Error(), nethttp.StatusText(nethttp.StatusProxyAuthRequired))
+}
+
+// fetchConnectChallenge sends a CONNECT request without credentials to the proxy of the request and stores its challenge.
+// It returns false when the proxy did not send a challenge the handler can answer.
+func (middleware *ProxyAuthenticationHandler) fetchConnectChallenge(req *nethttp.Request, answered bool) (bool, error) {
+	if middleware.transport == nil || middleware.transport.Proxy == nil {
+		return false, nil
+	}
+	proxyURL, err := middleware.transport.Proxy(req)
+	if err != nil || proxyURL == nil {
+		return false, err
+	}
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(req.Context(), "tcp", getHostWithPort(proxyURL))
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if deadline, ok := req.Context().Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if proxyURL.Scheme == "https" {
+		tlsConfig := &tls.Config{}
+		if middleware.transport.TLSClientConfig != nil {
+			tlsConfig = middleware.transport.TLSClientConfig.Clone()
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = proxyURL.Hostname()
+		}
+		conn = tls.Client(conn, tlsConfig)
+	}
+	target := getHostWithPort(req.URL)
+	connectReq := &nethttp.Request{
+		Method: nethttp.MethodConnect,
+		URL:    &url.URL{Opaque: target},
+		Host:   target,
+		Header: make(nethttp.Header),
+	}
+	if err = connectReq.Write(conn); err != nil {
+		return false, err
+	}
+	resp, err := nethttp.ReadResponse(bufio.NewReader(conn), connectReq)
+	if err != nil {
+		return false, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != nethttp.StatusProxyAuthRequired {
+		return false, nil
+	}
+	challenges := resp.Header.Values(proxyAuthenticateHeader)
+	if challenge, ok := parseDigestChallenge(challenges); ok {
+		return middleware.session.setChallenge(challenge, answered), nil
+	}
+	if hasBasicChallenge(challenges) {
+		return atomic.CompareAndSwapInt32(&middleware.connectBasic, 0, 1), nil
+	}
+	return false, nil
+}
+
+func getHostWithPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+func getBasicAuthorization(username string, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+// Intercept implements the interface and answers the authentication challenges of the proxy.
+func (middleware *ProxyAuthenticationHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(proxyAuthenticationKeyValue).(proxyAuthenticationHandlerOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	if obsOptions != nil {
+		ctx, span := getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(req.Context(), "ProxyAuthenticationHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.proxy_authentication.enable", reqOption.GetEnabled()))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	if !reqOption.GetEnabled() {
+		return pipeline.Next(req, middlewareIndex)
+	}
+	uri := req.URL.String()
+	authorization, err := middleware.session.authorize(req.Method, uri, reqOption.GetUsername(), reqOption.GetPassword())
+	if err != nil {
+		return nil, err
+	}
+	answered := authorization != ""
+	if answered {
+		req.Header.Set(proxyAuthorizationHeader, authorization)
+	}
+	resp, err := pipeline.Next(req, middlewareIndex)
+	if err != nil {
+		if !isProxyConnectRejected(req, err) {
+			return resp, err
+		}
+		return middleware.retryConnect(pipeline, middlewareIndex, req, err)
+	}
+	if resp.StatusCode != nethttp.StatusProxyAuthRequired {
+		middleware.session.setNextNonce(resp.Header.Get(proxyAuthenticationInfoHeader))
+		return resp, nil
+	}
+	challenges := resp.Header.Values(proxyAuthenticateHeader)
+	if challenge, ok := parseDigestChallenge(challenges); ok {
+		if !middleware.session.setChallenge(challenge, answered) {
+			return resp, nil
+		}
+		authorization, err = middleware.session.authorize(req.Method, uri, reqOption.GetUsername(), reqOption.GetPassword())
+		if err != nil {
+			return nil, err
+		}
+	} else if !answered && hasBasicChallenge(challenges) {
+		authorization = getBasicAuthorization(reqOption.GetUsername(), reqOption.GetPassword())
+	} else {
+		return resp, nil
+	}
+	if !rewindRequestBody(req) {
+		return resp, nil
+	}
+	if resp.Body != nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+	req.Header.Set(proxyAuthorizationHeader, authorization)
+	resp, err = pipeline.Next(req, middlewareIndex)
+	if err == nil && resp.StatusCode != nethttp.StatusProxyAuthRequired {
+		middleware.session.setNextNonce(resp.Header.Get(proxyAuthenticationInfoHeader))
+	}
+	return resp, err
+}
+
+// retryConnect fetches the challenge of the proxy after the transport failed to open the tunnel of the request, and retries the request once when it can be answered.
+func (middleware *ProxyAuthenticationHandler) retryConnect(pipeline Pipeline, middlewareIndex int, req *nethttp.Request, connectErr error) (*nethttp.Response, error) {
+	answered := middleware.session.hasChallenge() || atomic.LoadInt32(&middleware.connectBasic) == 1
+	fetched, err := middleware.fetchConnectChallenge(req, answered)
+	if err != nil || !fetched || !rewindRequestBody(req) {
+		return nil, connectErr
+	}
+	return pipeline.Next(req, middlewareIndex)
+}
+
+func hasBasicChallenge(headerValues []string) bool {
+	for _, headerValue := range headerValues {
+		if strings.EqualFold(strings.SplitN(strings.TrimSpace(headerValue), " ", 2)[0], "basic") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package nethttplibrary
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItAnswersDigestProxyChallenges(t *testing.T) {
+	challengeCount := 0
+	var authorizations []string
+	proxyServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		authorization := req.Header.Get("Proxy-Authorization")
+		if authorization == "" {
+			challengeCount++
+			res.Header().Add("Proxy-Authenticate", `Basic realm="proxy"`)
+			res.Header().Add("Proxy-Authenticate", `Digest realm="proxy", qop="auth", nonce="abc", algorithm=SHA-256`)
+			res.WriteHeader(407)
+			return
+		}
+		authorizations = append(authorizations, authorization)
+		res.WriteHeader(200)
+	}))
+	defer proxyServer.Close()
+	handler, err := NewProxyAuthenticationHandler("user", "pass")
+	assert.Nil(t, err)
+	client, err := GetClientWithProxySettings(proxyServer.URL, handler)
+	assert.Nil(t, err)
+
+	resp, err := client.Post("http://graph.microsoft.com/v1.0/me", "text/plain", strings.NewReader("content"))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	resp, err = client.Get("http://graph.microsoft.com/v1.0/me")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	assert.Equal(t, 1, challengeCount)
+	assert.Equal(t, 2, len(authorizations))
+	assert.True(t, strings.HasPrefix(authorizations[0], `Digest username="user", realm="proxy", nonce="abc", uri="http://graph.microsoft.com/v1.0/me", algorithm=SHA-256, qop=auth, nc=00000001`))
+	assert.Contains(t, authorizations[1], "nc=00000002")
+
+	header, err := handler.GetProxyConnectHeader(context.Background(), nil, "graph.microsoft.com:443")
+	assert.Nil(t, err)
+	assert.Contains(t, header.Get("Proxy-Authorization"), `uri="graph.microsoft.com:443"`)
+}
+
+func TestItAnswersBasicProxyChallengesOnce(t *testing.T) {
+	challengeCount := 0
+	proxyServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		if req.Header.Get("Proxy-Authorization") != "Basic dXNlcjpwYXNz" {
+			challengeCount++
+			res.Header().Add("Proxy-Authenticate", `Basic realm="proxy"`)
+			res.WriteHeader(407)
+			return
+		}
+		res.WriteHeader(200)
+	}))
+	defer proxyServer.Close()
+	handler, _ := NewProxyAuthenticationHandler("user", "pass")
+	client, _ := GetClientWithProxySettings(proxyServer.URL, handler)
+	resp, err := client.Get("http://graph.microsoft.com/v1.0/me")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 1, challengeCount)
+
+	wrongHandler, _ := NewProxyAuthenticationHandler("user", "wrong")
+	client, _ = GetClientWithProxySettings(proxyServer.URL, wrongHandler)
+	resp, err = client.Get("http://graph.microsoft.com/v1.0/me")
+	assert.Nil(t, err)
+	assert.Equal(t, 407, resp.StatusCode)
+	assert.Equal(t, 3, challengeCount)
+}
+
+func TestItAuthenticatesTheTunnelsOfHttpsRequests(t *testing.T) {
+	targetServer := httptest.NewTLSServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer targetServer.Close()
+	challengeCount := 0
+	var authorizations []string
+	proxyServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		if req.Method != nethttp.MethodConnect {
+			res.WriteHeader(405)
+			return
+		}
+		authorization := req.Header.Get("Proxy-Authorization")
+		if !strings.HasPrefix(authorization, "Digest ") {
+			challengeCount++
+			res.Header().Add("Proxy-Authenticate", `Digest realm="proxy", qop="auth", nonce="abc", algorithm=SHA-256`)
+			res.WriteHeader(407)
+			return
+		}
+		authorizations = append(authorizations, authorization)
+		targetConn, err := net.Dial("tcp", req.Host)
+		if err != nil {
+			res.WriteHeader(502)
+			return
+		}
+		res.WriteHeader(200)
+		clientConn, _, _ := res.(nethttp.Hijacker).Hijack()
+		go func() {
+			_, _ = io.Copy(targetConn, clientConn)
+			_ = targetConn.Close()
+		}()
+		_, _ = io.Copy(clientConn, targetConn)
+		_ = clientConn.Close()
+	}))
+	defer proxyServer.Close()
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(targetServer.Certificate())
+	handler, _ := NewProxyAuthenticationHandler("user", "pass")
+	client, err := GetClientWithProxySettingsAndTLSConfig(proxyServer.URL, &tls.Config{RootCAs: rootCAs}, handler)
+	assert.Nil(t, err)
+
+	resp, err := client.Post(targetServer.URL, "text/plain", strings.NewReader("content"))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	// the transport and the handler fetching the challenge each send a CONNECT request without credentials
+	assert.Equal(t, 2, challengeCount)
+	assert.Equal(t, 1, len(authorizations))
+	target := strings.TrimPrefix(targetServer.URL, "https://")
+	assert.True(t, strings.HasPrefix(authorizations[0], `Digest username="user", realm="proxy", nonce="abc", uri="`+target+`", algorithm=SHA-256, qop=auth, nc=00000001`))
+}
+
+func TestItReturnsTheConnectErrorWhenTheProxyRejectsTheCredentials(t *testing.T) {
+	challengeCount := 0
+	proxyServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		challengeCount++
+		res.Header().Add("Proxy-Authenticate", `Basic realm="proxy"`)
+		res.WriteHeader(407)
+	}))
+	defer proxyServer.Close()
+	handler, _ := NewProxyAuthenticationHandler("user", "wrong")
+	client, _ := GetClientWithProxySettings(proxyServer.URL, handler)
+	_, err := client.Get("https://graph.microsoft.com/v1.0/me")
+	assert.ErrorContains(t, err, "Proxy Authentication Required")
+	assert.Equal(t, 3, challengeCount)
+}
+
+func TestProxyAuthenticationHandlerRequiresAUsername(t *testing.T) {
+	_, err := NewProxyAuthenticationHandler("", "pass")
+	assert.Error(t, err)
+}
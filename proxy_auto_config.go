@@ -0,0 +1,165 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+)
+
+// ProxyAutoConfigEvaluator evaluates a proxy auto-configuration (PAC) script for a request.
+// The library does not embed a JavaScript engine, implementations typically wrap one to call the FindProxyForURL function of the script.
+type ProxyAutoConfigEvaluator interface {
+	// FindProxyForURL returns the result of the FindProxyForURL function of the script for the given url, e.g. "PROXY proxy.contoso.com:8080; DIRECT"
+	FindProxyForURL(requestUrl *url.URL) (string, error)
+}
+
+// ProxyAutoConfigEvaluatorFunc is an adapter to use a function as a ProxyAutoConfigEvaluator
+type ProxyAutoConfigEvaluatorFunc func(requestUrl *url.URL) (string, error)
+
+// FindProxyForURL calls the function
+func (f ProxyAutoConfigEvaluatorFunc) FindProxyForURL(requestUrl *url.URL) (string, error) {
+	return f(requestUrl)
+}
+
+// ProxyAutoConfigEvaluatorFactory creates an evaluator for the content of a PAC script
+type ProxyAutoConfigEvaluatorFactory func(script string) (ProxyAutoConfigEvaluator, error)
+
+// NewProxyAutoConfigEvaluatorFromUrl downloads the PAC script at the given url and creates an evaluator for it with the factory.
+// The script is downloaded without going through a proxy.
+func NewProxyAutoConfigEvaluatorFromUrl(ctx context.Context, pacUrl string, factory ProxyAutoConfigEvaluatorFactory) (ProxyAutoConfigEvaluator, error) {
+	if factory == nil {
+		return nil, errors.New("factory cannot be nil")
+	}
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, pacUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	transport := getDefaultHttpTransport()
+	transport.Proxy = nil
+	client := &nethttp.Client{Transport: transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to download the PAC script, status code %d", resp.StatusCode)
+	}
+	script, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return factory(string(script))
+}
+
+// ParseProxyAutoConfigResult parses the result of a FindProxyForURL call into the ordered list of proxies to use.
+// A nil entry stands for a DIRECT connection. PROXY and HTTP entries map to http urls, HTTPS to https urls and SOCKS/SOCKS5 to socks5 urls.
+func ParseProxyAutoConfigResult(result string) ([]*url.URL, error) {
+	proxies := make([]*url.URL, 0)
+	for _, entry := range strings.Split(result, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		directive := strings.ToUpper(fields[0])
+		if directive == "DIRECT" {
+			proxies = append(proxies, nil)
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid PAC entry %q", strings.TrimSpace(entry))
+		}
+		var scheme string
+		switch directive {
+		case "PROXY", "HTTP":
+			scheme = "http"
+		case "HTTPS":
+			scheme = "https"
+		case "SOCKS", "SOCKS5":
+			scheme = "socks5"
+		default:
+			return nil, fmt.Errorf("unsupported PAC directive %q", fields[0])
+		}
+		proxies = append(proxies, &url.URL{Scheme: scheme, Host: fields[1]})
+	}
+	if len(proxies) == 0 {
+		// an empty result means a direct connection
+		proxies = append(proxies, nil)
+	}
+	return proxies, nil
+}
+
+// NewProxyAutoConfigProxyFunc returns a proxy function for http.Transport which evaluates the PAC script for each request
+// and uses the first entry of the result. The proxy function alone cannot fail over to the following entries,
+// the clients built with WithProxyAutoConfig of the KiotaClientBuilder try them in order when the connection to a proxy fails.
+func NewProxyAutoConfigProxyFunc(evaluator ProxyAutoConfigEvaluator) func(*nethttp.Request) (*url.URL, error) {
+	return func(req *nethttp.Request) (*url.URL, error) {
+		if selected, ok := req.Context().Value(proxyAutoConfigContextKey{}).(*proxyAutoConfigSelection); ok {
+			return selected.proxy, nil
+		}
+		proxies, err := findProxiesForRequest(evaluator, req)
+		if err != nil {
+			return nil, err
+		}
+		return proxies[0], nil
+	}
+}
+
+// findProxiesForRequest evaluates the PAC script for the request and returns the entries of the result, nil for DIRECT
+func findProxiesForRequest(evaluator ProxyAutoConfigEvaluator, req *nethttp.Request) ([]*url.URL, error) {
+	result, err := evaluator.FindProxyForURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	return ParseProxyAutoConfigResult(result)
+}
+
+type proxyAutoConfigContextKey struct{}
+
+// proxyAutoConfigSelection is the entry of the PAC result the proxy function must use, nil for DIRECT
+type proxyAutoConfigSelection struct {
+	proxy *url.URL
+}
+
+// proxyAutoConfigTransport evaluates the PAC script once per request and tries the entries of the result in order,
+// failing over to the next entry when the connection to a proxy fails
+type proxyAutoConfigTransport struct {
+	transport nethttp.RoundTripper
+	evaluator ProxyAutoConfigEvaluator
+}
+
+func newProxyAutoConfigTransport(transport nethttp.RoundTripper, evaluator ProxyAutoConfigEvaluator) *proxyAutoConfigTransport {
+	return &proxyAutoConfigTransport{
+		transport: transport,
+		evaluator: evaluator,
+	}
+}
+
+// RoundTrip sends the request through the entries of the PAC result until a connection succeeds
+func (transport *proxyAutoConfigTransport) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	proxies, err := findProxiesForRequest(transport.evaluator, req)
+	if err != nil {
+		return nil, err
+	}
+	for i, proxyUrl := range proxies {
+		attemptReq := req.WithContext(context.WithValue(req.Context(), proxyAutoConfigContextKey{}, &proxyAutoConfigSelection{proxy: proxyUrl}))
+		resp, err := transport.transport.RoundTrip(attemptReq)
+		if err == nil || !isProxyConnectionError(err) || i == len(proxies)-1 {
+			return resp, err
+		}
+		req = req.Clone(req.Context())
+		if !rewindRequestBody(req) {
+			return nil, err
+		}
+	}
+	return nil, errors.New("the PAC result has no entry")
+}
+
+// withProxy returns a copy of the parent transport sending the requests through the proxy instead of the PAC result
+func (transport *proxyAutoConfigTransport) withProxy(proxy func(*nethttp.Request) (*url.URL, error)) (nethttp.RoundTripper, error) {
+	return withProxy(transport.transport, proxy)
+}
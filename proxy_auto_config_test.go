@@ -0,0 +1,132 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItParsesProxyAutoConfigResults(t *testing.T) {
+	proxies, err := ParseProxyAutoConfigResult("PROXY proxy.contoso.com:8080; SOCKS5 socks.contoso.com:1080;DIRECT")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(proxies))
+	assert.Equal(t, "http://proxy.contoso.com:8080", proxies[0].String())
+	assert.Equal(t, "socks5://socks.contoso.com:1080", proxies[1].String())
+	assert.Nil(t, proxies[2])
+
+	proxies, err = ParseProxyAutoConfigResult("")
+	assert.Nil(t, err)
+	assert.Equal(t, []*url.URL{nil}, proxies)
+
+	_, err = ParseProxyAutoConfigResult("FTP ftp.contoso.com")
+	assert.Error(t, err)
+	_, err = ParseProxyAutoConfigResult("PROXY")
+	assert.Error(t, err)
+}
+
+func TestItSelectsTheProxyPerRequest(t *testing.T) {
+	evaluator := ProxyAutoConfigEvaluatorFunc(func(requestUrl *url.URL) (string, error) {
+		if requestUrl.Hostname() == "intranet.contoso.com" {
+			return "DIRECT", nil
+		}
+		if requestUrl.Hostname() == "broken.contoso.com" {
+			return "", errors.New("evaluation failed")
+		}
+		return "PROXY proxy.contoso.com:8080; DIRECT", nil
+	})
+	client, err := NewKiotaClientBuilder().WithProxyAutoConfig(evaluator).Build()
+	assert.Nil(t, err)
+	transport := client.Transport.(*customTransport)
+	assert.Equal(t, ProxyEnabledFeatureUsageFlag, transport.featureUsage)
+	parent := transport.middlewarePipeline.transport.(*proxyAutoConfigTransport).transport.(*nethttp.Transport)
+
+	req, _ := nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com", nil)
+	proxyUrl, err := parent.Proxy(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "http://proxy.contoso.com:8080", proxyUrl.String())
+
+	req, _ = nethttp.NewRequest(nethttp.MethodGet, "https://intranet.contoso.com", nil)
+	proxyUrl, err = parent.Proxy(req)
+	assert.Nil(t, err)
+	assert.Nil(t, proxyUrl)
+
+	req, _ = nethttp.NewRequest(nethttp.MethodGet, "https://broken.contoso.com", nil)
+	_, err = parent.Proxy(req)
+	assert.Error(t, err)
+}
+
+func TestItFailsOverToTheNextEntryOfTheProxyAutoConfigResult(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		body, _ := io.ReadAll(req.Body)
+		res.WriteHeader(200)
+		res.Write(body)
+	}))
+	defer testServer.Close()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	unreachableProxy := listener.Addr().String()
+	listener.Close()
+	evaluations := 0
+	evaluator := ProxyAutoConfigEvaluatorFunc(func(requestUrl *url.URL) (string, error) {
+		evaluations++
+		return "PROXY " + unreachableProxy + "; DIRECT", nil
+	})
+	client, err := NewKiotaClientBuilder().WithProxyAutoConfig(evaluator).WithMiddleware(NewUserAgentHandler()).Build()
+	assert.Nil(t, err)
+
+	resp, err := client.Post(testServer.URL, "text/plain", strings.NewReader("content"))
+	if assert.Nil(t, err) {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "content", string(body))
+	}
+	assert.Equal(t, 1, evaluations)
+}
+
+func TestItDoesNotFailOverWhenTheDirectConnectionFails(t *testing.T) {
+	proxyCount := 0
+	proxyServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		proxyCount++
+		res.WriteHeader(200)
+	}))
+	defer proxyServer.Close()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	unreachableOrigin := listener.Addr().String()
+	listener.Close()
+	evaluator := ProxyAutoConfigEvaluatorFunc(func(requestUrl *url.URL) (string, error) {
+		return "DIRECT; PROXY " + strings.TrimPrefix(proxyServer.URL, "http://"), nil
+	})
+	client, err := NewKiotaClientBuilder().WithProxyAutoConfig(evaluator).WithMiddleware(NewUserAgentHandler()).Build()
+	assert.Nil(t, err)
+
+	_, err = client.Get("http://" + unreachableOrigin)
+	assert.Error(t, err)
+	assert.Equal(t, 0, proxyCount)
+}
+
+func TestItDownloadsTheProxyAutoConfigScript(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+		res.Write([]byte("function FindProxyForURL(url, host) { return \"DIRECT\"; }"))
+	}))
+	defer testServer.Close()
+	var receivedScript string
+	evaluator, err := NewProxyAutoConfigEvaluatorFromUrl(context.Background(), testServer.URL, func(script string) (ProxyAutoConfigEvaluator, error) {
+		receivedScript = script
+		return ProxyAutoConfigEvaluatorFunc(func(requestUrl *url.URL) (string, error) {
+			return "DIRECT", nil
+		}), nil
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, evaluator)
+	assert.Contains(t, receivedScript, "FindProxyForURL")
+}
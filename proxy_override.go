@@ -0,0 +1,104 @@
+package nethttplibrary
+
+import (
+	"errors"
+	nethttp "net/http"
+	"net/url"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+)
+
+// ProxyOverrideOptions is a request option sending the request through the given proxy, or without any proxy, in place of the proxy of the client.
+// The last hop of the pipeline sends the request with a copy of its parent transport configured with the proxy, the copies are reused across the requests.
+type ProxyOverrideOptions struct {
+	// ProxyUrl is the url of the proxy the request is sent through
+	ProxyUrl *url.URL
+	// NoProxy defines whether the request is sent without any proxy, it takes precedence over the proxy url
+	NoProxy bool
+}
+
+var proxyOverrideKeyValue = abs.RequestOptionKey{
+	Key: "ProxyOverride",
+}
+
+type proxyOverrideOptionsInt interface {
+	abs.RequestOption
+	GetProxyUrl() *url.URL
+	GetNoProxy() bool
+}
+
+// NewProxyOverrideOptions creates a new ProxyOverrideOptions sending the request through the proxy with the given url
+func NewProxyOverrideOptions(proxyUrlStr string) (*ProxyOverrideOptions, error) {
+	proxyUrl, err := url.Parse(proxyUrlStr)
+	if err != nil {
+		return nil, err
+	}
+	if proxyUrl.Host == "" {
+		return nil, errors.New("proxy url must be absolute: " + proxyUrlStr)
+	}
+	return &ProxyOverrideOptions{ProxyUrl: proxyUrl}, nil
+}
+
+// NewNoProxyOverrideOptions creates a new ProxyOverrideOptions sending the request without any proxy
+func NewNoProxyOverrideOptions() *ProxyOverrideOptions {
+	return &ProxyOverrideOptions{NoProxy: true}
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *ProxyOverrideOptions) GetKey() abs.RequestOptionKey {
+	return proxyOverrideKeyValue
+}
+
+// GetProxyUrl returns the url of the proxy the request is sent through
+func (options *ProxyOverrideOptions) GetProxyUrl() *url.URL {
+	return options.ProxyUrl
+}
+
+// GetNoProxy returns whether the request is sent without any proxy
+func (options *ProxyOverrideOptions) GetNoProxy() bool {
+	return options.NoProxy
+}
+
+// proxyOverridableTransport is implemented by the transports of the library wrapping a *http.Transport,
+// so the proxy of a request can be overridden without losing their TLS, dialer and connection settings
+type proxyOverridableTransport interface {
+	withProxy(proxy func(*nethttp.Request) (*url.URL, error)) (nethttp.RoundTripper, error)
+}
+
+// withProxy returns a copy of the transport sending the requests through the proxy, or without any proxy when nil.
+// Only *http.Transport and the transports of the library wrapping one can be copied.
+func withProxy(transport nethttp.RoundTripper, proxy func(*nethttp.Request) (*url.URL, error)) (nethttp.RoundTripper, error) {
+	switch typed := transport.(type) {
+	case *nethttp.Transport:
+		result := typed.Clone()
+		result.Proxy = proxy
+		return result, nil
+	case proxyOverridableTransport:
+		return typed.withProxy(proxy)
+	}
+	return nil, errors.New("the proxy of the request cannot be overridden, the parent transport must be a *http.Transport or a transport of the library wrapping one")
+}
+
+// getParentTransportForRequest returns the parent transport configured with the proxy of the ProxyOverrideOptions of the request, or the parent transport itself.
+// The parent transport is copied with its settings, an error is returned when it cannot be copied.
+func (pipeline *middlewarePipeline) getParentTransportForRequest(req *nethttp.Request) (nethttp.RoundTripper, error) {
+	reqOption, ok := req.Context().Value(proxyOverrideKeyValue).(proxyOverrideOptionsInt)
+	if !ok || (!reqOption.GetNoProxy() && reqOption.GetProxyUrl() == nil) {
+		return pipeline.transport, nil
+	}
+	key := ""
+	var proxy func(*nethttp.Request) (*url.URL, error)
+	if !reqOption.GetNoProxy() {
+		key = reqOption.GetProxyUrl().String()
+		proxy = nethttp.ProxyURL(reqOption.GetProxyUrl())
+	}
+	if transport, ok := pipeline.proxyTransports.Load(key); ok {
+		return transport.(nethttp.RoundTripper), nil
+	}
+	transport, err := withProxy(pipeline.transport, proxy)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := pipeline.proxyTransports.LoadOrStore(key, transport)
+	return actual.(nethttp.RoundTripper), nil
+}
@@ -0,0 +1,78 @@
+package nethttplibrary
+
+import (
+	"context"
+	"crypto/x509"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItSendsTheRequestThroughTheProxyOverride(t *testing.T) {
+	var proxiedUrls []string
+	proxy := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		proxiedUrls = append(proxiedUrls, req.URL.String())
+		res.WriteHeader(200)
+	}))
+	defer proxy.Close()
+	directCount := 0
+	target := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		directCount++
+		res.WriteHeader(204)
+	}))
+	defer target.Close()
+
+	parent := &nethttp.Transport{Proxy: nethttp.ProxyURL(&url.URL{Scheme: "http", Host: "127.0.0.1:1"})}
+	client := &nethttp.Client{Transport: NewCustomTransportWithParentTransport(parent, NewHeadersInspectionHandler())}
+
+	options, err := NewProxyOverrideOptions(proxy.URL)
+	assert.Nil(t, err)
+	req, _ := nethttp.NewRequestWithContext(context.WithValue(context.Background(), proxyOverrideKeyValue, options), nethttp.MethodGet, "http://example.com/items", nil)
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, []string{"http://example.com/items"}, proxiedUrls)
+
+	req, _ = nethttp.NewRequestWithContext(context.WithValue(context.Background(), proxyOverrideKeyValue, NewNoProxyOverrideOptions()), nethttp.MethodGet, target.URL, nil)
+	resp, err = client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, 204, resp.StatusCode)
+	assert.Equal(t, 1, directCount)
+
+	_, err = NewProxyOverrideOptions("/relative")
+	assert.Error(t, err)
+}
+
+func TestItKeepsTheSettingsOfTheParentTransportWithTheProxyOverride(t *testing.T) {
+	testServer := httptest.NewTLSServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(204)
+	}))
+	defer testServer.Close()
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(testServer.Certificate())
+	serverUrl, _ := url.Parse(testServer.URL)
+	client, err := NewKiotaClientBuilder().WithRootCAs(rootCAs).WithMaxConnsForHost(serverUrl.Hostname(), 2).WithMiddleware(NewHeadersInspectionHandler()).Build()
+	assert.Nil(t, err)
+
+	req, _ := nethttp.NewRequestWithContext(context.WithValue(context.Background(), proxyOverrideKeyValue, NewNoProxyOverrideOptions()), nethttp.MethodGet, testServer.URL, nil)
+	resp, err := client.Do(req)
+	if assert.Nil(t, err) {
+		assert.Equal(t, 204, resp.StatusCode)
+	}
+
+	// the copies of the transport are closed with the client
+	transport := client.Transport.(*customTransport)
+	idleTransport := &idleConnectionsTransport{RoundTripper: nethttp.DefaultTransport}
+	transport.middlewarePipeline.proxyTransports.Store("idle", idleTransport)
+	client.CloseIdleConnections()
+	assert.Equal(t, 1, idleTransport.closed)
+
+	// the defaults aren't used in place of a transport which cannot be copied
+	client = &nethttp.Client{Transport: NewCustomTransportWithParentTransport(&idleConnectionsTransport{RoundTripper: nethttp.DefaultTransport}, NewHeadersInspectionHandler())}
+	req, _ = nethttp.NewRequestWithContext(context.WithValue(context.Background(), proxyOverrideKeyValue, NewNoProxyOverrideOptions()), nethttp.MethodGet, testServer.URL, nil)
+	_, err = client.Do(req)
+	assert.ErrorContains(t, err, "cannot be overridden")
+}
@@ -0,0 +1,184 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+	"net"
+	nethttp "net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const defaultProxyUnhealthyCooldown = 30 * time.Second
+
+// ProxyRotationOptions to use when rotating the requests across proxies.
+type ProxyRotationOptions struct {
+	// Proxies is the list of proxy urls to rotate across
+	Proxies []string
+	// UnhealthyCooldown is the duration during which a proxy is skipped after a connection failure, defaults to 30 seconds
+	UnhealthyCooldown time.Duration
+}
+
+// ProxyRotator selects the proxies in a round-robin fashion, skipping the proxies which recently failed to connect.
+// When all the proxies are unhealthy, the one which failed first is selected.
+type ProxyRotator struct {
+	proxies        []*url.URL
+	unhealthyUntil []time.Time
+	cooldown       time.Duration
+	next           int
+	mutex          sync.Mutex
+}
+
+// NewProxyRotator creates a new ProxyRotator with the given options
+func NewProxyRotator(options ProxyRotationOptions) (*ProxyRotator, error) {
+	if len(options.Proxies) == 0 {
+		return nil, errors.New("at least one proxy is required")
+	}
+	if options.UnhealthyCooldown < 0 {
+		return nil, errors.New("unhealthy cooldown cannot be negative")
+	}
+	cooldown := options.UnhealthyCooldown
+	if cooldown == 0 {
+		cooldown = defaultProxyUnhealthyCooldown
+	}
+	proxies := make([]*url.URL, len(options.Proxies))
+	for i, proxyUrlStr := range options.Proxies {
+		proxyUrl, err := url.Parse(proxyUrlStr)
+		if err != nil {
+			return nil, err
+		}
+		if proxyUrl.Host == "" {
+			return nil, errors.New("proxy url must be absolute: " + proxyUrlStr)
+		}
+		proxies[i] = proxyUrl
+	}
+	return &ProxyRotator{
+		proxies:        proxies,
+		unhealthyUntil: make([]time.Time, len(proxies)),
+		cooldown:       cooldown,
+	}, nil
+}
+
+// Next returns the next proxy to use
+func (rotator *ProxyRotator) Next() *url.URL {
+	rotator.mutex.Lock()
+	defer rotator.mutex.Unlock()
+	now := time.Now()
+	selected := -1
+	for i := 0; i < len(rotator.proxies); i++ {
+		index := (rotator.next + i) % len(rotator.proxies)
+		if !rotator.unhealthyUntil[index].After(now) {
+			selected = index
+			break
+		}
+		if selected < 0 || rotator.unhealthyUntil[index].Before(rotator.unhealthyUntil[selected]) {
+			selected = index
+		}
+	}
+	rotator.next = (selected + 1) % len(rotator.proxies)
+	return rotator.proxies[selected]
+}
+
+// MarkUnhealthy skips the given proxy for the cooldown duration
+func (rotator *ProxyRotator) MarkUnhealthy(proxyUrl *url.URL) {
+	rotator.mutex.Lock()
+	defer rotator.mutex.Unlock()
+	for i, proxy := range rotator.proxies {
+		if proxy == proxyUrl || proxy.String() == proxyUrl.String() {
+			rotator.unhealthyUntil[i] = time.Now().Add(rotator.cooldown)
+		}
+	}
+}
+
+// IsHealthy returns whether the given proxy is currently used by the rotation
+func (rotator *ProxyRotator) IsHealthy(proxyUrl *url.URL) bool {
+	rotator.mutex.Lock()
+	defer rotator.mutex.Unlock()
+	now := time.Now()
+	for i, proxy := range rotator.proxies {
+		if proxy.String() == proxyUrl.String() {
+			return !rotator.unhealthyUntil[i].After(now)
+		}
+	}
+	return false
+}
+
+func (rotator *ProxyRotator) count() int {
+	return len(rotator.proxies)
+}
+
+type proxyRotationContextKey struct{}
+
+// proxyRotationTransport sends the requests through the proxy selected by the rotator and fails over to the next proxy on connection errors
+type proxyRotationTransport struct {
+	transport *nethttp.Transport
+	rotator   *ProxyRotator
+}
+
+func newProxyRotationTransport(transport *nethttp.Transport, rotator *ProxyRotator) *proxyRotationTransport {
+	transport = transport.Clone()
+	transport.Proxy = func(req *nethttp.Request) (*url.URL, error) {
+		proxyUrl, _ := req.Context().Value(proxyRotationContextKey{}).(*url.URL)
+		return proxyUrl, nil
+	}
+	return &proxyRotationTransport{
+		transport: transport,
+		rotator:   rotator,
+	}
+}
+
+// isProxyConnectionError returns whether the error happened while connecting to the proxy.
+// The transport reports the failures to connect to a proxy as proxyconnect operations, the failures of direct connections to the origin are not proxy errors.
+func isProxyConnectionError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "proxyconnect"
+}
+
+// RoundTrip sends the request through the next healthy proxy
+func (transport *proxyRotationTransport) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	for attempt := 1; ; attempt++ {
+		proxyUrl := transport.rotator.Next()
+		resp, err := transport.transport.RoundTrip(req.WithContext(context.WithValue(req.Context(), proxyRotationContextKey{}, proxyUrl)))
+		if err == nil || !isProxyConnectionError(err) {
+			return resp, err
+		}
+		transport.rotator.MarkUnhealthy(proxyUrl)
+		if attempt >= transport.rotator.count() {
+			return nil, err
+		}
+		if req.Body != nil && req.Body != nethttp.NoBody {
+			if req.GetBody == nil {
+				return nil, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// withProxy returns a copy of the transport sending the requests through the proxy instead of rotating them
+func (transport *proxyRotationTransport) withProxy(proxy func(*nethttp.Request) (*url.URL, error)) (nethttp.RoundTripper, error) {
+	return withProxy(transport.transport, proxy)
+}
+
+// GetClientWithProxyRotation creates a new default net/http client rotating the requests across the given proxies and default middleware.
+// Not providing any middleware would result in having default middleware provided
+func GetClientWithProxyRotation(options ProxyRotationOptions, middleware ...Middleware) (*nethttp.Client, error) {
+	rotator, err := NewProxyRotator(options)
+	if err != nil {
+		return nil, err
+	}
+	if len(middleware) == 0 {
+		middleware = GetDefaultMiddlewares()
+	}
+	client := getDefaultClientWithoutMiddleware()
+	transport := NewCustomTransportWithParentTransport(newProxyRotationTransport(getDefaultHttpTransport(), rotator), middleware...)
+	transport.featureUsage |= ProxyEnabledFeatureUsageFlag
+	client.Transport = transport
+	return client, nil
+}
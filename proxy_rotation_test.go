@@ -0,0 +1,89 @@
+package nethttplibrary
+
+import (
+	"net"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getClosedProxyUrl(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	return "http://" + address
+}
+
+func TestProxyRotatorRoundRobinsHealthyProxies(t *testing.T) {
+	rotator, err := NewProxyRotator(ProxyRotationOptions{
+		Proxies: []string{"http://proxy1.contoso.com", "http://proxy2.contoso.com", "http://proxy3.contoso.com"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "proxy1.contoso.com", rotator.Next().Host)
+	assert.Equal(t, "proxy2.contoso.com", rotator.Next().Host)
+	unhealthy, _ := url.Parse("http://proxy3.contoso.com")
+	rotator.MarkUnhealthy(unhealthy)
+	assert.False(t, rotator.IsHealthy(unhealthy))
+	assert.Equal(t, "proxy1.contoso.com", rotator.Next().Host)
+	assert.Equal(t, "proxy2.contoso.com", rotator.Next().Host)
+	assert.Equal(t, "proxy1.contoso.com", rotator.Next().Host)
+}
+
+func TestProxyRotatorUsesTheOldestUnhealthyProxyWhenNoneIsHealthy(t *testing.T) {
+	rotator, err := NewProxyRotator(ProxyRotationOptions{
+		Proxies:           []string{"http://proxy1.contoso.com", "http://proxy2.contoso.com"},
+		UnhealthyCooldown: time.Minute,
+	})
+	assert.Nil(t, err)
+	proxy2, _ := url.Parse("http://proxy2.contoso.com")
+	rotator.MarkUnhealthy(proxy2)
+	time.Sleep(time.Millisecond)
+	proxy1, _ := url.Parse("http://proxy1.contoso.com")
+	rotator.MarkUnhealthy(proxy1)
+	assert.Equal(t, "proxy2.contoso.com", rotator.Next().Host)
+}
+
+func TestProxyRotatorValidatesTheOptions(t *testing.T) {
+	_, err := NewProxyRotator(ProxyRotationOptions{})
+	assert.Error(t, err)
+	_, err = NewProxyRotator(ProxyRotationOptions{Proxies: []string{"proxy.contoso.com"}})
+	assert.Error(t, err)
+	_, err = NewProxyRotator(ProxyRotationOptions{Proxies: []string{"http://proxy.contoso.com"}, UnhealthyCooldown: -1})
+	assert.Error(t, err)
+}
+
+func TestItFailsOverToTheNextProxy(t *testing.T) {
+	requestCount := 0
+	proxyServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		requestCount++
+		res.WriteHeader(200)
+	}))
+	defer proxyServer.Close()
+	closedProxy := getClosedProxyUrl(t)
+	client, err := GetClientWithProxyRotation(ProxyRotationOptions{
+		Proxies: []string{closedProxy, proxyServer.URL},
+	}, NewCompressionHandler())
+	assert.Nil(t, err)
+
+	resp, err := client.Post("http://graph.microsoft.com/v1.0/me", "text/plain", strings.NewReader("content"))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	resp, err = client.Get("http://graph.microsoft.com/v1.0/me")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 2, requestCount)
+
+	transport := client.Transport.(*customTransport)
+	rotator := transport.middlewarePipeline.transport.(*proxyRotationTransport).rotator
+	closedProxyUrl, _ := url.Parse(closedProxy)
+	assert.False(t, rotator.IsHealthy(closedProxyUrl))
+	assert.Equal(t, ProxyEnabledFeatureUsageFlag, transport.featureUsage)
+}
@@ -0,0 +1,124 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+)
+
+const msRateLimitRemainingHeaderPrefix = "X-Ms-Ratelimit-Remaining-"
+
+// minRateLimitResetEpoch is the smallest reset value considered as a unix timestamp rather than as a number of seconds
+const minRateLimitResetEpoch = 1000000000
+
+// RateLimitInfo describes the rate limit state advertised by a response
+type RateLimitInfo struct {
+	// Limit is the quota of the current window (RateLimit-Limit, X-RateLimit-Limit or RateLimit limit=), -1 when the response didn't advertise it
+	Limit int64
+	// Remaining is the quota left in the current window (RateLimit-Remaining, X-RateLimit-Remaining or RateLimit remaining=),
+	// or the lowest of the x-ms-ratelimit-remaining-* headers, -1 when the response didn't advertise it
+	Remaining int64
+	// Reset is the time the quota is reset at (RateLimit-Reset, X-RateLimit-Reset or RateLimit reset=), zero when the response didn't advertise it
+	Reset time.Time
+	// ServiceRemaining holds the quotas left advertised by the x-ms-ratelimit-remaining-* headers, keyed by the lowercase header suffix (e.g. subscription-reads)
+	ServiceRemaining map[string]int64
+}
+
+// RateLimitInspectionOptions is a request option which is filled with the rate limit state advertised by the responses received for the request,
+// so callers can pace their workloads with the data of the service
+type RateLimitInspectionOptions struct {
+	mutex      sync.Mutex
+	info       RateLimitInfo
+	advertised bool
+}
+
+// NewRateLimitInspectionOptions creates a new RateLimitInspectionOptions
+func NewRateLimitInspectionOptions() *RateLimitInspectionOptions {
+	return &RateLimitInspectionOptions{}
+}
+
+var rateLimitInspectionKeyValue = abs.RequestOptionKey{
+	Key: "nethttplibrary.RateLimitInspectionOptions",
+}
+
+// GetKey returns the key for the RateLimitInspectionOptions
+func (o *RateLimitInspectionOptions) GetKey() abs.RequestOptionKey {
+	return rateLimitInspectionKeyValue
+}
+
+// GetRateLimit returns the rate limit state advertised by the last response which had rate limit headers, and false when no response had any
+func (o *RateLimitInspectionOptions) GetRateLimit() (RateLimitInfo, bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	info := o.info
+	if info.ServiceRemaining != nil {
+		info.ServiceRemaining = make(map[string]int64, len(o.info.ServiceRemaining))
+		for scope, remaining := range o.info.ServiceRemaining {
+			info.ServiceRemaining[scope] = remaining
+		}
+	}
+	return info, o.advertised
+}
+
+func (o *RateLimitInspectionOptions) set(info RateLimitInfo) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.info = info
+	o.advertised = true
+}
+
+// getRateLimitInfo returns the rate limit state advertised by the headers, and false when the headers don't advertise any
+func getRateLimitInfo(header nethttp.Header, now time.Time) (RateLimitInfo, bool) {
+	info := RateLimitInfo{Limit: -1, Remaining: -1}
+	advertised := false
+	if limit, ok := getRateLimitValue(header, "Limit", "limit"); ok {
+		info.Limit = limit
+		advertised = true
+	}
+	if reset, ok := getRateLimitValue(header, "Reset", "reset", "t"); ok && reset >= 0 {
+		if reset >= minRateLimitResetEpoch {
+			info.Reset = time.Unix(reset, 0)
+		} else {
+			info.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+		advertised = true
+	}
+	for name, values := range header {
+		if len(values) == 0 || !strings.HasPrefix(name, msRateLimitRemainingHeaderPrefix) {
+			continue
+		}
+		remaining, err := strconv.ParseInt(strings.TrimSpace(values[0]), 10, 64)
+		if err != nil {
+			continue
+		}
+		if info.ServiceRemaining == nil {
+			info.ServiceRemaining = make(map[string]int64)
+		}
+		info.ServiceRemaining[strings.ToLower(strings.TrimPrefix(name, msRateLimitRemainingHeaderPrefix))] = remaining
+		if info.Remaining < 0 || remaining < info.Remaining {
+			info.Remaining = remaining
+		}
+		advertised = true
+	}
+	if remaining, ok := getRateLimitRemaining(header); ok {
+		info.Remaining = remaining
+		advertised = true
+	}
+	return info, advertised
+}
+
+// inspectRateLimit records the rate limit state advertised by the response in the rate limit inspection options of the context
+func inspectRateLimit(ctx context.Context, resp *nethttp.Response) {
+	options, ok := ctx.Value(rateLimitInspectionKeyValue).(*RateLimitInspectionOptions)
+	if !ok {
+		return
+	}
+	if info, advertised := getRateLimitInfo(resp.Header, time.Now()); advertised {
+		options.set(info)
+	}
+}
@@ -0,0 +1,59 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItInspectsTheRateLimitState(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.Header().Set("RateLimit-Limit", "100")
+		res.Header().Set("RateLimit-Remaining", "42")
+		res.Header().Set("RateLimit-Reset", "30")
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	client := GetDefaultClient()
+	options := NewRateLimitInspectionOptions()
+	_, advertised := options.GetRateLimit()
+	assert.False(t, advertised)
+	req, _ := nethttp.NewRequestWithContext(context.WithValue(context.Background(), rateLimitInspectionKeyValue, options), nethttp.MethodGet, testServer.URL, nil)
+
+	start := time.Now()
+	_, err := client.Do(req)
+	assert.Nil(t, err)
+	info, advertised := options.GetRateLimit()
+	assert.True(t, advertised)
+	assert.Equal(t, int64(100), info.Limit)
+	assert.Equal(t, int64(42), info.Remaining)
+	assert.WithinDuration(t, start.Add(30*time.Second), info.Reset, 5*time.Second)
+	assert.Nil(t, info.ServiceRemaining)
+}
+
+func TestItParsesTheRateLimitHeaderVariants(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	header := nethttp.Header{}
+	header.Set("x-ms-ratelimit-remaining-subscription-reads", "11999")
+	header.Set("x-ms-ratelimit-remaining-tenant-reads", "250")
+	header.Set("X-RateLimit-Reset", "1704067260")
+	info, advertised := getRateLimitInfo(header, now)
+	assert.True(t, advertised)
+	assert.Equal(t, int64(-1), info.Limit)
+	assert.Equal(t, int64(250), info.Remaining)
+	assert.Equal(t, now.Add(time.Minute), info.Reset.UTC())
+	assert.Equal(t, map[string]int64{"subscription-reads": 11999, "tenant-reads": 250}, info.ServiceRemaining)
+
+	header = nethttp.Header{}
+	header.Set("RateLimit", "limit=100, remaining=5, reset=10")
+	info, advertised = getRateLimitInfo(header, now)
+	assert.True(t, advertised)
+	assert.Equal(t, RateLimitInfo{Limit: 100, Remaining: 5, Reset: now.Add(10 * time.Second)}, info)
+
+	_, advertised = getRateLimitInfo(nethttp.Header{}, now)
+	assert.False(t, advertised)
+}
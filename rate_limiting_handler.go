@@ -0,0 +1,185 @@
+package nethttplibrary
+
+import (
+	"errors"
+	nethttp "net/http"
+	"sync"
+	"time"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RateLimitingHandler limits the rate of the requests on the client side with token buckets.
+// The requests are delayed until a token of their bucket is available, a key extractor can maintain independent buckets (e.g. per tenant, per user or per route).
+type RateLimitingHandler struct {
+	options        RateLimitingHandlerOptions
+	mutex          sync.Mutex
+	buckets        map[string]*rateLimitingBucket
+	sweepThreshold int
+}
+
+// RateLimitingHandlerOptions to use when limiting the rate of the requests.
+type RateLimitingHandlerOptions struct {
+	// RequestsPerSecond is the rate the buckets are refilled at
+	RequestsPerSecond float64
+	// Burst is the number of requests a bucket lets through without delay, at least 1
+	Burst int
+	// KeyExtractor returns the key of the bucket of the request, all the requests share the same bucket when nil
+	KeyExtractor func(req *nethttp.Request) string
+	// Clock provides the time and waits for the tokens, the system clock is used when nil
+	Clock Clock
+}
+
+const minRateLimitingSweepThreshold = 1024
+
+var rateLimitingKeyValue = abs.RequestOptionKey{
+	Key: "RateLimitingHandler",
+}
+
+// NewRateLimitingHandlerOptions creates a new RateLimitingHandlerOptions with the given rate and burst, all the requests share the same bucket
+func NewRateLimitingHandlerOptions(requestsPerSecond float64, burst int) *RateLimitingHandlerOptions {
+	return &RateLimitingHandlerOptions{
+		RequestsPerSecond: requestsPerSecond,
+		Burst:             burst,
+	}
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *RateLimitingHandlerOptions) GetKey() abs.RequestOptionKey {
+	return rateLimitingKeyValue
+}
+
+// GetRequestsPerSecond returns the rate the buckets are refilled at
+func (options *RateLimitingHandlerOptions) GetRequestsPerSecond() float64 {
+	return options.RequestsPerSecond
+}
+
+// GetBurst returns the number of requests a bucket lets through without delay
+func (options *RateLimitingHandlerOptions) GetBurst() int {
+	return options.Burst
+}
+
+// GetKeyExtractor returns the function returning the key of the bucket of a request
+func (options *RateLimitingHandlerOptions) GetKeyExtractor() func(req *nethttp.Request) string {
+	return options.KeyExtractor
+}
+
+// GetClock returns the clock providing the time and waiting for the tokens
+func (options *RateLimitingHandlerOptions) GetClock() Clock {
+	return options.Clock
+}
+
+// NewRateLimitingHandler creates a new RateLimitingHandler letting the given rate of requests through, with a single bucket
+func NewRateLimitingHandler(requestsPerSecond float64, burst int) (*RateLimitingHandler, error) {
+	return NewRateLimitingHandlerWithOptions(*NewRateLimitingHandlerOptions(requestsPerSecond, burst))
+}
+
+// NewRateLimitingHandlerWithOptions creates a new RateLimitingHandler with the given options
+func NewRateLimitingHandlerWithOptions(options RateLimitingHandlerOptions) (*RateLimitingHandler, error) {
+	if options.RequestsPerSecond <= 0 {
+		return nil, errors.New("RequestsPerSecond must be greater than 0")
+	}
+	if options.Burst < 1 {
+		return nil, errors.New("Burst must be greater than 0")
+	}
+	return &RateLimitingHandler{
+		options:        options,
+		buckets:        make(map[string]*rateLimitingBucket),
+		sweepThreshold: minRateLimitingSweepThreshold,
+	}, nil
+}
+
+// rateLimitingBucket is a token bucket, the tokens go negative when requests are waiting for them
+type rateLimitingBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// refill adds the tokens accumulated since the last update, up to the burst
+func (bucket *rateLimitingBucket) refill(now time.Time, requestsPerSecond float64, burst int) {
+	if elapsed := now.Sub(bucket.updated); elapsed > 0 {
+		bucket.tokens += elapsed.Seconds() * requestsPerSecond
+		bucket.updated = now
+	}
+	if bucket.tokens > float64(burst) {
+		bucket.tokens = float64(burst)
+	}
+}
+
+// reserve takes a token from the bucket of the key and returns how long the request needs to wait for it
+func (middleware *RateLimitingHandler) reserve(key string, now time.Time) time.Duration {
+	middleware.mutex.Lock()
+	defer middleware.mutex.Unlock()
+	bucket, ok := middleware.buckets[key]
+	if !ok {
+		middleware.sweepLocked(now)
+		bucket = &rateLimitingBucket{tokens: float64(middleware.options.Burst), updated: now}
+		middleware.buckets[key] = bucket
+	}
+	bucket.refill(now, middleware.options.RequestsPerSecond, middleware.options.Burst)
+	bucket.tokens--
+	if bucket.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-bucket.tokens / middleware.options.RequestsPerSecond * float64(time.Second))
+}
+
+// cancel gives back the token reserved by a request which stopped waiting for it
+func (middleware *RateLimitingHandler) cancel(key string) {
+	middleware.mutex.Lock()
+	defer middleware.mutex.Unlock()
+	if bucket, ok := middleware.buckets[key]; ok {
+		bucket.tokens++
+	}
+}
+
+// sweepLocked removes the full buckets once there are many of them, a full bucket behaves like a new one
+func (middleware *RateLimitingHandler) sweepLocked(now time.Time) {
+	if len(middleware.buckets) < middleware.sweepThreshold {
+		return
+	}
+	for key, bucket := range middleware.buckets {
+		bucket.refill(now, middleware.options.RequestsPerSecond, middleware.options.Burst)
+		if bucket.tokens >= float64(middleware.options.Burst) {
+			delete(middleware.buckets, key)
+		}
+	}
+	middleware.sweepThreshold = 2 * len(middleware.buckets)
+	if middleware.sweepThreshold < minRateLimitingSweepThreshold {
+		middleware.sweepThreshold = minRateLimitingSweepThreshold
+	}
+}
+
+// Intercept implements the interface and delays the request until a token of its bucket is available.
+func (middleware *RateLimitingHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	key := ""
+	if middleware.options.KeyExtractor != nil {
+		key = middleware.options.KeyExtractor(req)
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	ctx := req.Context()
+	var span trace.Span
+	if obsOptions != nil {
+		ctx, span = getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "RateLimitingHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.rate_limiting.enable", true))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	clock := getClock(&middleware.options)
+	delay := middleware.reserve(key, clock.Now())
+	if span != nil {
+		span.SetAttributes(attribute.Int64("com.microsoft.kiota.handler.rate_limiting.delay_ms", delay.Milliseconds()))
+	}
+	if delay > 0 {
+		if err := clock.Sleep(req.Context(), delay); err != nil {
+			middleware.cancel(key)
+			if span != nil {
+				span.RecordError(err)
+			}
+			return nil, err
+		}
+	}
+	return pipeline.Next(req, middlewareIndex)
+}
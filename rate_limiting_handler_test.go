@@ -0,0 +1,81 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingPipeline struct {
+	count int
+}
+
+func (pipeline *countingPipeline) Next(req *nethttp.Request, middlewareIndex int) (*nethttp.Response, error) {
+	pipeline.count++
+	return &nethttp.Response{StatusCode: 200, Body: nethttp.NoBody}, nil
+}
+
+func TestItValidatesTheRateLimitingOptions(t *testing.T) {
+	_, err := NewRateLimitingHandler(0, 1)
+	assert.Error(t, err)
+	_, err = NewRateLimitingHandler(1, 0)
+	assert.Error(t, err)
+}
+
+func TestItMaintainsIndependentBucketsPerKey(t *testing.T) {
+	handler, err := NewRateLimitingHandlerWithOptions(RateLimitingHandlerOptions{
+		RequestsPerSecond: 10,
+		Burst:             1,
+		KeyExtractor: func(req *nethttp.Request) string {
+			return req.Header.Get("X-Tenant")
+		},
+	})
+	assert.Nil(t, err)
+	pipeline := &countingPipeline{}
+	send := func(tenant string) time.Duration {
+		req, _ := nethttp.NewRequest(nethttp.MethodGet, "https://example.com/items", nil)
+		req.Header.Set("X-Tenant", tenant)
+		start := time.Now()
+		_, err := handler.Intercept(pipeline, 0, req)
+		assert.Nil(t, err)
+		return time.Since(start)
+	}
+	assert.Less(t, send("contoso"), 50*time.Millisecond)
+	assert.Less(t, send("fabrikam"), 50*time.Millisecond)
+	assert.GreaterOrEqual(t, send("contoso"), 50*time.Millisecond)
+	assert.Equal(t, 3, pipeline.count)
+}
+
+func TestItStopsWaitingForATokenWhenTheContextIsDone(t *testing.T) {
+	handler, err := NewRateLimitingHandler(0.1, 1)
+	assert.Nil(t, err)
+	pipeline := &countingPipeline{}
+	req, _ := nethttp.NewRequest(nethttp.MethodGet, "https://example.com/items", nil)
+	_, err = handler.Intercept(pipeline, 0, req)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ = nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, "https://example.com/items", nil)
+	_, err = handler.Intercept(pipeline, 0, req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, pipeline.count)
+	assert.InDelta(t, 0, handler.buckets[""].tokens, 0.01)
+}
+
+func TestItWaitsForTheTokensOnTheClock(t *testing.T) {
+	clock := newFakeClock()
+	handler, err := NewRateLimitingHandlerWithOptions(RateLimitingHandlerOptions{RequestsPerSecond: 2, Burst: 1, Clock: clock})
+	assert.Nil(t, err)
+	pipeline := &countingPipeline{}
+	for i := 0; i < 3; i++ {
+		req, _ := nethttp.NewRequest(nethttp.MethodGet, "https://example.com/items", nil)
+		_, err = handler.Intercept(pipeline, 0, req)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 3, pipeline.count)
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, 500 * time.Millisecond}, clock.getSleeps())
+}
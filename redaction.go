@@ -0,0 +1,176 @@
+package nethttplibrary
+
+import (
+	"errors"
+	nethttp "net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const defaultRedactionMask = "REDACTED"
+
+// Redactor removes the sensitive data (credentials, tokens, secrets in query parameters...) from the diagnostics emitted by the library
+// so secrets never leak from the logs, traces or recordings
+type Redactor struct {
+	// AllowedHeaders are the headers whose values are kept as is, all the other headers are redacted when the list isn't empty
+	AllowedHeaders []string
+	// DeniedHeaders are the headers whose values are always redacted
+	DeniedHeaders []string
+	// QueryParameters are the query parameters whose values are redacted
+	QueryParameters []string
+	// Mask is the value replacing the redacted data, defaults to REDACTED
+	Mask string
+}
+
+// NewRedactor creates a new Redactor redacting the usual credential headers, query parameters and tokens
+func NewRedactor() *Redactor {
+	return &Redactor{
+		DeniedHeaders: []string{
+			"Authorization",
+			"Proxy-Authorization",
+			"Cookie",
+			"Set-Cookie",
+			"X-Api-Key",
+			"Api-Key",
+			"Ocp-Apim-Subscription-Key",
+		},
+		QueryParameters: []string{
+			"access_token",
+			"api_key",
+			"api-key",
+			"client_secret",
+			"code",
+			"key",
+			"password",
+			"refresh_token",
+			"sig",
+			"signature",
+			"token",
+		},
+		Mask: defaultRedactionMask,
+	}
+}
+
+var (
+	bearerTokenRegex = regexp.MustCompile(`(?i)\b(bearer|basic|digest|pop)\s+[A-Za-z0-9\-._~+/=,"]+`)
+	jwtRegex         = regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)
+)
+
+func (r *Redactor) getMask() string {
+	if r.Mask == "" {
+		return defaultRedactionMask
+	}
+	return r.Mask
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldRedactHeader returns whether the value of the header with the given name should be redacted
+func (r *Redactor) ShouldRedactHeader(name string) bool {
+	if containsFold(r.DeniedHeaders, name) {
+		return true
+	}
+	return len(r.AllowedHeaders) > 0 && !containsFold(r.AllowedHeaders, name)
+}
+
+// RedactHeader returns the redacted value of the header, the authentication scheme of the credential headers is kept (e.g. Bearer REDACTED)
+func (r *Redactor) RedactHeader(name string, value string) string {
+	if !r.ShouldRedactHeader(name) {
+		return r.RedactToken(value)
+	}
+	if scheme, _, found := strings.Cut(value, " "); found && bearerTokenRegex.MatchString(value) {
+		return scheme + " " + r.getMask()
+	}
+	return r.getMask()
+}
+
+// RedactHeaders returns a copy of the headers with the sensitive values redacted
+func (r *Redactor) RedactHeaders(headers nethttp.Header) nethttp.Header {
+	result := make(nethttp.Header, len(headers))
+	for name, values := range headers {
+		redacted := make([]string, len(values))
+		for i, value := range values {
+			redacted[i] = r.RedactHeader(name, value)
+		}
+		result[name] = redacted
+	}
+	return result
+}
+
+// RedactUrl returns the URL with the values of the sensitive query parameters and the user information redacted
+func (r *Redactor) RedactUrl(value *url.URL) string {
+	if value == nil {
+		return ""
+	}
+	redacted := *value
+	if redacted.User != nil {
+		redacted.User = url.User(r.getMask())
+	}
+	if redacted.RawQuery != "" {
+		parameters := strings.Split(redacted.RawQuery, "&")
+		for i, parameter := range parameters {
+			name, _, found := strings.Cut(parameter, "=")
+			decodedName, err := url.QueryUnescape(name)
+			if err != nil {
+				decodedName = name
+			}
+			if found && containsFold(r.QueryParameters, decodedName) {
+				parameters[i] = name + "=" + r.getMask()
+			}
+		}
+		redacted.RawQuery = strings.Join(parameters, "&")
+	}
+	return redacted.String()
+}
+
+// RedactUrlString parses and redacts the URL, the whole value is redacted when it cannot be parsed
+func (r *Redactor) RedactUrlString(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return r.getMask()
+	}
+	return r.RedactUrl(parsed)
+}
+
+// RedactToken masks the tokens (e.g. JSON web tokens, bearer credentials) found in the value
+func (r *Redactor) RedactToken(value string) string {
+	value = bearerTokenRegex.ReplaceAllString(value, "$1 "+r.getMask())
+	return jwtRegex.ReplaceAllString(value, r.getMask())
+}
+
+// redactorOptionsInt is implemented by the observability options which can provide a redactor
+type redactorOptionsInt interface {
+	GetRedactor() *Redactor
+}
+
+var defaultRedactor = NewRedactor()
+
+// getRedactor returns the redactor of the observability options of the request or the default one
+func getRedactor(req *nethttp.Request) *Redactor {
+	if obsOptions, ok := GetObservabilityOptionsFromRequest(req).(redactorOptionsInt); ok && obsOptions.GetRedactor() != nil {
+		return obsOptions.GetRedactor()
+	}
+	return defaultRedactor
+}
+
+// RedactError returns the message of the error with the URL of the request and the tokens redacted
+func (r *Redactor) RedactError(err error) string {
+	if err == nil {
+		return ""
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		redacted := *urlErr
+		redacted.URL = r.RedactUrlString(urlErr.URL)
+		return r.RedactToken(redacted.Error())
+	}
+	return r.RedactToken(err.Error())
+}
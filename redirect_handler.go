@@ -9,7 +9,6 @@ import (
 	"strings"
 
 	abs "github.com/microsoft/kiota-abstractions-go"
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -91,11 +90,12 @@ func (middleware RedirectHandler) Intercept(pipeline Pipeline, middlewareIndex i
 	var observabilityName string
 	if obsOptions != nil {
 		observabilityName = obsOptions.GetTracerInstrumentationName()
-		ctx, span = otel.GetTracerProvider().Tracer(observabilityName).Start(ctx, "RedirectHandler_Intercept")
+		ctx, span = getTracerProvider(req.Context()).Tracer(observabilityName).Start(ctx, "RedirectHandler_Intercept")
 		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.redirect.enable", true))
 		defer span.End()
 		req = req.WithContext(ctx)
 	}
+	req = RegisterFeatureUsage(req, RedirectHandlerEnabledFeatureUsageFlag)
 	response, err := pipeline.Next(req, middlewareIndex)
 	if err != nil {
 		return response, err
@@ -114,11 +114,26 @@ func (middleware RedirectHandler) redirectRequest(ctx context.Context, pipeline
 		shouldRedirect {
 		redirectCount++
 		redirectRequest, err := middleware.getRedirectRequest(req, response)
+		if err == nil {
+			err = checkAllowedHost(redirectRequest)
+		}
 		if err != nil {
-			return response, err
+			if response.Body != nil {
+				response.Body.Close()
+			}
+			return nil, err
+		}
+		redirectAttributes := []attribute.KeyValue{
+			attribute.Int("com.microsoft.kiota.handler.redirect.count", redirectCount),
+			httpResponseStatusCodeAttribute.Int(response.StatusCode),
+		}
+		if obsOptions := GetObservabilityOptionsFromRequest(req); obsOptions != nil && obsOptions.GetIncludeEUIIAttributes() {
+			redirectAttributes = append(redirectAttributes, redirectLocationAttribute.String(getRedactor(req).RedactUrl(redirectRequest.URL)))
 		}
+		emitHttpEvent(req, InfoLogSeverity, RequestRedirectLogEventName, "Following redirect", redirectAttributes...)
 		if observabilityName != "" {
-			ctx, span := otel.GetTracerProvider().Tracer(observabilityName).Start(ctx, "RedirectHandler_Intercept - redirect "+fmt.Sprint(redirectCount))
+			ctx, span := getTracerProvider(req.Context()).Tracer(observabilityName).Start(ctx, "RedirectHandler_Intercept - redirect "+fmt.Sprint(redirectCount))
+			span = withLegacyAttributes(span, GetObservabilityOptionsFromRequest(req))
 			span.SetAttributes(attribute.Int("com.microsoft.kiota.handler.redirect.count", redirectCount),
 				httpResponseStatusCodeAttribute.Int(response.StatusCode),
 			)
@@ -174,6 +189,11 @@ func (middleware RedirectHandler) getRedirectRequest(request *nethttp.Request, r
 		result.Header.Del("Content-Type")
 		result.Header.Del("Content-Length")
 		result.Body = nil
+		result.GetBody = nil
+		result.ContentLength = 0
+	} else if !rewindRequestBody(result) {
+		// the body was consumed by the first request and would be sent empty
+		return nil, &RequestBodyNotReplayableError{Method: request.Method, Url: request.URL.String()}
 	}
 	return result, nil
 }
@@ -1,11 +1,14 @@
 package nethttplibrary
 
 import (
+	"errors"
+	"io"
 	nethttp "net/http"
 	httptest "net/http/httptest"
 	testing "testing"
 
 	"strconv"
+	"strings"
 
 	assert "github.com/stretchr/testify/assert"
 )
@@ -113,3 +116,47 @@ func TestItStripsAuthorizationHeaderOnDifferentHost(t *testing.T) {
 	assert.Equal(t, "www.bing.com", result.Host)
 	assert.Equal(t, "", result.Header.Get("Authorization"))
 }
+
+func TestItReplaysTheBodyOnTemporaryRedirect(t *testing.T) {
+	var receivedBodies []string
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		body, _ := io.ReadAll(req.Body)
+		receivedBodies = append(receivedBodies, req.Method+" "+req.URL.Path+" "+string(body))
+		if req.URL.Path == "/" {
+			res.Header().Set("Location", "/redirected")
+			res.WriteHeader(307)
+			return
+		}
+		res.WriteHeader(200)
+	}))
+	defer func() { testServer.Close() }()
+	handler := NewRedirectHandler()
+	req, err := nethttp.NewRequest(nethttp.MethodPost, testServer.URL+"/", strings.NewReader("payload"))
+	if err != nil {
+		t.Error(err)
+	}
+	resp, err := handler.Intercept(newNoopPipeline(), 0, req)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, []string{"POST / payload", "POST /redirected payload"}, receivedBodies)
+}
+
+func TestItFailsToRedirectBodiesThatCannotBeReplayed(t *testing.T) {
+	requestCount := 0
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		requestCount++
+		res.Header().Set("Location", "/redirected")
+		res.WriteHeader(308)
+	}))
+	defer func() { testServer.Close() }()
+	handler := NewRedirectHandler()
+	req, err := nethttp.NewRequest(nethttp.MethodPut, testServer.URL, &onlyReader{reader: strings.NewReader("payload")})
+	if err != nil {
+		t.Error(err)
+	}
+	resp, err := handler.Intercept(newNoopPipeline(), 0, req)
+	assert.Nil(t, resp)
+	var replayErr *RequestBodyNotReplayableError
+	assert.True(t, errors.As(err, &replayErr))
+	assert.Equal(t, 1, requestCount)
+}
@@ -0,0 +1,58 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+)
+
+// singleValueHeaders are the headers which can only be sent once, the first value is used when multiple values are set
+var singleValueHeaders = map[string]bool{
+	"Authorization":  true,
+	"Content-Length": true,
+	"Content-Type":   true,
+	"Host":           true,
+}
+
+// setRequestHeaders adds the headers of the request information to the native request.
+// The header names are normalized to their canonical form so case variants are merged, every value of multi-valued headers is sent,
+// and the values are sorted since the request headers don't preserve their insertion order.
+func setRequestHeaders(request *nethttp.Request, headers *abs.RequestHeaders) {
+	if headers == nil {
+		return
+	}
+	keys := headers.ListKeys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(key))
+		values := headers.Get(key)
+		sort.Strings(values)
+		for _, value := range values {
+			value = strings.TrimSpace(value)
+			if value == "" || containsHeaderValue(request.Header[name], value) {
+				continue
+			}
+			if singleValueHeaders[name] && len(request.Header[name]) > 0 {
+				break
+			}
+			request.Header.Add(name, value)
+		}
+	}
+	// net/http sends the Host field of the request and ignores the header
+	if host := request.Header.Get("Host"); host != "" {
+		request.Host = host
+		request.Header.Del("Host")
+	}
+}
+
+func containsHeaderValue(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
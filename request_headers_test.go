@@ -0,0 +1,37 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItNormalizesTheRequestHeaders(t *testing.T) {
+	headers := abs.NewRequestHeaders()
+	headers.Add("content-type", "application/json")
+	headers.Add("CONTENT-TYPE", "text/plain")
+	headers.Add("accept", "text/plain", "application/json")
+	headers.Add("x-custom", " value ")
+	headers.Add("Host", "graph.contoso.com")
+	request, _ := nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com", nil)
+
+	setRequestHeaders(request, headers)
+	assert.Equal(t, []string{"application/json"}, request.Header["Content-Type"])
+	assert.Equal(t, []string{"application/json", "text/plain"}, request.Header["Accept"])
+	assert.Equal(t, []string{"value"}, request.Header["X-Custom"])
+	assert.Equal(t, "graph.contoso.com", request.Host)
+	assert.Empty(t, request.Header.Get("Host"))
+}
+
+func TestItKeepsTheExistingRequestHeaders(t *testing.T) {
+	headers := abs.NewRequestHeaders()
+	headers.Add("Accept", "application/json")
+	request, _ := nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com", nil)
+	request.Header.Add("Accept", "application/json")
+
+	setRequestHeaders(request, headers)
+	setRequestHeaders(request, nil)
+	assert.Equal(t, []string{"application/json"}, request.Header["Accept"])
+}
@@ -0,0 +1,30 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+)
+
+// WithRequestOptions returns a copy of the context holding the given request options (retry, compression, observability...),
+// so they apply to the requests sent with that context in place of the options of the middlewares.
+func WithRequestOptions(ctx context.Context, options ...abs.RequestOption) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for _, option := range options {
+		if option != nil {
+			ctx = context.WithValue(ctx, option.GetKey(), option)
+		}
+	}
+	return ctx
+}
+
+// AddOptionsToRequest returns a shallow copy of the request with the given request options added to its context.
+func AddOptionsToRequest(req *nethttp.Request, options ...abs.RequestOption) *nethttp.Request {
+	if req == nil || len(options) == 0 {
+		return req
+	}
+	return req.WithContext(WithRequestOptions(req.Context(), options...))
+}
@@ -0,0 +1,37 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItAddsTheRequestOptionsToTheContext(t *testing.T) {
+	retryOptions := &RetryHandlerOptions{MaxRetries: 1}
+	compressionOptions := NewCompressionOptions(false)
+	ctx := WithRequestOptions(context.Background(), retryOptions, &compressionOptions, nil)
+
+	assert.Equal(t, retryOptions, ctx.Value(retryKeyValue))
+	assert.Equal(t, &compressionOptions, ctx.Value(compressKey))
+	assert.NotNil(t, WithRequestOptions(nil))
+}
+
+func TestItAddsTheRequestOptionsToTheRequest(t *testing.T) {
+	var userAgent string
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		userAgent = req.Header.Get("User-Agent")
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	client := GetDefaultClient(NewUserAgentHandler())
+	req, _ := nethttp.NewRequest(nethttp.MethodGet, testServer.URL, nil)
+	req = AddOptionsToRequest(req, &UserAgentHandlerOptions{Enabled: true, ProductName: "contoso", ProductVersion: "1.0"})
+
+	_, err := client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "contoso/1.0", userAgent)
+	assert.Equal(t, req, AddOptionsToRequest(req))
+}
@@ -0,0 +1,36 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type operationSpanKey struct{}
+
+// withOperationSpan returns a context carrying the span of the request adapter operation (e.g. Send - /users/{user-id})
+func withOperationSpan(ctx context.Context, span trace.Span) context.Context {
+	return context.WithValue(ctx, operationSpanKey{}, span)
+}
+
+// GetSpanFromRequest returns the span of the request adapter operation sending the request, or the active span of the request context when
+// the request is not sent with the request adapter, as well as the tracer configured with the observability options of the request.
+// Custom middlewares can use them to add attributes and events or to start child spans consistently with the built-in handlers.
+// The span is non-recording and the tracer is a no-op tracer when the request doesn't carry any observability options.
+func GetSpanFromRequest(req *nethttp.Request) (trace.Span, trace.Tracer) {
+	if req == nil {
+		return trace.SpanFromContext(context.Background()), noop.NewTracerProvider().Tracer("")
+	}
+	ctx := req.Context()
+	span, ok := ctx.Value(operationSpanKey{}).(trace.Span)
+	if !ok {
+		span = trace.SpanFromContext(ctx)
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	if obsOptions == nil {
+		return span, noop.NewTracerProvider().Tracer("")
+	}
+	return span, getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName())
+}
@@ -0,0 +1,77 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"net/url"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type spanAttributeMiddleware struct{}
+
+func (middleware *spanAttributeMiddleware) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	span, tracer := GetSpanFromRequest(req)
+	span.SetAttributes(attribute.String("custom.attribute", "value"))
+	ctx, childSpan := tracer.Start(req.Context(), "CustomMiddleware_Intercept")
+	defer childSpan.End()
+	return pipeline.Next(req.WithContext(ctx), middlewareIndex)
+}
+
+func TestItReturnsTheOperationSpanFromTheRequest(t *testing.T) {
+	provider := useTestTracerProvider(t)
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(204)
+	}))
+	defer testServer.Close()
+	client := GetDefaultClient(NewRetryHandler(), &spanAttributeMiddleware{})
+	adapter, err := NewNetHttpRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(&absauth.AnonymousAuthenticationProvider{}, nil, nil, client)
+	assert.Nil(t, err)
+	uri, _ := url.Parse(testServer.URL)
+	request := abs.NewRequestInformation()
+	request.SetUri(*uri)
+	request.Method = abs.GET
+	request.UrlTemplate = "{+baseurl}/users"
+
+	assert.Nil(t, adapter.SendNoContent(context.Background(), request, nil))
+	operationSpans := provider.getSpans("SendNoContent - {+baseurl}/users")
+	if assert.Equal(t, 1, len(operationSpans)) {
+		value, ok := operationSpans[0].getAttribute("custom.attribute")
+		assert.True(t, ok)
+		assert.Equal(t, "value", value.AsString())
+	}
+	childSpans := provider.getSpans("CustomMiddleware_Intercept")
+	if assert.Equal(t, 1, len(childSpans)) {
+		assert.Equal(t, "RetryHandler_Intercept", childSpans[0].parent.name)
+	}
+}
+
+func TestItReturnsTheActiveSpanFromTheRequest(t *testing.T) {
+	provider := useTestTracerProvider(t)
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	client := GetDefaultClient(&observabilityOptionsHandler{}, NewRetryHandler(), &spanAttributeMiddleware{})
+
+	_, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	retrySpans := provider.getSpans("RetryHandler_Intercept")
+	if assert.Equal(t, 1, len(retrySpans)) {
+		_, ok := retrySpans[0].getAttribute("custom.attribute")
+		assert.True(t, ok)
+	}
+}
+
+func TestItReturnsANonRecordingSpanWithoutObservabilityOptions(t *testing.T) {
+	req, _ := nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com", nil)
+	span, tracer := GetSpanFromRequest(req)
+	assert.False(t, span.IsRecording())
+	_, childSpan := tracer.Start(req.Context(), "child")
+	assert.False(t, childSpan.IsRecording())
+}
@@ -10,7 +10,6 @@ import (
 	"time"
 
 	abs "github.com/microsoft/kiota-abstractions-go"
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -48,6 +47,8 @@ type RetryHandlerOptions struct {
 	MaxRetries int
 	// The delay in seconds between retries
 	DelaySeconds int
+	// Clock provides the time and waits between the retries, the system clock is used when nil
+	Clock Clock
 }
 
 type retryHandlerOptionsInt interface {
@@ -82,6 +83,11 @@ func (options *RetryHandlerOptions) GetDelaySeconds() int {
 	}
 }
 
+// GetClock returns the clock providing the time and waiting between the retries
+func (options *RetryHandlerOptions) GetClock() Clock {
+	return options.Clock
+}
+
 // GetMaxRetries returns the maximum number of times a request can be retried
 func (options *RetryHandlerOptions) GetMaxRetries() int {
 	if options.MaxRetries < 1 {
@@ -93,6 +99,41 @@ func (options *RetryHandlerOptions) GetMaxRetries() int {
 	}
 }
 
+// RequestBodyNotReplayableError is returned when a request must be sent again but its body was consumed and cannot be rewound.
+// Set GetBody on the request or use a body implementing io.Seeker so it can be replayed.
+type RequestBodyNotReplayableError struct {
+	// Method is the HTTP method of the request
+	Method string
+	// Url is the url of the request
+	Url string
+}
+
+// Error returns the error message
+func (e *RequestBodyNotReplayableError) Error() string {
+	return "the body of the " + e.Method + " request to " + e.Url + " cannot be replayed, set GetBody on the request or use a seekable body"
+}
+
+// rewindRequestBody resets the body of the request so it can be sent again, it returns false when the body cannot be replayed.
+// GetBody is preferred as it creates a new body which is not shared with the clones of the request.
+func rewindRequestBody(req *nethttp.Request) bool {
+	if req.Body == nil || req.Body == nethttp.NoBody {
+		return true
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return false
+		}
+		req.Body = body
+		return true
+	}
+	if s, ok := req.Body.(io.Seeker); ok {
+		_, err := s.Seek(0, io.SeekStart)
+		return err == nil
+	}
+	return false
+}
+
 const retryAttemptHeader = "Retry-Attempt"
 const retryAfterHeader = "Retry-After"
 
@@ -106,14 +147,18 @@ func (middleware RetryHandler) Intercept(pipeline Pipeline, middlewareIndex int,
 	ctx := req.Context()
 	var span trace.Span
 	var observabilityName string
+	var previousAttempt trace.SpanContext
 	if obsOptions != nil {
 		observabilityName = obsOptions.GetTracerInstrumentationName()
-		ctx, span = otel.GetTracerProvider().Tracer(observabilityName).Start(ctx, "RetryHandler_Intercept")
+		ctx, _ = withAttemptGroup(ctx)
+		ctx, span = getTracerProvider(req.Context()).Tracer(observabilityName).Start(ctx, "RetryHandler_Intercept", getAttemptSpanOptions(ctx, trace.SpanContext{})...)
 		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.retry.enable", true))
 		defer span.End()
+		previousAttempt = span.SpanContext()
 		req = req.WithContext(ctx)
 	}
-	response, err := pipeline.Next(req, middlewareIndex)
+	req = RegisterFeatureUsage(req, RetryHandlerEnabledFeatureUsageFlag)
+	response, err := pipeline.Next(req.Clone(req.Context()), middlewareIndex)
 	if err != nil {
 		return response, err
 	}
@@ -121,49 +166,55 @@ func (middleware RetryHandler) Intercept(pipeline Pipeline, middlewareIndex int,
 	if !ok {
 		reqOption = &middleware.options
 	}
-	return middleware.retryRequest(ctx, pipeline, middlewareIndex, reqOption, req, response, 0, 0, observabilityName)
+	return middleware.retryRequest(ctx, pipeline, middlewareIndex, reqOption, req, response, 0, 0, observabilityName, previousAttempt)
 }
 
-func (middleware RetryHandler) retryRequest(ctx context.Context, pipeline Pipeline, middlewareIndex int, options retryHandlerOptionsInt, req *nethttp.Request, resp *nethttp.Response, executionCount int, cumulativeDelay time.Duration, observabilityName string) (*nethttp.Response, error) {
+// retryRequest retries the request while the response is retriable, the span of each attempt is linked to the span of the previous attempt
+func (middleware RetryHandler) retryRequest(ctx context.Context, pipeline Pipeline, middlewareIndex int, options retryHandlerOptionsInt, req *nethttp.Request, resp *nethttp.Response, executionCount int, cumulativeDelay time.Duration, observabilityName string, previousAttempt trace.SpanContext) (*nethttp.Response, error) {
 	if middleware.isRetriableErrorCode(resp.StatusCode) &&
 		middleware.isRetriableRequest(req) &&
 		executionCount < options.GetMaxRetries() &&
 		cumulativeDelay < time.Duration(absoluteMaxDelaySeconds)*time.Second &&
 		options.GetShouldRetry()(cumulativeDelay, executionCount, req, resp) {
 		executionCount++
-		delay := middleware.getRetryDelay(req, resp, options, executionCount)
+		clock := getClock(options, &middleware.options)
+		delay := middleware.getRetryDelay(req, resp, options, executionCount, clock.Now())
 		cumulativeDelay += delay
-		req.Header.Set(retryAttemptHeader, strconv.Itoa(executionCount))
-		if req.Body != nil {
-			s, ok := req.Body.(io.Seeker)
-			if ok {
-				s.Seek(0, io.SeekStart)
-			}
-		}
+		emitHttpEvent(req, WarnLogSeverity, RequestRetryLogEventName, "Retrying request",
+			httpRequestResendCountAttribute.Int(executionCount),
+			httpResponseStatusCodeAttribute.Int(resp.StatusCode),
+			attribute.Float64("http.request.resend_delay", delay.Seconds()))
+		attemptCtx := ctx
 		if observabilityName != "" {
-			ctx, span := otel.GetTracerProvider().Tracer(observabilityName).Start(ctx, "RetryHandler_Intercept - attempt "+fmt.Sprint(executionCount))
-			span.SetAttributes(attribute.Int("http.request.resend_count", executionCount),
+			var span trace.Span
+			attemptCtx, span = getTracerProvider(req.Context()).Tracer(observabilityName).Start(ctx, "RetryHandler_Intercept - attempt "+fmt.Sprint(executionCount), getAttemptSpanOptions(ctx, previousAttempt)...)
+			span = withLegacyAttributes(span, GetObservabilityOptionsFromRequest(req))
+			span.SetAttributes(httpRequestResendCountAttribute.Int(executionCount),
 
 				httpResponseStatusCodeAttribute.Int(resp.StatusCode),
 				attribute.Float64("http.request.resend_delay", delay.Seconds()),
 			)
 			defer span.End()
-			req = req.WithContext(ctx)
+			previousAttempt = span.SpanContext()
+		}
+		// every attempt is sent as a clone of the original request so the changes made by the next middlewares don't leak into the next attempts
+		attemptReq := req.Clone(attemptCtx)
+		attemptReq.Header.Set(retryAttemptHeader, strconv.Itoa(executionCount))
+		if !rewindRequestBody(attemptReq) {
+			if resp.Body != nil {
+				resp.Body.Close()
+			}
+			return nil, &RequestBodyNotReplayableError{Method: req.Method, Url: req.URL.String()}
 		}
-		t := time.NewTimer(delay)
-		select {
-		case <-ctx.Done():
+		if err := clock.Sleep(ctx, delay); err != nil {
 			// Return without retrying if the context was cancelled.
-			return nil, ctx.Err()
-
-			// Leaving this case empty causes it to exit the switch-block.
-		case <-t.C:
+			return nil, err
 		}
-		response, err := pipeline.Next(req, middlewareIndex)
+		response, err := pipeline.Next(attemptReq, middlewareIndex)
 		if err != nil {
 			return response, err
 		}
-		return middleware.retryRequest(ctx, pipeline, middlewareIndex, options, req, response, executionCount, cumulativeDelay, observabilityName)
+		return middleware.retryRequest(ctx, pipeline, middlewareIndex, options, req, response, executionCount, cumulativeDelay, observabilityName, previousAttempt)
 	}
 	return resp, nil
 }
@@ -179,7 +230,7 @@ func (middleware RetryHandler) isRetriableRequest(req *nethttp.Request) bool {
 	return true
 }
 
-func (middleware RetryHandler) getRetryDelay(req *nethttp.Request, resp *nethttp.Response, options retryHandlerOptionsInt, executionCount int) time.Duration {
+func (middleware RetryHandler) getRetryDelay(req *nethttp.Request, resp *nethttp.Response, options retryHandlerOptionsInt, executionCount int, now time.Time) time.Duration {
 	retryAfter := resp.Header.Get(retryAfterHeader)
 	if retryAfter != "" {
 		retryAfterDelay, err := strconv.ParseFloat(retryAfter, 64)
@@ -190,7 +241,7 @@ func (middleware RetryHandler) getRetryDelay(req *nethttp.Request, resp *nethttp
 		// parse the header if it's a date
 		t, err := time.Parse(time.RFC1123, retryAfter)
 		if err == nil {
-			return t.Sub(time.Now())
+			return t.Sub(now)
 		}
 	}
 	return time.Duration(math.Pow(float64(options.GetDelaySeconds()), float64(executionCount))) * time.Second
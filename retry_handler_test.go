@@ -2,12 +2,15 @@ package nethttplibrary
 
 import (
 	"context"
+	"errors"
+	"io"
 	nethttp "net/http"
 	httptest "net/http/httptest"
 	testing "testing"
 	"time"
 
 	"strconv"
+	"strings"
 
 	assert "github.com/stretchr/testify/assert"
 )
@@ -43,7 +46,9 @@ func TestItAddsRetryAttemptHeaders(t *testing.T) {
 		res.Write([]byte("body"))
 	}))
 	defer func() { testServer.Close() }()
+	clock := newFakeClock()
 	handler := NewRetryHandler()
+	handler.options.Clock = clock
 	req, err := nethttp.NewRequest(nethttp.MethodGet, testServer.URL, nil)
 	if err != nil {
 		t.Error(err)
@@ -54,6 +59,7 @@ func TestItAddsRetryAttemptHeaders(t *testing.T) {
 	}
 	assert.NotNil(t, resp)
 	assert.Equal(t, 1, retryAttemptInt)
+	assert.Equal(t, []time.Duration{3 * time.Second}, clock.getSleeps())
 }
 
 func TestItHonoursShouldRetry(t *testing.T) {
@@ -92,7 +98,9 @@ func TestItHonoursMaxRetries(t *testing.T) {
 		res.Write([]byte("body"))
 	}))
 	defer func() { testServer.Close() }()
+	clock := newFakeClock()
 	handler := NewRetryHandler()
+	handler.options.Clock = clock
 	req, err := nethttp.NewRequest(nethttp.MethodGet, testServer.URL, nil)
 	if err != nil {
 		t.Error(err)
@@ -104,12 +112,13 @@ func TestItHonoursMaxRetries(t *testing.T) {
 	assert.NotNil(t, resp)
 	assert.Equal(t, 429, resp.StatusCode)
 	assert.Equal(t, defaultMaxRetries, retryAttemptInt)
+	assert.Equal(t, []time.Duration{3 * time.Second, 9 * time.Second, 27 * time.Second}, clock.getSleeps())
 }
 
 func TestItHonoursRetryAfterDate(t *testing.T) {
 	retryAttemptInt := -1
-	start := time.Now()
-	retryAfterTimeStr := start.Add(4 * time.Second).Format(time.RFC1123)
+	clock := newFakeClock()
+	retryAfterTimeStr := clock.Now().Add(4 * time.Second).Format(time.RFC1123)
 	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
 		res.Header().Set("Retry-After", retryAfterTimeStr)
 		res.WriteHeader(429)
@@ -119,6 +128,7 @@ func TestItHonoursRetryAfterDate(t *testing.T) {
 
 	defer func() { testServer.Close() }()
 	handler := NewRetryHandler()
+	handler.options.Clock = clock
 	req, err := nethttp.NewRequest(nethttp.MethodGet, testServer.URL, nil)
 	if err != nil {
 		t.Error(err)
@@ -128,10 +138,12 @@ func TestItHonoursRetryAfterDate(t *testing.T) {
 		t.Error(err)
 	}
 	assert.NotNil(t, resp)
-	end := time.Now()
 
 	assert.Equal(t, defaultMaxRetries, retryAttemptInt)
-	assert.Greater(t, end.Sub(start), 3*time.Second) // delay should be greater than 3 seconds (ignoring microsecond differences)
+	sleeps := clock.getSleeps()
+	if assert.NotEmpty(t, sleeps) {
+		assert.Equal(t, 4*time.Second, sleeps[0])
+	}
 }
 
 func TestItHonoursContextExpiry(t *testing.T) {
@@ -143,7 +155,7 @@ func TestItHonoursContextExpiry(t *testing.T) {
 		res.Write([]byte("body"))
 	}))
 	defer func() { testServer.Close() }()
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
 	handler := NewRetryHandler()
 	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, testServer.URL, nil)
@@ -176,7 +188,7 @@ func TestItHonoursContextCancelled(t *testing.T) {
 		t.Error(err)
 	}
 	go func() {
-		time.Sleep(1 * time.Second)
+		time.Sleep(100 * time.Millisecond)
 		cancel()
 	}()
 	start := time.Now()
@@ -209,3 +221,97 @@ func TestItDoesntRetryOnSuccess(t *testing.T) {
 	assert.NotNil(t, resp)
 	assert.Equal(t, 0, retryAttemptInt)
 }
+
+func TestItRewindsTheBodyBeforeRetrying(t *testing.T) {
+	var receivedBodies []string
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		body, _ := io.ReadAll(req.Body)
+		receivedBodies = append(receivedBodies, string(body))
+		if len(receivedBodies) == 1 {
+			res.Header().Set("Retry-After", "0")
+			res.WriteHeader(429)
+			return
+		}
+		res.WriteHeader(200)
+	}))
+	defer func() { testServer.Close() }()
+	handler := NewRetryHandler()
+	req, err := nethttp.NewRequest(nethttp.MethodPost, testServer.URL, strings.NewReader("payload"))
+	if err != nil {
+		t.Error(err)
+	}
+	resp, err := handler.Intercept(newNoopPipeline(), 0, req)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, []string{"payload", "payload"}, receivedBodies)
+}
+
+type onlyReader struct {
+	reader io.Reader
+}
+
+func (r *onlyReader) Read(p []byte) (int, error) {
+	return r.reader.Read(p)
+}
+
+func TestItDoesntRetryRequestsWithBodiesThatCannotBeReplayed(t *testing.T) {
+	requestCount := 0
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		requestCount++
+		res.Header().Set("Retry-After", "0")
+		res.WriteHeader(429)
+	}))
+	defer func() { testServer.Close() }()
+	handler := NewRetryHandler()
+	req, err := nethttp.NewRequest(nethttp.MethodPost, testServer.URL, &onlyReader{reader: strings.NewReader("payload")})
+	if err != nil {
+		t.Error(err)
+	}
+	resp, err := handler.Intercept(newNoopPipeline(), 0, req)
+	assert.Nil(t, resp)
+	var replayErr *RequestBodyNotReplayableError
+	assert.True(t, errors.As(err, &replayErr))
+	assert.Equal(t, nethttp.MethodPost, replayErr.Method)
+	assert.Equal(t, 1, requestCount)
+}
+
+type mutatingPipeline struct {
+	requests []*nethttp.Request
+}
+
+func (pipeline *mutatingPipeline) Next(req *nethttp.Request, middlewareIndex int) (*nethttp.Response, error) {
+	req.Header.Add("X-Downstream", "added")
+	pipeline.requests = append(pipeline.requests, req)
+	header := make(nethttp.Header)
+	header.Set("Retry-After", "0")
+	return &nethttp.Response{StatusCode: 503, Header: header, Body: nethttp.NoBody}, nil
+}
+
+func TestItSendsEachAttemptAsAClone(t *testing.T) {
+	handler := NewRetryHandlerWithOptions(RetryHandlerOptions{
+		MaxRetries: 2,
+		ShouldRetry: func(delay time.Duration, executionCount int, request *nethttp.Request, response *nethttp.Response) bool {
+			return true
+		},
+	})
+	pipeline := &mutatingPipeline{}
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://localhost/items", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	resp, err := handler.Intercept(pipeline, 0, req)
+	assert.Nil(t, err)
+	assert.Equal(t, 503, resp.StatusCode)
+	if assert.Equal(t, 3, len(pipeline.requests)) {
+		for i, attempt := range pipeline.requests {
+			assert.NotSame(t, req, attempt)
+			assert.Equal(t, []string{"added"}, attempt.Header.Values("X-Downstream"))
+			if i > 0 {
+				assert.Equal(t, strconv.Itoa(i), attempt.Header.Get("Retry-Attempt"))
+			}
+		}
+		assert.Equal(t, "", pipeline.requests[0].Header.Get("Retry-Attempt"))
+	}
+	assert.Equal(t, "", req.Header.Get("Retry-Attempt"))
+	assert.Equal(t, "", req.Header.Get("X-Downstream"))
+}
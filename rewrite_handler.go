@@ -0,0 +1,131 @@
+package nethttplibrary
+
+import (
+	"errors"
+	nethttp "net/http"
+	"net/url"
+	"regexp"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RewriteRule is a regular expression based rule used to rewrite the request URL.
+type RewriteRule struct {
+	// Pattern is the regular expression matched against the full request URL
+	Pattern *regexp.Regexp
+	// Replacement is the replacement value, it can reference capture groups of the pattern with $1 or ${name}
+	Replacement string
+}
+
+// NewRewriteRule creates a new RewriteRule compiling the given pattern
+func NewRewriteRule(pattern string, replacement string) (RewriteRule, error) {
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return RewriteRule{}, err
+	}
+	return RewriteRule{Pattern: compiled, Replacement: replacement}, nil
+}
+
+// RewriteHandler is a middleware handler that rewrites the request URL with an ordered list of regular expression rules.
+type RewriteHandler struct {
+	options RewriteOptions
+}
+
+// RewriteOptions is a configuration object for the RewriteHandler middleware
+type RewriteOptions struct {
+	// Enabled defines whether the rules should be applied
+	Enabled bool
+	// Rules is the ordered list of rules, each rule is applied to the result of the previous one
+	Rules []RewriteRule
+}
+
+var rewriteOptionKey = abs.RequestOptionKey{Key: "RewriteHandler"}
+
+type rewriteOptionsInt interface {
+	abs.RequestOption
+	IsEnabled() bool
+	GetRules() []RewriteRule
+}
+
+// GetKey returns RewriteOptions unique name in context object
+func (o *RewriteOptions) GetKey() abs.RequestOptionKey {
+	return rewriteOptionKey
+}
+
+// IsEnabled reads Enabled setting from RewriteOptions
+func (o *RewriteOptions) IsEnabled() bool {
+	return o.Enabled
+}
+
+// GetRules reads Rules setting from RewriteOptions
+func (o *RewriteOptions) GetRules() []RewriteRule {
+	return o.Rules
+}
+
+// NewRewriteHandler creates a new RewriteHandler applying the given rules in order
+func NewRewriteHandler(rules ...RewriteRule) *RewriteHandler {
+	return NewRewriteHandlerWithOptions(RewriteOptions{Enabled: true, Rules: rules})
+}
+
+// NewRewriteHandlerWithOptions creates a new RewriteHandler with the given options
+func NewRewriteHandlerWithOptions(options RewriteOptions) *RewriteHandler {
+	return &RewriteHandler{options: options}
+}
+
+// Intercept is invoked by the middleware pipeline to rewrite the request URL before moving
+// the request to the next middleware in the pipeline
+func (c *RewriteHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(rewriteOptionKey).(rewriteOptionsInt)
+	if !ok {
+		reqOption = &c.options
+	}
+
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	ctx := req.Context()
+	var span trace.Span
+	if obsOptions != nil {
+		ctx, span = getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "RewriteHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.rewrite.enable", true))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+
+	if !reqOption.IsEnabled() || len(reqOption.GetRules()) == 0 {
+		return pipeline.Next(req, middlewareIndex)
+	}
+
+	rewrittenUrl, err := RewriteUrl(req.URL, reqOption.GetRules())
+	if err != nil {
+		if span != nil {
+			span.RecordError(err)
+		}
+		return nil, err
+	}
+	if rewrittenUrl.Host != req.URL.Host {
+		req.Host = rewrittenUrl.Host
+	}
+	req.URL = rewrittenUrl
+
+	if span != nil && obsOptions.GetIncludeEUIIAttributes() {
+		span.SetAttributes(urlFullAttribute.String(rewrittenUrl.String()))
+	}
+
+	return pipeline.Next(req, middlewareIndex)
+}
+
+// RewriteUrl applies the rules in order to the given URL and returns the rewritten URL
+func RewriteUrl(source *url.URL, rules []RewriteRule) (*url.URL, error) {
+	if source == nil {
+		return nil, errors.New("source cannot be nil")
+	}
+	result := source.String()
+	for _, rule := range rules {
+		if rule.Pattern == nil {
+			continue
+		}
+		result = rule.Pattern.ReplaceAllString(result, rule.Replacement)
+	}
+	return url.Parse(result)
+}
@@ -0,0 +1,45 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItRewritesTheUrlWithCaptureGroups(t *testing.T) {
+	versionRule, err := NewRewriteRule(`/v1\.0/(\w+)`, "/beta/$1")
+	assert.Nil(t, err)
+	hostRule, err := NewRewriteRule(`^https://graph\.microsoft\.com`, "https://gateway.contoso.com")
+	assert.Nil(t, err)
+	handler := NewRewriteHandler(versionRule, hostRule)
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com/v1.0/users?$top=1", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	pipeline := newSpyPipeline()
+	_, _ = handler.Intercept(pipeline, 0, req)
+	assert.Equal(t, "https://gateway.contoso.com/beta/users?$top=1", pipeline.GetReceivedRequest().URL.String())
+	assert.Equal(t, "gateway.contoso.com", pipeline.GetReceivedRequest().Host)
+}
+
+func TestItHonoursTheRewriteRequestOption(t *testing.T) {
+	rule, err := NewRewriteRule(`/v1\.0/`, "/beta/")
+	assert.Nil(t, err)
+	handler := NewRewriteHandler(rule)
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com/v1.0/users", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	options := &RewriteOptions{Enabled: false}
+	req = req.WithContext(context.WithValue(req.Context(), rewriteOptionKey, options))
+	pipeline := newSpyPipeline()
+	_, _ = handler.Intercept(pipeline, 0, req)
+	assert.Equal(t, "/v1.0/users", pipeline.GetReceivedRequest().URL.Path)
+}
+
+func TestItFailsOnInvalidRewritePatterns(t *testing.T) {
+	_, err := NewRewriteRule(`(`, "")
+	assert.Error(t, err)
+}
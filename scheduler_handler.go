@@ -0,0 +1,190 @@
+package nethttplibrary
+
+import (
+	"container/heap"
+	"errors"
+	nethttp "net/http"
+	"sync"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestPriority is the priority of a request, requests with a higher priority are dispatched first
+type RequestPriority int
+
+const (
+	// LowRequestPriority is the priority for background traffic
+	LowRequestPriority RequestPriority = 0
+	// NormalRequestPriority is the priority used when none is specified
+	NormalRequestPriority RequestPriority = 50
+	// HighRequestPriority is the priority for interactive calls
+	HighRequestPriority RequestPriority = 100
+)
+
+// RequestPriorityOptions is a request option carrying the priority of the request for the SchedulerHandler
+type RequestPriorityOptions struct {
+	// Priority is the priority of the request
+	Priority RequestPriority
+}
+
+var requestPriorityKeyValue = abs.RequestOptionKey{
+	Key: "RequestPriorityOptions",
+}
+
+type requestPriorityOptionsInt interface {
+	abs.RequestOption
+	GetPriority() RequestPriority
+}
+
+// NewRequestPriorityOptions creates a new RequestPriorityOptions with the given priority
+func NewRequestPriorityOptions(priority RequestPriority) *RequestPriorityOptions {
+	return &RequestPriorityOptions{Priority: priority}
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *RequestPriorityOptions) GetKey() abs.RequestOptionKey {
+	return requestPriorityKeyValue
+}
+
+// GetPriority returns the priority of the request
+func (options *RequestPriorityOptions) GetPriority() RequestPriority {
+	return options.Priority
+}
+
+// SchedulerHandlerOptions to use when scheduling the requests.
+type SchedulerHandlerOptions struct {
+	// MaxConcurrency is the maximum number of requests in flight at the same time
+	MaxConcurrency int
+}
+
+// SchedulerHandler enforces a global concurrency limit for the client and dispatches the waiting requests by priority.
+type SchedulerHandler struct {
+	options SchedulerHandlerOptions
+	mutex   sync.Mutex
+	active  int
+	waiting schedulerQueue
+	counter uint64
+}
+
+// NewSchedulerHandler creates a new SchedulerHandler allowing the given number of requests in flight
+func NewSchedulerHandler(maxConcurrency int) (*SchedulerHandler, error) {
+	return NewSchedulerHandlerWithOptions(SchedulerHandlerOptions{MaxConcurrency: maxConcurrency})
+}
+
+// NewSchedulerHandlerWithOptions creates a new SchedulerHandler with the given options
+func NewSchedulerHandlerWithOptions(options SchedulerHandlerOptions) (*SchedulerHandler, error) {
+	if options.MaxConcurrency < 1 {
+		return nil, errors.New("MaxConcurrency must be greater than 0")
+	}
+	return &SchedulerHandler{options: options}, nil
+}
+
+// schedulerWaiter is a request waiting to be dispatched
+type schedulerWaiter struct {
+	priority RequestPriority
+	sequence uint64
+	ready    chan struct{}
+	index    int
+}
+
+// schedulerQueue is a priority queue of waiters, ordered by priority then arrival
+type schedulerQueue []*schedulerWaiter
+
+func (q schedulerQueue) Len() int { return len(q) }
+func (q schedulerQueue) Less(i, j int) bool {
+	if q[i].priority == q[j].priority {
+		return q[i].sequence < q[j].sequence
+	}
+	return q[i].priority > q[j].priority
+}
+func (q schedulerQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+func (q *schedulerQueue) Push(x any) {
+	waiter := x.(*schedulerWaiter)
+	waiter.index = len(*q)
+	*q = append(*q, waiter)
+}
+func (q *schedulerQueue) Pop() any {
+	old := *q
+	n := len(old)
+	waiter := old[n-1]
+	old[n-1] = nil
+	waiter.index = -1
+	*q = old[:n-1]
+	return waiter
+}
+
+func (middleware *SchedulerHandler) acquire(req *nethttp.Request, priority RequestPriority) error {
+	middleware.mutex.Lock()
+	if middleware.active < middleware.options.MaxConcurrency && middleware.waiting.Len() == 0 {
+		middleware.active++
+		middleware.mutex.Unlock()
+		return nil
+	}
+	middleware.counter++
+	waiter := &schedulerWaiter{priority: priority, sequence: middleware.counter, ready: make(chan struct{})}
+	heap.Push(&middleware.waiting, waiter)
+	middleware.mutex.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return nil
+	case <-req.Context().Done():
+		middleware.mutex.Lock()
+		defer middleware.mutex.Unlock()
+		if waiter.index < 0 {
+			// the slot was handed over concurrently, give it back
+			middleware.releaseLocked()
+		} else {
+			heap.Remove(&middleware.waiting, waiter.index)
+		}
+		return req.Context().Err()
+	}
+}
+
+func (middleware *SchedulerHandler) release() {
+	middleware.mutex.Lock()
+	defer middleware.mutex.Unlock()
+	middleware.releaseLocked()
+}
+
+func (middleware *SchedulerHandler) releaseLocked() {
+	if middleware.waiting.Len() > 0 {
+		// hand over the slot to the waiter with the highest priority
+		waiter := heap.Pop(&middleware.waiting).(*schedulerWaiter)
+		close(waiter.ready)
+		return
+	}
+	middleware.active--
+}
+
+// Intercept implements the interface and waits for a dispatch slot before moving the request through the pipeline.
+func (middleware *SchedulerHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	priority := NormalRequestPriority
+	if reqOption, ok := req.Context().Value(requestPriorityKeyValue).(requestPriorityOptionsInt); ok {
+		priority = reqOption.GetPriority()
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	ctx := req.Context()
+	var span trace.Span
+	if obsOptions != nil {
+		ctx, span = getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "SchedulerHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.scheduler.enable", true),
+			attribute.Int("com.microsoft.kiota.handler.scheduler.priority", int(priority)))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	if err := middleware.acquire(req, priority); err != nil {
+		if span != nil {
+			span.RecordError(err)
+		}
+		return nil, err
+	}
+	defer middleware.release()
+	return pipeline.Next(req, middlewareIndex)
+}
@@ -0,0 +1,81 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type blockingPipeline struct {
+	mutex   sync.Mutex
+	order   []string
+	release chan struct{}
+}
+
+func (pipeline *blockingPipeline) Next(req *nethttp.Request, middlewareIndex int) (*nethttp.Response, error) {
+	pipeline.mutex.Lock()
+	pipeline.order = append(pipeline.order, req.URL.Path)
+	pipeline.mutex.Unlock()
+	if req.URL.Path == "/first" {
+		<-pipeline.release
+	}
+	return &nethttp.Response{StatusCode: 200}, nil
+}
+
+func TestItDispatchesWaitingRequestsByPriority(t *testing.T) {
+	handler, err := NewSchedulerHandler(1)
+	assert.Nil(t, err)
+	pipeline := &blockingPipeline{release: make(chan struct{})}
+	send := func(path string, priority RequestPriority, wg *sync.WaitGroup) {
+		defer wg.Done()
+		ctx := context.WithValue(context.Background(), requestPriorityKeyValue, NewRequestPriorityOptions(priority))
+		req, _ := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, "https://example.com"+path, nil)
+		_, _ = handler.Intercept(pipeline, 0, req)
+	}
+	first := &sync.WaitGroup{}
+	first.Add(1)
+	go send("/first", NormalRequestPriority, first)
+	assert.Eventually(t, func() bool {
+		pipeline.mutex.Lock()
+		defer pipeline.mutex.Unlock()
+		return len(pipeline.order) == 1
+	}, time.Second, time.Millisecond)
+
+	others := &sync.WaitGroup{}
+	others.Add(1)
+	go send("/background", LowRequestPriority, others)
+	assert.Eventually(t, func() bool {
+		handler.mutex.Lock()
+		defer handler.mutex.Unlock()
+		return handler.waiting.Len() == 1
+	}, time.Second, time.Millisecond)
+	others.Add(1)
+	go send("/interactive", HighRequestPriority, others)
+	assert.Eventually(t, func() bool {
+		handler.mutex.Lock()
+		defer handler.mutex.Unlock()
+		return handler.waiting.Len() == 2
+	}, time.Second, time.Millisecond)
+
+	close(pipeline.release)
+	first.Wait()
+	others.Wait()
+	assert.Equal(t, []string{"/first", "/interactive", "/background"}, pipeline.order)
+	assert.Equal(t, 0, handler.active)
+}
+
+func TestItStopsWaitingWhenTheContextIsCancelled(t *testing.T) {
+	handler, err := NewSchedulerHandler(1)
+	assert.Nil(t, err)
+	assert.Nil(t, handler.acquire(&nethttp.Request{}, NormalRequestPriority))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, "https://example.com", nil)
+	_, err = handler.Intercept(newSpyPipeline(), 0, req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, handler.waiting.Len())
+}
@@ -0,0 +1,377 @@
+package nethttplibrary
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	nethttp "net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// JsonSchema is the subset of JSON schema (and OpenAPI schema objects) supported by the SchemaValidationHandler
+type JsonSchema struct {
+	// Type is the expected type: object, array, string, number, integer, boolean or null
+	Type string `json:"-"`
+	// Types are the accepted types when the schema lists several of them (e.g. ["string", "null"]), Type is ignored when set
+	Types []string `json:"-"`
+	// Ref is the reference to the schema to validate the value against instead, resolved against the components of the options
+	// by its full value (e.g. #/components/schemas/user) or by its last segment (e.g. user)
+	Ref string `json:"$ref"`
+	// Nullable defines whether null is accepted in addition to the type
+	Nullable bool `json:"nullable"`
+	// Properties are the schemas of the properties of an object
+	Properties map[string]*JsonSchema `json:"properties"`
+	// Required are the names of the properties an object must contain
+	Required []string `json:"required"`
+	// AdditionalProperties defines whether an object can contain properties which are not described, allowed when nil
+	AdditionalProperties *bool `json:"additionalProperties"`
+	// Items is the schema of the items of an array
+	Items *JsonSchema `json:"items"`
+	// Enum is the list of accepted values
+	Enum []any `json:"enum"`
+}
+
+// UnmarshalJSON decodes the schema, the type can be a string or an array of strings
+func (schema *JsonSchema) UnmarshalJSON(content []byte) error {
+	type jsonSchema JsonSchema
+	decoded := struct {
+		*jsonSchema
+		Type json.RawMessage `json:"type"`
+	}{jsonSchema: (*jsonSchema)(schema)}
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		return err
+	}
+	if len(decoded.Type) == 0 {
+		return nil
+	}
+	if decoded.Type[0] == '[' {
+		return json.Unmarshal(decoded.Type, &schema.Types)
+	}
+	return json.Unmarshal(decoded.Type, &schema.Type)
+}
+
+// getTypes returns the accepted types, empty when any type is accepted
+func (schema *JsonSchema) getTypes() []string {
+	if len(schema.Types) > 0 {
+		return schema.Types
+	}
+	if schema.Type != "" {
+		return []string{schema.Type}
+	}
+	return nil
+}
+
+// ParseJsonSchema parses the given JSON schema or OpenAPI schema object
+func ParseJsonSchema(content []byte) (*JsonSchema, error) {
+	schema := &JsonSchema{}
+	if err := json.Unmarshal(content, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// SchemaViolation describes a discrepancy between a response body and its expected schema
+type SchemaViolation struct {
+	// Path is the JSON path of the value which doesn't match the schema
+	Path string
+	// Message describes the discrepancy
+	Message string
+}
+
+// ResponseSchema associates a request method, URL pattern and status code to the schema of the response body
+type ResponseSchema struct {
+	// Method is the HTTP method of the request, any method matches when empty
+	Method string
+	// UrlPattern is the regular expression the full request URL must match, any URL matches when nil
+	UrlPattern *regexp.Regexp
+	// StatusCode is the status code of the response, any status code matches when 0
+	StatusCode int
+	// Schema is the expected schema of the response body
+	Schema *JsonSchema
+}
+
+// SchemaValidationOptions is a configuration object for the SchemaValidationHandler middleware
+type SchemaValidationOptions struct {
+	// Enabled defines whether the responses should be validated
+	Enabled bool
+	// Schemas is the ordered list of schemas, the first matching one is used
+	Schemas []ResponseSchema
+	// Components are the schemas the references of the schemas are resolved against (e.g. the components.schemas of an OpenAPI description)
+	Components map[string]*JsonSchema
+	// OnViolation is called with the violations found in a response body
+	OnViolation func(req *nethttp.Request, resp *nethttp.Response, violations []SchemaViolation)
+}
+
+// SchemaValidationHandler validates the JSON response bodies against their expected schema and reports the discrepancies.
+// It is meant to be used in debug mode to diagnose drift between the service responses and the generated models, it never fails the requests.
+type SchemaValidationHandler struct {
+	options SchemaValidationOptions
+}
+
+// SchemaViolationEventKey is the key used for the open telemetry event recorded for every violation
+const SchemaViolationEventKey = "com.microsoft.kiota.schema_violation"
+
+var schemaValidationKey = abs.RequestOptionKey{Key: "SchemaValidationHandler"}
+
+type schemaValidationOptionsInt interface {
+	abs.RequestOption
+	IsEnabled() bool
+	GetSchemas() []ResponseSchema
+	GetComponents() map[string]*JsonSchema
+	GetOnViolation() func(req *nethttp.Request, resp *nethttp.Response, violations []SchemaViolation)
+}
+
+// GetKey returns SchemaValidationOptions unique name in context object
+func (o *SchemaValidationOptions) GetKey() abs.RequestOptionKey {
+	return schemaValidationKey
+}
+
+// IsEnabled returns whether the responses should be validated
+func (o *SchemaValidationOptions) IsEnabled() bool {
+	return o.Enabled
+}
+
+// GetSchemas returns the ordered list of schemas
+func (o *SchemaValidationOptions) GetSchemas() []ResponseSchema {
+	return o.Schemas
+}
+
+// GetComponents returns the schemas the references are resolved against
+func (o *SchemaValidationOptions) GetComponents() map[string]*JsonSchema {
+	return o.Components
+}
+
+// GetOnViolation returns the callback called with the violations found in a response body
+func (o *SchemaValidationOptions) GetOnViolation() func(req *nethttp.Request, resp *nethttp.Response, violations []SchemaViolation) {
+	return o.OnViolation
+}
+
+// NewSchemaValidationHandler creates a new SchemaValidationHandler validating the responses against the given schemas
+func NewSchemaValidationHandler(schemas ...ResponseSchema) *SchemaValidationHandler {
+	return NewSchemaValidationHandlerWithOptions(SchemaValidationOptions{Enabled: true, Schemas: schemas})
+}
+
+// NewSchemaValidationHandlerWithOptions creates a new SchemaValidationHandler with the given options
+func NewSchemaValidationHandlerWithOptions(options SchemaValidationOptions) *SchemaValidationHandler {
+	return &SchemaValidationHandler{options: options}
+}
+
+// Intercept implements the interface and validates the response body once the response is received.
+func (middleware SchemaValidationHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(schemaValidationKey).(schemaValidationOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	ctx := req.Context()
+	var span trace.Span
+	if obsOptions != nil {
+		ctx, span = getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "SchemaValidationHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.schema_validation.enable", reqOption.IsEnabled()))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	response, err := pipeline.Next(req, middlewareIndex)
+	if err != nil || response == nil || !reqOption.IsEnabled() {
+		return response, err
+	}
+	schema := findResponseSchema(reqOption.GetSchemas(), req, response)
+	if schema == nil || response.Body == nil || !strings.Contains(strings.ToLower(response.Header.Get("Content-Type")), "json") {
+		return response, nil
+	}
+	body, readErr := io.ReadAll(response.Body)
+	response.Body.Close()
+	if readErr != nil {
+		if span != nil {
+			span.RecordError(readErr)
+		}
+		response.Body = &readErrorBody{Reader: bytes.NewReader(body), err: readErr}
+		return response, nil
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	var violations []SchemaViolation
+	var value any
+	if unmarshalErr := json.Unmarshal(body, &value); unmarshalErr != nil {
+		violations = []SchemaViolation{{Path: "$", Message: "the body is not valid JSON: " + unmarshalErr.Error()}}
+	} else {
+		violations = ValidateJsonValueWithComponents(schema, value, reqOption.GetComponents())
+	}
+	if len(violations) == 0 {
+		return response, nil
+	}
+	if span != nil {
+		for _, violation := range violations {
+			span.AddEvent(SchemaViolationEventKey, trace.WithAttributes(
+				attribute.String("com.microsoft.kiota.schema_violation.path", violation.Path),
+				attribute.String("com.microsoft.kiota.schema_violation.message", violation.Message),
+			))
+		}
+	}
+	if callback := reqOption.GetOnViolation(); callback != nil {
+		callback(req, response, violations)
+	}
+	return response, nil
+}
+
+func findResponseSchema(schemas []ResponseSchema, req *nethttp.Request, resp *nethttp.Response) *JsonSchema {
+	requestUrl := req.URL.String()
+	for _, candidate := range schemas {
+		if candidate.Method != "" && !strings.EqualFold(candidate.Method, req.Method) {
+			continue
+		}
+		if candidate.StatusCode != 0 && candidate.StatusCode != resp.StatusCode {
+			continue
+		}
+		if candidate.UrlPattern != nil && !candidate.UrlPattern.MatchString(requestUrl) {
+			continue
+		}
+		return candidate.Schema
+	}
+	return nil
+}
+
+// ValidateJsonValue validates a decoded JSON value against the schema and returns the violations
+func ValidateJsonValue(schema *JsonSchema, value any) []SchemaViolation {
+	return ValidateJsonValueWithComponents(schema, value, nil)
+}
+
+// ValidateJsonValueWithComponents validates a decoded JSON value against the schema, resolving its references against the components, and returns the violations
+func ValidateJsonValueWithComponents(schema *JsonSchema, value any, components map[string]*JsonSchema) []SchemaViolation {
+	return validateJsonValue(schema, value, "$", components, nil)
+}
+
+func validateJsonValue(schema *JsonSchema, value any, path string, components map[string]*JsonSchema, violations []SchemaViolation) []SchemaViolation {
+	schema, message := resolveJsonSchema(schema, components)
+	if message != "" {
+		return append(violations, SchemaViolation{Path: path, Message: message})
+	}
+	if schema == nil {
+		return violations
+	}
+	types := schema.getTypes()
+	if value == nil {
+		if len(types) > 0 && !acceptsJsonType(types, "null") && !schema.Nullable {
+			violations = append(violations, SchemaViolation{Path: path, Message: "expected " + strings.Join(types, " or ") + " but got null"})
+		}
+		return violations
+	}
+	if len(schema.Enum) > 0 && !isEnumValue(schema.Enum, value) {
+		violations = append(violations, SchemaViolation{Path: path, Message: fmt.Sprintf("value %v is not one of the accepted values", value)})
+	}
+	actualType := getJsonType(value)
+	if len(types) > 0 && !acceptsJsonType(types, actualType) {
+		return append(violations, SchemaViolation{Path: path, Message: "expected " + strings.Join(types, " or ") + " but got " + actualType})
+	}
+	switch typed := value.(type) {
+	case map[string]any:
+		for _, required := range schema.Required {
+			if _, ok := typed[required]; !ok {
+				violations = append(violations, SchemaViolation{Path: path + "." + required, Message: "required property is missing"})
+			}
+		}
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			propertySchema, ok := schema.Properties[key]
+			if !ok {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					violations = append(violations, SchemaViolation{Path: path + "." + key, Message: "property is not described by the schema"})
+				}
+				continue
+			}
+			violations = validateJsonValue(propertySchema, typed[key], path+"."+key, components, violations)
+		}
+	case []any:
+		for i, item := range typed {
+			violations = validateJsonValue(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), components, violations)
+		}
+	}
+	return violations
+}
+
+// resolveJsonSchema follows the references of the schema to the components, the message describes why a reference cannot be resolved
+func resolveJsonSchema(schema *JsonSchema, components map[string]*JsonSchema) (*JsonSchema, string) {
+	for followed := 0; schema != nil && schema.Ref != ""; followed++ {
+		if followed > len(components) {
+			return nil, "the reference " + schema.Ref + " is circular"
+		}
+		target, ok := components[schema.Ref]
+		if !ok {
+			target, ok = components[schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]]
+		}
+		if !ok {
+			return nil, "the reference " + schema.Ref + " cannot be resolved"
+		}
+		schema = target
+	}
+	return schema, ""
+}
+
+// acceptsJsonType returns whether the actual type is one of the types, integers being numbers too
+func acceptsJsonType(types []string, actualType string) bool {
+	for _, candidate := range types {
+		if candidate == actualType || (candidate == "number" && actualType == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func getJsonType(value any) string {
+	switch typed := value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if typed == math.Trunc(typed) {
+			return "integer"
+		}
+		return "number"
+	default:
+		return "null"
+	}
+}
+
+func isEnumValue(enum []any, value any) bool {
+	for _, candidate := range enum {
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// readErrorBody replays the bytes read from a response body before returning the error which interrupted the read
+type readErrorBody struct {
+	io.Reader
+	err error
+}
+
+func (b *readErrorBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if err == io.EOF {
+		return n, b.err
+	}
+	return n, err
+}
+
+func (b *readErrorBody) Close() error {
+	return nil
+}
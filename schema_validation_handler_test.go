@@ -0,0 +1,136 @@
+package nethttplibrary
+
+import (
+	"encoding/json"
+	"io"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItValidatesJsonValues(t *testing.T) {
+	schema, err := ParseJsonSchema([]byte(`{
+		"type": "object",
+		"required": ["id", "displayName"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "string"},
+			"displayName": {"type": "string"},
+			"age": {"type": "integer"},
+			"kind": {"type": "string", "enum": ["user", "group"]},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		}
+	}`))
+	assert.Nil(t, err)
+	violations := ValidateJsonValue(schema, map[string]any{
+		"id":    "1",
+		"age":   1.5,
+		"kind":  "device",
+		"tags":  []any{"a", 1.0},
+		"extra": true,
+	})
+	assert.Equal(t, []SchemaViolation{
+		{Path: "$.displayName", Message: "required property is missing"},
+		{Path: "$.age", Message: "expected integer but got number"},
+		{Path: "$.extra", Message: "property is not described by the schema"},
+		{Path: "$.kind", Message: "value device is not one of the accepted values"},
+		{Path: "$.tags[1]", Message: "expected string but got integer"},
+	}, violations)
+}
+
+func TestItResolvesTheReferencesOfTheSchemas(t *testing.T) {
+	var components map[string]*JsonSchema
+	err := json.Unmarshal([]byte(`{
+		"user": {
+			"type": "object",
+			"properties": {
+				"id": {"type": ["string", "integer"]},
+				"manager": {"$ref": "#/components/schemas/user"},
+				"nickname": {"type": ["string", "null"]},
+				"group": {"$ref": "#/components/schemas/group"}
+			}
+		},
+		"loop": {"$ref": "#/components/schemas/loop"}
+	}`), &components)
+	assert.Nil(t, err)
+	schema, err := ParseJsonSchema([]byte(`{"type": "array", "items": {"$ref": "#/components/schemas/user"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"string", "integer"}, components["user"].Properties["id"].Types)
+
+	violations := ValidateJsonValueWithComponents(schema, []any{
+		map[string]any{"id": 1.0, "nickname": nil, "manager": map[string]any{"id": true}},
+		map[string]any{"id": "2", "group": map[string]any{}},
+	}, components)
+	assert.Equal(t, []SchemaViolation{
+		{Path: "$[0].manager.id", Message: "expected string or integer but got boolean"},
+		{Path: "$[1].group", Message: "the reference #/components/schemas/group cannot be resolved"},
+	}, violations)
+
+	violations = ValidateJsonValueWithComponents(&JsonSchema{Ref: "loop"}, map[string]any{}, components)
+	assert.Equal(t, []SchemaViolation{{Path: "$", Message: "the reference #/components/schemas/loop is circular"}}, violations)
+}
+
+func TestItReportsSchemaViolations(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(200)
+		res.Write([]byte(`{"id":1}`))
+	}))
+	defer func() { testServer.Close() }()
+	var reported []SchemaViolation
+	handler := NewSchemaValidationHandlerWithOptions(SchemaValidationOptions{
+		Enabled: true,
+		Schemas: []ResponseSchema{{
+			Method:     nethttp.MethodGet,
+			UrlPattern: regexp.MustCompile(`/users/\d+$`),
+			Schema:     &JsonSchema{Type: "object", Properties: map[string]*JsonSchema{"id": {Type: "string"}}},
+		}},
+		OnViolation: func(req *nethttp.Request, resp *nethttp.Response, violations []SchemaViolation) {
+			reported = violations
+		},
+	})
+	req, err := nethttp.NewRequest(nethttp.MethodGet, testServer.URL+"/users/1", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	resp, err := handler.Intercept(newNoopPipeline(), 0, req)
+	assert.Nil(t, err)
+	assert.Equal(t, []SchemaViolation{{Path: "$.id", Message: "expected string but got integer"}}, reported)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, `{"id":1}`, string(body))
+}
+
+func TestItReturnsTheReadErrorsOfTheValidatedBodies(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		conn, buf, _ := res.(nethttp.Hijacker).Hijack()
+		_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 100\r\n\r\n{\"id\"")
+		_ = buf.Flush()
+		_ = conn.Close()
+	}))
+	defer testServer.Close()
+	reported := false
+	handler := NewSchemaValidationHandlerWithOptions(SchemaValidationOptions{
+		Enabled: true,
+		Schemas: []ResponseSchema{{
+			Method:     nethttp.MethodGet,
+			UrlPattern: regexp.MustCompile(`/users/\d+$`),
+			Schema:     &JsonSchema{Type: "object"},
+		}},
+		OnViolation: func(req *nethttp.Request, resp *nethttp.Response, violations []SchemaViolation) {
+			reported = true
+		},
+	})
+	req, err := nethttp.NewRequest(nethttp.MethodGet, testServer.URL+"/users/1", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	resp, err := handler.Intercept(newNoopPipeline(), 0, req)
+	assert.Nil(t, err)
+	assert.False(t, reported)
+	body, err := io.ReadAll(resp.Body)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	assert.Equal(t, `{"id"`, string(body))
+}
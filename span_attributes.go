@@ -1,6 +1,11 @@
 package nethttplibrary
 
-import "go.opentelemetry.io/otel/attribute"
+import (
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
 
 // HTTP Request attributes
 const (
@@ -19,12 +24,14 @@ const (
 
 // Network attributes
 const (
-	networkProtocolNameAttribute = attribute.Key("network.protocol.name")
+	networkProtocolNameAttribute    = attribute.Key("network.protocol.name")
+	networkProtocolVersionAttribute = attribute.Key("network.protocol.version")
 )
 
 // Server attributes
 const (
 	serverAddressAttribute = attribute.Key("server.address")
+	serverPortAttribute    = attribute.Key("server.port")
 )
 
 // URL attributes
@@ -33,3 +40,59 @@ const (
 	urlSchemeAttribute      = attribute.Key("url.scheme")
 	urlUriTemplateAttribute = attribute.Key("url.uri_template")
 )
+
+// legacyAttributeNames maps the stable HTTP semantic conventions attributes to the names used before the conventions were stable
+var legacyAttributeNames = map[attribute.Key]attribute.Key{
+	httpRequestMethodAttribute:      "http.method",
+	httpRequestBodySizeAttribute:    "http.request_content_length",
+	httpRequestResendCountAttribute: "http.retry_count",
+	httpResponseStatusCodeAttribute: "http.status_code",
+	httpResponseBodySizeAttribute:   "http.response_content_length",
+	networkProtocolVersionAttribute: "http.flavor",
+	serverAddressAttribute:          "net.peer.name",
+	serverPortAttribute:             "net.peer.port",
+	urlFullAttribute:                "http.url",
+	urlSchemeAttribute:              "http.scheme",
+}
+
+// getLegacyAttributes returns the legacy equivalents of the given attributes
+func getLegacyAttributes(attributes []attribute.KeyValue) []attribute.KeyValue {
+	result := make([]attribute.KeyValue, 0)
+	for _, kv := range attributes {
+		if legacyName, ok := legacyAttributeNames[kv.Key]; ok {
+			result = append(result, attribute.KeyValue{Key: legacyName, Value: kv.Value})
+		}
+	}
+	return result
+}
+
+// legacyAttributesSpan emits the legacy attribute names along with the stable ones during the transition to the stable semantic conventions
+type legacyAttributesSpan struct {
+	trace.Span
+}
+
+// SetAttributes sets the attributes and their legacy equivalents
+func (s legacyAttributesSpan) SetAttributes(attributes ...attribute.KeyValue) {
+	s.Span.SetAttributes(append(attributes, getLegacyAttributes(attributes)...)...)
+}
+
+// legacyAttributesOptionsInt is implemented by the observability options which can enable the legacy attributes
+type legacyAttributesOptionsInt interface {
+	GetEmitLegacyAttributes() bool
+}
+
+// withLegacyAttributes wraps the span to also emit the legacy attribute names when enabled in the observability options
+func withLegacyAttributes(span trace.Span, obsOptions ObservabilityOptionsInt) trace.Span {
+	if legacyOptions, ok := obsOptions.(legacyAttributesOptionsInt); ok && legacyOptions.GetEmitLegacyAttributes() {
+		return legacyAttributesSpan{Span: span}
+	}
+	return span
+}
+
+// getProtocolVersion returns the version of the protocol of a request or response (e.g. 1.1 for HTTP/1.1)
+func getProtocolVersion(proto string) string {
+	if version := strings.TrimPrefix(proto, "HTTP/"); version != proto {
+		return version
+	}
+	return ""
+}
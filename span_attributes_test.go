@@ -0,0 +1,105 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func sendWithObservabilityOptions(t *testing.T, options ObservabilityOptions) (*testTracerProvider, *url.URL) {
+	provider := useTestTracerProvider(t)
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(204)
+	}))
+	t.Cleanup(testServer.Close)
+	adapter, err := NewNetHttpRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClientAndObservabilityOptions(&absauth.AnonymousAuthenticationProvider{}, nil, nil, nil, options)
+	assert.Nil(t, err)
+	uri, _ := url.Parse(testServer.URL + "/users?sig=secret")
+	request := abs.NewRequestInformation()
+	request.SetUri(*uri)
+	request.Method = abs.GET
+	request.UrlTemplate = "{+baseurl}/users"
+	assert.Nil(t, adapter.SendNoContent(context.Background(), request, nil))
+	return provider, uri
+}
+
+func TestItEmitsTheStableSemanticConventionsAttributes(t *testing.T) {
+	provider, uri := sendWithObservabilityOptions(t, ObservabilityOptions{IncludeEUIIAttributes: true})
+	spans := provider.getSpans("SendNoContent - {+baseurl}/users")
+	if !assert.Equal(t, 1, len(spans)) {
+		return
+	}
+	port, _ := strconv.Atoi(uri.Port())
+	expected := map[attribute.Key]attribute.Value{
+		serverAddressAttribute:          attribute.StringValue("127.0.0.1"),
+		serverPortAttribute:             attribute.IntValue(port),
+		urlSchemeAttribute:              attribute.StringValue("http"),
+		urlFullAttribute:                attribute.StringValue("http://" + uri.Host + "/users?sig=REDACTED"),
+		httpRequestMethodAttribute:      attribute.StringValue("GET"),
+		httpResponseStatusCodeAttribute: attribute.IntValue(204),
+		networkProtocolNameAttribute:    attribute.StringValue("http"),
+		networkProtocolVersionAttribute: attribute.StringValue("1.1"),
+	}
+	for key, value := range expected {
+		actual, ok := spans[0].getAttribute(key)
+		assert.True(t, ok, key)
+		assert.Equal(t, value, actual, key)
+	}
+	_, ok := spans[0].getAttribute("http.status_code")
+	assert.False(t, ok)
+}
+
+func TestItEmitsTheLegacyAttributesWhenEnabled(t *testing.T) {
+	provider, _ := sendWithObservabilityOptions(t, ObservabilityOptions{EmitLegacyAttributes: true})
+	spans := provider.getSpans("SendNoContent - {+baseurl}/users")
+	if !assert.Equal(t, 1, len(spans)) {
+		return
+	}
+	for key, value := range map[attribute.Key]attribute.Value{
+		"http.status_code":              attribute.IntValue(204),
+		"http.method":                   attribute.StringValue("GET"),
+		"http.scheme":                   attribute.StringValue("http"),
+		"net.peer.name":                 attribute.StringValue("127.0.0.1"),
+		"http.flavor":                   attribute.StringValue("1.1"),
+		httpResponseStatusCodeAttribute: attribute.IntValue(204),
+	} {
+		actual, ok := spans[0].getAttribute(key)
+		assert.True(t, ok, key)
+		assert.Equal(t, value, actual, key)
+	}
+}
+
+func TestItSetsTheCustomSpanAttributes(t *testing.T) {
+	invocations := 0
+	provider, _ := sendWithObservabilityOptions(t, ObservabilityOptions{
+		SpanAttributesCallback: func(req *nethttp.Request, resp *nethttp.Response) []attribute.KeyValue {
+			invocations++
+			if resp == nil {
+				return []attribute.KeyValue{attribute.String("tenant.id", "contoso"), attribute.String("operation", req.Method)}
+			}
+			return []attribute.KeyValue{attribute.Bool("business.empty_result", resp.StatusCode == 204)}
+		},
+	})
+	assert.Equal(t, 2, invocations)
+	spans := provider.getSpans("SendNoContent - {+baseurl}/users")
+	if !assert.Equal(t, 1, len(spans)) {
+		return
+	}
+	for key, value := range map[attribute.Key]attribute.Value{
+		"tenant.id":             attribute.StringValue("contoso"),
+		"operation":             attribute.StringValue("GET"),
+		"business.empty_result": attribute.BoolValue(true),
+	} {
+		actual, ok := spans[0].getAttribute(key)
+		assert.True(t, ok, key)
+		assert.Equal(t, value, actual, key)
+	}
+}
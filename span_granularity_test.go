@@ -0,0 +1,59 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"net/url"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/stretchr/testify/assert"
+)
+
+func sendWithSpanGranularity(t *testing.T, granularity SpanGranularity) *testTracerProvider {
+	provider := useTestTracerProvider(t)
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(204)
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClientAndObservabilityOptions(&absauth.AnonymousAuthenticationProvider{}, nil, nil, nil, ObservabilityOptions{SpanGranularity: granularity})
+	assert.Nil(t, err)
+	uri, _ := url.Parse(testServer.URL)
+	request := abs.NewRequestInformation()
+	request.SetUri(*uri)
+	request.Method = abs.GET
+	request.UrlTemplate = "{+baseurl}/users"
+	assert.Nil(t, adapter.SendNoContent(context.Background(), request, nil))
+	return provider
+}
+
+func TestItCreatesDetailedSpansByDefault(t *testing.T) {
+	provider := sendWithSpanGranularity(t, DetailedSpanGranularity)
+	assert.Equal(t, 1, len(provider.getSpans("SendNoContent - {+baseurl}/users")))
+	assert.Equal(t, 1, len(provider.getSpans("getHttpResponseMessage")))
+	assert.Equal(t, 1, len(provider.getSpans("throwIfFailedResponse")))
+}
+
+func TestItCreatesBasicSpans(t *testing.T) {
+	provider := sendWithSpanGranularity(t, BasicSpanGranularity)
+	spans := provider.getSpans("SendNoContent - {+baseurl}/users")
+	assert.Equal(t, 1, len(spans))
+	assert.Empty(t, provider.getSpans("getHttpResponseMessage"))
+	assert.Empty(t, provider.getSpans("getRequestFromRequestInformation"))
+	assert.Empty(t, provider.getSpans("throwIfFailedResponse"))
+	status, _ := spans[0].getAttribute(httpResponseStatusCodeAttribute)
+	assert.Equal(t, int64(204), status.AsInt64())
+	transportSpans := provider.getSpans("request_transport")
+	if assert.Equal(t, 1, len(transportSpans)) {
+		assert.NotNil(t, transportSpans[0].parent)
+	}
+}
+
+func TestItDoesNotCreateAdapterSpansWhenOff(t *testing.T) {
+	provider := sendWithSpanGranularity(t, OffSpanGranularity)
+	assert.Empty(t, provider.getSpans("SendNoContent - {+baseurl}/users"))
+	assert.Empty(t, provider.getSpans("getHttpResponseMessage"))
+	assert.Equal(t, 1, len(provider.getSpans("request_transport")))
+}
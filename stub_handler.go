@@ -0,0 +1,224 @@
+package nethttplibrary
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	nethttp "net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StubResponse is a canned response returned by the StubHandler
+type StubResponse struct {
+	// StatusCode is the status code of the response, 200 when not set
+	StatusCode int `json:"statusCode"`
+	// Headers are the headers of the response
+	Headers map[string][]string `json:"headers"`
+	// Body is the body of the response
+	Body string `json:"body"`
+	// BodyFile is the path of a file to read the body from, it takes precedence over Body
+	BodyFile string `json:"bodyFile"`
+}
+
+// StubRule associates a request method and URL pattern to a canned response
+type StubRule struct {
+	// Method is the HTTP method of the request, any method matches when empty
+	Method string
+	// UrlPattern is the regular expression the full request URL must match
+	UrlPattern *regexp.Regexp
+	// Response is the canned response returned when the rule matches
+	Response StubResponse
+}
+
+// StubHandlerOptions is a configuration object for the StubHandler middleware
+type StubHandlerOptions struct {
+	// Rules is the ordered list of rules, the first matching rule is used
+	Rules []StubRule
+	// PassThrough defines whether requests which don't match any rule are moved to the next middleware, an error is returned otherwise
+	PassThrough bool
+}
+
+// StubHandler returns canned responses for the requests matching the configured rules without sending them.
+// It is a deterministic alternative to the ChaosHandler for unit tests.
+type StubHandler struct {
+	options StubHandlerOptions
+}
+
+// StubHandlerTriggeredEventKey is the key used for the open telemetry event
+const StubHandlerTriggeredEventKey = "com.microsoft.kiota.stub_handler_triggered"
+
+// ErrNoMatchingStub is returned when a request doesn't match any rule and pass through is disabled
+var ErrNoMatchingStub = errors.New("no stub matches the request")
+
+var stubHandlerKey = abs.RequestOptionKey{Key: "StubHandler"}
+
+type stubHandlerOptionsInt interface {
+	abs.RequestOption
+	GetRules() []StubRule
+	GetPassThrough() bool
+}
+
+// GetKey returns StubHandlerOptions unique name in context object
+func (o *StubHandlerOptions) GetKey() abs.RequestOptionKey {
+	return stubHandlerKey
+}
+
+// GetRules returns the ordered list of rules
+func (o *StubHandlerOptions) GetRules() []StubRule {
+	return o.Rules
+}
+
+// GetPassThrough returns whether requests which don't match any rule are moved to the next middleware
+func (o *StubHandlerOptions) GetPassThrough() bool {
+	return o.PassThrough
+}
+
+// NewStubHandler creates a new StubHandler from a map of "METHOD url-pattern" keys to canned responses.
+// The method can be omitted to match any method. Longer patterns are evaluated first.
+func NewStubHandler(stubs map[string]StubResponse) (*StubHandler, error) {
+	rules, err := parseStubRules(stubs)
+	if err != nil {
+		return nil, err
+	}
+	return NewStubHandlerWithOptions(StubHandlerOptions{Rules: rules}), nil
+}
+
+// NewStubHandlerFromFile creates a new StubHandler from a JSON file containing a map of "METHOD url-pattern" keys to canned responses.
+// Body files are resolved relative to the directory of the given file.
+func NewStubHandlerFromFile(path string) (*StubHandler, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	stubs := make(map[string]StubResponse)
+	if err := json.Unmarshal(content, &stubs); err != nil {
+		return nil, err
+	}
+	directory := filepath.Dir(path)
+	for key, stub := range stubs {
+		if stub.BodyFile != "" && !filepath.IsAbs(stub.BodyFile) {
+			stub.BodyFile = filepath.Join(directory, stub.BodyFile)
+			stubs[key] = stub
+		}
+	}
+	return NewStubHandler(stubs)
+}
+
+// NewStubHandlerWithOptions creates a new StubHandler with the given options
+func NewStubHandlerWithOptions(options StubHandlerOptions) *StubHandler {
+	return &StubHandler{options: options}
+}
+
+func parseStubRules(stubs map[string]StubResponse) ([]StubRule, error) {
+	keys := make([]string, 0, len(stubs))
+	for key := range stubs {
+		keys = append(keys, key)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if len(keys[i]) == len(keys[j]) {
+			return keys[i] < keys[j]
+		}
+		return len(keys[i]) > len(keys[j])
+	})
+	rules := make([]StubRule, 0, len(keys))
+	for _, key := range keys {
+		method := ""
+		pattern := strings.TrimSpace(key)
+		if parts := strings.SplitN(pattern, " ", 2); len(parts) == 2 {
+			method = strings.ToUpper(parts[0])
+			pattern = strings.TrimSpace(parts[1])
+		}
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, StubRule{Method: method, UrlPattern: compiled, Response: stubs[key]})
+	}
+	return rules, nil
+}
+
+func findStubRule(rules []StubRule, req *nethttp.Request) *StubRule {
+	requestUrl := req.URL.String()
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Method != "" && !strings.EqualFold(rule.Method, req.Method) {
+			continue
+		}
+		if rule.UrlPattern == nil || rule.UrlPattern.MatchString(requestUrl) {
+			return rule
+		}
+	}
+	return nil
+}
+
+func createStubResponse(stub StubResponse, req *nethttp.Request) (*nethttp.Response, error) {
+	body := []byte(stub.Body)
+	if stub.BodyFile != "" {
+		content, err := os.ReadFile(stub.BodyFile)
+		if err != nil {
+			return nil, err
+		}
+		body = content
+	}
+	statusCode := stub.StatusCode
+	if statusCode == 0 {
+		statusCode = nethttp.StatusOK
+	}
+	header := make(nethttp.Header)
+	for key, values := range stub.Headers {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	return &nethttp.Response{
+		StatusCode:    statusCode,
+		Status:        strconv.Itoa(statusCode) + " " + httpStatusCode[statusCode],
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Intercept returns the canned response of the first matching rule or moves the request to the next middleware
+func (middleware StubHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	reqOption, ok := req.Context().Value(stubHandlerKey).(stubHandlerOptionsInt)
+	if !ok {
+		reqOption = &middleware.options
+	}
+
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	ctx := req.Context()
+	var span trace.Span
+	if obsOptions != nil {
+		ctx, span = getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "StubHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.stub.enable", true))
+		req = req.WithContext(ctx)
+		defer span.End()
+	}
+
+	rule := findStubRule(reqOption.GetRules(), req)
+	if rule == nil {
+		if reqOption.GetPassThrough() {
+			return pipeline.Next(req, middlewareIndex)
+		}
+		return nil, ErrNoMatchingStub
+	}
+	if span != nil {
+		span.AddEvent(StubHandlerTriggeredEventKey)
+	}
+	return createStubResponse(rule.Response, req)
+}
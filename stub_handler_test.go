@@ -0,0 +1,69 @@
+package nethttplibrary
+
+import (
+	"io"
+	nethttp "net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItReturnsTheMatchingStub(t *testing.T) {
+	handler, err := NewStubHandler(map[string]StubResponse{
+		"GET /users$":      {StatusCode: 200, Body: `{"value":[]}`, Headers: map[string][]string{"Content-Type": {"application/json"}}},
+		"GET /users/[^/]+": {StatusCode: 404},
+	})
+	assert.Nil(t, err)
+
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com/users", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	pipeline := newSpyPipeline()
+	resp, err := handler.Intercept(pipeline, 0, req)
+	assert.Nil(t, err)
+	assert.Nil(t, pipeline.GetReceivedRequest())
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, `{"value":[]}`, string(body))
+
+	req, err = nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com/users/123", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	resp, err = handler.Intercept(pipeline, 0, req)
+	assert.Nil(t, err)
+	assert.Equal(t, 404, resp.StatusCode)
+}
+
+func TestItFailsWhenNoStubMatches(t *testing.T) {
+	handler, err := NewStubHandler(map[string]StubResponse{
+		"POST /users": {StatusCode: 201},
+	})
+	assert.Nil(t, err)
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com/users", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = handler.Intercept(newSpyPipeline(), 0, req)
+	assert.Equal(t, ErrNoMatchingStub, err)
+}
+
+func TestItLoadsStubsFromAFile(t *testing.T) {
+	directory := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(directory, "user.json"), []byte(`{"id":"123"}`), 0600))
+	assert.Nil(t, os.WriteFile(filepath.Join(directory, "stubs.json"), []byte(`{"GET /users/123":{"statusCode":200,"bodyFile":"user.json"}}`), 0600))
+	handler, err := NewStubHandlerFromFile(filepath.Join(directory, "stubs.json"))
+	assert.Nil(t, err)
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://graph.microsoft.com/users/123", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	resp, err := handler.Intercept(newSpyPipeline(), 0, req)
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, `{"id":"123"}`, string(body))
+}
@@ -0,0 +1,107 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+	"strings"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TelemetryHandler adds a telemetry header describing the client (SDK version, host OS, features...) to the requests.
+type TelemetryHandler struct {
+	options TelemetryHandlerOptions
+}
+
+// TelemetryHandlerOptions to use when adding the telemetry header.
+type TelemetryHandlerOptions struct {
+	// Enabled defines whether the telemetry header should be added
+	Enabled bool
+	// HeaderName is the name of the telemetry header
+	HeaderName string
+	// ValueBuilder is the callback building the value of the telemetry header for the given request
+	ValueBuilder func(req *nethttp.Request) string
+}
+
+const defaultTelemetryHeaderName = "SdkVersion"
+
+var telemetryKeyValue = abs.RequestOptionKey{
+	Key: "TelemetryHandler",
+}
+
+type telemetryHandlerOptionsInt interface {
+	abs.RequestOption
+	GetEnabled() bool
+	GetHeaderName() string
+	GetValueBuilder() func(req *nethttp.Request) string
+}
+
+// NewTelemetryHandlerOptions creates a new TelemetryHandlerOptions with the given value builder and the default values
+func NewTelemetryHandlerOptions(valueBuilder func(req *nethttp.Request) string) *TelemetryHandlerOptions {
+	return &TelemetryHandlerOptions{
+		Enabled:      true,
+		HeaderName:   defaultTelemetryHeaderName,
+		ValueBuilder: valueBuilder,
+	}
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *TelemetryHandlerOptions) GetKey() abs.RequestOptionKey {
+	return telemetryKeyValue
+}
+
+// GetEnabled returns whether the telemetry header should be added
+func (options *TelemetryHandlerOptions) GetEnabled() bool {
+	return options.Enabled
+}
+
+// GetHeaderName returns the name of the telemetry header
+func (options *TelemetryHandlerOptions) GetHeaderName() string {
+	if options.HeaderName == "" {
+		return defaultTelemetryHeaderName
+	}
+	return options.HeaderName
+}
+
+// GetValueBuilder returns the callback building the value of the telemetry header
+func (options *TelemetryHandlerOptions) GetValueBuilder() func(req *nethttp.Request) string {
+	return options.ValueBuilder
+}
+
+// NewTelemetryHandler creates a new TelemetryHandler using the given callback to build the header value
+func NewTelemetryHandler(valueBuilder func(req *nethttp.Request) string) *TelemetryHandler {
+	return NewTelemetryHandlerWithOptions(*NewTelemetryHandlerOptions(valueBuilder))
+}
+
+// NewTelemetryHandlerWithOptions creates a new TelemetryHandler with the given options
+func NewTelemetryHandlerWithOptions(options TelemetryHandlerOptions) *TelemetryHandler {
+	return &TelemetryHandler{options: options}
+}
+
+// Intercept implements the interface and adds the telemetry header to the request.
+func (middleware TelemetryHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
+	obsOptions := GetObservabilityOptionsFromRequest(req)
+	if obsOptions != nil {
+		ctx, span := getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(req.Context(), "TelemetryHandler_Intercept")
+		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.telemetry.enable", true))
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+	options, ok := req.Context().Value(telemetryKeyValue).(telemetryHandlerOptionsInt)
+	if !ok {
+		options = &middleware.options
+	}
+	if options.GetEnabled() && options.GetValueBuilder() != nil {
+		additionalValue := options.GetValueBuilder()(req)
+		if additionalValue != "" {
+			headerName := options.GetHeaderName()
+			currentValue := req.Header.Get(headerName)
+			if currentValue == "" {
+				req.Header.Set(headerName, additionalValue)
+			} else if !strings.Contains(currentValue, additionalValue) {
+				req.Header.Set(headerName, currentValue+", "+additionalValue)
+			}
+		}
+	}
+	return pipeline.Next(req, middlewareIndex)
+}
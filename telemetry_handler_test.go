@@ -0,0 +1,38 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItAddsTheTelemetryHeaderOnce(t *testing.T) {
+	handler := NewTelemetryHandler(func(req *nethttp.Request) string {
+		return "kiota-go/1.0.0 (hostOS=linux)"
+	})
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://example.com", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	pipeline := newSpyPipeline()
+	_, _ = handler.Intercept(pipeline, 0, req)
+	_, _ = handler.Intercept(pipeline, 0, req)
+	assert.Equal(t, "kiota-go/1.0.0 (hostOS=linux)", pipeline.GetReceivedRequest().Header.Get("SdkVersion"))
+}
+
+func TestItAppendsToTheExistingTelemetryHeader(t *testing.T) {
+	options := NewTelemetryHandlerOptions(func(req *nethttp.Request) string {
+		return "kiota-go/1.0.0"
+	})
+	options.HeaderName = "X-Telemetry"
+	handler := NewTelemetryHandlerWithOptions(*options)
+	req, err := nethttp.NewRequest(nethttp.MethodGet, "https://example.com", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("X-Telemetry", "my-sdk/2.0.0")
+	pipeline := newSpyPipeline()
+	_, _ = handler.Intercept(pipeline, 0, req)
+	assert.Equal(t, "my-sdk/2.0.0, kiota-go/1.0.0", pipeline.GetReceivedRequest().Header.Get("X-Telemetry"))
+}
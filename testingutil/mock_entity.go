@@ -1,9 +1,10 @@
-package internal
+package testingutil
 
 import (
 	absser "github.com/microsoft/kiota-abstractions-go/serialization"
 )
 
+// MockEntity is a Parsable without any property
 type MockEntity struct {
 }
 
@@ -17,6 +18,8 @@ func (e *MockEntity) Serialize(writer absser.SerializationWriter) error {
 func (e *MockEntity) GetFieldDeserializers() map[string]func(absser.ParseNode) error {
 	return make(map[string]func(absser.ParseNode) error)
 }
+
+// MockEntityFactory is a ParsableFactory returning a new MockEntity
 func MockEntityFactory(parseNode absser.ParseNode) (absser.Parsable, error) {
 	return &MockEntity{}, nil
 }
@@ -1,4 +1,4 @@
-package internal
+package testingutil
 
 import (
 	"time"
@@ -7,6 +7,7 @@ import (
 	absser "github.com/microsoft/kiota-abstractions-go/serialization"
 )
 
+// MockParseNodeFactory is a ParseNodeFactory returning a MockParseNode for any content
 type MockParseNodeFactory struct {
 }
 
@@ -17,27 +18,24 @@ func (e *MockParseNodeFactory) GetRootParseNode(contentType string, content []by
 	return &MockParseNode{}, nil
 }
 
+// MockParseNode is a ParseNode returning zero values
 type MockParseNode struct {
 }
 
 func (e *MockParseNode) GetOnBeforeAssignFieldValues() absser.ParsableAction {
-	//TODO implement me
-	panic("implement me")
+	return nil
 }
 
 func (e *MockParseNode) SetOnBeforeAssignFieldValues(action absser.ParsableAction) error {
-	//TODO implement me
-	panic("implement me")
+	return nil
 }
 
 func (e *MockParseNode) GetOnAfterAssignFieldValues() absser.ParsableAction {
-	//TODO implement me
-	panic("implement me")
+	return nil
 }
 
 func (e *MockParseNode) SetOnAfterAssignFieldValues(action absser.ParsableAction) error {
-	//TODO implement me
-	panic("implement me")
+	return nil
 }
 
 func (*MockParseNode) GetRawValue() (interface{}, error) {
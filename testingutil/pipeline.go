@@ -0,0 +1,85 @@
+// Package testingutil provides test doubles to unit test code depending on the Kiota HTTP library
+// without sending requests to a service: pipelines to exercise middlewares, a fake request adapter and serialization mocks.
+package testingutil
+
+import (
+	"errors"
+	nethttp "net/http"
+	"sync"
+)
+
+// NoopPipeline is a pipeline sending the requests with a plain HTTP client, ignoring the middlewares after the one under test.
+type NoopPipeline struct {
+	// Client is the HTTP client used to send the requests, http.DefaultClient is used when nil
+	Client *nethttp.Client
+}
+
+// NewNoopPipeline creates a new NoopPipeline using a client without middleware
+func NewNoopPipeline() *NoopPipeline {
+	return &NoopPipeline{
+		Client: &nethttp.Client{},
+	}
+}
+
+// Next sends the request with the client of the pipeline
+func (pipeline *NoopPipeline) Next(req *nethttp.Request, middlewareIndex int) (*nethttp.Response, error) {
+	client := pipeline.Client
+	if client == nil {
+		client = nethttp.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// ErrSpyPipeline is the error returned by a SpyPipeline when no response nor error is configured
+var ErrSpyPipeline = errors.New("spy pipeline only")
+
+// SpyPipeline is a pipeline recording the requests it receives instead of sending them.
+type SpyPipeline struct {
+	// Response is the response returned for every request
+	Response *nethttp.Response
+	// Err is the error returned for every request, ErrSpyPipeline is returned when both Response and Err are nil
+	Err error
+
+	mutex            sync.Mutex
+	receivedRequests []*nethttp.Request
+}
+
+// NewSpyPipeline creates a new SpyPipeline returning ErrSpyPipeline for every request
+func NewSpyPipeline() *SpyPipeline {
+	return &SpyPipeline{}
+}
+
+// NewSpyPipelineWithResponse creates a new SpyPipeline returning the given response for every request
+func NewSpyPipelineWithResponse(response *nethttp.Response) *SpyPipeline {
+	return &SpyPipeline{Response: response}
+}
+
+// Next records the request and returns the configured response or error
+func (pipeline *SpyPipeline) Next(req *nethttp.Request, middlewareIndex int) (*nethttp.Response, error) {
+	pipeline.mutex.Lock()
+	defer pipeline.mutex.Unlock()
+	pipeline.receivedRequests = append(pipeline.receivedRequests, req)
+	if pipeline.Response == nil && pipeline.Err == nil {
+		return nil, ErrSpyPipeline
+	}
+	return pipeline.Response, pipeline.Err
+}
+
+// GetReceivedRequest returns the last request received by the pipeline, nil when no request was received
+func (pipeline *SpyPipeline) GetReceivedRequest() *nethttp.Request {
+	pipeline.mutex.Lock()
+	defer pipeline.mutex.Unlock()
+	if len(pipeline.receivedRequests) == 0 {
+		return nil
+	}
+	return pipeline.receivedRequests[len(pipeline.receivedRequests)-1]
+}
+
+// GetReceivedRequests returns all the requests received by the pipeline in order
+func (pipeline *SpyPipeline) GetReceivedRequests() []*nethttp.Request {
+	pipeline.mutex.Lock()
+	defer pipeline.mutex.Unlock()
+	result := make([]*nethttp.Request, len(pipeline.receivedRequests))
+	copy(result, pipeline.receivedRequests)
+	return result
+}
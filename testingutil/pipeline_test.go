@@ -0,0 +1,38 @@
+package testingutil
+
+import (
+	"errors"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoopPipelineSendsTheRequest(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(202)
+	}))
+	defer testServer.Close()
+	req, _ := nethttp.NewRequest(nethttp.MethodGet, testServer.URL, nil)
+
+	resp, err := NewNoopPipeline().Next(req, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 202, resp.StatusCode)
+}
+
+func TestSpyPipelineRecordsTheRequests(t *testing.T) {
+	pipeline := NewSpyPipeline()
+	assert.Nil(t, pipeline.GetReceivedRequest())
+	first, _ := nethttp.NewRequest(nethttp.MethodGet, "https://example.com/first", nil)
+	second, _ := nethttp.NewRequest(nethttp.MethodGet, "https://example.com/second", nil)
+
+	_, err := pipeline.Next(first, 0)
+	assert.True(t, errors.Is(err, ErrSpyPipeline))
+	pipeline.Response = &nethttp.Response{StatusCode: 204}
+	resp, err := pipeline.Next(second, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 204, resp.StatusCode)
+	assert.Equal(t, second, pipeline.GetReceivedRequest())
+	assert.Equal(t, []*nethttp.Request{first, second}, pipeline.GetReceivedRequests())
+}
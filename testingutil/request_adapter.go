@@ -0,0 +1,149 @@
+package testingutil
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"sync"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absser "github.com/microsoft/kiota-abstractions-go/serialization"
+	"github.com/microsoft/kiota-abstractions-go/store"
+)
+
+// FakeResponder returns the result of a request sent through a FakeRequestAdapter.
+// The result must be of the type returned by the Send method used (e.g. a Parsable for Send, a []Parsable for SendCollection).
+type FakeResponder func(ctx context.Context, requestInfo *abs.RequestInformation) (any, error)
+
+// FakeRequestAdapter is a request adapter recording the requests and returning the results of a responder, without sending any request.
+type FakeRequestAdapter struct {
+	// Responder returns the results of the requests, the requests return nil results when it is nil
+	Responder FakeResponder
+	// SerializationWriterFactory is the factory returned by GetSerializationWriterFactory
+	SerializationWriterFactory absser.SerializationWriterFactory
+
+	mutex            sync.Mutex
+	baseUrl          string
+	receivedRequests []*abs.RequestInformation
+}
+
+// NewFakeRequestAdapter creates a new FakeRequestAdapter with the given responder
+func NewFakeRequestAdapter(responder FakeResponder) *FakeRequestAdapter {
+	return &FakeRequestAdapter{Responder: responder}
+}
+
+// GetReceivedRequests returns the requests sent through the adapter in order
+func (a *FakeRequestAdapter) GetReceivedRequests() []*abs.RequestInformation {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	result := make([]*abs.RequestInformation, len(a.receivedRequests))
+	copy(result, a.receivedRequests)
+	return result
+}
+
+// respond records the request and returns the result of the responder
+func (a *FakeRequestAdapter) respond(ctx context.Context, requestInfo *abs.RequestInformation) (any, error) {
+	a.mutex.Lock()
+	a.receivedRequests = append(a.receivedRequests, requestInfo)
+	responder := a.Responder
+	a.mutex.Unlock()
+	if responder == nil {
+		return nil, nil
+	}
+	return responder(ctx, requestInfo)
+}
+
+// respondAs returns the result of the responder converted to the type expected by the Send method
+func respondAs[T any](a *FakeRequestAdapter, ctx context.Context, requestInfo *abs.RequestInformation) (T, error) {
+	var zero T
+	result, err := a.respond(ctx, requestInfo)
+	if err != nil || result == nil {
+		return zero, err
+	}
+	typedResult, ok := result.(T)
+	if !ok {
+		return zero, fmt.Errorf("the fake responder returned a %T where a %T was expected", result, zero)
+	}
+	return typedResult, nil
+}
+
+// Send returns the Parsable returned by the responder
+func (a *FakeRequestAdapter) Send(ctx context.Context, requestInfo *abs.RequestInformation, constructor absser.ParsableFactory, errorMappings abs.ErrorMappings) (absser.Parsable, error) {
+	return respondAs[absser.Parsable](a, ctx, requestInfo)
+}
+
+// SendEnum returns the value returned by the responder
+func (a *FakeRequestAdapter) SendEnum(ctx context.Context, requestInfo *abs.RequestInformation, parser absser.EnumFactory, errorMappings abs.ErrorMappings) (any, error) {
+	return a.respond(ctx, requestInfo)
+}
+
+// SendCollection returns the []Parsable returned by the responder
+func (a *FakeRequestAdapter) SendCollection(ctx context.Context, requestInfo *abs.RequestInformation, constructor absser.ParsableFactory, errorMappings abs.ErrorMappings) ([]absser.Parsable, error) {
+	return respondAs[[]absser.Parsable](a, ctx, requestInfo)
+}
+
+// SendEnumCollection returns the []any returned by the responder
+func (a *FakeRequestAdapter) SendEnumCollection(ctx context.Context, requestInfo *abs.RequestInformation, parser absser.EnumFactory, errorMappings abs.ErrorMappings) ([]any, error) {
+	return respondAs[[]any](a, ctx, requestInfo)
+}
+
+// SendPrimitive returns the value returned by the responder
+func (a *FakeRequestAdapter) SendPrimitive(ctx context.Context, requestInfo *abs.RequestInformation, typeName string, errorMappings abs.ErrorMappings) (any, error) {
+	return a.respond(ctx, requestInfo)
+}
+
+// SendPrimitiveCollection returns the []any returned by the responder
+func (a *FakeRequestAdapter) SendPrimitiveCollection(ctx context.Context, requestInfo *abs.RequestInformation, typeName string, errorMappings abs.ErrorMappings) ([]any, error) {
+	return respondAs[[]any](a, ctx, requestInfo)
+}
+
+// SendNoContent returns the error returned by the responder
+func (a *FakeRequestAdapter) SendNoContent(ctx context.Context, requestInfo *abs.RequestInformation, errorMappings abs.ErrorMappings) error {
+	_, err := a.respond(ctx, requestInfo)
+	return err
+}
+
+// GetSerializationWriterFactory returns the serialization writer factory of the adapter
+func (a *FakeRequestAdapter) GetSerializationWriterFactory() absser.SerializationWriterFactory {
+	return a.SerializationWriterFactory
+}
+
+// EnableBackingStore does nothing as the fake adapter doesn't deserialize responses
+func (a *FakeRequestAdapter) EnableBackingStore(factory store.BackingStoreFactory) {
+}
+
+// SetBaseUrl sets the base url for every request
+func (a *FakeRequestAdapter) SetBaseUrl(baseUrl string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.baseUrl = baseUrl
+}
+
+// GetBaseUrl gets the base url for every request
+func (a *FakeRequestAdapter) GetBaseUrl() string {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.baseUrl
+}
+
+// ConvertToNativeRequest converts the given RequestInformation into a native HTTP request without a body
+func (a *FakeRequestAdapter) ConvertToNativeRequest(ctx context.Context, requestInfo *abs.RequestInformation) (any, error) {
+	if requestInfo.PathParameters == nil {
+		requestInfo.PathParameters = make(map[string]string)
+	}
+	requestInfo.PathParameters["baseurl"] = a.GetBaseUrl()
+	uri, err := requestInfo.GetUri()
+	if err != nil {
+		return nil, err
+	}
+	request, err := nethttp.NewRequestWithContext(ctx, requestInfo.Method.String(), uri.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range requestInfo.Headers.ListKeys() {
+		for _, value := range requestInfo.Headers.Get(key) {
+			request.Header.Add(key, value)
+		}
+	}
+	return request, nil
+}
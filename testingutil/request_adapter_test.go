@@ -0,0 +1,64 @@
+package testingutil
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absser "github.com/microsoft/kiota-abstractions-go/serialization"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeRequestAdapterReturnsTheResultsOfTheResponder(t *testing.T) {
+	adapter := NewFakeRequestAdapter(func(ctx context.Context, requestInfo *abs.RequestInformation) (any, error) {
+		switch requestInfo.Method {
+		case abs.GET:
+			return &MockEntity{}, nil
+		case abs.DELETE:
+			return nil, errors.New("not found")
+		default:
+			return "unexpected", nil
+		}
+	})
+	var _ abs.RequestAdapter = adapter
+	getRequest := abs.NewRequestInformation()
+	getRequest.Method = abs.GET
+	deleteRequest := abs.NewRequestInformation()
+	deleteRequest.Method = abs.DELETE
+	postRequest := abs.NewRequestInformation()
+	postRequest.Method = abs.POST
+
+	result, err := adapter.Send(context.Background(), getRequest, MockEntityFactory, nil)
+	assert.Nil(t, err)
+	assert.IsType(t, &MockEntity{}, result)
+	err = adapter.SendNoContent(context.Background(), deleteRequest, nil)
+	assert.Error(t, err)
+	_, err = adapter.SendCollection(context.Background(), postRequest, MockEntityFactory, nil)
+	assert.Error(t, err)
+	primitive, err := adapter.SendPrimitive(context.Background(), postRequest, "string", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "unexpected", primitive)
+	assert.Equal(t, []*abs.RequestInformation{getRequest, deleteRequest, postRequest, postRequest}, adapter.GetReceivedRequests())
+
+	var emptyCollection []absser.Parsable
+	collection, err := NewFakeRequestAdapter(nil).SendCollection(context.Background(), getRequest, MockEntityFactory, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, emptyCollection, collection)
+}
+
+func TestFakeRequestAdapterConvertsToNativeRequests(t *testing.T) {
+	adapter := NewFakeRequestAdapter(nil)
+	adapter.SetBaseUrl("https://graph.microsoft.com/v1.0")
+	requestInfo := abs.NewRequestInformation()
+	requestInfo.Method = abs.GET
+	requestInfo.UrlTemplate = "{+baseurl}/users"
+	requestInfo.Headers.Add("Accept", "application/json")
+
+	request, err := adapter.ConvertToNativeRequest(context.Background(), requestInfo)
+	assert.Nil(t, err)
+	nativeRequest := request.(*nethttp.Request)
+	assert.Equal(t, "https://graph.microsoft.com/v1.0/users", nativeRequest.URL.String())
+	assert.Equal(t, "application/json", nativeRequest.Header.Get("Accept"))
+}
@@ -0,0 +1,151 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const throttledResponsesMetricName = "com.microsoft.kiota.throttling.responses"
+const throttlingWaitTimeMetricName = "com.microsoft.kiota.throttling.wait_time"
+const rateLimitRemainingMetricName = "com.microsoft.kiota.rate_limit.remaining"
+
+// ThrottledEventKey is the name of the span event added when a throttling response is received
+const ThrottledEventKey = "com.microsoft.kiota.throttled"
+
+var (
+	retryAfterAttribute         = attribute.Key("com.microsoft.kiota.throttling.retry_after")
+	rateLimitRemainingAttribute = attribute.Key("com.microsoft.kiota.rate_limit.remaining")
+)
+
+// throttlingInstruments holds the instruments recording the throttling metrics for a meter
+type throttlingInstruments struct {
+	throttledResponses metric.Int64Counter
+	waitTime           metric.Float64Counter
+	rateLimitRemaining metric.Int64Histogram
+}
+
+var throttlingInstrumentsByMeter sync.Map
+
+// getThrottlingInstruments returns the throttling instruments of the meter with the given name
+func getThrottlingInstruments(meterName string) *throttlingInstruments {
+	return getInstruments(&throttlingInstrumentsByMeter, meterName, func(meter metric.Meter) *throttlingInstruments {
+		instruments := &throttlingInstruments{}
+		// the instruments are no-ops when they fail to be created
+		instruments.throttledResponses, _ = meter.Int64Counter(throttledResponsesMetricName,
+			metric.WithUnit("{response}"),
+			metric.WithDescription("Number of throttling responses (429, or 503 with a Retry-After header) received"))
+		instruments.waitTime, _ = meter.Float64Counter(throttlingWaitTimeMetricName,
+			metric.WithUnit("s"),
+			metric.WithDescription("Total wait time requested by the Retry-After headers of the throttling responses"))
+		instruments.rateLimitRemaining, _ = meter.Int64Histogram(rateLimitRemainingMetricName,
+			metric.WithUnit("{request}"),
+			metric.WithDescription("Remaining quota advertised by the RateLimit headers of the responses"))
+		return instruments
+	})
+}
+
+type uriTemplateKey struct{}
+
+// withUriTemplate returns a context carrying the URI template of the request adapter operation, used as the route of the metrics
+func withUriTemplate(ctx context.Context, uriTemplate string) context.Context {
+	return context.WithValue(ctx, uriTemplateKey{}, uriTemplate)
+}
+
+// isThrottlingResponse returns whether the response is a throttling response
+func isThrottlingResponse(resp *nethttp.Response) bool {
+	return resp.StatusCode == tooManyRequests || (resp.StatusCode == serviceUnavailable && resp.Header.Get(retryAfterHeader) != "")
+}
+
+// parseRetryAfter parses the Retry-After header value, expressed either in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if date, err := nethttp.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// getRateLimitRemaining returns the remaining quota advertised by the X-RateLimit-Remaining, RateLimit-Remaining or RateLimit (remaining=, r=) headers
+func getRateLimitRemaining(header nethttp.Header) (int64, bool) {
+	return getRateLimitValue(header, "Remaining", "remaining", "r")
+}
+
+// getRateLimitValue returns the value of the RateLimit-<field> or X-RateLimit-<field> header,
+// or of the given parameters of the RateLimit header
+func getRateLimitValue(header nethttp.Header, field string, parameters ...string) (int64, bool) {
+	for _, name := range []string{"RateLimit-" + field, "X-RateLimit-" + field} {
+		if value := header.Get(name); value != "" {
+			if parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+				return parsed, true
+			}
+		}
+	}
+	if value := header.Get("RateLimit"); value != "" {
+		for _, parameter := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+			name, parameterValue, found := strings.Cut(strings.TrimSpace(parameter), "=")
+			if !found || !containsFold(parameters, name) {
+				continue
+			}
+			if parsed, err := strconv.ParseInt(strings.TrimSpace(parameterValue), 10, 64); err == nil {
+				return parsed, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// recordThrottling records the throttling metrics and adds a span event when the response is a throttling response,
+// as well as the remaining quota advertised by the rate limit headers of any response
+func recordThrottling(ctx context.Context, meterName string, span trace.Span, req *nethttp.Request, resp *nethttp.Response) {
+	remaining, hasRemaining := getRateLimitRemaining(resp.Header)
+	throttled := isThrottlingResponse(resp)
+	if !throttled && !hasRemaining {
+		return
+	}
+	instruments := getThrottlingInstruments(meterName)
+	attributes := []attribute.KeyValue{serverAddressAttribute.String(req.URL.Hostname())}
+	if uriTemplate, ok := ctx.Value(uriTemplateKey{}).(string); ok && uriTemplate != "" {
+		attributes = append(attributes, urlUriTemplateAttribute.String(uriTemplate))
+	}
+	if hasRemaining && instruments.rateLimitRemaining != nil {
+		instruments.rateLimitRemaining.Record(ctx, remaining, metric.WithAttributes(attributes...))
+	}
+	if !throttled {
+		return
+	}
+	attributes = append(attributes, httpResponseStatusCodeAttribute.Int(resp.StatusCode))
+	if instruments.throttledResponses != nil {
+		instruments.throttledResponses.Add(ctx, 1, metric.WithAttributes(attributes...))
+	}
+	eventAttributes := []attribute.KeyValue{httpResponseStatusCodeAttribute.Int(resp.StatusCode)}
+	if delay, ok := parseRetryAfter(resp.Header.Get(retryAfterHeader), time.Now()); ok {
+		if instruments.waitTime != nil {
+			instruments.waitTime.Add(ctx, delay.Seconds(), metric.WithAttributes(attributes...))
+		}
+		eventAttributes = append(eventAttributes, retryAfterAttribute.Float64(delay.Seconds()))
+	}
+	if hasRemaining {
+		eventAttributes = append(eventAttributes, rateLimitRemainingAttribute.Int64(remaining))
+	}
+	if span != nil {
+		span.AddEvent(ThrottledEventKey, trace.WithAttributes(eventAttributes...))
+	}
+}
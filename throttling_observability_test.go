@@ -0,0 +1,78 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItRecordsThrottlingMetricsAndEvents(t *testing.T) {
+	meterProvider := useTestMeterProvider(t)
+	tracerProvider := useTestTracerProvider(t)
+	callCount := 0
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		callCount++
+		if callCount == 1 {
+			res.Header().Set("Retry-After", "0.5")
+			res.Header().Set("X-RateLimit-Remaining", "0")
+			res.WriteHeader(429)
+			return
+		}
+		res.Header().Set("RateLimit", "limit=100, remaining=42, reset=30")
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	client := GetDefaultClient(&observabilityOptionsHandler{}, NewRetryHandler(), NewNetworkObservabilityHandler())
+
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	throttled := meterProvider.getMeasurements(throttledResponsesMetricName)
+	if assert.Equal(t, 1, len(throttled)) {
+		host, _ := throttled[0].attributes.Value(serverAddressAttribute)
+		assert.Equal(t, "127.0.0.1", host.AsString())
+	}
+	waitTime := meterProvider.getMeasurements(throttlingWaitTimeMetricName)
+	if assert.Equal(t, 1, len(waitTime)) {
+		assert.Equal(t, 0.5, waitTime[0].value)
+	}
+	remaining := meterProvider.getMeasurements(rateLimitRemainingMetricName)
+	if assert.Equal(t, 2, len(remaining)) {
+		assert.Equal(t, float64(0), remaining[0].value)
+		assert.Equal(t, float64(42), remaining[1].value)
+	}
+	spans := tracerProvider.getSpans("request_transport")
+	if assert.Equal(t, 2, len(spans)) {
+		assert.Equal(t, []string{ThrottledEventKey}, spans[0].events)
+		assert.Empty(t, spans[1].events)
+	}
+}
+
+func TestItParsesRetryAfterHeaders(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	delay, ok := parseRetryAfter("10", now)
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Second, delay)
+	delay, ok = parseRetryAfter(now.Add(30*time.Second).Format(nethttp.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
+
+func TestItParsesRateLimitHeaders(t *testing.T) {
+	header := nethttp.Header{}
+	_, ok := getRateLimitRemaining(header)
+	assert.False(t, ok)
+	header.Set("RateLimit", `"default";r=12;t=30`)
+	remaining, ok := getRateLimitRemaining(header)
+	assert.True(t, ok)
+	assert.Equal(t, int64(12), remaining)
+	header.Set("RateLimit-Remaining", "7")
+	remaining, _ = getRateLimitRemaining(header)
+	assert.Equal(t, int64(7), remaining)
+}
@@ -0,0 +1,117 @@
+package nethttplibrary
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+)
+
+// RequestTimings holds the durations of the phases of a request sent with the request adapter.
+// Durations are summed over the attempts when the request is sent multiple times (retries, redirects, continuous access evaluation challenges).
+type RequestTimings struct {
+	// Queueing is the time spent in the middleware pipeline before reaching the transport (e.g. retry delays, rate limiting)
+	Queueing time.Duration
+	// Authentication is the time spent by the authentication provider to authenticate the request
+	Authentication time.Duration
+	// Serialization is the time spent converting the request information into a native request
+	Serialization time.Duration
+	// Network is the time spent by the transport until the response headers are received
+	Network time.Duration
+	// Deserialization is the time spent reading and deserializing the response
+	Deserialization time.Duration
+}
+
+// TimingInspectionOptions is a request option which is filled with the durations of the phases of the request,
+// so applications without a tracing backend can measure where the time goes
+type TimingInspectionOptions struct {
+	mutex   sync.Mutex
+	timings RequestTimings
+}
+
+// NewTimingInspectionOptions creates a new TimingInspectionOptions
+func NewTimingInspectionOptions() *TimingInspectionOptions {
+	return &TimingInspectionOptions{}
+}
+
+var timingInspectionKeyValue = abs.RequestOptionKey{
+	Key: "nethttplibrary.TimingInspectionOptions",
+}
+
+// GetKey returns the key for the TimingInspectionOptions
+func (o *TimingInspectionOptions) GetKey() abs.RequestOptionKey {
+	return timingInspectionKeyValue
+}
+
+// GetTimings returns the durations recorded for the request
+func (o *TimingInspectionOptions) GetTimings() RequestTimings {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.timings
+}
+
+type timingPhase int
+
+const (
+	queueingTimingPhase timingPhase = iota
+	authenticationTimingPhase
+	serializationTimingPhase
+	networkTimingPhase
+	deserializationTimingPhase
+)
+
+func (o *TimingInspectionOptions) add(phase timingPhase, duration time.Duration) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	switch phase {
+	case queueingTimingPhase:
+		o.timings.Queueing += duration
+	case authenticationTimingPhase:
+		o.timings.Authentication += duration
+	case serializationTimingPhase:
+		o.timings.Serialization += duration
+	case networkTimingPhase:
+		o.timings.Network += duration
+	case deserializationTimingPhase:
+		o.timings.Deserialization += duration
+	}
+}
+
+func getTimingInspectionOptions(ctx context.Context) *TimingInspectionOptions {
+	if ctx == nil {
+		return nil
+	}
+	options, _ := ctx.Value(timingInspectionKeyValue).(*TimingInspectionOptions)
+	return options
+}
+
+// startTimingPhase starts measuring the given phase and returns the function to call when the phase ends
+func startTimingPhase(ctx context.Context, phase timingPhase) func() {
+	options := getTimingInspectionOptions(ctx)
+	if options == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		options.add(phase, time.Since(start))
+	}
+}
+
+// startPipelineTiming starts measuring the time spent in the middleware pipeline and returns the function to call when the response is received.
+// The time which wasn't spent in the transport is recorded as queueing.
+func startPipelineTiming(ctx context.Context) func() {
+	options := getTimingInspectionOptions(ctx)
+	if options == nil {
+		return func() {}
+	}
+	start := time.Now()
+	network := options.GetTimings().Network
+	return func() {
+		elapsed := time.Since(start)
+		queueing := elapsed - (options.GetTimings().Network - network)
+		if queueing > 0 {
+			options.add(queueingTimingPhase, queueing)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type slowAuthenticationProvider struct {
+	delay time.Duration
+}
+
+func (p *slowAuthenticationProvider) AuthenticateRequest(ctx context.Context, request *abs.RequestInformation, additionalAuthenticationContext map[string]interface{}) error {
+	time.Sleep(p.delay)
+	return nil
+}
+
+func TestItInspectsTheTimingsOfTheRequest(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		time.Sleep(30 * time.Millisecond)
+		res.Header().Set("Content-Type", "application/octet-stream")
+		res.WriteHeader(200)
+		res.Write([]byte("content"))
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapter(&slowAuthenticationProvider{delay: 20 * time.Millisecond})
+	assert.Nil(t, err)
+	uri, _ := url.Parse(testServer.URL)
+	request := abs.NewRequestInformation()
+	request.SetUri(*uri)
+	request.Method = abs.GET
+	timingOptions := NewTimingInspectionOptions()
+	request.AddRequestOptions([]abs.RequestOption{timingOptions})
+
+	result, err := adapter.SendPrimitive(context.Background(), request, "[]byte", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("content"), result)
+
+	timings := timingOptions.GetTimings()
+	assert.GreaterOrEqual(t, timings.Authentication, 20*time.Millisecond)
+	assert.GreaterOrEqual(t, timings.Network, 30*time.Millisecond)
+	assert.Less(t, timings.Queueing, timings.Network)
+	assert.Greater(t, timings.Serialization, time.Duration(0))
+	assert.Greater(t, timings.Deserialization, time.Duration(0))
+}
+
+func TestItDoesNotMeasureWithoutTimingInspectionOptions(t *testing.T) {
+	stop := startTimingPhase(context.Background(), networkTimingPhase)
+	stop()
+	stop = startPipelineTiming(context.Background())
+	stop()
+	assert.Nil(t, getTimingInspectionOptions(context.Background()))
+}
@@ -0,0 +1,51 @@
+package nethttplibrary
+
+import (
+	"context"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// TracingOptions is a request option suppressing the spans of a single request (e.g. health checks or polling),
+// the request adapter and the middlewares don't create any span for it while the logs and metrics are still emitted.
+type TracingOptions struct {
+	// Disabled defines whether the spans of the request are suppressed
+	Disabled bool
+}
+
+var tracingKeyValue = abs.RequestOptionKey{
+	Key: "TracingOptions",
+}
+
+type tracingOptionsInt interface {
+	abs.RequestOption
+	GetDisabled() bool
+}
+
+// NewTracingOptions creates a new TracingOptions suppressing the spans of the request when disabled is true
+func NewTracingOptions(disabled bool) *TracingOptions {
+	return &TracingOptions{Disabled: disabled}
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *TracingOptions) GetKey() abs.RequestOptionKey {
+	return tracingKeyValue
+}
+
+// GetDisabled returns whether the spans of the request are suppressed
+func (options *TracingOptions) GetDisabled() bool {
+	return options.Disabled
+}
+
+// getTracerProvider returns the global tracer provider, or a no-op provider when the spans of the request are suppressed by TracingOptions
+func getTracerProvider(ctx context.Context) trace.TracerProvider {
+	if ctx != nil {
+		if options, ok := ctx.Value(tracingKeyValue).(tracingOptionsInt); ok && options.GetDisabled() {
+			return noop.NewTracerProvider()
+		}
+	}
+	return otel.GetTracerProvider()
+}
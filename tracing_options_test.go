@@ -0,0 +1,97 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItSuppressesTheSpansOfTheRequest(t *testing.T) {
+	provider := useTestTracerProvider(t)
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(204)
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.Nil(t, err)
+	adapter.SetBaseUrl(testServer.URL)
+
+	request := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/health", map[string]string{})
+	request.AddRequestOptions([]abs.RequestOption{NewTracingOptions(true)})
+	err = adapter.SendNoContent(context.Background(), request, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, provider.getSpans("request_transport"))
+	assert.Empty(t, provider.getSpans("RetryHandler_Intercept"))
+	assert.Empty(t, provider.getSpans("getHttpResponseMessage"))
+
+	request = abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/health", map[string]string{})
+	err = adapter.SendNoContent(context.Background(), request, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(provider.getSpans("request_transport")))
+	assert.Equal(t, 1, len(provider.getSpans("RetryHandler_Intercept")))
+	assert.Equal(t, 1, len(provider.getSpans("getHttpResponseMessage")))
+}
+
+func TestItAttributesTheSpansToTheObservabilityName(t *testing.T) {
+	provider := useTestTracerProvider(t)
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(204)
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.Nil(t, err)
+	adapter.SetBaseUrl(testServer.URL)
+	assert.Equal(t, "github.com/microsoft/kiota-http-go", adapter.GetObservabilityName())
+	adapter.SetObservabilityName("contoso-sdk")
+
+	request := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/health", map[string]string{})
+	err = adapter.SendNoContent(context.Background(), request, nil)
+	assert.Nil(t, err)
+	spans := provider.getSpans("SendNoContent - {+baseurl}/health")
+	if assert.Equal(t, 1, len(spans)) {
+		assert.Equal(t, "contoso-sdk", spans[0].tracerName)
+	}
+
+	request = abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/health", map[string]string{})
+	request.AddRequestOptions([]abs.RequestOption{NewObservabilityNameOptions("fabrikam-sdk")})
+	err = adapter.SendNoContent(context.Background(), request, nil)
+	assert.Nil(t, err)
+	spans = provider.getSpans("SendNoContent - {+baseurl}/health")
+	if assert.Equal(t, 2, len(spans)) {
+		assert.Equal(t, "fabrikam-sdk", spans[1].tracerName)
+	}
+	for _, span := range provider.getSpans("RetryHandler_Intercept") {
+		assert.NotEqual(t, "github.com/microsoft/kiota-http-go", span.tracerName)
+	}
+	assert.Equal(t, "contoso-sdk", adapter.GetObservabilityName())
+}
+
+func TestItChangesTheObservabilityNameWhileSending(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(204)
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.Nil(t, err)
+	adapter.SetBaseUrl(testServer.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			adapter.SetObservabilityName("contoso-sdk-" + strconv.Itoa(i))
+			request := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/health", map[string]string{})
+			assert.Nil(t, adapter.SendNoContent(context.Background(), request, nil))
+			assert.Contains(t, adapter.GetObservabilityName(), "contoso-sdk-")
+		}(i)
+	}
+	wg.Wait()
+}
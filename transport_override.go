@@ -0,0 +1,46 @@
+package nethttplibrary
+
+import (
+	nethttp "net/http"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+)
+
+// TransportOverrideOptions is a request option carrying the round tripper the last hop of the pipeline uses to send the request
+// in place of the parent transport, e.g. for one-off calls through a different proxy or socket, or a test double.
+type TransportOverrideOptions struct {
+	// Transport is the round tripper sending the request
+	Transport nethttp.RoundTripper
+}
+
+var transportOverrideKeyValue = abs.RequestOptionKey{
+	Key: "TransportOverride",
+}
+
+type transportOverrideOptionsInt interface {
+	abs.RequestOption
+	GetTransport() nethttp.RoundTripper
+}
+
+// NewTransportOverrideOptions creates a new TransportOverrideOptions with the given round tripper
+func NewTransportOverrideOptions(transport nethttp.RoundTripper) *TransportOverrideOptions {
+	return &TransportOverrideOptions{Transport: transport}
+}
+
+// GetKey returns the key value to be used when the option is added to the request context
+func (options *TransportOverrideOptions) GetKey() abs.RequestOptionKey {
+	return transportOverrideKeyValue
+}
+
+// GetTransport returns the round tripper sending the request
+func (options *TransportOverrideOptions) GetTransport() nethttp.RoundTripper {
+	return options.Transport
+}
+
+// getTransportForRequest returns the round tripper set on the request by TransportOverrideOptions, or the given default one
+func getTransportForRequest(req *nethttp.Request, defaultTransport nethttp.RoundTripper) nethttp.RoundTripper {
+	if reqOption, ok := req.Context().Value(transportOverrideKeyValue).(transportOverrideOptionsInt); ok && reqOption.GetTransport() != nil {
+		return reqOption.GetTransport()
+	}
+	return defaultTransport
+}
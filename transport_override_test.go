@@ -0,0 +1,49 @@
+package nethttplibrary
+
+import (
+	"context"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"net/url"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripperFunc func(req *nethttp.Request) (*nethttp.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	return f(req)
+}
+
+func TestItSendsTheRequestThroughTheOverriddenTransport(t *testing.T) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	overrideCount := 0
+	override := NewTransportOverrideOptions(roundTripperFunc(func(req *nethttp.Request) (*nethttp.Response, error) {
+		overrideCount++
+		return &nethttp.Response{StatusCode: 204, Request: req, Header: make(nethttp.Header), Body: nethttp.NoBody}, nil
+	}))
+	client := GetDefaultClient(NewHeadersInspectionHandler())
+	adapter, err := NewNetHttpRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(&absauth.AnonymousAuthenticationProvider{}, nil, nil, client)
+	assert.Nil(t, err)
+
+	uri, err := url.Parse(testServer.URL)
+	assert.Nil(t, err)
+	request := abs.NewRequestInformation()
+	request.SetUri(*uri)
+	request.Method = abs.GET
+	request.AddRequestOptions([]abs.RequestOption{override})
+	err = adapter.SendNoContent(context.Background(), request, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, overrideCount)
+
+	resp, err := client.Get(testServer.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 1, overrideCount)
+}
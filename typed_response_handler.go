@@ -0,0 +1,64 @@
+package nethttplibrary
+
+import (
+	"fmt"
+	nethttp "net/http"
+	"reflect"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+)
+
+// ResponseHandlerTypeError is returned when a response handler receives or returns a value of an unexpected type
+type ResponseHandlerTypeError struct {
+	// Expected is the name of the expected type
+	Expected string
+	// Actual is the name of the type of the value
+	Actual string
+}
+
+func (e *ResponseHandlerTypeError) Error() string {
+	return fmt.Sprintf("the response handler got a %s where a %s was expected", e.Actual, e.Expected)
+}
+
+// NewProcessHandlerFor wraps a typed function handling the responses into a response handler for the RequestHandlerOption.
+// The handler receives the response as a *http.Response, an error is returned instead of panicking when the request adapter expects another type of result.
+func NewProcessHandlerFor[T any](handler func(response *nethttp.Response, errorMappings abs.ErrorMappings) (T, error)) abs.ResponseHandler {
+	return func(response interface{}, errorMappings abs.ErrorMappings) (interface{}, error) {
+		httpResponse, ok := response.(*nethttp.Response)
+		if !ok && response != nil {
+			return nil, &ResponseHandlerTypeError{Expected: "*http.Response", Actual: fmt.Sprintf("%T", response)}
+		}
+		result, err := handler(httpResponse, errorMappings)
+		if err != nil {
+			return nil, err
+		}
+		if isNilValue(result) {
+			return nil, nil
+		}
+		return result, nil
+	}
+}
+
+// isNilValue returns true for nil interfaces, pointers, maps, slices, channels and functions
+func isNilValue(value any) bool {
+	if value == nil {
+		return true
+	}
+	switch reflected := reflect.ValueOf(value); reflected.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.Interface:
+		return reflected.IsNil()
+	}
+	return false
+}
+
+// getResponseHandlerResult converts the result of a response handler to the type returned by the send method
+func getResponseHandlerResult[T any](result interface{}) (T, error) {
+	typed, ok := result.(T)
+	if !ok {
+		return typed, &ResponseHandlerTypeError{
+			Expected: reflect.TypeOf((*T)(nil)).Elem().String(),
+			Actual:   fmt.Sprintf("%T", result),
+		}
+	}
+	return typed, nil
+}
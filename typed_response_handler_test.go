@@ -0,0 +1,63 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	absauth "github.com/microsoft/kiota-abstractions-go/authentication"
+	absser "github.com/microsoft/kiota-abstractions-go/serialization"
+	"github.com/microsoft/kiota-http-go/testingutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func sendWithResponseHandler(t *testing.T, handler abs.ResponseHandler) (absser.Parsable, error) {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	adapter, err := NewNetHttpRequestAdapter(&absauth.AnonymousAuthenticationProvider{})
+	assert.Nil(t, err)
+	adapter.SetBaseUrl(testServer.URL)
+	requestInfo := abs.NewRequestInformationWithMethodAndUrlTemplateAndPathParameters(abs.GET, "{+baseurl}/items", map[string]string{})
+	handlerOption := abs.NewRequestHandlerOption()
+	handlerOption.SetResponseHandler(handler)
+	requestInfo.AddRequestOptions([]abs.RequestOption{handlerOption})
+	return adapter.Send(context.Background(), requestInfo, testingutil.MockEntityFactory, nil)
+}
+
+func TestItProcessesTheResponsesWithATypedHandler(t *testing.T) {
+	var statusCode int
+	result, err := sendWithResponseHandler(t, NewProcessHandlerFor(func(response *nethttp.Response, errorMappings abs.ErrorMappings) (*testingutil.MockEntity, error) {
+		statusCode = response.StatusCode
+		return &testingutil.MockEntity{}, nil
+	}))
+	assert.Nil(t, err)
+	assert.IsType(t, &testingutil.MockEntity{}, result)
+	assert.Equal(t, 200, statusCode)
+
+	result, err = sendWithResponseHandler(t, NewProcessHandlerFor(func(response *nethttp.Response, errorMappings abs.ErrorMappings) (*testingutil.MockEntity, error) {
+		return nil, nil
+	}))
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+}
+
+func TestItReturnsAnErrorWhenTheResponseHandlerResultHasTheWrongType(t *testing.T) {
+	_, err := sendWithResponseHandler(t, NewProcessHandlerFor(func(response *nethttp.Response, errorMappings abs.ErrorMappings) (string, error) {
+		return "not a parsable", nil
+	}))
+	var typeErr *ResponseHandlerTypeError
+	if assert.True(t, errors.As(err, &typeErr)) {
+		assert.Equal(t, "serialization.Parsable", typeErr.Expected)
+		assert.Equal(t, "string", typeErr.Actual)
+	}
+
+	_, err = NewProcessHandlerFor(func(response *nethttp.Response, errorMappings abs.ErrorMappings) (int, error) {
+		return 0, nil
+	})("not a response", nil)
+	assert.True(t, errors.As(err, &typeErr))
+}
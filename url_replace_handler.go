@@ -2,7 +2,6 @@ package nethttplibrary
 
 import (
 	abstractions "github.com/microsoft/kiota-abstractions-go"
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"net/http"
@@ -22,11 +21,18 @@ func NewUrlReplaceHandler(enabled bool, replacementPairs map[string]string) *Url
 }
 
 // UrlReplaceOptions is a configuration object for the UrlReplaceHandler middleware
+// When added to a request, the options override the ones of the handler for that request only:
+// Enabled toggles the replacement and ReplacementPairs, when not empty, replaces the pairs of the handler.
 type UrlReplaceOptions struct {
 	Enabled          bool
 	ReplacementPairs map[string]string
 }
 
+// NewUrlReplaceOptions creates a new UrlReplaceOptions to add to a request to change the replacements for that request only
+func NewUrlReplaceOptions(enabled bool, replacementPairs map[string]string) *UrlReplaceOptions {
+	return &UrlReplaceOptions{Enabled: enabled, ReplacementPairs: replacementPairs}
+}
+
 // GetKey returns UrlReplaceOptions unique name in context object
 func (u *UrlReplaceOptions) GetKey() abstractions.RequestOptionKey {
 	return urlReplaceOptionKey
@@ -55,25 +61,30 @@ func (c *UrlReplaceHandler) Intercept(pipeline Pipeline, middlewareIndex int, re
 	if !ok {
 		reqOption = &c.options
 	}
+	replacementPairs := reqOption.GetReplacementPairs()
+	if len(replacementPairs) == 0 {
+		// the request option only toggles the replacement, the pairs of the handler apply
+		replacementPairs = c.options.GetReplacementPairs()
+	}
 
 	obsOptions := GetObservabilityOptionsFromRequest(req)
 	ctx := req.Context()
 	var span trace.Span
 	if obsOptions != nil {
-		ctx, span = otel.GetTracerProvider().Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "UrlReplaceHandler_Intercept")
+		ctx, span = getTracerProvider(req.Context()).Tracer(obsOptions.GetTracerInstrumentationName()).Start(ctx, "UrlReplaceHandler_Intercept")
 		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.url_replacer.enable", true))
 		defer span.End()
 		req = req.WithContext(ctx)
 	}
 
-	if !reqOption.IsEnabled() || len(reqOption.GetReplacementPairs()) == 0 {
+	if !reqOption.IsEnabled() || len(replacementPairs) == 0 {
 		return pipeline.Next(req, middlewareIndex)
 	}
 
-	req.URL.Path = ReplacePathTokens(req.URL.Path, reqOption.GetReplacementPairs())
+	req.URL.Path = ReplacePathTokens(req.URL.Path, replacementPairs)
 
-	if span != nil {
-		span.SetAttributes(attribute.String("http.request_url", req.RequestURI))
+	if span != nil && obsOptions.GetIncludeEUIIAttributes() {
+		span.SetAttributes(urlFullAttribute.String(getRedactor(req).RedactUrl(req.URL)))
 	}
 
 	return pipeline.Next(req, middlewareIndex)
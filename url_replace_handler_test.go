@@ -1,6 +1,7 @@
 package nethttplibrary
 
 import (
+	"context"
 	"errors"
 	"github.com/stretchr/testify/assert"
 	nethttp "net/http"
@@ -42,3 +43,25 @@ func TestURLReplacementHandler(t *testing.T) {
 
 	assert.Equal(t, pipeline.GetReceivedRequest().URL.Path, "/me/contactFolders")
 }
+
+func TestURLReplacementHandlerHonorsTheRequestOption(t *testing.T) {
+	handler := NewUrlReplaceHandler(true, map[string]string{"/users/me-token-to-replace": "/me"})
+	url := "https://msgraph.com/users/me-token-to-replace/contactFolders"
+
+	req, _ := nethttp.NewRequest(nethttp.MethodGet, url, nil)
+	req = req.WithContext(context.WithValue(req.Context(), urlReplaceOptionKey, NewUrlReplaceOptions(false, nil)))
+	pipeline := newSpyPipeline()
+	_, _ = handler.Intercept(pipeline, 0, req)
+	assert.Equal(t, "/users/me-token-to-replace/contactFolders", pipeline.GetReceivedRequest().URL.Path)
+
+	req, _ = nethttp.NewRequest(nethttp.MethodGet, url, nil)
+	req = req.WithContext(context.WithValue(req.Context(), urlReplaceOptionKey, NewUrlReplaceOptions(true, map[string]string{"/users/me-token-to-replace": "/users/42"})))
+	_, _ = handler.Intercept(pipeline, 0, req)
+	assert.Equal(t, "/users/42/contactFolders", pipeline.GetReceivedRequest().URL.Path)
+
+	disabledHandler := NewUrlReplaceHandler(false, map[string]string{"/users/me-token-to-replace": "/me"})
+	req, _ = nethttp.NewRequest(nethttp.MethodGet, url, nil)
+	req = req.WithContext(context.WithValue(req.Context(), urlReplaceOptionKey, NewUrlReplaceOptions(true, nil)))
+	_, _ = disabledHandler.Intercept(pipeline, 0, req)
+	assert.Equal(t, "/me/contactFolders", pipeline.GetReceivedRequest().URL.Path)
+}
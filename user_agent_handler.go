@@ -3,10 +3,11 @@ package nethttplibrary
 import (
 	"fmt"
 	nethttp "net/http"
+	"regexp"
+	"runtime"
 	"strings"
 
 	abs "github.com/microsoft/kiota-abstractions-go"
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 )
 
@@ -35,6 +36,35 @@ type UserAgentHandlerOptions struct {
 	Enabled        bool
 	ProductName    string
 	ProductVersion string
+	// IncludeFeatureUsage defines whether the feature usage flags registered for the request should be appended to the user agent, disabled by default
+	IncludeFeatureUsage bool
+	// IncludeRuntimeInformation defines whether a comment with the Go runtime version, the operating system and the architecture should follow the product (e.g. kiota-go/1.4.7 (go1.22.1; linux/amd64))
+	IncludeRuntimeInformation bool
+	// AdditionalProducts are the products layered on top of this library (e.g. service SDK, application), in decreasing order of significance.
+	// Per RFC 9110 they are written before the product of this library.
+	AdditionalProducts []UserAgentProduct
+}
+
+// UserAgentProduct is a product token of the user agent header (e.g. msgraph-sdk-go/1.0.0 (beta))
+type UserAgentProduct struct {
+	// Name of the product
+	Name string
+	// Version of the product, optional
+	Version string
+	// Comment following the product, optional, without the enclosing parentheses
+	Comment string
+}
+
+// String returns the product token as written in the user agent header
+func (product UserAgentProduct) String() string {
+	value := product.Name
+	if product.Version != "" {
+		value = fmt.Sprintf("%s/%s", value, product.Version)
+	}
+	if product.Comment != "" {
+		value = fmt.Sprintf("%s (%s)", value, product.Comment)
+	}
+	return value
 }
 
 // NewUserAgentHandlerOptions creates a new user agent handler options with the default values.
@@ -58,6 +88,9 @@ type userAgentHandlerOptionsInt interface {
 	GetEnabled() bool
 	GetProductName() string
 	GetProductVersion() string
+	GetIncludeFeatureUsage() bool
+	GetIncludeRuntimeInformation() bool
+	GetAdditionalProducts() []UserAgentProduct
 }
 
 // GetKey returns the key value to be used when the option is added to the request context
@@ -80,14 +113,37 @@ func (options *UserAgentHandlerOptions) GetProductVersion() string {
 	return options.ProductVersion
 }
 
+// GetIncludeFeatureUsage returns the value of the include feature usage property
+func (options *UserAgentHandlerOptions) GetIncludeFeatureUsage() bool {
+	return options.IncludeFeatureUsage
+}
+
+// GetIncludeRuntimeInformation returns the value of the include runtime information property
+func (options *UserAgentHandlerOptions) GetIncludeRuntimeInformation() bool {
+	return options.IncludeRuntimeInformation
+}
+
+// GetAdditionalProducts returns the value of the additional products property
+func (options *UserAgentHandlerOptions) GetAdditionalProducts() []UserAgentProduct {
+	return options.AdditionalProducts
+}
+
+// getRuntimeInformationComment returns the user agent comment describing the runtime (e.g. (go1.22.1; linux/amd64))
+func getRuntimeInformationComment() string {
+	return fmt.Sprintf("(%s; %s/%s)", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
+
 const userAgentHeaderKey = "User-Agent"
+const featureUsageProductName = "featureUsage"
+
+var featureUsageTokenRegex = regexp.MustCompile(` ?` + featureUsageProductName + `/[0-9a-f]+`)
 
 func (middleware UserAgentHandler) Intercept(pipeline Pipeline, middlewareIndex int, req *nethttp.Request) (*nethttp.Response, error) {
 	obsOptions := GetObservabilityOptionsFromRequest(req)
 	if obsOptions != nil {
 		observabilityName := obsOptions.GetTracerInstrumentationName()
 		ctx := req.Context()
-		ctx, span := otel.GetTracerProvider().Tracer(observabilityName).Start(ctx, "UserAgentHandler_Intercept")
+		ctx, span := getTracerProvider(req.Context()).Tracer(observabilityName).Start(ctx, "UserAgentHandler_Intercept")
 		span.SetAttributes(attribute.Bool("com.microsoft.kiota.handler.useragent.enable", true))
 		defer span.End()
 		req = req.WithContext(ctx)
@@ -97,13 +153,42 @@ func (middleware UserAgentHandler) Intercept(pipeline Pipeline, middlewareIndex
 		options = &middleware.options
 	}
 	if options.GetEnabled() {
-		additionalValue := fmt.Sprintf("%s/%s", options.GetProductName(), options.GetProductVersion())
+		libraryValue := fmt.Sprintf("%s/%s", options.GetProductName(), options.GetProductVersion())
+		if options.GetIncludeRuntimeInformation() {
+			libraryValue = fmt.Sprintf("%s %s", libraryValue, getRuntimeInformationComment())
+		}
 		currentValue := req.Header.Get(userAgentHeaderKey)
-		if currentValue == "" {
-			req.Header.Set(userAgentHeaderKey, additionalValue)
-		} else if !strings.Contains(currentValue, additionalValue) {
-			req.Header.Set(userAgentHeaderKey, fmt.Sprintf("%s %s", currentValue, additionalValue))
+		for _, product := range options.GetAdditionalProducts() {
+			if product.Name != "" {
+				currentValue = appendUserAgentProduct(currentValue, product.String())
+			}
+		}
+		req.Header.Set(userAgentHeaderKey, appendUserAgentProduct(currentValue, libraryValue))
+		if options.GetIncludeFeatureUsage() {
+			// feature flags can be registered by the middlewares down the pipeline, the FeatureUsageHandler updates the token before the request is sent
+			req = enableFeatureUsageUserAgentToken(req)
+			setFeatureUsageUserAgentToken(req)
 		}
 	}
 	return pipeline.Next(req, middlewareIndex)
 }
+
+// appendUserAgentProduct appends the product to the user agent value unless it is already present
+func appendUserAgentProduct(currentValue string, product string) string {
+	if currentValue == "" {
+		return product
+	} else if strings.Contains(currentValue, product) {
+		return currentValue
+	}
+	return fmt.Sprintf("%s %s", currentValue, product)
+}
+
+// setFeatureUsageUserAgentToken replaces the feature usage token of the user agent header with the flags currently registered
+func setFeatureUsageUserAgentToken(req *nethttp.Request) {
+	flags := GetFeatureUsage(req.Context())
+	currentValue := featureUsageTokenRegex.ReplaceAllString(req.Header.Get(userAgentHeaderKey), "")
+	if flags != NoneFeatureUsageFlag {
+		currentValue = strings.TrimSpace(fmt.Sprintf("%s %s/%s", currentValue, featureUsageProductName, flags.String()))
+	}
+	req.Header.Set(userAgentHeaderKey, currentValue)
+}
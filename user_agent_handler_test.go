@@ -3,6 +3,7 @@ package nethttplibrary
 import (
 	nethttp "net/http"
 	httptest "net/http/httptest"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -72,3 +73,86 @@ func TestItDoesNotAddTheUserAgentHeaderWhenDisabled(t *testing.T) {
 	assert.NotNil(t, resp)
 	assert.Equal(t, false, strings.Contains(req.Header.Get("User-Agent"), "kiota-go"))
 }
+
+func TestItAddsTheFeatureUsageToTheUserAgentHeader(t *testing.T) {
+	var userAgent string
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		userAgent = req.Header.Get("User-Agent")
+		res.WriteHeader(200)
+		res.Write([]byte("body"))
+	}))
+	defer func() { testServer.Close() }()
+	client := GetDefaultClient(NewUserAgentHandler(), NewRedirectHandler(), NewRetryHandler(), NewFeatureUsageHandler())
+	_, err := client.Get(testServer.URL)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.NotContains(t, userAgent, "featureUsage")
+
+	options := NewUserAgentHandlerOptions()
+	options.IncludeFeatureUsage = true
+	client = GetDefaultClient(NewUserAgentHandlerWithOptions(options), NewRedirectHandler(), NewRetryHandler(), NewFeatureUsageHandler())
+	_, err = client.Get(testServer.URL)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.True(t, strings.HasSuffix(userAgent, " featureUsage/3"), userAgent)
+}
+
+func TestItAddsTheRuntimeInformationToTheUserAgentHeader(t *testing.T) {
+	handler := NewUserAgentHandlerWithOptions(&UserAgentHandlerOptions{
+		Enabled:                   true,
+		ProductName:               "kiota-go",
+		ProductVersion:            "1.1.0",
+		IncludeRuntimeInformation: true,
+	})
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	req, err := nethttp.NewRequest(nethttp.MethodGet, testServer.URL, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = handler.Intercept(newNoopPipeline(), 0, req)
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = handler.Intercept(newNoopPipeline(), 0, req)
+	if err != nil {
+		t.Error(err)
+	}
+	expected := "kiota-go/1.1.0 (" + runtime.Version() + "; " + runtime.GOOS + "/" + runtime.GOARCH + ")"
+	assert.Equal(t, expected, req.Header.Get("User-Agent"))
+}
+
+func TestItAddsTheAdditionalProductsToTheUserAgentHeader(t *testing.T) {
+	handler := NewUserAgentHandlerWithOptions(&UserAgentHandlerOptions{
+		Enabled:        true,
+		ProductName:    "kiota-go",
+		ProductVersion: "1.1.0",
+		AdditionalProducts: []UserAgentProduct{
+			{Name: "contoso-app", Version: "2.0.0"},
+			{Name: "msgraph-sdk-go", Version: "1.0.0", Comment: "beta"},
+			{Version: "ignored"},
+		},
+	})
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		res.WriteHeader(200)
+	}))
+	defer testServer.Close()
+	req, err := nethttp.NewRequest(nethttp.MethodGet, testServer.URL, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("User-Agent", "existing/1.0")
+	_, err = handler.Intercept(newNoopPipeline(), 0, req)
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = handler.Intercept(newNoopPipeline(), 0, req)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, "existing/1.0 contoso-app/2.0.0 msgraph-sdk-go/1.0.0 (beta) kiota-go/1.1.0", req.Header.Get("User-Agent"))
+}
@@ -0,0 +1,138 @@
+package nethttplibrary
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"io"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+)
+
+// webSocketAcceptGuid is appended to the key of the handshake to compute the accept value of the server (RFC 6455)
+const webSocketAcceptGuid = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const webSocketVersion = "13"
+
+// WebSocketUpgradeError is returned when the server doesn't switch the connection to the WebSocket protocol
+type WebSocketUpgradeError struct {
+	// StatusCode is the status code of the handshake response
+	StatusCode int
+	// Reason describes why the handshake failed
+	Reason string
+}
+
+func (e *WebSocketUpgradeError) Error() string {
+	return "the WebSocket upgrade failed with the status code " + strconv.Itoa(e.StatusCode) + ": " + e.Reason
+}
+
+type upgradeRequestKey struct{}
+
+// withUpgradeRequest returns a context marking the request as a protocol upgrade, whose response body is the upgraded connection
+func withUpgradeRequest(ctx context.Context) context.Context {
+	return context.WithValue(ctx, upgradeRequestKey{}, true)
+}
+
+// getHttpClient returns the client sending the request, without the timeout of the client for protocol upgrades:
+// the timeout would apply to the lifetime of the upgraded connection and prevent handing it back
+func (a *NetHttpRequestAdapter) getHttpClient(ctx context.Context) *nethttp.Client {
+	if upgrade, _ := ctx.Value(upgradeRequestKey{}).(bool); !upgrade || a.httpClient.Timeout == 0 {
+		return a.httpClient
+	}
+	client := *a.httpClient
+	client.Timeout = 0
+	return &client
+}
+
+// UpgradeToWebSocket performs the WebSocket opening handshake (RFC 6455) of the GET request through the middleware pipeline,
+// authenticated with the authentication provider of the request adapter, and returns the upgraded connection with the handshake response.
+// The connection carries raw WebSocket frames, the framing is left to the caller or to a WebSocket library, and must be closed by the caller.
+// The subprotocols are offered to the server in order of preference, the one it selected is in the Sec-WebSocket-Protocol header of the response.
+// The context and the timeout of the client only bound the handshake.
+func (a *NetHttpRequestAdapter) UpgradeToWebSocket(ctx context.Context, requestInfo *abs.RequestInformation, errorMappings abs.ErrorMappings, subprotocols ...string) (io.ReadWriteCloser, *nethttp.Response, error) {
+	if requestInfo == nil {
+		return nil, nil, errors.New("requestInfo cannot be nil")
+	}
+	if requestInfo.Method != abs.GET {
+		return nil, nil, errors.New("the WebSocket handshake must be a GET request")
+	}
+	if err := a.startSend(); err != nil {
+		return nil, nil, err
+	}
+	defer a.endSend()
+	key, err := newWebSocketKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	requestInfo.Headers.TryAdd("Connection", "Upgrade")
+	requestInfo.Headers.TryAdd("Upgrade", "websocket")
+	requestInfo.Headers.TryAdd("Sec-WebSocket-Version", webSocketVersion)
+	requestInfo.Headers.TryAdd("Sec-WebSocket-Key", key)
+	if len(subprotocols) > 0 {
+		requestInfo.Headers.TryAdd("Sec-WebSocket-Protocol", strings.Join(subprotocols, ", "))
+	}
+	ctx = a.prepareContext(ctx, requestInfo)
+	ctx, span := a.startTracingSpan(ctx, requestInfo, "UpgradeToWebSocket")
+	defer span.End()
+	response, err := a.getHttpResponseMessage(withUpgradeRequest(ctx), requestInfo, "", span)
+	if err != nil {
+		return nil, nil, err
+	}
+	if response == nil {
+		return nil, nil, errors.New("response is nil")
+	}
+	if response.StatusCode != nethttp.StatusSwitchingProtocols {
+		defer a.purge(response)
+		if err := a.throwIfFailedResponse(ctx, response, errorMappings, span); err != nil {
+			return nil, nil, err
+		}
+		err := &WebSocketUpgradeError{StatusCode: response.StatusCode, Reason: "the server didn't switch protocols"}
+		span.RecordError(err)
+		return nil, nil, err
+	}
+	conn, ok := response.Body.(io.ReadWriteCloser)
+	if !ok {
+		response.Body.Close()
+		err := &WebSocketUpgradeError{StatusCode: response.StatusCode, Reason: "the upgraded connection was wrapped by the transport or a middleware"}
+		span.RecordError(err)
+		return nil, nil, err
+	}
+	if reason := validateWebSocketHandshake(response, key); reason != "" {
+		conn.Close()
+		err := &WebSocketUpgradeError{StatusCode: response.StatusCode, Reason: reason}
+		span.RecordError(err)
+		return nil, nil, err
+	}
+	return conn, response, nil
+}
+
+// newWebSocketKey returns a random key for the handshake
+func newWebSocketKey() (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(nonce), nil
+}
+
+// getWebSocketAccept returns the value of the Sec-WebSocket-Accept header the server must answer the key with
+func getWebSocketAccept(key string) string {
+	hash := sha1.Sum([]byte(key + webSocketAcceptGuid))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// validateWebSocketHandshake returns why the handshake response is invalid, empty when it's valid
+func validateWebSocketHandshake(response *nethttp.Response, key string) string {
+	if !strings.EqualFold(response.Header.Get("Upgrade"), "websocket") {
+		return "the server upgraded the connection to another protocol"
+	}
+	if response.Header.Get("Sec-WebSocket-Accept") != getWebSocketAccept(key) {
+		return "the Sec-WebSocket-Accept header doesn't match the key of the handshake"
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package nethttplibrary
+
+import (
+	"context"
+	"errors"
+	"io"
+	nethttp "net/http"
+	httptest "net/http/httptest"
+	"net/url"
+	"testing"
+
+	abs "github.com/microsoft/kiota-abstractions-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWebSocketTestServer(t *testing.T, accept func(key string) string) *httptest.Server {
+	testServer := httptest.NewServer(nethttp.HandlerFunc(func(res nethttp.ResponseWriter, req *nethttp.Request) {
+		if req.Header.Get("X-Api-Key") != "secret" {
+			res.WriteHeader(401)
+			return
+		}
+		conn, buffer, err := res.(nethttp.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buffer.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n")
+		buffer.WriteString("Sec-WebSocket-Accept: " + accept(req.Header.Get("Sec-WebSocket-Key")) + "\r\n")
+		buffer.WriteString("Sec-WebSocket-Protocol: " + req.Header.Get("Sec-WebSocket-Protocol") + "\r\n\r\n")
+		buffer.Flush()
+		payload := make([]byte, 4)
+		if _, err := io.ReadFull(buffer, payload); err == nil {
+			conn.Write(payload)
+		}
+	}))
+	t.Cleanup(testServer.Close)
+	return testServer
+}
+
+type apiKeyAuthenticationProvider struct {
+	apiKey string
+}
+
+func (p *apiKeyAuthenticationProvider) AuthenticateRequest(ctx context.Context, request *abs.RequestInformation, additionalAuthenticationContext map[string]interface{}) error {
+	request.Headers.Add("X-Api-Key", p.apiKey)
+	return nil
+}
+
+func newWebSocketTestAdapter(t *testing.T, testServer *httptest.Server, apiKey string) (*NetHttpRequestAdapter, *abs.RequestInformation) {
+	adapter, err := NewNetHttpRequestAdapter(&apiKeyAuthenticationProvider{apiKey: apiKey})
+	assert.Nil(t, err)
+	uri, _ := url.Parse(testServer.URL)
+	requestInfo := abs.NewRequestInformation()
+	requestInfo.SetUri(*uri)
+	requestInfo.Method = abs.GET
+	return adapter, requestInfo
+}
+
+func TestItUpgradesTheConnectionToWebSocket(t *testing.T) {
+	testServer := newWebSocketTestServer(t, getWebSocketAccept)
+	adapter, requestInfo := newWebSocketTestAdapter(t, testServer, "secret")
+
+	conn, response, err := adapter.UpgradeToWebSocket(context.Background(), requestInfo, nil, "chat")
+	assert.Nil(t, err)
+	if assert.NotNil(t, conn) {
+		defer conn.Close()
+		assert.Equal(t, "chat", response.Header.Get("Sec-WebSocket-Protocol"))
+		_, err = conn.Write([]byte("ping"))
+		assert.Nil(t, err)
+		payload := make([]byte, 4)
+		_, err = io.ReadFull(conn, payload)
+		assert.Nil(t, err)
+		assert.Equal(t, "ping", string(payload))
+	}
+}
+
+func TestItFailsTheWebSocketUpgrade(t *testing.T) {
+	testServer := newWebSocketTestServer(t, func(key string) string { return "invalid" })
+	adapter, requestInfo := newWebSocketTestAdapter(t, testServer, "secret")
+	_, _, err := adapter.UpgradeToWebSocket(context.Background(), requestInfo, nil)
+	var upgradeErr *WebSocketUpgradeError
+	if assert.True(t, errors.As(err, &upgradeErr)) {
+		assert.Equal(t, 101, upgradeErr.StatusCode)
+	}
+
+	adapter, requestInfo = newWebSocketTestAdapter(t, testServer, "wrong")
+	_, _, err = adapter.UpgradeToWebSocket(context.Background(), requestInfo, nil)
+	var apiErr *abs.ApiError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, 401, apiErr.ResponseStatusCode)
+	}
+
+	requestInfo.Method = abs.POST
+	_, _, err = adapter.UpgradeToWebSocket(context.Background(), requestInfo, nil)
+	assert.Error(t, err)
+}